@@ -0,0 +1,50 @@
+package main
+
+import (
+	"DMS/internal/config"
+	"DMS/internal/jwtkeys"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Run the subcommand of the binary with its arguments and exit.
+func runCommand(name string, args []string) {
+	switch name {
+	case "gen-jwt-key":
+		genJWTKey(args)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %s\nCommands:\n  gen-jwt-key\tGenerate a new key pair for signing JWTs\n", name)
+		os.Exit(2)
+	}
+}
+
+// Generate a new key pair in the keys directory. After restarting the server, the new key
+// signs new JWTs and the previous keys just verify JWTs signed before. The directory is
+// jwt.keys_dir of the config, unless it's given by -dir flag.
+func genJWTKey(args []string) {
+	flags := flag.NewFlagSet("gen-jwt-key", flag.ExitOnError)
+	dir := flags.String("dir", "", "Directory of JWT keys (default is jwt.keys_dir of the config)")
+	configPath := flags.String("config", "", "Path of the YAML config file (default is $"+config.ConfigFileEnv+")")
+	flags.Parse(args)
+	if *dir == "" {
+		// Other values are not used, so the config is not validated.
+		cfg, err := config.Read(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		*dir = cfg.JWT.KeysDir
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "Directory of JWT keys is not specified. Set -dir or jwt.keys_dir of the config.")
+		os.Exit(2)
+	}
+
+	kid, err := jwtkeys.GenerateKeyPair(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate JWT key: %s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Generated JWT key %s in %s\n", kid, *dir)
+}
@@ -1,25 +1,22 @@
 package main
 
 import (
+	"DMS/internal/app"
+	"DMS/internal/config"
 	"DMS/internal/controllers"
-	"DMS/internal/dal"
-	"DMS/internal/db"
-	"DMS/internal/graph"
 	grpcserver "DMS/internal/grpc"
-	"DMS/internal/hierarchy"
+	pbUpload "DMS/internal/grpc/pb/upload"
 	"DMS/internal/logger"
 	"DMS/internal/routes"
-	"DMS/internal/services"
+	"flag"
 	"fmt"
 	"net"
 	"os"
-	"strconv"
-	"time"
+	"strings"
 
 	_ "DMS/docs/api"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	pbAuth "github.com/q-sharafian/file-transfer/pkg/pb/auth"
 	"google.golang.org/grpc"
 )
@@ -41,70 +38,37 @@ import (
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
 func main() {
-	var appMode = os.Getenv("APP_MODE")
-	if appMode != "production" {
-		if err := godotenv.Load(".env"); err != nil {
-			panic(fmt.Sprintf("Error loading .env file: %s", err.Error()))
-		}
-	}
-	lgr := logger.NewSLogger(logger.Debug, nil, os.Stderr)
-	if appMode != "production" {
-		if jwtPrivate, err := os.ReadFile(os.Getenv("JWT_PRIVATE_KEY_FILE_PATH")); err != nil {
-			lgr.Panic(err)
-		} else if err = os.Setenv("JWT_PRIVATE_KEY", string(jwtPrivate)); err != nil {
-			lgr.Panic(err)
-		}
-		if jwtPublic, err := os.ReadFile(os.Getenv("JWT_PUBLIC_KEY_FILE_PATH")); err != nil {
-			lgr.Panic(err)
-		} else if err = os.Setenv("JWT_PUBLIC_KEY", string(jwtPublic)); err != nil {
-			lgr.Panic(err)
-		}
-	}
-
-	// Init Redis
-	dbIndex, err := strconv.Atoi(os.Getenv("REDIS_DB"))
-	if err != nil {
-		lgr.Panic(err)
-	}
-	expireTime, err := strconv.Atoi(os.Getenv("REDIS_EXPIRE"))
-	if err != nil {
-		lgr.Panic(err)
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		runCommand(os.Args[1], os.Args[2:])
+		return
 	}
-	redisConnDetails := &db.RedisConnDetails{
-		Addr:     os.Getenv("REDIS_ADDR"),
-		Password: os.Getenv("REDIS_PASSWORD"),
-		DB:       dbIndex,
-		Expire:   time.Second * time.Duration(expireTime),
-	}
-	redisDAL := dal.NewRedisInMemoeyDAL(redisConnDetails, lgr)
+	// Replicas could start with this flag together; the migrations are applied just once.
+	doMigrate := flag.Bool("migrate", false, "Apply the migrations that are not applied yet before starting the server")
+	configPath := flag.String("config", "", "Path of the YAML config file (default is $"+config.ConfigFileEnv+")")
+	printConfig := flag.Bool("print-config", false, "Print the loaded config with redacted secrets and exit")
+	flag.Parse()
 
-	// Init PostgreSQL
-	dbPort, err := strconv.Atoi(os.Getenv("PSQL_PORT"))
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		lgr.Panic(err)
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
 	}
-	psqlConnDetails := db.PsqlConnDetails{
-		Host:            os.Getenv("PSQL_HOST"),
-		Port:            dbPort,
-		Username:        os.Getenv("PSQL_USER"),
-		Password:        os.Getenv("PSQL_PASSWORD"),
-		DB:              os.Getenv("PSQL_DB"),
-		MaxConnLifetime: time.Hour,
-		MaxIdleConns:    5,
-		MAxOpenConns:    5,
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
 	}
-	psqlDAL := dal.NewPostgresDAL(psqlConnDetails, redisDAL, lgr, true)
-
-	// Init hierarchy tree
-	graphStorage := graph.NewInMemoryDBStorage(redisDAL, []byte("edge"), lgr)
-	dynamicGraph := graph.NewDynamicGraph(graphStorage, lgr)
-	hierarchyTree := hierarchy.NewHierarchyTree(dynamicGraph, lgr)
 
-	services := services.NewService(&psqlDAL, hierarchyTree, redisDAL, lgr)
-	httpController := controllers.NewHttpController(services, lgr)
+	logLevel, _ := logger.ParseLogLevel(cfg.App.LogLevel)
+	lgr := logger.NewSLogger(logLevel, nil, os.Stderr)
+	gin.SetMode(cfg.HTTP.GinMode)
+	services := app.New(cfg, lgr, *doMigrate).Services
+	httpController := controllers.NewHttpController(services, cfg, lgr)
 
 	// Init gRPC server
-	grpcAddr := fmt.Sprintf(":%s", os.Getenv("GRPC_PORT"))
+	grpcAddr := fmt.Sprintf(":%d", cfg.GRPC.Port)
 	grpcListener, grpcErr := net.Listen("tcp", grpcAddr)
 	if grpcErr != nil {
 		lgr.Panicf("Failed to create gRPC server: failed to listen on %s", grpcAddr)
@@ -113,6 +77,8 @@ func main() {
 	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcAuthService.LoggerInterceptor,
 		grpcAuthService.ErrorInterceptor))
 	pbAuth.RegisterAuthServer(grpcServer, &grpcAuthService)
+	grpcUploadService := grpcserver.NewGRPCUploadServer(services.FilePermission(), cfg.Upload.CallbackSecret, lgr)
+	pbUpload.RegisterUploadServer(grpcServer, &grpcUploadService)
 	go func() {
 		lgr.Infof("Starting gRPC server on address %s", grpcAddr)
 		if serveErr := grpcServer.Serve(grpcListener); serveErr != nil {
@@ -122,7 +88,7 @@ func main() {
 
 	router := gin.Default()
 	routes.SetupRouter(router, httpController)
-	lgr.Infof("Starting server on port %s", os.Getenv("GIN_PORT"))
-	router.Run(fmt.Sprintf(":%s", os.Getenv("GIN_PORT")))
+	lgr.Infof("Starting server on port %d", cfg.HTTP.Port)
+	router.Run(fmt.Sprintf(":%d", cfg.HTTP.Port))
 
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	m "DMS/internal/models"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Create the first admin and its admin job position. It's allowed just while there's not
+// any admin job position, so after that admins are created by the existing admins. Both
+// are created together, so running it concurrently creates just one admin.
+func bootstrapAdmin(args []string) {
+	flags := flag.NewFlagSet("bootstrap-admin", flag.ExitOnError)
+	name := flags.String("name", "", "Name of the admin")
+	phone := flags.String("phone", "", "Phone number of the admin")
+	title := flags.String("title", "", "Title of the admin job position")
+	region := flags.String("region", "", "Region id of the admin job position (optional)")
+	flags.Parse(args)
+	checkAdminFlags(flags, *name, *phone, *title)
+	regionID := parseIDFlag(flags, "region", *region, false)
+
+	a := newApp()
+	jp := m.AdminJobPosition{CommonJobPosition: m.CommonJobPosition{
+		Title:    *title,
+		RegionID: regionID,
+	}}
+	userID, jpID, err := a.Services.JP.BootstrapAdmin(*name, m.PhoneNumber(*phone), &jp, cliClient)
+	if err != nil {
+		fail("Failed to create the admin: %s", err.Error())
+	}
+	fmt.Printf("Created admin %s with admin job position %s\n", userID.String(), jpID.String())
+}
+
+// Create an admin and its admin job position with all permissions. It's done with an
+// existing admin job position.
+func createAdmin(args []string) {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	as := flags.String("as", "", "Id of the admin job position the admin is created with")
+	name := flags.String("name", "", "Name of the admin")
+	phone := flags.String("phone", "", "Phone number of the admin")
+	title := flags.String("title", "", "Title of the admin job position")
+	region := flags.String("region", "", "Region id of the admin job position (optional)")
+	flags.Parse(args)
+	callerJPID := parseIDFlag(flags, "as", *as, true)
+	checkAdminFlags(flags, *name, *phone, *title)
+	regionID := parseIDFlag(flags, "region", *region, false)
+
+	a := newApp()
+	callerID := getActor(a, callerJPID)
+	userID, err := a.Services.User.CreateAdmin(callerID, callerJPID, *name, m.PhoneNumber(*phone), cliClient)
+	if err != nil {
+		fail("Failed to create the admin: %s", err.Error())
+	}
+	jp := m.AdminJobPosition{CommonJobPosition: m.CommonJobPosition{
+		UserID:   *userID,
+		Title:    *title,
+		RegionID: regionID,
+	}}
+	permission := m.Permission{IsAllowCreateJP: true, IsAllowApproveEvent: true}
+	jpID, err := a.Services.JP.CreateAdminJP(callerID, callerJPID, &jp, &permission, cliClient)
+	if err != nil {
+		fail("Created admin %s but failed to create its admin job position: %s", userID.String(), err.Error())
+	}
+	fmt.Printf("Created admin %s with admin job position %s\n", userID.String(), jpID.String())
+}
+
+func checkAdminFlags(flags *flag.FlagSet, name, phone, title string) {
+	if name == "" || phone == "" || title == "" {
+		fmt.Fprintln(os.Stderr, "Flags -name, -phone and -title are required.")
+		flags.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Export data of the database as JSON lines to a file or the standard output.
+func exportData(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "Path of the output file (default is the standard output)")
+	flags.Parse(args)
+
+	a := newApp()
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fail("Failed to create file %s: %s", *output, err.Error())
+		}
+		defer file.Close()
+		w = file
+	}
+	count, err := a.DAL.Dump.Export(w)
+	if err != nil {
+		fail("Failed to export data: %s", err.Error())
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d rows\n", count)
+}
+
+// Import data exported by the export command from a file or the standard input. Rows
+// that exist in the database are skipped.
+func importData(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	input := flags.String("i", "", "Path of the input file (default is the standard input)")
+	flags.Parse(args)
+
+	a := newApp()
+	var r io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			fail("Failed to open file %s: %s", *input, err.Error())
+		}
+		defer file.Close()
+		r = file
+	}
+	count, err := a.DAL.Dump.Import(r)
+	if err != nil {
+		fail("Failed to import data: %s", err.Error())
+	}
+	fmt.Printf("Imported %d rows\n", count)
+	fmt.Println("Restart the API servers and run rebuild-graph-cache to reload the hierarchy of job positions.")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"DMS/internal/app"
+	m "DMS/internal/models"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// List all job positions or job positions of a user.
+func listJPs(args []string) {
+	flags := flag.NewFlagSet("list-jps", flag.ExitOnError)
+	user := flags.String("user", "", "Id of the user to list its job positions (optional)")
+	flags.Parse(args)
+	userID := parseIDFlag(flags, "user", *user, false)
+
+	nodes := getAllJPNodes(newApp())
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tUSER\tPARENTS\tDISABLED")
+	for _, node := range nodes {
+		if !userID.IsNil() && node.UserID != userID {
+			continue
+		}
+		parents := make([]string, len(node.ParentIDs))
+		for i, parentID := range node.ParentIDs {
+			parents[i] = parentID.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s (%s)\t%s\t%t\n", node.ID.String(), node.Title, node.UserName,
+			node.UserID.String(), strings.Join(parents, ","), node.IsDisabled)
+	}
+	w.Flush()
+}
+
+// Change parent of a job position. It's done with an existing job position that is
+// allowed to manage the job position.
+func moveJP(args []string) {
+	flags := flag.NewFlagSet("move-jp", flag.ExitOnError)
+	as := flags.String("as", "", "Id of the job position the job position is moved with")
+	jp := flags.String("jp", "", "Id of the job position to move")
+	parent := flags.String("parent", "", "Id of the new parent")
+	flags.Parse(args)
+	callerJPID := parseIDFlag(flags, "as", *as, true)
+	jpID := parseIDFlag(flags, "jp", *jp, true)
+	parentID := parseIDFlag(flags, "parent", *parent, true)
+
+	a := newApp()
+	if err := a.Services.JP.MoveJP(getActor(a, callerJPID), callerJPID, jpID, parentID, cliClient); err != nil {
+		fail("Failed to move job position %s: %s", jpID.String(), err.Error())
+	}
+	fmt.Printf("Moved job position %s under %s\n", jpID.String(), parentID.String())
+	fmt.Println("Restart the API servers to reload the hierarchy of job positions.")
+}
+
+// Print the hierarchy of job positions as a tree. A job position with several parents
+// is printed under each of them.
+func printTree(args []string) {
+	flags := flag.NewFlagSet("tree", flag.ExitOnError)
+	root := flags.String("root", "", "Id of the job position to print its subtree (optional)")
+	flags.Parse(args)
+	rootID := parseIDFlag(flags, "root", *root, false)
+
+	nodes := getAllJPNodes(newApp())
+	byID := make(map[m.ID]*m.JPNode, len(nodes))
+	childs := make(map[m.ID][]*m.JPNode)
+	roots := []*m.JPNode{}
+	for i := range nodes {
+		node := &nodes[i]
+		byID[node.ID] = node
+		for _, parentID := range node.ParentIDs {
+			childs[parentID] = append(childs[parentID], node)
+		}
+		if len(node.ParentIDs) == 0 {
+			roots = append(roots, node)
+		}
+	}
+	if !rootID.IsNil() {
+		node, isFound := byID[rootID]
+		if !isFound {
+			fail("Job position %s not found", rootID.String())
+		}
+		roots = []*m.JPNode{node}
+	}
+	sortJPNodes(roots)
+	for _, node := range roots {
+		fmt.Println(formatJPNode(node))
+		printSubtree(node, childs, "", map[m.ID]bool{node.ID: true})
+	}
+}
+
+// Print childs of the node with the given indentation prefix. path contains the job
+// positions from the root to the node, so a cycle is not printed infinitely.
+func printSubtree(node *m.JPNode, childs map[m.ID][]*m.JPNode, prefix string, path map[m.ID]bool) {
+	nodeChilds := childs[node.ID]
+	sortJPNodes(nodeChilds)
+	for i, child := range nodeChilds {
+		branch, indent := "├── ", "│   "
+		if i == len(nodeChilds)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if path[child.ID] {
+			fmt.Printf("%s%s%s (cycle)\n", prefix, branch, formatJPNode(child))
+			continue
+		}
+		fmt.Printf("%s%s%s\n", prefix, branch, formatJPNode(child))
+		path[child.ID] = true
+		printSubtree(child, childs, prefix+indent, path)
+		delete(path, child.ID)
+	}
+}
+
+func formatJPNode(node *m.JPNode) string {
+	result := fmt.Sprintf("%s - %s [%s]", node.Title, node.UserName, node.ID.String())
+	if node.IsDisabled {
+		result += " (disabled)"
+	}
+	return result
+}
+
+func sortJPNodes(nodes []*m.JPNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Title < nodes[j].Title })
+}
+
+// Return details of all job positions.
+func getAllJPNodes(a *app.App) []m.JPNode {
+	nodes, err := a.DAL.JP.GetJPNodes(getAllJPIDs(a))
+	if err != nil {
+		fail("Failed to get job positions: %s", err.Error())
+	}
+	return *nodes
+}
+
+// Return ids of all job positions.
+func getAllJPIDs(a *app.App) []m.ID {
+	jpIDs := []m.ID{}
+	isAdded := make(map[m.ID]bool)
+	jpIter := a.DAL.JP.GetJPEdgeIter(500)
+	for {
+		edge, exists := jpIter.Next()
+		if !exists {
+			break
+		}
+		if !isAdded[edge.JP] {
+			isAdded[edge.JP] = true
+			jpIDs = append(jpIDs, edge.JP)
+		}
+	}
+	return jpIDs
+}
@@ -0,0 +1,141 @@
+// dmsctl is the command line tool for administrating the DMS. It uses the same database
+// and services of the API server, so it must run with the same config file and
+// environment variables.
+package main
+
+import (
+	"DMS/internal/app"
+	"DMS/internal/config"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: dmsctl <command> [flags]
+
+Commands:
+  bootstrap-admin      Create the first admin and its admin job position
+  create-admin         Create an admin and its admin job position
+  list-jps             List job positions
+  move-jp              Change parent of a job position
+  tree                 Print the hierarchy of job positions as a tree
+  revoke-sessions      Revoke all sessions of a user
+  rebuild-graph-cache  Rebuild the cached paths of the hierarchy in Redis from jp_edges
+  migrate              Apply, roll back or show status of the migrations
+  export               Export data of the database as JSON lines
+  import               Import data exported by the export command
+
+Run "dmsctl <command> -h" to see flags of the command.
+`
+
+// Client info of the audit events recorded by dmsctl.
+var cliClient = m.ClientInfo{UserAgent: "dmsctl"}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	runCommand(os.Args[1], os.Args[2:])
+}
+
+// Run the subcommand with its arguments and exit.
+func runCommand(name string, args []string) {
+	switch name {
+	case "bootstrap-admin":
+		bootstrapAdmin(args)
+	case "create-admin":
+		createAdmin(args)
+	case "list-jps":
+		listJPs(args)
+	case "move-jp":
+		moveJP(args)
+	case "tree":
+		printTree(args)
+	case "revoke-sessions":
+		revokeSessions(args)
+	case "rebuild-graph-cache":
+		rebuildGraphCache(args)
+	case "migrate":
+		migrate(args)
+	case "export":
+		exportData(args)
+	case "import":
+		importData(args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %s\n%s", name, usage)
+		os.Exit(2)
+	}
+}
+
+// Load the config and initialize the databases and the services. The migrations are not
+// applied, so run the migrate command (or the API server with -migrate flag) before.
+func newApp() *app.App {
+	return app.New(loadConfig(), newLogger(), false)
+}
+
+// Load the config from the file in CONFIG_FILE environment variable (if it's set) and the
+// environment variables. If the config is invalid, print the errors and exit.
+func loadConfig() *config.Config {
+	cfg, err := config.Load("")
+	if err != nil {
+		fail("%s", err.Error())
+	}
+	return cfg
+}
+
+// Load the config like loadConfig, but just the PostgreSQL config is validated. It's
+// used to manage the migrations, so the other services don't need to be configured.
+func loadPsqlConfig() *config.Config {
+	cfg, err := config.Read("")
+	if err == nil {
+		err = cfg.ValidatePsql()
+	}
+	if err != nil {
+		fail("%s", err.Error())
+	}
+	return cfg
+}
+
+// Only warnings and errors are logged, so they don't mix with the output of the commands.
+func newLogger() l.Logger {
+	return l.NewSLogger(l.Warn, nil, os.Stderr)
+}
+
+// Print the error message and exit with status 1.
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// Parse value of the flag name as an id. If the value is invalid or it's empty and
+// the flag is required, print the usage and exit with status 2.
+func parseIDFlag(flags *flag.FlagSet, name, value string, isRequired bool) m.ID {
+	id, err := m.ID{}.FromString2(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid id %s for flag -%s: %s\n", value, name, err.Error())
+		os.Exit(2)
+	} else if isRequired && id.IsNil() {
+		fmt.Fprintf(os.Stderr, "Flag -%s is required.\n", name)
+		flags.Usage()
+		os.Exit(2)
+	}
+	return id
+}
+
+// Return the user of the job position jpID. Commands that change something are done
+// with a job position given by the -as flag, so the services check its permissions and
+// record it in the audit log like the API.
+func getActor(a *app.App, jpID m.ID) m.ID {
+	jp, err := a.DAL.JP.GetJPByID(jpID)
+	if err != nil {
+		fail("Failed to get job position %s: %s", jpID.String(), err.Error())
+	} else if jp == nil {
+		fail("Job position %s not found", jpID.String())
+	}
+	return jp.UserID
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"DMS/internal/app"
+	"DMS/internal/db"
+	"DMS/internal/graph"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// Rebuild the cached paths of the hierarchy graph in Redis. The graph of this process is
+// loaded from jp_edges on start, so the cache is cleared and then the path from each
+// ancestor to each job position is cached again from it. It fixes a stale cache, e.g.
+// after changing job positions by hand. (API servers load the graph on start, so they
+// must be restarted too)
+func rebuildGraphCache(args []string) {
+	flags := flag.NewFlagSet("rebuild-graph-cache", flag.ExitOnError)
+	flags.Parse(args)
+
+	a := newApp()
+	g := a.Hierarchy.Graph()
+	if err := g.ClearCache(); err != nil {
+		fail("Failed to clear the graph cache: %s", err.Error())
+	}
+	pathCount := 0
+	for _, jpID := range getAllJPIDs(a) {
+		vertex := graph.Vertex(jpID.String())
+		ancestors, err := a.Hierarchy.GetAncestors(vertex)
+		if err != nil {
+			fail("Failed to get ancestors of job position %s: %s", jpID.String(), err.Error())
+		}
+		for _, ancestor := range ancestors {
+			if _, err := g.HasPath(ancestor, vertex); err != nil {
+				fail("Failed to cache path from %s to %s: %s", ancestor.String(), jpID.String(), err.Error())
+			}
+			pathCount++
+		}
+	}
+	edgeCount, _ := g.Size()
+	fmt.Printf("Rebuilt the graph cache with %d paths of %d edges.\n", pathCount, edgeCount)
+}
+
+// Apply the migrations that are not applied yet, roll back the last applied ones or
+// print status of the migrations.
+func migrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	down := flags.Int("down", 0, "Number of the last applied migrations to roll back")
+	status := flags.Bool("status", false, "Print status of the migrations")
+	flags.Parse(args)
+	if *down < 0 {
+		fmt.Fprintln(os.Stderr, "Value of -down must not be negative.")
+		os.Exit(2)
+	}
+
+	lgr := newLogger()
+	psqlDB := app.NewPsqlDB(loadPsqlConfig(), lgr)
+	switch {
+	case *status:
+		statuses, err := db.GetMigrationStatus(psqlDB)
+		if err != nil {
+			fail("Failed to get status of the migrations: %s", err.Error())
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, migration := range statuses {
+			appliedAt := "pending"
+			if migration.AppliedAt != nil {
+				appliedAt = migration.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, migration.Name, appliedAt)
+		}
+		w.Flush()
+	case *down > 0:
+		count, err := db.MigrateDown(psqlDB, *down, lgr)
+		if err != nil {
+			fail("Rolled back %d migrations but failed: %s", count, err.Error())
+		}
+		fmt.Printf("Rolled back %d migrations\n", count)
+	default:
+		count, err := db.MigrateUp(psqlDB, lgr)
+		if err != nil {
+			fail("Applied %d migrations but failed: %s", count, err.Error())
+		}
+		fmt.Printf("Applied %d migrations\n", count)
+	}
+}
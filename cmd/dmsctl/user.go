@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Revoke all sessions of a user. It's done with an existing job position that is allowed
+// to manage sessions of the user.
+func revokeSessions(args []string) {
+	flags := flag.NewFlagSet("revoke-sessions", flag.ExitOnError)
+	as := flags.String("as", "", "Id of the job position the sessions are revoked with")
+	user := flags.String("user", "", "Id of the user to revoke its sessions")
+	flags.Parse(args)
+	callerJPID := parseIDFlag(flags, "as", *as, true)
+	userID := parseIDFlag(flags, "user", *user, true)
+
+	a := newApp()
+	count, err := a.Services.User.RevokeUserSessions(getActor(a, callerJPID), callerJPID, userID, cliClient)
+	if err != nil {
+		fail("Failed to revoke sessions of user %s: %s", userID.String(), err.Error())
+	}
+	fmt.Printf("Revoked %d sessions of user %s\n", count, userID.String())
+}
@@ -20,6 +20,160 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get last N events of the audit log that match the filters. Just actions done with the job position or its nested childs are returned, unless the job position be admin. To get the next page, pass the returned next_cursor as the cursor query.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Get the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't have any job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of events to fetch. Default is 20. Max is 50.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page returned in the previous response. If it's empty, the first page is returned.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just events of this action, e.g. doc.create",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failure"
+                        ],
+                        "type": "string",
+                        "description": "Just events with this outcome",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just events done with this job position",
+                        "name": "actor_jp_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just events done on this entity",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just events created at or after this time. (Unix timestamp in seconds)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just events created at or before this time. (Unix timestamp in seconds)",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Events and cursor of the next page",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.AuditPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Jon position doesn't belong to current user.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
         "/docs": {
             "get": {
                 "security": [
@@ -27,7 +181,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get n last documents (according to the limit and offset values) that are accessible for the user (and one of his job positions) who sent the request. (If the job position is admin, he has access to all documents.) For example, offset = 20 and limit = 10 would return 10 records (documents 21-30).",
+                "description": "Get n last documents that are accessible for the user (and one of his job positions) who sent the request and match the filters. (If the job position is admin, he has access to all documents.) To get the next page, pass the returned next_cursor as the cursor query. The pages are stable even when new documents are created.",
                 "consumes": [
                     "application/json"
                 ],
@@ -46,9 +200,9 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of documents to skip",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "Cursor of the page returned in the previous response. If it's empty, the first page is returned.",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
@@ -57,11 +211,58 @@ const docTemplate = `{
                         "name": "jpid",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just items created at or after this time. (Unix timestamp in seconds)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just items created at or before this time. (Unix timestamp in seconds)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just items created by this job position",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just items of this event",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just items created by job positions of this region",
+                        "name": "region_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Just items that have (or don't have) multimedia files",
+                        "name": "has_media",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1,
+                            2
+                        ],
+                        "type": "integer",
+                        "description": "Just items that have a multimedia file with this type",
+                        "name": "media_type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Documents",
+                        "description": "Documents and cursor of the next page",
                         "schema": {
                             "allOf": [
                                 {
@@ -71,10 +272,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.DocWithSomeDetails"
-                                            }
+                                            "$ref": "#/definitions/models.DocsPage"
                                         }
                                     }
                                 }
@@ -143,7 +341,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create document for specified event and current user in the current time and return its id.",
+                "description": "Create document for specified event and current user in the current time and return its id. Just the owner of the event and its contributors could create documents for it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -185,7 +383,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request error",
+                        "description": "Bad request error. Also if a file is not allowed by the upload policy or its size is missing.",
                         "schema": {
                             "allOf": [
                                 {
@@ -203,7 +401,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden error. The user is disabled.",
+                        "description": "Forbidden error. The user is disabled, the job position is not allowed to create docs or the storage quota is exceeded.",
                         "schema": {
                             "allOf": [
                                 {
@@ -259,48 +457,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/events": {
+        "/docs/{doc_id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get last N events by job position id.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the document together with name of its event and title of the job position created it. Just the creator of the document and his ancestors could read it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "document"
                 ],
-                "summary": "Get last N events by job position id",
+                "summary": "Get document",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job position id",
-                        "name": "jpid",
-                        "in": "query",
+                        "description": "Document id",
+                        "name": "doc_id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit of events to fetch. Default is 40. Max is 100. if limit be equals 0, then return all events from offset to the end.",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Offset of events to fetch. Default is 0.",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success fetching events",
+                        "description": "The document",
                         "schema": {
                             "allOf": [
                                 {
@@ -310,10 +499,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Event"
-                                            }
+                                            "$ref": "#/definitions/models.DocWithSomeDetails"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -321,7 +525,25 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Jon position doesn't belong to current user.",
+                        "description": "The job position doesn't belong to current user or is not the creator of the document or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The document doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -358,37 +580,38 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create event for specified job position and return its id.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete the document and its multimedia files. Just the creator of the document and his ancestors could delete it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "document"
                 ],
-                "summary": "Create event",
+                "summary": "Delete document",
                 "parameters": [
                     {
-                        "description": "Event",
-                        "name": "event",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Event"
-                        }
+                        "type": "string",
+                        "description": "Document id",
+                        "name": "doc_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success creating event",
+                        "description": "Success deleting document",
                         "schema": {
                             "allOf": [
                                 {
@@ -398,7 +621,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "$ref": "#/definitions/controllers.idResponse"
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -423,8 +646,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not found error. The job position doesn't belongs to current user.",
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the creator of the document or his ancestor.",
                         "schema": {
                             "allOf": [
                                 {
@@ -441,8 +664,26 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Server or database error",
+                    "404": {
+                        "description": "The document doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
                         "schema": {
                             "allOf": [
                                 {
@@ -460,34 +701,51 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/jps": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new job position for specified user. Each user job position must be created with another job position.",
+                "description": "Edit context and/or multimedia files of the document. Just the creator of the document and his ancestors could edit it. The previous context and multimedia files are kept as a new version of the document. The confirmed multimedia files could just be reordered or removed by their file names and pending files are kept. New files are added by uploading them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "job-position"
+                    "document"
                 ],
-                "summary": "Create a new user job position",
+                "summary": "Edit document",
                 "parameters": [
                     {
-                        "description": "Job position",
-                        "name": "jPWithPermission",
+                        "type": "string",
+                        "description": "Document id",
+                        "name": "doc_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to edit. Omitted fields remain unchanged.",
+                        "name": "update",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserJPWithPermission"
+                            "$ref": "#/definitions/models.DocUpdate"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Job position created and response its id",
+                        "description": "Success editing document",
                         "schema": {
                             "allOf": [
                                 {
@@ -497,7 +755,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "$ref": "#/definitions/controllers.idResponse"
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -505,7 +763,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request error",
+                        "description": "Bad request error. Also if a file is not a confirmed file of the document.",
                         "schema": {
                             "allOf": [
                                 {
@@ -522,8 +780,26 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized access to resource",
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the creator of the document or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The document doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -561,32 +837,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/jps/admin": {
-            "post": {
+        "/docs/{doc_id}/versions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new job position for specified user. Each Admin job position is created without a job position and has no parent job position.",
+                "description": "Get previous versions of the document together with the person who replaced each version. The newest versions come first. Just the creator of the document and his ancestors could read the versions.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "job-position"
+                    "document"
                 ],
-                "summary": "Create a new job position",
+                "summary": "Get versions of document",
                 "parameters": [
                     {
-                        "description": "Job position",
-                        "name": "jPWithPermission",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AdminJPWithPermission"
-                        }
+                        "type": "string",
+                        "description": "Document id",
+                        "name": "doc_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Job position created and response its id",
+                        "description": "Previous versions of the document",
                         "schema": {
                             "allOf": [
                                 {
@@ -596,7 +879,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "$ref": "#/definitions/controllers.idResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.DocVersion"
+                                            }
                                         }
                                     }
                                 }
@@ -621,8 +907,26 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized access to resource",
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the creator of the document or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The document doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -660,49 +964,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/jps/{jp_id}/events/{event_id}/docs": {
-            "get": {
+        "/docs/{doc_id}/versions/{version}/restore": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the latest documents within the event with the given ID. The documents can be retrieved only by the owner of the event and its ancestors, if they have the appropriate permissions.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Replace context and multimedia files of the document with the ones in the specified version. The current state of the document is kept as a new version, so the restoring could be undone too.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "document"
                 ],
-                "summary": "Get last documents",
+                "summary": "Restore document to a version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job position id",
-                        "name": "jp_id",
+                        "description": "Document id",
+                        "name": "doc_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Event id",
-                        "name": "event_id",
+                        "type": "integer",
+                        "description": "Version of the document to restore",
+                        "name": "version",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of documents to get",
-                        "name": "count",
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Documents",
+                        "description": "Success restoring document",
                         "schema": {
                             "allOf": [
                                 {
@@ -712,10 +1013,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.Doc"
-                                            }
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -740,26 +1038,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "The user is not authenticated",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/controllers.HttpResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "details": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
                     "403": {
-                        "description": "Forbidden error. The job position doesn't have permission to access this event and their docs.",
+                        "description": "The job position doesn't belong to current user or is not the creator of the document or his ancestor.",
                         "schema": {
                             "allOf": [
                                 {
@@ -777,7 +1057,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Not found error. The event doesn't exists.",
+                        "description": "The document or the version doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -815,32 +1095,95 @@ const docTemplate = `{
                 }
             }
         },
-        "/login/phone-based": {
-            "post": {
+        "/events": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Login/Create JWT with phone number only.",
+                "description": "Get last N events by job position id that match the filters. To get the next page, pass the returned next_cursor as the cursor query. The pages are stable even when new events are created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "session"
+                    "event"
                 ],
-                "summary": "Login/Create JWT with phone number only",
+                "summary": "Get last N events by job position id",
                 "parameters": [
                     {
-                        "description": "Phone number",
-                        "name": "phone",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PhoneBasedLoginInfo"
-                        }
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jpid",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of events to fetch. Default is 40. Max is 100.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page returned in the previous response. If it's empty, the first page is returned.",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just items created at or after this time. (Unix timestamp in seconds)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just items created at or before this time. (Unix timestamp in seconds)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just items created by this job position",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just this event",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just items created by job positions of this region",
+                        "name": "region_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Just items that have (or don't have) multimedia files",
+                        "name": "has_media",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1,
+                            2
+                        ],
+                        "type": "integer",
+                        "description": "Just items that have a multimedia file with this type",
+                        "name": "media_type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success login and response created JWT token",
+                        "description": "Events and cursor of the next page",
                         "schema": {
                             "allOf": [
                                 {
@@ -850,7 +1193,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/models.EventsPage"
                                         }
                                     }
                                 }
@@ -858,7 +1201,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request error",
+                        "description": "Bad request error. e.g. the cursor or a filter is not valid.",
                         "schema": {
                             "allOf": [
                                 {
@@ -875,8 +1218,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "User not found with such phone number",
+                    "403": {
+                        "description": "Jon position doesn't belong to current user.",
                         "schema": {
                             "allOf": [
                                 {
@@ -912,23 +1255,38 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/logout": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Logout from the current session.",
+                "description": "Create event for specified job position and return its id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "session"
+                    "event"
+                ],
+                "summary": "Create event",
+                "parameters": [
+                    {
+                        "description": "Event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    }
                 ],
-                "summary": "Logout",
                 "responses": {
                     "200": {
-                        "description": "Success logout",
+                        "description": "Success creating event",
                         "schema": {
                             "allOf": [
                                 {
@@ -938,7 +1296,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/controllers.idResponse"
                                         }
                                     }
                                 }
@@ -963,8 +1321,26 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized access to resource",
+                    "403": {
+                        "description": "The job position is not allowed to create events",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not found error. The job position doesn't belongs to current user.",
                         "schema": {
                             "allOf": [
                                 {
@@ -1002,35 +1378,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/jps": {
+        "/events/approved": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get user job positions",
+                "description": "Get last approved (featured) events created by the specified job position or his nested childs. If the job position is admin, approved events of all job positions are returned. The newest approvals come first.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "job-position"
+                    "event"
                 ],
-                "summary": "Get user job positions",
+                "summary": "Get approved events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Job position id",
+                        "name": "jpid",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of events to fetch. Default is 20. Max is 100.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset of events to fetch. Default is 0.",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just items created at or after this time. (Unix timestamp in seconds)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Just items created at or before this time. (Unix timestamp in seconds)",
+                        "name": "created_to",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "User phone number",
-                        "name": "phone",
+                        "description": "Just items created by this job position",
+                        "name": "created_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just this event",
+                        "name": "event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Just items created by job positions of this region",
+                        "name": "region_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Just items that have (or don't have) multimedia files",
+                        "name": "has_media",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1,
+                            2
+                        ],
+                        "type": "integer",
+                        "description": "Just items that have a multimedia file with this type",
+                        "name": "media_type",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Job positions",
+                        "description": "Success fetching approved events",
                         "schema": {
                             "allOf": [
                                 {
@@ -1042,7 +1475,7 @@ const docTemplate = `{
                                         "details": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/models.UserJobPosition"
+                                                "$ref": "#/definitions/models.EventWithApproval"
                                             }
                                         }
                                     }
@@ -1068,8 +1501,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized access to resource",
+                    "403": {
+                        "description": "Job position doesn't belong to current user.",
                         "schema": {
                             "allOf": [
                                 {
@@ -1107,38 +1540,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/": {
-            "post": {
+        "/events/{event_id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a user and return its id. Each user must created by a job position.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the event. Just the owner of the event, his ancestors and the ones the access list of the event grants them could read it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "event"
                 ],
-                "summary": "Create user",
+                "summary": "Get event",
                 "parameters": [
                     {
-                        "description": "User",
-                        "name": "admin",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success creating admin",
+                        "description": "The event",
                         "schema": {
                             "allOf": [
                                 {
@@ -1148,7 +1582,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "$ref": "#/definitions/controllers.idResponse"
+                                            "$ref": "#/definitions/models.Event"
                                         }
                                     }
                                 }
@@ -1173,8 +1607,26 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "409": {
-                        "description": "This user exists previously or disabled",
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -1210,40 +1662,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/admin": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create admin user and return its id. Admin users are users that don't have created by any user.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete the event and its documents. Just the owner of the event and his ancestors could delete it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "event"
                 ],
-                "summary": "Create admin",
+                "summary": "Delete event",
                 "parameters": [
                     {
-                        "description": "AdminUser",
-                        "name": "adminUser",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AdminUser"
-                        }
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success creating admin",
+                        "description": "Success deleting event",
                         "schema": {
                             "allOf": [
                                 {
@@ -1253,7 +1704,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "$ref": "#/definitions/controllers.idResponse"
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1278,8 +1729,26 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "409": {
-                        "description": "This user exists previously or disabled",
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -1315,23 +1784,51 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/current": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details of current user according to the authentication token.",
+                "description": "Edit name and/or description of the event. Just the owner of the event and his ancestors could edit it. Each edit is recorded in the edit history of the event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "event"
+                ],
+                "summary": "Edit event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to edit. Omitted fields remain unchanged.",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EventUpdate"
+                        }
+                    }
                 ],
-                "summary": "Get details of the current user",
                 "responses": {
                     "200": {
-                        "description": "User details",
+                        "description": "Success editing event",
                         "schema": {
                             "allOf": [
                                 {
@@ -1341,7 +1838,43 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "details": {
-                                            "$ref": "#/definitions/models.User"
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
                                         }
                                     }
                                 }
@@ -1349,7 +1882,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "The event doesn't exists.",
                         "schema": {
                             "allOf": [
                                 {
@@ -1386,309 +1919,6258 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/events/{event_id}/acl": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all accesses granted on the event, including the expired ones. The newest accesses come first. Just the owner of the event and admins could read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get access list of event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access list of the event",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.EventACL"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Grant read or contribute access on the event to a job position, a user or a job position with all its nested childs. Contributors could create documents for the event too. Just the owner of the event and admins could grant accesses.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Grant access on event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "The access. Its id, event id, granter and creation time are ignored.",
+                        "name": "acl",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EventACL"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access granted and response id of the access",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error or the granted job position doesn't exist.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{event_id}/acl/{acl_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an access granted on the event. Just the owner of the event and admins could revoke accesses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Revoke access on event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access id",
+                        "name": "acl_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success revoking access",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event or the access doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{event_id}/approval": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approve (feature) the event by the specified job position of the current user. The job position must have permission to approve events and be the same as or an ancestor of the event owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Approve event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success approving event. Returns the approval id.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not allowed to approve the event.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the approval of the event that is made previously by the specified job position of the current user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Revoke event approval",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success revoking approval",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position has not approved the event.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{event_id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all edits of the event together with the person who edited it. The newest edits come first. Just the owner of the event, his ancestors and the ones the access list of the event grants them could read the history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get edit history of event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Edit history of the event",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.EventRevision"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user or is not the owner of the event or his ancestor.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The event doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new job position for specified user. Each user job position must be created with another job position. A job position could have several parents, so it could be in several separate subtrees.",
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Create a new user job position",
+                "parameters": [
+                    {
+                        "description": "Job position",
+                        "name": "jPWithPermission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserJPWithPermission"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job position created and response its id",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller is not allowed to create job position under the parents",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "A parent job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/admin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new job position for specified user. Each Admin job position is created without a job position and has no parent job position. Just admin job positions could create admin job positions. The first one is created by the bootstrap-admin command of dmsctl.",
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Create a new job position",
+                "parameters": [
+                    {
+                        "description": "Job position",
+                        "name": "jPWithPermission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminJPWithPermission"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job position created and response its id",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position is not admin",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the job position and its permissions. Job positions that have childs couldn't be deleted. Just admins and ancestors of the job position could delete it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Delete job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success deleting job position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "The job position has childs.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Edit title and/or region of the job position. Just admins and ancestors of the job position could edit it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Edit job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to edit. Omitted fields remain unchanged.",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.JPUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success editing job position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/ancestors": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get ancestors of the job position together with their users and permissions. The nearer ancestors come first. Ancestors out of the subtree of the caller job position are not returned, except the caller is admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Get ancestor job positions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ancestor job positions",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.JPNode"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or the job position is not in its subtree.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/children": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get direct child job positions of the job position together with their users and permissions. A job position could just browse its own subtree.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Get child job positions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Child job positions",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.JPNode"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or the job position is not in its subtree.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/descendants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all nested child job positions of the job position together with their users and permissions. A job position could just browse its own subtree.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Get descendant job positions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Nested child job positions",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.JPNode"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or the job position is not in its subtree.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disable the job position, so its user couldn't use it anymore. Just admins and ancestors of the job position could disable it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Disable job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success disabling job position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable the disabled job position. Just admins and ancestors of the job position could enable it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Enable job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success enabling job position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/events/{event_id}/docs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the latest documents within the event with the given ID. The documents can be retrieved only by the owner of the event and its ancestors, if they have the appropriate permissions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "document"
+                ],
+                "summary": "Get last documents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event id",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of documents to get",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Documents",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Doc"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "The user is not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden error. The job position doesn't have permission to access this event and their docs.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not found error. The event doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change parent of the job position. The caller must be admin or an ancestor of both the job position and the new parent. The job position couldn't be moved under itself or its nested childs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Move job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "The new parent",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.JPMove"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success moving job position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error. e.g. the move makes a cycle.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not admin or an ancestor of the job positions.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the roles assigned to the job position. The caller must be the job position itself or be allowed to view its subtree.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Get roles of job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Roles of the job position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.JPRole"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to view the job position.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assign the role to the job position. If the role is inheritable, it applies to all nested childs of the job position too. The caller must be allowed to manage roles, be an ancestor of the job position and be allowed to do all actions of the role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Assign role to job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "The role. Its name is ignored.",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.JPRole"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success assigning role",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user, is not allowed to manage roles of the job position or isn't allowed to do an action of the role.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position or the role doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/roles/{role_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take the role from the job position. The caller must be allowed to manage roles and be an ancestor of the job position.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Unassign role from job position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role id",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success unassigning role",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to manage roles of the job position.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists or the role is not assigned to it.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jps/{jp_id}/tree": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the subtree of the job position as nested job positions together with their users and permissions. A job position could just browse its own subtree.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Get job position tree",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id",
+                        "name": "jp_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of levels of childs in the tree. Default is 3. Max is 10.",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The tree",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.JPNode"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or the job position is not in its subtree.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job position doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/login/phone-based/request-otp": {
+            "post": {
+                "description": "Generate a one-time code and send it to the phone number by SMS. The code expires after a few minutes and the number of requests for each phone number and each IP address is limited. If the phone number doesn't belong to any enabled user, nothing is sent but the response is the same.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Request one-time code for phone based login",
+                "parameters": [
+                    {
+                        "description": "Phone number",
+                        "name": "phone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PhoneOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The code is sent",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests for the phone number or from the IP address",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/login/phone-based/verify": {
+            "post": {
+                "description": "Login/Create JWT if the one-time code sent to the phone number is correct. After some wrong codes, the code is revoked and a new one must be requested.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Login/Create JWT with phone number and one-time code",
+                "parameters": [
+                    {
+                        "description": "Phone number and one-time code",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PhoneBasedLoginInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success login and response created access token and refresh token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.SessionTokens"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "The code is wrong or expired or user not found with such phone number",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The user is disabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Logout from the current session.",
+                "tags": [
+                    "session"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "Success logout",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all roles with their actions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Get roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Roles",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Role"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a named role that bundles some actions. Just job positions that are allowed to manage roles could create roles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Create role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role created and response its id",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to manage roles.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "A role with the same name exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{role_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the role and take it from all job positions. Built-in roles couldn't be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Delete role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role id",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success deleting role",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error. e.g. the role is built-in.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to manage roles.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The role doesn't exists.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search in context of documents or name and description of events that are accessible for the job position. (If the job position is admin, he has access to all documents and events.) The results are ordered by their relevance and the matched words in the title and snippet of each result are wrapped in \u003cmark\u003e tags. The query supports web search syntax. e.g. \"quoted phrase\", \"or\" and \"-\" to exclude a word.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Search documents or events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "doc",
+                            "event"
+                        ],
+                        "type": "string",
+                        "default": "doc",
+                        "description": "Type of entities to search",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to get. Maximum is 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Search results",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SearchResult"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "The user is not authorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position doesn't belong to current user.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get active sessions (logged in devices) of the current user. The recently used sessions come first and the session of the current request is marked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get my sessions",
+                "responses": {
+                    "200": {
+                        "description": "Active sessions",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Session"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke all sessions of the current user and response number of revoked sessions. If all_except_current is true, the session of the current request is kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke my sessions",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Keep the current session",
+                        "name": "all_except_current",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of revoked sessions",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{session_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the sessions of the current user. Its tokens stop working immediately.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session id",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success revoking session",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The session doesn't exist or doesn't belong to the current user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Issue a new access token (JWT) and a new refresh token for the session of the refresh token. Each refresh token could be used once. If a used refresh token is sent again, the whole session is revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Refresh session",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New access token and refresh token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.SessionTokens"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "The refresh token is invalid, expired or used previously",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The user is disabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/confirm": {
+            "post": {
+                "description": "It's called by the file-transfer service after storing an uploaded file (It's the HTTP twin of the ConfirmUpload gRPC method). The pending file of the doc with the same name is confirmed with its size, checksum and MIME type. The doc must be created by the job position of the auth token for its event. The shared secret of the file-transfer service must be sent in the X-Service-Token header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Confirm upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shared secret of the file-transfer service",
+                        "name": "X-Service-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Stored file",
+                        "name": "confirmation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UploadConfirmation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The confirmed file",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.MediaPath"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error. Also if the file is not allowed by the upload policy.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "The auth token is invalid",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden error. The service token is wrong, the doc doesn't belong to the auth token or the storage quota is exceeded.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not found error. The doc doesn't exists or doesn't have such pending file.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/user/jps": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get user job positions",
+                "tags": [
+                    "job-position"
+                ],
+                "summary": "Get user job positions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User phone number",
+                        "name": "phone",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job positions",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.UserJobPosition"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized access to resource",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get last N created users. Just users created by users of the caller job position or its nested childs are returned, unless the caller be admin. To get the next page, pass the returned next_cursor as the cursor query.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit of users to fetch. Default is 40. Max is 100.",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page returned in the previous response. If it's empty, the first page is returned.",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Users and cursor of the next page",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.UsersPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Jon position doesn't belong to current user.",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a user and return its id. Each user must created by a job position that is allowed to create users.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "User",
+                        "name": "admin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success creating admin",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position is not allowed to create users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "This user exists previously or disabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/admin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create admin user and return its id. Admin users are users that don't have created by any user. Just admin job positions could create admins. The first admin is created by the bootstrap-admin command of dmsctl.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create admin",
+                "parameters": [
+                    {
+                        "description": "AdminUser",
+                        "name": "adminUser",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUser"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success creating admin",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/controllers.idResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The job position is not admin",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "This user exists previously",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/current": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get details of current user according to the authentication token.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get details of the current user",
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Edit name and/or phone number of the user. The caller job position must be allowed to manage users and, unless it's admin, the user must be created by a user of its subtree.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Edit user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to edit. Omitted fields remain unchanged.",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success editing user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to manage users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The user doesn't exist in the subtree of the caller",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Another user with the phone number exists",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disable the user and revoke all of its sessions, so it couldn't login or use the APIs anymore. The access of the caller is the same as editing the user. Users couldn't disable themselves.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Disable user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success disabling user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to manage users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The user doesn't exist in the subtree of the caller",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable the disabled user. The access of the caller is the same as editing the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Enable user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job position id of the caller. It's required if the JWT doesn't contain a job position.",
+                        "name": "jpid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success enabling user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "The caller job position doesn't belong to current user or is not allowed to manage users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The user doesn't exist in the subtree of the caller",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server or database error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.HttpResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
         "controllers.HttpResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "HTTP status code",
+                "code": {
+                    "description": "HTTP status code",
+                    "type": "integer"
+                },
+                "details": {},
+                "message": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Its type of response. e.g. error, warning, or success.\nIt's better to just have these three types.",
+                    "type": "string",
+                    "enum": [
+                        "error",
+                        "warning",
+                        "success"
+                    ]
+                }
+            }
+        },
+        "controllers.idResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Because the UUID in the response will be an array, we use string as id.",
+                    "type": "string",
+                    "example": "8b2d1c6b-6c2c-4a8b-8b2d-1c6b6c2c4a8b"
+                }
+            }
+        },
+        "models.Action": {
+            "type": "string",
+            "enum": [
+                "create_user",
+                "create_jp",
+                "manage_jp",
+                "create_event",
+                "create_doc",
+                "approve_event",
+                "view_subtree",
+                "edit_subtree",
+                "manage_sessions",
+                "manage_roles",
+                "manage_users"
+            ],
+            "x-enum-varnames": [
+                "ActionCreateUser",
+                "ActionCreateJP",
+                "ActionManageJP",
+                "ActionCreateEvent",
+                "ActionCreateDoc",
+                "ActionApproveEvent",
+                "ActionViewSubtree",
+                "ActionEditSubtree",
+                "ActionManageSessions",
+                "ActionManageRoles",
+                "ActionManageUsers"
+            ]
+        },
+        "models.AdminJPWithPermission": {
+            "type": "object",
+            "required": [
+                "job_position",
+                "permission"
+            ],
+            "properties": {
+                "job_position": {
+                    "$ref": "#/definitions/models.AdminJobPosition"
+                },
+                "permission": {
+                    "$ref": "#/definitions/models.Permission"
+                }
+            }
+        },
+        "models.AdminJobPosition": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "The time the JP is created with UTC timezone and unix timestamp in seconds.",
+                    "type": "integer",
+                    "example": 1641011200
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "is_disabled": {
+                    "description": "A disabled JP can't be used by its user. It's ignored on creating a JP.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "region_id": {
+                    "description": "The region the JP belongs to",
+                    "type": "string",
+                    "example": "b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "معاون مدرسه"
+                },
+                "user_id": {
+                    "description": "ID of the user the JP is for that.",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                }
+            }
+        },
+        "models.AdminUser": {
+            "type": "object",
+            "required": [
+                "name",
+                "phone_number"
+            ],
+            "properties": {
+                "id": {
+                    "description": "ID of the admin user",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "is_disabled": {
+                    "description": "* 0: enabled user\n* 1: disabled user",
+                    "enum": [
+                        0,
+                        1
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Disability"
+                        }
+                    ],
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "phone_number": {
+                    "type": "string",
+                    "example": "9171234567"
+                }
+            }
+        },
+        "models.ApprovedEvent": {
+            "type": "object",
+            "properties": {
+                "approved_by": {
+                    "description": "Name of Person approved the event",
+                    "type": "string"
+                },
+                "approved_by_jp_id": {
+                    "description": "ID of the job position approved the event",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditAction": {
+            "type": "string",
+            "enum": [
+                "user.create",
+                "user.create_admin",
+                "user.update",
+                "user.disable",
+                "user.enable",
+                "jp.create",
+                "jp.create_admin",
+                "jp.update",
+                "jp.move",
+                "jp.disable",
+                "jp.enable",
+                "jp.delete",
+                "event.create",
+                "event.update",
+                "event.delete",
+                "event.approve",
+                "event.revoke_approval",
+                "event.grant_access",
+                "event.revoke_access",
+                "doc.create",
+                "doc.update",
+                "doc.delete",
+                "doc.restore",
+                "role.create",
+                "role.delete",
+                "role.assign",
+                "role.unassign",
+                "session.request_otp",
+                "session.login",
+                "session.refresh",
+                "session.logout",
+                "session.revoke",
+                "session.revoke_all",
+                "file.download",
+                "file.upload",
+                "file.confirm_upload"
+            ],
+            "x-enum-varnames": [
+                "AuditUserCreate",
+                "AuditAdminCreate",
+                "AuditUserUpdate",
+                "AuditUserDisable",
+                "AuditUserEnable",
+                "AuditJPCreate",
+                "AuditAdminJPCreate",
+                "AuditJPUpdate",
+                "AuditJPMove",
+                "AuditJPDisable",
+                "AuditJPEnable",
+                "AuditJPDelete",
+                "AuditEventCreate",
+                "AuditEventUpdate",
+                "AuditEventDelete",
+                "AuditEventApprove",
+                "AuditEventRevokeApproval",
+                "AuditEventGrantAccess",
+                "AuditEventRevokeAccess",
+                "AuditDocCreate",
+                "AuditDocUpdate",
+                "AuditDocDelete",
+                "AuditDocRestore",
+                "AuditRoleCreate",
+                "AuditRoleDelete",
+                "AuditRoleAssign",
+                "AuditRoleUnassign",
+                "AuditOTPRequest",
+                "AuditLogin",
+                "AuditSessionRefresh",
+                "AuditLogout",
+                "AuditSessionRevoke",
+                "AuditSessionsRevoke",
+                "AuditFileDownload",
+                "AuditFileUpload",
+                "AuditFileConfirm"
+            ]
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AuditAction"
+                        }
+                    ],
+                    "example": "doc.create"
+                },
+                "actor_jp_id": {
+                    "description": "The job position the action is done with",
+                    "type": "string",
+                    "example": "54a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "actor_user_id": {
+                    "description": "The user who did the action",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "created_at": {
+                    "description": "It's in UTC time zone and Unix timestamp. (in seconds)",
+                    "type": "integer",
+                    "example": 1641011200
+                },
+                "details": {
+                    "description": "Reason of the failure or more details of the action",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "192.168.1.10"
+                },
+                "outcome": {
+                    "enum": [
+                        "success",
+                        "failure"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AuditOutcome"
+                        }
+                    ]
+                },
+                "target_id": {
+                    "type": "string",
+                    "example": "32a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "target_type": {
+                    "description": "Type of the entity the action is done on. It's empty if the action doesn't have any target.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AuditTargetType"
+                        }
+                    ],
+                    "example": "doc"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditOutcome": {
+            "type": "string",
+            "enum": [
+                "success",
+                "failure"
+            ],
+            "x-enum-varnames": [
+                "AuditSuccess",
+                "AuditFailure"
+            ]
+        },
+        "models.AuditPage": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Pass it as the cursor query to get the next page. If it's empty, there's not any more event.",
+                    "type": "string",
+                    "example": "AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"
+                }
+            }
+        },
+        "models.AuditTargetType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "jp",
+                "event",
+                "doc",
+                "role",
+                "session"
+            ],
+            "x-enum-varnames": [
+                "AuditTargetUser",
+                "AuditTargetJP",
+                "AuditTargetEvent",
+                "AuditTargetDoc",
+                "AuditTargetRole",
+                "AuditTargetSession"
+            ]
+        },
+        "models.Disability": {
+            "type": "integer",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "IsNotDisabled",
+                "IsDisabled"
+            ]
+        },
+        "models.Doc": {
+            "type": "object",
+            "required": [
+                "created_by",
+                "event_id"
+            ],
+            "properties": {
+                "context": {
+                    "type": "string",
+                    "example": "some context"
+                },
+                "created_at": {
+                    "description": "The time the document is created. It's in UTC time zone and Unix timestamp. (in seconds)",
+                    "type": "integer",
+                    "example": 1641011200
+                },
+                "created_by": {
+                    "description": "The id of job position who created the document",
+                    "type": "string",
+                    "example": "54a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "event_id": {
+                    "description": "The id of event the document is for that",
+                    "type": "string",
+                    "example": "32a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
+                },
+                "media_paths": {
+                    "description": "Contains path of multimedia files in the document. (If there's in the document)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MediaPath"
+                    }
+                }
+            }
+        },
+        "models.DocUpdate": {
+            "type": "object",
+            "properties": {
+                "context": {
+                    "type": "string",
+                    "example": "corrected context"
+                },
+                "media_paths": {
+                    "description": "If it's not nil, confirmed multimedia files of the document are reordered or removed\nto match it. Files are matched by their file names and new files couldn't be added.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MediaPath"
+                    }
+                }
+            }
+        },
+        "models.DocVersion": {
+            "type": "object",
+            "properties": {
+                "context": {
+                    "type": "string",
+                    "example": "some context"
+                },
+                "created_at": {
+                    "description": "The time this version is replaced with a newer one. It's in UTC time zone and Unix timestamp. (in seconds)",
+                    "type": "integer",
+                    "example": 1641011200
+                },
+                "doc_id": {
+                    "type": "string",
+                    "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
+                },
+                "edited_by": {
+                    "description": "The id of job position who edited the document and replaced this version with a newer one",
+                    "type": "string",
+                    "example": "54a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "editor_name": {
+                    "description": "Name of the person who edited the document",
+                    "type": "string"
+                },
+                "media_paths": {
+                    "description": "Contains path of multimedia files in this version of the document.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MediaPath"
+                    }
+                },
+                "version": {
+                    "description": "Sequence number of the version in the document. The first version is 1.",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.DocWithSomeDetails": {
+            "type": "object",
+            "required": [
+                "created_by",
+                "event_id"
+            ],
+            "properties": {
+                "context": {
+                    "type": "string",
+                    "example": "some context"
+                },
+                "created_at": {
+                    "description": "The time the document is created. It's in UTC time zone and Unix timestamp. (in seconds)",
+                    "type": "integer",
+                    "example": 1641011200
+                },
+                "created_by": {
+                    "description": "The id of job position who created the document",
+                    "type": "string",
+                    "example": "54a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "event_id": {
+                    "description": "The id of event the document is for that",
+                    "type": "string",
+                    "example": "32a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
+                },
+                "jp_name": {
+                    "type": "string"
+                },
+                "media_paths": {
+                    "description": "Contains path of multimedia files in the document. (If there's in the document)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MediaPath"
+                    }
+                }
+            }
+        },
+        "models.DocsPage": {
+            "type": "object",
+            "properties": {
+                "docs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DocWithSomeDetails"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Pass it as the cursor query to get the next page. If it's empty, there's not any more document.",
+                    "type": "string",
+                    "example": "AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"
+                }
+            }
+        },
+        "models.Event": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "Date when the event is created. Based on UTC time zone and Unix timestamp. (In seconds)",
+                    "type": "integer"
+                },
+                "created_by": {
+                    "description": "ID of job position wants to create event",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
+                },
+                "name": {
+                    "description": "event name",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Date when the event is updated. Based on UTC time zone and Unix timestamp. (In seconds)\nIf it is nil, means the event is not updated.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EventACL": {
+            "type": "object",
+            "required": [
+                "access",
+                "grantee_id",
+                "grantee_type"
+            ],
+            "properties": {
+                "access": {
+                    "enum": [
+                        "read",
+                        "contribute"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.EventAccess"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "description": "Date when the access is granted. Based on UTC time zone and Unix timestamp. (In seconds)",
                     "type": "integer"
                 },
-                "details": {},
-                "message": {
-                    "type": "string"
+                "event_id": {
+                    "type": "string",
+                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
                 },
-                "type": {
-                    "description": "Its type of response. e.g. error, warning, or success.\nIt's better to just have these three types.",
+                "expires_at": {
+                    "description": "Date when the access expires. Based on UTC time zone and Unix timestamp. (In seconds)\nIf it's nil, the access never expires.",
+                    "type": "integer"
+                },
+                "granted_by": {
+                    "description": "ID of the job position granted the access",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "grantee_id": {
+                    "description": "ID of the job position or user the access is granted to",
                     "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "grantee_type": {
+                    "description": "The access is granted to a job position, a user or a job position and all its nested childs.",
                     "enum": [
-                        "error",
-                        "warning",
-                        "success"
+                        "jp",
+                        "user",
+                        "subtree"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.GranteeType"
+                        }
                     ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
                 }
             }
         },
-        "controllers.idResponse": {
+        "models.EventAccess": {
+            "type": "string",
+            "enum": [
+                "read",
+                "contribute"
+            ],
+            "x-enum-varnames": [
+                "EventAccessRead",
+                "EventAccessContribute"
+            ]
+        },
+        "models.EventRevision": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "description": "Date when the event is edited. Based on UTC time zone and Unix timestamp. (In seconds)",
+                    "type": "integer"
+                },
+                "edited_by": {
+                    "description": "ID of job position who edited the event",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "editor_name": {
+                    "description": "Name of the person who edited the event",
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
+                },
                 "id": {
-                    "description": "Because the UUID in the response will be an array, we use string as id.",
                     "type": "string",
-                    "example": "8b2d1c6b-6c2c-4a8b-8b2d-1c6b6c2c4a8b"
+                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
+                },
+                "new_description": {
+                    "type": "string"
+                },
+                "new_name": {
+                    "type": "string"
+                },
+                "old_description": {
+                    "type": "string"
+                },
+                "old_name": {
+                    "type": "string"
                 }
             }
         },
-        "models.AdminJPWithPermission": {
+        "models.EventUpdate": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "new description"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "new name"
+                }
+            }
+        },
+        "models.EventWithApproval": {
             "type": "object",
             "required": [
-                "job_position",
-                "permission"
+                "name"
             ],
             "properties": {
-                "job_position": {
-                    "$ref": "#/definitions/models.AdminJobPosition"
+                "approval": {
+                    "$ref": "#/definitions/models.ApprovedEvent"
                 },
-                "permission": {
-                    "$ref": "#/definitions/models.Permission"
+                "created_at": {
+                    "description": "Date when the event is created. Based on UTC time zone and Unix timestamp. (In seconds)",
+                    "type": "integer"
+                },
+                "created_by": {
+                    "description": "ID of job position wants to create event",
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
+                },
+                "name": {
+                    "description": "event name",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Date when the event is updated. Based on UTC time zone and Unix timestamp. (In seconds)\nIf it is nil, means the event is not updated.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EventsPage": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Event"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Pass it as the cursor query to get the next page. If it's empty, there's not any more event.",
+                    "type": "string",
+                    "example": "AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"
+                }
+            }
+        },
+        "models.GranteeType": {
+            "type": "string",
+            "enum": [
+                "jp",
+                "user",
+                "subtree"
+            ],
+            "x-enum-varnames": [
+                "GranteeJP",
+                "GranteeUser",
+                "GranteeSubtree"
+            ]
+        },
+        "models.JPMove": {
+            "type": "object",
+            "required": [
+                "parent_id"
+            ],
+            "properties": {
+                "parent_id": {
+                    "description": "ID of the new parent of the job position",
+                    "type": "string",
+                    "example": "5abcdeff-0685-49d1-bbdd-31ab1b4c1613"
+                }
+            }
+        },
+        "models.JPNode": {
+            "type": "object",
+            "properties": {
+                "childs": {
+                    "description": "Child job positions of the job position. It's just filled in the tree responses.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JPNode"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "is_disabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "parent_ids": {
+                    "description": "If the job position has no parent, it's empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "region_id": {
+                    "type": "string",
+                    "example": "b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"
+                },
+                "roles": {
+                    "description": "Roles assigned directly to the job position",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JPRole"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "معاون مدرسه"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                },
+                "user_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "models.JPRole": {
+            "type": "object",
+            "required": [
+                "role_id"
+            ],
+            "properties": {
+                "is_inheritable": {
+                    "description": "If it's true, the role applies to all nested childs of the job position too.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "secretary"
+                },
+                "role_id": {
+                    "type": "string",
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                }
+            }
+        },
+        "models.JPUpdate": {
+            "type": "object",
+            "properties": {
+                "region_id": {
+                    "type": "string",
+                    "example": "b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "مدیر مدرسه"
+                }
+            }
+        },
+        "models.MediaPath": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "description": "Checksum of the file, e.g. \"sha256:\u003chex\u003e\". It's set when the upload of the file is\nconfirmed and ignored in requests.",
+                    "type": "string"
+                },
+                "file_name": {
+                    "description": "Just contains filename and its type",
+                    "type": "string"
+                },
+                "media_type": {
+                    "$ref": "#/definitions/models.MediaType"
+                },
+                "mime_type": {
+                    "description": "MIME type of the file detected from its content. It's set when the upload of the\nfile is confirmed and ignored in requests.",
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "object_token": {
+                    "description": "Signed and expiring token that must be sent to the file-transfer service to download\nthe file. It's set by the server in responses and ignored in requests. Pending files\ndon't have it.",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size of the file in Kbytes. It's required on creating and editing docs.",
+                    "type": "integer",
+                    "example": 2048
+                },
+                "src": {
+                    "description": "Full path and file name (contains type too)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "The file is pending until the file-transfer service confirms it's stored. It's set\nby the server in responses and ignored in requests.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.MediaStatus"
+                        }
+                    ],
+                    "example": "confirmed"
+                }
+            }
+        },
+        "models.MediaStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed"
+            ],
+            "x-enum-varnames": [
+                "MediaPending",
+                "MediaConfirmed"
+            ]
+        },
+        "models.MediaType": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "MediaImage",
+                "MediaVideo",
+                "MediaAudio"
+            ]
+        },
+        "models.Permission": {
+            "type": "object",
+            "required": [
+                "is_allow_create_jp"
+            ],
+            "properties": {
+                "is_allow_approve_event": {
+                    "description": "Does the current job position is allowed to approve (feature) events created by\nhimself or his nested childs?",
+                    "type": "boolean"
+                },
+                "is_allow_create_jp": {
+                    "description": "Does the current job position is allowed to create a job position as child of himself?",
+                    "type": "boolean"
                 }
             }
         },
-        "models.AdminJobPosition": {
+        "models.PhoneBasedLoginInfo": {
             "type": "object",
             "required": [
-                "title"
+                "code",
+                "phone_number",
+                "user_agent"
             ],
             "properties": {
-                "created_at": {
-                    "description": "The time the JP is created with UTC timezone and unix timestamp in seconds.",
-                    "type": "integer",
-                    "example": 1641011200
-                },
-                "id": {
-                    "type": "string",
-                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
-                },
-                "region_id": {
-                    "description": "The region the JP belongs to",
+                "code": {
+                    "description": "The one-time code that is sent to the phone number.",
                     "type": "string",
-                    "example": "b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"
+                    "example": "123456"
                 },
-                "title": {
+                "phone_number": {
                     "type": "string",
-                    "example": "معاون مدرسه"
+                    "example": "9171234567"
                 },
-                "user_id": {
-                    "description": "ID of the user the JP is for that.",
+                "user_agent": {
+                    "description": "Details of the device from which the user logged in.",
                     "type": "string",
-                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                    "example": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/89.0.142.86 Safari/537.36"
                 }
             }
         },
-        "models.AdminUser": {
+        "models.PhoneOTPRequest": {
             "type": "object",
             "required": [
-                "name",
                 "phone_number"
             ],
             "properties": {
-                "id": {
-                    "description": "ID of the admin user",
-                    "type": "string",
-                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
-                },
-                "is_disabled": {
-                    "description": "* 0: enabled user\n* 1: disabled user",
-                    "enum": [
-                        0,
-                        1
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.Disability"
-                        }
-                    ],
-                    "example": 0
-                },
-                "name": {
-                    "type": "string",
-                    "example": "John Doe"
-                },
                 "phone_number": {
                     "type": "string",
                     "example": "9171234567"
                 }
             }
         },
-        "models.Disability": {
-            "type": "integer",
-            "enum": [
-                0,
-                1
+        "models.RefreshSessionRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
             ],
-            "x-enum-varnames": [
-                "IsNotDisabled",
-                "IsDisabled"
-            ]
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
         },
-        "models.Doc": {
+        "models.Role": {
             "type": "object",
             "required": [
-                "created_by",
-                "event_id"
+                "actions",
+                "name"
             ],
             "properties": {
-                "context": {
-                    "type": "string",
-                    "example": "some context"
-                },
-                "created_at": {
-                    "description": "The time the document is created. It's in UTC time zone and Unix timestamp. (in seconds)",
-                    "type": "integer",
-                    "example": 1641011200
-                },
-                "created_by": {
-                    "description": "The id of job position who created the document",
-                    "type": "string",
-                    "example": "54a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                "actions": {
+                    "description": "Actions the role allows",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "enum": [
+                            "create_user",
+                            "create_jp",
+                            "manage_jp",
+                            "create_event",
+                            "create_doc",
+                            "approve_event",
+                            "view_subtree",
+                            "edit_subtree",
+                            "manage_sessions",
+                            "manage_roles",
+                            "manage_users"
+                        ],
+                        "$ref": "#/definitions/models.Action"
+                    }
                 },
-                "event_id": {
-                    "description": "The id of event the document is for that",
+                "description": {
                     "type": "string",
-                    "example": "32a79030f-0685-49d1-bbdd-31ab1b4c1613"
+                    "example": "Could create events and docs"
                 },
                 "id": {
                     "type": "string",
-                    "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
+                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
                 },
-                "media_paths": {
-                    "description": "Contains path of multimedia files in the document. (If there's in the document)",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.MediaPath"
-                    }
+                "is_builtin": {
+                    "description": "Built-in roles are created by the system and couldn't be deleted.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "secretary"
                 }
             }
         },
-        "models.DocWithSomeDetails": {
+        "models.SearchResult": {
             "type": "object",
-            "required": [
-                "created_by",
-                "event_id"
-            ],
             "properties": {
-                "context": {
-                    "type": "string",
-                    "example": "some context"
-                },
                 "created_at": {
-                    "description": "The time the document is created. It's in UTC time zone and Unix timestamp. (in seconds)",
+                    "description": "The time the document or event is created. It's in UTC time zone and Unix timestamp. (in seconds)",
                     "type": "integer",
                     "example": 1641011200
                 },
                 "created_by": {
-                    "description": "The id of job position who created the document",
+                    "description": "The id of job position who created the document or event",
                     "type": "string",
                     "example": "54a79030f-0685-49d1-bbdd-31ab1b4c1613"
                 },
                 "event_id": {
-                    "description": "The id of event the document is for that",
+                    "description": "ID of the event. If the result is a document, it's the event the document is for that.",
                     "type": "string",
                     "example": "32a79030f-0685-49d1-bbdd-31ab1b4c1613"
                 },
-                "event_name": {
-                    "type": "string"
-                },
                 "id": {
+                    "description": "ID of the matched document or event",
                     "type": "string",
                     "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
                 },
-                "jp_name": {
+                "rank": {
+                    "description": "Relevance of the result to the search query. Greater is more relevant.",
+                    "type": "number",
+                    "example": 0.0607927
+                },
+                "snippet": {
+                    "description": "Some fragments of the context of document or description of event that contain\nthe matched words. It's HTML escaped and matched words are wrapped in \u003cmark\u003e tags.",
                     "type": "string"
                 },
-                "media_paths": {
-                    "description": "Contains path of multimedia files in the document. (If there's in the document)",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.MediaPath"
-                    }
+                "title": {
+                    "description": "Name of the event. It's HTML escaped and matched words in it are wrapped in \u003cmark\u003e tags.",
+                    "type": "string"
+                },
+                "type": {
+                    "enum": [
+                        "doc",
+                        "event"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SearchType"
+                        }
+                    ]
                 }
             }
         },
-        "models.Event": {
-            "type": "object",
-            "required": [
-                "name"
+        "models.SearchType": {
+            "type": "string",
+            "enum": [
+                "doc",
+                "event"
             ],
+            "x-enum-varnames": [
+                "SearchDoc",
+                "SearchEvent"
+            ]
+        },
+        "models.Session": {
+            "type": "object",
             "properties": {
-                "created_at": {
-                    "description": "Date when the event is created. Based on UTC time zone and Unix timestamp. (In seconds)",
+                "expired_at": {
+                    "description": "It's stored as a Unix timestamp. (In seconds and UTC time zone)\nUnix time the session expires",
                     "type": "integer"
                 },
-                "created_by": {
-                    "description": "ID of job position wants to create event",
-                    "type": "string",
-                    "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
-                },
-                "description": {
+                "id": {
+                    "description": "Session id",
                     "type": "string"
                 },
-                "id": {
-                    "type": "string",
-                    "example": "46bbd388-d251-4a53-9f5b-da2c909fe14a"
+                "is_current": {
+                    "description": "Is it the session the current request is sent by?",
+                    "type": "boolean"
                 },
-                "name": {
-                    "description": "event name",
-                    "type": "string"
+                "issued_at": {
+                    "description": "Unix time the session is created",
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "description": "Date when the event is updated. Based on UTC time zone and Unix timestamp. (In seconds)\nIf it is nil, means the event is not updated.",
+                "last_usage_at": {
+                    "description": "Last usage time of the session\nIt's stored as a Unix timestamp. (In seconds and UTC time zone)\nIf it be 0 means it's not used.",
                     "type": "integer"
-                }
-            }
-        },
-        "models.MediaPath": {
-            "type": "object",
-            "properties": {
-                "file_name": {
-                    "description": "Just contains filename and its type",
-                    "type": "string"
                 },
-                "media_type": {
-                    "$ref": "#/definitions/models.MediaType"
+                "user_agent": {
+                    "description": "Details of the device on which the user is logged in.",
+                    "type": "string"
                 },
-                "src": {
-                    "description": "Full path and file name (contains type too)",
+                "user_id": {
+                    "description": "It's stored as a Unix timestamp. (In seconds and UTC time zone)\nThis session is belong to the user id",
                     "type": "string"
                 }
             }
         },
-        "models.MediaType": {
-            "type": "integer",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "MediaImage",
-                "MediaVideo",
-                "MediaAudio"
-            ]
-        },
-        "models.Permission": {
+        "models.SessionTokens": {
             "type": "object",
-            "required": [
-                "is_allow_create_jp"
-            ],
             "properties": {
-                "is_allow_create_jp": {
-                    "description": "Does the current job position is allowed to create a job position as child of himself?",
-                    "type": "boolean"
+                "access_token": {
+                    "description": "Short-lived JWT that is used to access the APIs",
+                    "type": "string"
+                },
+                "access_token_expired_at": {
+                    "description": "It's stored as a Unix timestamp. (In seconds and UTC time zone)",
+                    "type": "integer"
                 },
-                "jpid": {
-                    "description": "ID of the job position the permission is for",
+                "refresh_token": {
+                    "description": "Long-lived token that is used just for getting new tokens. Each refresh token could\nbe used once.",
                     "type": "string"
+                },
+                "refresh_token_expired_at": {
+                    "description": "It's stored as a Unix timestamp. (In seconds and UTC time zone)",
+                    "type": "integer"
                 }
             }
         },
-        "models.PhoneBasedLoginInfo": {
+        "models.UploadConfirmation": {
             "type": "object",
             "required": [
-                "phone_number",
-                "user_agent"
+                "auth_token",
+                "checksum",
+                "doc_id",
+                "file_name",
+                "mime_type"
             ],
             "properties": {
-                "phone_number": {
+                "auth_token": {
+                    "description": "The auth token the client has uploaded the file with",
+                    "type": "string"
+                },
+                "checksum": {
+                    "description": "Checksum of the stored file",
                     "type": "string",
-                    "example": "9171234567"
+                    "example": "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
                 },
-                "user_agent": {
-                    "description": "Details of the device from which the user logged in.",
+                "doc_id": {
+                    "description": "The doc the file belongs to",
                     "type": "string",
-                    "example": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/89.0.142.86 Safari/537.36"
+                    "example": "20354d7a-e4fe-47af-8ff6-187bca92f3f9"
+                },
+                "file_name": {
+                    "description": "Name of the stored file together with its extension",
+                    "type": "string",
+                    "example": "photo.jpg"
+                },
+                "mime_type": {
+                    "description": "MIME type of the stored file that is detected from its content",
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "size": {
+                    "description": "Size of the stored file in Kbytes",
+                    "type": "integer",
+                    "example": 2048
+                },
+                "src": {
+                    "description": "Full path of the stored file. If it's empty, FileName is used.",
+                    "type": "string",
+                    "example": "events/photo.jpg"
                 }
             }
         },
@@ -1700,7 +8182,7 @@ const docTemplate = `{
             ],
             "properties": {
                 "created_by": {
-                    "description": "The id of job position created this user",
+                    "description": "The id of user created this user. It's set by the server according to the caller.",
                     "type": "string",
                     "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
                 },
@@ -1746,7 +8228,6 @@ const docTemplate = `{
         "models.UserJobPosition": {
             "type": "object",
             "required": [
-                "parent_id",
                 "title"
             ],
             "properties": {
@@ -1759,10 +8240,26 @@ const docTemplate = `{
                     "type": "string",
                     "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
                 },
+                "is_disabled": {
+                    "description": "A disabled JP can't be used by its user. It's ignored on creating a JP.",
+                    "type": "boolean",
+                    "example": false
+                },
                 "parent_id": {
+                    "description": "The first parent of the job position. On creating the job position, it's added to\nthe parents if it's set.",
                     "type": "string",
                     "example": "5abcdeff-0685-49d1-bbdd-31ab1b4c1613"
                 },
+                "parent_ids": {
+                    "description": "All parents of the job position. The job position reports to each of them.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "5abcdeff-0685-49d1-bbdd-31ab1b4c1613"
+                    ]
+                },
                 "region_id": {
                     "description": "The region the JP belongs to",
                     "type": "string",
@@ -1778,6 +8275,35 @@ const docTemplate = `{
                     "example": "6a79030f-0685-49d1-bbdd-31ab1b4c1613"
                 }
             }
+        },
+        "models.UserUpdate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "phone_number": {
+                    "type": "string",
+                    "example": "9171234567"
+                }
+            }
+        },
+        "models.UsersPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "Pass it as the cursor query to get the next page. If it's empty, there's not any more user.",
+                    "type": "string",
+                    "example": "AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,85 @@
+// Package app wires the databases, the DAL and the services together, so the API server
+// and the command line tools are initialized in the same way.
+package app
+
+import (
+	"DMS/internal/config"
+	"DMS/internal/dal"
+	"DMS/internal/db"
+	"DMS/internal/graph"
+	"DMS/internal/hierarchy"
+	l "DMS/internal/logger"
+	"DMS/internal/services"
+	"DMS/internal/sms"
+	"time"
+)
+
+type App struct {
+	DAL       dal.DAL
+	Cache     dal.InMemoryDAL
+	Hierarchy *hierarchy.HierarchyTree
+	Services  services.Service
+	Logger    l.Logger
+}
+
+// Connect to the databases according to the config and create the services. If migrate
+// be true, apply the migrations that are not applied yet too. It panics if some
+// components couldn't be initialized.
+func New(cfg *config.Config, lgr l.Logger, migrate bool) *App {
+	// Init Redis
+	redisConnDetails := &db.RedisConnDetails{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+		Expire:   time.Second * time.Duration(cfg.Redis.ExpireSec),
+	}
+	redisDAL := dal.NewRedisInMemoeyDAL(redisConnDetails, lgr)
+
+	// Init PostgreSQL
+	psqlDAL := dal.NewPostgresDAL(psqlConnDetails(&cfg.Psql), redisDAL, lgr, migrate)
+
+	// Init hierarchy tree
+	graphStorage := graph.NewInMemoryDBStorage(redisDAL, []byte("edge"), lgr)
+	dynamicGraph := graph.NewDynamicGraph(graphStorage, lgr)
+	hierarchyTree := hierarchy.NewHierarchyTree(dynamicGraph, lgr)
+
+	// Init SMS sender
+	var smsSender sms.SMSSender
+	switch cfg.SMS.Sender {
+	case "file":
+		smsSender = sms.NewFileSMSSender(cfg.SMS.FilePath)
+	case "console":
+		smsSender = sms.NewConsoleSMSSender(lgr)
+	default:
+		lgr.Panicf("Unknown SMS sender %s", cfg.SMS.Sender)
+	}
+
+	return &App{
+		DAL:       psqlDAL,
+		Cache:     redisDAL,
+		Hierarchy: hierarchyTree,
+		Services:  services.NewService(&psqlDAL, hierarchyTree, redisDAL, smsSender, cfg, lgr),
+		Logger:    lgr,
+	}
+}
+
+// Connect to the PostgreSQL database without migrating it. It's used to manage the
+// migrations.
+func NewPsqlDB(cfg *config.Config, lgr l.Logger) *db.PSQLDB {
+	conn := psqlConnDetails(&cfg.Psql)
+	psqlDB := db.NewPsqlConn(&conn, false, lgr)
+	return &psqlDB
+}
+
+func psqlConnDetails(cfg *config.PsqlConfig) db.PsqlConnDetails {
+	return db.PsqlConnDetails{
+		Host:            cfg.Host,
+		Port:            cfg.Port,
+		Username:        cfg.User,
+		Password:        cfg.Password,
+		DB:              cfg.DB,
+		MaxConnLifetime: time.Minute * time.Duration(cfg.ConnMaxLifetimeMin),
+		MaxIdleConns:    cfg.MaxIdleConns,
+		MAxOpenConns:    cfg.MaxOpenConns,
+	}
+}
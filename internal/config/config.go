@@ -0,0 +1,179 @@
+// Package config loads the configuration of the API server and dmsctl from a YAML file
+// and the environment variables and validates it.
+//
+// Each value has a default, then it's set by the YAML file (if there's any) and at the
+// end by its environment variable (if it's set and not empty). Names of the environment
+// variables are in the env tags of the fields.
+package config
+
+// Values of the fields with secret tag are redacted on printing the config.
+type Config struct {
+	App         AppConfig         `yaml:"app"`
+	HTTP        HTTPConfig        `yaml:"http"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	Psql        PsqlConfig        `yaml:"psql"`
+	Redis       RedisConfig       `yaml:"redis"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	OTP         OTPConfig         `yaml:"otp"`
+	ObjectToken ObjectTokenConfig `yaml:"object_token"`
+	SMS         SMSConfig         `yaml:"sms"`
+	Upload      UploadConfig      `yaml:"upload"`
+	Hierarchy   HierarchyConfig   `yaml:"hierarchy"`
+}
+
+type AppConfig struct {
+	// It could be development or production. The .env file is loaded except in production
+	// mode, so just APP_MODE environment variable could disable loading it.
+	Mode string `yaml:"mode" env:"APP_MODE"`
+	// One of debug, info, warn, error, fatal, panic or none
+	LogLevel string `yaml:"log_level" env:"LOG_LEVEL"`
+}
+
+type HTTPConfig struct {
+	Port int `yaml:"port" env:"GIN_PORT"`
+	// It could be debug, release or test.
+	GinMode string `yaml:"gin_mode" env:"GIN_MODE"`
+	// In the environment variable, origins are separated by space.
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+type GRPCConfig struct {
+	Port int `yaml:"port" env:"GRPC_PORT"`
+}
+
+type PsqlConfig struct {
+	Host     string `yaml:"host" env:"PSQL_HOST"`
+	Port     int    `yaml:"port" env:"PSQL_PORT"`
+	User     string `yaml:"user" env:"PSQL_USER"`
+	Password string `yaml:"password" env:"PSQL_PASSWORD" secret:"true"`
+	DB       string `yaml:"db" env:"PSQL_DB"`
+	// Size of the connection pool
+	MaxOpenConns int `yaml:"max_open_conns" env:"PSQL_MAX_OPEN_CONNS"`
+	MaxIdleConns int `yaml:"max_idle_conns" env:"PSQL_MAX_IDLE_CONNS"`
+	// Maximum lifetime of each connection (in minutes)
+	ConnMaxLifetimeMin int `yaml:"conn_max_lifetime_min" env:"PSQL_CONN_MAX_LIFETIME_MIN"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr" env:"REDIS_ADDR"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+	// Maximum time a key-value would be kept in the cache. (In seconds) Zero means the
+	// key-value will never expire.
+	ExpireSec int `yaml:"expire_sec" env:"REDIS_EXPIRE"`
+}
+
+type JWTConfig struct {
+	// Directory of JWT keys. If it's set, the other keys are ignored. Each key is stored as
+	// "<key id>.key" (private key) or "<key id>.pub" (public key just for verifying old JWTs).
+	// JWTs signed by private_key before moving to the keys directory are verified by "env.pub".
+	KeysDir string `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+	// Id of the key that signs new JWTs. If it's empty, the newest private key is used.
+	ActiveKeyID string `yaml:"active_key_id" env:"JWT_ACTIVE_KEY_ID"`
+	// PEM encoded key pair. If they're empty, they're read from the key files.
+	PrivateKey     string `yaml:"private_key" env:"JWT_PRIVATE_KEY" secret:"true"`
+	PublicKey      string `yaml:"public_key" env:"JWT_PUBLIC_KEY"`
+	PrivateKeyFile string `yaml:"private_key_file" env:"JWT_PRIVATE_KEY_FILE_PATH"`
+	PublicKeyFile  string `yaml:"public_key_file" env:"JWT_PUBLIC_KEY_FILE_PATH"`
+	// Time the JWT (access token) expires after it is issued (in minutes).
+	LifetimeMin int `yaml:"lifetime_min" env:"JWT_EXPIRED_TIME_MIN"`
+	// Time the refresh token expires after it is issued (in minutes). A session expires if
+	// it isn't refreshed in this period.
+	RefreshTokenLifetimeMin int `yaml:"refresh_token_lifetime_min" env:"REFRESH_TOKEN_EXPIRED_TIME_MIN"`
+}
+
+// One-time codes of phone based login
+type OTPConfig struct {
+	// Number of digits of the code
+	Length int `yaml:"length" env:"OTP_LENGTH"`
+	// Time the code expires after it is generated (in seconds).
+	ExpireSec int `yaml:"expire_sec" env:"OTP_EXPIRE_SEC"`
+	// Maximum number of codes could be requested for a phone number in each window.
+	MaxRequests      int `yaml:"max_requests" env:"OTP_MAX_REQUESTS"`
+	RequestWindowSec int `yaml:"request_window_sec" env:"OTP_REQUEST_WINDOW_SEC"`
+	// Maximum number of codes could be requested from an IP address in each window.
+	MaxRequestsPerIP int `yaml:"max_requests_per_ip" env:"OTP_MAX_REQUESTS_PER_IP"`
+	// Maximum number of wrong codes could be entered before the code is revoked.
+	MaxAttempts int `yaml:"max_attempts" env:"OTP_MAX_ATTEMPTS"`
+}
+
+type ObjectTokenConfig struct {
+	// Secret key that object tokens (references to downloadable files) are signed with.
+	Secret string `yaml:"secret" env:"OBJECT_TOKEN_SECRET" secret:"true"`
+	// Time the object tokens expire after they're issued (in minutes).
+	LifetimeMin int `yaml:"lifetime_min" env:"OBJECT_TOKEN_EXPIRED_TIME_MIN"`
+}
+
+type SMSConfig struct {
+	// It could be console (writes SMSs in the logs) or file (appends SMSs to FilePath).
+	Sender   string `yaml:"sender" env:"SMS_SENDER"`
+	FilePath string `yaml:"file_path" env:"SMS_FILE_PATH"`
+}
+
+// Upload policy. All sizes are in Kbytes and zero quotas and MaxFiles mean unlimited.
+type UploadConfig struct {
+	ImageMaxSizeKB uint64 `yaml:"image_max_size_kb" env:"UPLOAD_IMAGE_MAX_SIZE_KB"`
+	VideoMaxSizeKB uint64 `yaml:"video_max_size_kb" env:"UPLOAD_VIDEO_MAX_SIZE_KB"`
+	AudioMaxSizeKB uint64 `yaml:"audio_max_size_kb" env:"UPLOAD_AUDIO_MAX_SIZE_KB"`
+	// In the environment variables, extensions are separated by space.
+	ImageExtensions []string `yaml:"image_extensions" env:"UPLOAD_IMAGE_EXTENSIONS"`
+	VideoExtensions []string `yaml:"video_extensions" env:"UPLOAD_VIDEO_EXTENSIONS"`
+	AudioExtensions []string `yaml:"audio_extensions" env:"UPLOAD_AUDIO_EXTENSIONS"`
+	// Maximum number of files could be uploaded at once.
+	MaxFiles uint `yaml:"max_files" env:"UPLOAD_MAX_FILES"`
+	// Maximum total size of the files of each event and the files uploaded by each job position.
+	EventQuotaKB uint64 `yaml:"event_quota_kb" env:"UPLOAD_EVENT_QUOTA_KB"`
+	JPQuotaKB    uint64 `yaml:"jp_quota_kb" env:"UPLOAD_JP_QUOTA_KB"`
+	// Shared secret the file-transfer service sends when it confirms uploads, in the
+	// X-Service-Token header over HTTP or the x-service-token metadata over gRPC. If it's
+	// empty, upload confirmations are rejected.
+	CallbackSecret string `yaml:"callback_secret" env:"UPLOAD_CALLBACK_SECRET" secret:"true"`
+}
+
+type HierarchyConfig struct {
+	// Number of job positions fetched from the database in each batch on loading the
+	// hierarchy graph.
+	BatchSize int `yaml:"batch_size" env:"HIERARCHY_BATCH_SIZE"`
+}
+
+// Return the config with default values. Values without a reasonable default (e.g.
+// database names and secrets) are empty.
+func Default() *Config {
+	return &Config{
+		App:  AppConfig{Mode: "development", LogLevel: "debug"},
+		HTTP: HTTPConfig{Port: 8080, GinMode: "debug"},
+		GRPC: GRPCConfig{Port: 50051},
+		Psql: PsqlConfig{
+			Host:               "localhost",
+			Port:               5432,
+			MaxOpenConns:       5,
+			MaxIdleConns:       5,
+			ConnMaxLifetimeMin: 60,
+		},
+		Redis: RedisConfig{Addr: "localhost:6379"},
+		JWT: JWTConfig{
+			LifetimeMin:             15,
+			RefreshTokenLifetimeMin: 43200,
+		},
+		OTP: OTPConfig{
+			Length:           6,
+			ExpireSec:        120,
+			MaxRequests:      3,
+			RequestWindowSec: 600,
+			MaxRequestsPerIP: 20,
+			MaxAttempts:      5,
+		},
+		ObjectToken: ObjectTokenConfig{LifetimeMin: 60},
+		SMS:         SMSConfig{Sender: "console", FilePath: "sms.log"},
+		Upload: UploadConfig{
+			ImageMaxSizeKB:  10 * 1024,
+			VideoMaxSizeKB:  500 * 1024,
+			AudioMaxSizeKB:  50 * 1024,
+			ImageExtensions: []string{"jpg", "jpeg", "png", "gif", "webp"},
+			VideoExtensions: []string{"mp4", "mkv", "webm", "mov"},
+			AudioExtensions: []string{"mp3", "ogg", "wav", "m4a"},
+			MaxFiles:        10,
+		},
+		Hierarchy: HierarchyConfig{BatchSize: 500},
+	}
+}
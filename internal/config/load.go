@@ -0,0 +1,261 @@
+package config
+
+import (
+	l "DMS/internal/logger"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variable of the config file path that is used if the path is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Load the config from the YAML file in path and the environment variables, then
+// validate it. If path is empty, CONFIG_FILE environment variable is used and if it's
+// empty too, just the defaults and the environment variables are used. Except in
+// production mode, the .env file (if exists) is loaded into the environment variables.
+// All invalid values are reported together in the returned error.
+func Load(path string) (*Config, error) {
+	config, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.JWT.loadKeyFiles(); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Read the config like Load, but the JWT key files are not read and the values are not
+// validated. It's used by the commands that need just a part of the config, so they
+// must validate the part they use. (e.g. by ValidatePsql)
+func Read(path string) (*Config, error) {
+	if os.Getenv("APP_MODE") != "production" {
+		if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to load .env file: %s", err.Error())
+		}
+	}
+
+	config := Default()
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %s", err.Error())
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid config file %s: %s", path, err.Error())
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(config).Elem()); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Set the fields with env tag from their environment variables. Empty variables are ignored.
+func applyEnv(v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		field, fieldType := v.Field(i), v.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+		name := fieldType.Tag.Get("env")
+		value := os.Getenv(name)
+		if name == "" || value == "" {
+			continue
+		}
+		if err := setFromString(field, value); err != nil {
+			return fmt.Errorf("invalid value of environment variable %s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+func setFromString(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(strings.Fields(value)))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Read the key pair from the key files if the keys directory and the keys are not set.
+func (c *JWTConfig) loadKeyFiles() error {
+	if c.KeysDir != "" {
+		return nil
+	}
+	if c.PrivateKey == "" && c.PrivateKeyFile != "" {
+		key, err := os.ReadFile(c.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read JWT private key: %s", err.Error())
+		}
+		c.PrivateKey = string(key)
+	}
+	if c.PublicKey == "" && c.PublicKeyFile != "" {
+		key, err := os.ReadFile(c.PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read JWT public key: %s", err.Error())
+		}
+		c.PublicKey = string(key)
+	}
+	return nil
+}
+
+// Invalid values found by checking the config
+type validator struct {
+	errs []error
+}
+
+// Add the error if the value is not valid.
+func (v *validator) check(isValid bool, format string, args ...any) {
+	if !isValid {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+// Return an error containing all invalid values or nil if there's not any.
+func (v *validator) err() error {
+	if len(v.errs) > 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(v.errs...))
+	}
+	return nil
+}
+
+func isPort(port int) bool { return port > 0 && port < 65536 }
+
+// Check all values of the config and return an error containing all invalid values.
+func (c *Config) Validate() error {
+	v := &validator{}
+	check := v.check
+
+	check(c.App.Mode == "development" || c.App.Mode == "production",
+		"app.mode: must be development or production, not %q", c.App.Mode)
+	_, err := l.ParseLogLevel(c.App.LogLevel)
+	check(err == nil, "app.log_level: must be one of debug, info, warn, error, fatal, panic or none, not %q",
+		c.App.LogLevel)
+	check(isPort(c.HTTP.Port), "http.port: %d is not a valid port", c.HTTP.Port)
+	check(c.HTTP.GinMode == "debug" || c.HTTP.GinMode == "release" || c.HTTP.GinMode == "test",
+		"http.gin_mode: must be debug, release or test, not %q", c.HTTP.GinMode)
+	check(isPort(c.GRPC.Port), "grpc.port: %d is not a valid port", c.GRPC.Port)
+
+	c.Psql.validate(v)
+
+	check(c.Redis.Addr != "", "redis.addr: is required")
+	check(c.Redis.DB >= 0, "redis.db: must not be negative")
+	check(c.Redis.ExpireSec >= 0, "redis.expire_sec: must not be negative")
+
+	check(c.JWT.KeysDir != "" || (c.JWT.PrivateKey != "" && c.JWT.PublicKey != ""),
+		"jwt: keys_dir or both private_key and public_key (or their files) are required")
+	check(c.JWT.LifetimeMin > 0, "jwt.lifetime_min: must be positive")
+	check(c.JWT.RefreshTokenLifetimeMin > 0, "jwt.refresh_token_lifetime_min: must be positive")
+
+	check(c.OTP.Length >= 4 && c.OTP.Length <= 10, "otp.length: must be between 4 and 10")
+	check(c.OTP.ExpireSec > 0, "otp.expire_sec: must be positive")
+	check(c.OTP.MaxRequests > 0, "otp.max_requests: must be positive")
+	check(c.OTP.RequestWindowSec > 0, "otp.request_window_sec: must be positive")
+	check(c.OTP.MaxRequestsPerIP > 0, "otp.max_requests_per_ip: must be positive")
+	check(c.OTP.MaxAttempts > 0, "otp.max_attempts: must be positive")
+
+	check(c.ObjectToken.Secret != "", "object_token.secret: is required")
+	check(c.ObjectToken.LifetimeMin > 0, "object_token.lifetime_min: must be positive")
+
+	check(c.SMS.Sender == "console" || c.SMS.Sender == "file",
+		"sms.sender: must be console or file, not %q", c.SMS.Sender)
+	check(c.SMS.Sender != "file" || c.SMS.FilePath != "", "sms.file_path: is required for file sender")
+
+	check(c.Upload.ImageMaxSizeKB > 0, "upload.image_max_size_kb: must be positive")
+	check(c.Upload.VideoMaxSizeKB > 0, "upload.video_max_size_kb: must be positive")
+	check(c.Upload.AudioMaxSizeKB > 0, "upload.audio_max_size_kb: must be positive")
+	// Files with other extensions are rejected, so an empty list rejects all files of its type.
+	check(len(c.Upload.ImageExtensions) > 0, "upload.image_extensions: must not be empty")
+	check(len(c.Upload.VideoExtensions) > 0, "upload.video_extensions: must not be empty")
+	check(len(c.Upload.AudioExtensions) > 0, "upload.audio_extensions: must not be empty")
+
+	check(c.Hierarchy.BatchSize > 0, "hierarchy.batch_size: must be positive")
+	return v.err()
+}
+
+// Check just the values needed to connect to PostgreSQL, e.g. for managing the
+// migrations, and return an error containing all invalid values.
+func (c *Config) ValidatePsql() error {
+	v := &validator{}
+	c.Psql.validate(v)
+	return v.err()
+}
+
+func (c *PsqlConfig) validate(v *validator) {
+	v.check(c.Host != "", "psql.host: is required")
+	v.check(isPort(c.Port), "psql.port: %d is not a valid port", c.Port)
+	v.check(c.User != "", "psql.user: is required")
+	v.check(c.DB != "", "psql.db: is required")
+	v.check(c.MaxOpenConns > 0, "psql.max_open_conns: must be positive")
+	v.check(c.MaxIdleConns >= 0 && c.MaxIdleConns <= c.MaxOpenConns,
+		"psql.max_idle_conns: must be between 0 and max_open_conns")
+	v.check(c.ConnMaxLifetimeMin >= 0, "psql.conn_max_lifetime_min: must not be negative")
+}
+
+// Write the config as YAML to w. Values of the secrets are redacted.
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	redact(reflect.ValueOf(&redacted).Elem())
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&redacted); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Replace values of the non-empty fields with secret tag.
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			redact(field)
+		} else if v.Type().Field(i).Tag.Get("secret") == "true" && field.String() != "" {
+			field.SetString("<redacted>")
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Return a valid config with the default values.
+func validConfig() *Config {
+	c := Default()
+	c.Psql.User, c.Psql.DB = "dms", "dms"
+	c.JWT.KeysDir = "keys"
+	c.ObjectToken.Secret = "object-secret"
+	return c
+}
+
+// Write the YAML config in a temporary file and return its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %s", err.Error())
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	// The .env file is not loaded in production mode.
+	t.Setenv("APP_MODE", "production")
+	t.Setenv("PSQL_USER", "")
+	t.Setenv("PSQL_DB", "")
+	t.Setenv("JWT_KEYS_DIR", "env-keys")
+	t.Setenv("OBJECT_TOKEN_SECRET", "env-secret")
+	t.Setenv("GIN_PORT", "9090")
+	t.Setenv("UPLOAD_IMAGE_EXTENSIONS", "png  jpg")
+	t.Setenv("GRPC_PORT", "")
+	path := writeConfigFile(t, `
+http:
+  port: 8000
+grpc:
+  port: 6000
+psql:
+  user: yaml-user
+  db: yaml-db
+jwt:
+  keys_dir: yaml-keys
+`)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	tests := []struct {
+		name            string
+		value, expected any
+	}{
+		{name: "default", value: c.Psql.Port, expected: 5432},
+		{name: "YAML overrides default", value: c.GRPC.Port, expected: 6000},
+		{name: "YAML without env", value: c.Psql.User, expected: "yaml-user"},
+		{name: "env overrides YAML", value: c.HTTP.Port, expected: 9090},
+		{name: "env overrides YAML string", value: c.JWT.KeysDir, expected: "env-keys"},
+		{name: "env overrides default", value: c.ObjectToken.Secret, expected: "env-secret"},
+		{name: "env list separated by space", value: strings.Join(c.Upload.ImageExtensions, ","), expected: "png,jpg"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.value != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, test.value)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Setenv("APP_MODE", "production")
+	tests := []struct {
+		name string
+		env  map[string]string
+		yaml string
+		// Expected part of the error
+		err string
+	}{
+		{name: "invalid int", env: map[string]string{"GIN_PORT": "http"},
+			err: "invalid value of environment variable GIN_PORT"},
+		{name: "negative uint", env: map[string]string{"UPLOAD_MAX_FILES": "-1"},
+			err: "invalid value of environment variable UPLOAD_MAX_FILES"},
+		{name: "unknown YAML field", yaml: "http:\n  prot: 80\n", err: "field prot not found"},
+		{name: "invalid value", env: map[string]string{"GIN_MODE": "fast"}, err: "http.gin_mode"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			_, err := Load(writeConfigFile(t, test.yaml))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		// Expected parts of the error. If it's empty, the config must be valid.
+		errs []string
+	}{
+		{name: "valid config", change: func(c *Config) {}},
+		{
+			name: "all invalid values are reported",
+			change: func(c *Config) {
+				c.HTTP.Port = 0
+				c.Psql.User = ""
+				c.JWT.KeysDir = ""
+				c.ObjectToken.Secret = ""
+			},
+			errs: []string{"http.port", "psql.user", "jwt: keys_dir", "object_token.secret"},
+		},
+		{
+			name:   "empty extensions",
+			change: func(c *Config) { c.Upload.ImageExtensions, c.Upload.AudioExtensions = nil, []string{} },
+			errs:   []string{"upload.image_extensions", "upload.audio_extensions"},
+		},
+		{
+			name:   "key pair instead of keys directory",
+			change: func(c *Config) { c.JWT.KeysDir, c.JWT.PrivateKey, c.JWT.PublicKey = "", "private", "public" },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := validConfig()
+			test.change(c)
+			err := c.Validate()
+			if len(test.errs) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error %s", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v, got nil", test.errs)
+			}
+			for _, expected := range test.errs {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q, got %s", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePsql(t *testing.T) {
+	c := validConfig()
+	c.JWT.KeysDir, c.ObjectToken.Secret = "", ""
+	if err := c.ValidatePsql(); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	c.Psql.DB = ""
+	if err := c.ValidatePsql(); err == nil || !strings.Contains(err.Error(), "psql.db") {
+		t.Errorf("expected error of psql.db, got %v", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	c := validConfig()
+	c.Psql.Password = "psql-password"
+	c.JWT.PrivateKey = "private-key"
+	c.Upload.CallbackSecret = ""
+	var buffer bytes.Buffer
+	if err := c.Print(&buffer); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	printed := buffer.String()
+	for _, secret := range []string{"psql-password", "private-key", "object-secret"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("expected secret %q to be redacted, got\n%s", secret, printed)
+		}
+	}
+	if strings.Count(printed, "<redacted>") != 3 {
+		t.Errorf("expected 3 redacted secrets (empty ones are not redacted), got\n%s", printed)
+	}
+	if !strings.Contains(printed, "user: dms") {
+		t.Errorf("expected other values to be printed, got\n%s", printed)
+	}
+	if c.Psql.Password != "psql-password" {
+		t.Errorf("expected the config not to be changed")
+	}
+}
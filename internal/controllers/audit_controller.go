@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	s "DMS/internal/services"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHttp struct {
+	auditService s.AuditService
+	logger       l.Logger
+}
+
+func newAuditHttp(auditService s.AuditService, logger l.Logger) AuditHttp {
+	return AuditHttp{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// @Security BearerAuth
+// @Summary Get the audit log
+// @Description Get last N events of the audit log that match the filters. Just actions done with the job position or its nested childs are returned, unless the job position be admin. To get the next page, pass the returned next_cursor as the cursor query.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't have any job position."
+// @Param limit query int false "Limit of events to fetch. Default is 20. Max is 50."
+// @Param cursor query string false "Cursor of the page returned in the previous response. If it's empty, the first page is returned."
+// @Param action query string false "Just events of this action, e.g. doc.create"
+// @Param outcome query string false "Just events with this outcome" Enums(success, failure)
+// @Param actor_jp_id query string false "Just events done with this job position"
+// @Param target_id query string false "Just events done on this entity"
+// @Param created_from query int false "Just events created at or after this time. (Unix timestamp in seconds)"
+// @Param created_to query int false "Just events created at or before this time. (Unix timestamp in seconds)"
+// @Success 200 {object} HttpResponse{details=models.AuditPage} "Events and cursor of the next page"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "Jon position doesn't belong to current user."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /audit [get]
+func (h *AuditHttp) GetAuditEvents(c *gin.Context) {
+	queryParser := newQueryParser(c, h.logger)
+	limitDefaultValue := uint64(20)
+	limit, _ := queryParser.ParseUInt("limit", &limitDefaultValue)
+	maxLimit := uint64(50)
+	if *limit > maxLimit {
+		*limit = maxLimit
+	} else if *limit < 1 {
+		*limit = 1
+	}
+	cursor, err := queryParser.ParseCursor("cursor")
+	if err != nil {
+		return
+	}
+	filter, err := queryParser.ParseAuditFilter()
+	if err != nil {
+		return
+	}
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	jpID := getCallerJP(c, jwt, h.logger)
+	if jpID == nil {
+		return
+	}
+
+	events, nextCursor, err2 := h.auditService.GetAuditEvents(jwt.UserID, *jpID, cursor, *limit, filter)
+	if err2 == nil {
+		h.logger.Debugf("Fetched %d audit events for job position id %s. (limit: %d, cursor: %+v)",
+			len(*events), jpID.String(), *limit, cursor)
+		successResp(c, MsgSuccessAction, m.AuditPage{Events: *events, NextCursor: encodeNextCursor(nextCursor)})
+		return
+	}
+
+	switch code := err2.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to fetch audit events (%s)", err2.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to fetch audit events: %s", err2.Error())
+		customErrResp(c, hCJPNotMatchedUser, fmt.Sprintf(MsgNotFoundC, MsgJP), MsgCheckInfoAgain)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err2.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"DMS/internal/config"
 	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
@@ -39,18 +40,26 @@ type HttpConrtoller struct {
 	Doc        DocHttp
 	Middleware MiddlewareHttp
 	Session    SessionHttp
+	Search     SearchHttp
+	Role       RoleHttp
+	Upload     UploadHttp
+	Audit      AuditHttp
 	logger     l.Logger
 }
 
 // Create new controller layer based on HTTP protocol
-func NewHttpController(services s.Service, logger l.Logger) HttpConrtoller {
+func NewHttpController(services s.Service, cfg *config.Config, logger l.Logger) HttpConrtoller {
 	return HttpConrtoller{
 		User:       newUserHttp(services.User, logger),
 		JP:         newJPHttp(services.JP, logger),
 		Event:      newEventHttp(services.Event, logger),
 		Doc:        newDocHttp(services.Doc, logger),
-		Middleware: newMiddlewareHttp(services.Session, logger),
+		Middleware: newMiddlewareHttp(services.Session, cfg.HTTP.CORSAllowedOrigins, logger),
 		Session:    newSessionHttp(services.Session, logger),
+		Search:     newSearchHttp(services.Search, logger),
+		Role:       newRoleHttp(services.Role, logger),
+		Upload:     newUploadHttp(services.FilePer, cfg.Upload.CallbackSecret, logger),
+		Audit:      newAuditHttp(services.Audit, logger),
 		logger:     logger,
 	}
 }
@@ -97,6 +106,64 @@ const (
 	MsgSomeActionsFailed        = "خطایی در برخی بخش‌ها رخ داده است"
 	MsgRequiredValueC           = "مقدار %s الزامی است"
 	MsgIsNotValidC              = "مقدار %s اشتباه است"
+	MsgEvent                    = "رویداد"
+	MsgEventName                = "نام رویداد"
+	MsgApproval                 = "برگزیدگی رویداد"
+	MsgEventApproved            = "رویداد با موفقیت برگزیده شد"
+	MsgEventApprovalRevoked     = "برگزیدگی رویداد با موفقیت لغو شد"
+	MsgApproveNotAllowed        = "برگزیده کردن این رویداد برای شما مجاز نیست"
+	MsgEventUpdated             = "رویداد با موفقیت ویرایش شد"
+	MsgEventDeleted             = "رویداد با موفقیت حذف شد"
+	MsgNothingToUpdate          = "مقداری برای ویرایش ارسال نشده است"
+	MsgDoc                      = "مستند"
+	MsgDocVersion               = "نسخه مستند"
+	MsgMediaType                = "نوع فایل"
+	MsgFileNotAllowed           = "نوع، پسوند یا حجم فایل مجاز نیست"
+	MsgQuotaExceeded            = "فضای مجاز ذخیره فایل‌ها تمام شده است"
+	MsgFileNotInDoc             = "فایل مورد نظر از فایل‌های تایید شده این مستند نیست"
+	MsgDocUpdated               = "مستند با موفقیت ویرایش شد"
+	MsgDocDeleted               = "مستند با موفقیت حذف شد"
+	MsgDocRestored              = "مستند با موفقیت به نسخه مورد نظر بازگردانده شد"
+	MsgSearchQuery              = "عبارت جستجو"
+	MsgSearchType               = "نوع جستجو"
+	MsgInvalidTimeRange         = "زمان شروع بازه نباید بعد از زمان پایان آن باشد"
+	MsgParentJP                 = "سمت شغلی والد"
+	MsgJPUpdated                = "سمت شغلی با موفقیت ویرایش شد"
+	MsgJPMoved                  = "سمت شغلی با موفقیت جابجا شد"
+	MsgJPDisabled               = "سمت شغلی با موفقیت غیر فعال شد"
+	MsgJPEnabled                = "سمت شغلی با موفقیت فعال شد"
+	MsgJPDeleted                = "سمت شغلی با موفقیت حذف شد"
+	MsgJPHasChilds              = "سمت شغلی مورد نظر دارای زیرمجموعه است"
+	MsgRemoveChildsFirst        = "ابتدا زیرمجموعه‌های آن را حذف یا جابجا کنید"
+	MsgRole                     = "نقش"
+	MsgRoleCreated              = "نقش با موفقیت ایجاد شد"
+	MsgRoleDeleted              = "نقش با موفقیت حذف شد"
+	MsgRoleAssigned             = "نقش با موفقیت به سمت شغلی داده شد"
+	MsgRoleUnassigned           = "نقش با موفقیت از سمت شغلی گرفته شد"
+	MsgRoleExists               = "نقشی با این نام از قبل وجود دارد"
+	MsgBuiltinRole              = "نقش‌های پیش‌فرض سیستم قابل حذف نیستند"
+	MsgEventAccess              = "دسترسی رویداد"
+	MsgEventAccessGranted       = "دسترسی به رویداد با موفقیت داده شد"
+	MsgEventAccessRevoked       = "دسترسی به رویداد با موفقیت لغو شد"
+	MsgNotEventOwner            = "فقط ایجاد کننده رویداد می‌تواند دسترسی‌های آن را مدیریت کند"
+	MsgOTPSent                  = "کد ورود به شماره تلفن ارسال شد"
+	MsgWrongOTP                 = "کد ورود اشتباه است یا منقضی شده است"
+	MsgTooManyRequests          = "تعداد درخواست‌ها بیش از حد مجاز است"
+	MsgTryLater                 = "لطفا چند دقیقه دیگر مجددا تلاش نمایید"
+	MsgRequestNewOTP            = "لطفا کد ورود جدید درخواست کنید"
+	MsgSessionRefreshed         = "جلسه با موفقیت تمدید شد"
+	MsgLoginAgain               = "لطفا مجددا وارد شوید"
+	MsgSessionRevoked           = "جلسه با موفقیت لغو شد"
+	MsgSessionsRevoked          = "جلسه‌ها با موفقیت لغو شدند"
+	MsgUploadConfirmed          = "بارگذاری فایل با موفقیت تایید شد"
+	MsgInvalidServiceToken      = "توکن سرویس اشتباه است"
+	MsgUser                     = "کاربر"
+	MsgUserUpdated              = "کاربر با موفقیت ویرایش شد"
+	MsgUserDisabled             = "کاربر با موفقیت غیر فعال شد"
+	MsgUserEnabled              = "کاربر با موفقیت فعال شد"
+	MsgPhoneExists              = "کاربر دیگری با این شماره تلفن وجود دارد"
+	MsgCantChangeSelf           = "کاربر نمی‌تواند خود را غیر فعال یا فعال کند"
+	MsgEmptyNameOrPhone         = "نام و شماره تلفن کاربر نمی‌تواند خالی باشد"
 )
 
 // hC = http code
@@ -107,6 +174,7 @@ const (
 	hCJPNotMatchedUser = http.StatusForbidden
 	hCBadValue         = http.StatusBadRequest
 	hCParsingError     = http.StatusBadRequest
+	hCTooManyRequests  = http.StatusTooManyRequests
 )
 const authInfo = "AuthInfo"
 
@@ -116,6 +184,14 @@ type idResponse struct {
 	ID string `json:"id,omitempty" example:"8b2d1c6b-6c2c-4a8b-8b2d-1c6b6c2c4a8b"`
 }
 
+// Encode the cursor of the next page. If it be nil, return an empty string.
+func encodeNextCursor(cursor *m.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return cursor.Encode()
+}
+
 func newIDResponse(id m.ID) idResponse {
 	return idResponse{
 		ID: id.String(),
@@ -250,7 +326,7 @@ func (p *parseParam) parseUInt(paramKey string, dest *uint64) error {
 		badRequestResp(p.c, MsgBadValue, MsgParsingError)
 		return e.NewSError("the input parameter must be uint but it's not")
 	}
-	dest = &uint
+	*dest = uint
 	return nil
 }
 
@@ -267,7 +343,7 @@ func (p *parseParam) parseInt(paramKey string, dest *int64) error {
 		badRequestResp(p.c, MsgBadValue, MsgParsingError)
 		return e.NewSError("the input parameter must be int but it's not")
 	}
-	dest = &int
+	*dest = int
 	return nil
 }
 
@@ -372,6 +448,159 @@ func (p *queryParser) ParsePhone(queryKey string, defaultValue *m.PhoneNumber) (
 	return &phone, nil
 }
 
+// Parse the filters of lists of docs and events from the url queries. Absent queries
+// are not applied. If a query is invalid, sends HTTP bad request response and returns error.
+//
+// Supported queries: created_from, created_to (Unix timestamps in seconds), created_by,
+// event_id, region_id (ids), has_media (boolean) and media_type (0: image, 1: video, 2: audio).
+func (p *queryParser) ParseListFilter() (*m.ListFilter, error) {
+	filter := m.ListFilter{}
+	var err error
+	if filter.CreatedFrom, err = p.parseOptionalInt("created_from"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = p.parseOptionalInt("created_to"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBy, err = p.parseOptionalID("created_by"); err != nil {
+		return nil, err
+	}
+	if filter.EventID, err = p.parseOptionalID("event_id"); err != nil {
+		return nil, err
+	}
+	if filter.RegionID, err = p.parseOptionalID("region_id"); err != nil {
+		return nil, err
+	}
+	if filter.HasMedia, err = p.parseOptionalBool("has_media"); err != nil {
+		return nil, err
+	}
+	mediaType, err := p.parseOptionalInt("media_type")
+	if err != nil {
+		return nil, err
+	} else if mediaType != nil {
+		if *mediaType < 0 || !m.MediaType(*mediaType).IsValid() {
+			p.logger.Debugf("the media type %d is not valid", *mediaType)
+			badRequestResp(p.c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, MsgMediaType))
+			return nil, fmt.Errorf("the media type is not valid")
+		}
+		filter.MediaType = new(m.MediaType)
+		*filter.MediaType = m.MediaType(*mediaType)
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && *filter.CreatedFrom > *filter.CreatedTo {
+		p.logger.Debugf("created_from %d is after created_to %d", *filter.CreatedFrom, *filter.CreatedTo)
+		badRequestResp(p.c, MsgBadValue, MsgInvalidTimeRange)
+		return nil, fmt.Errorf("the time range is not valid")
+	}
+	return &filter, nil
+}
+
+// Parse the filters of the audit log from the url queries. Absent queries are not
+// applied. If a query is invalid, sends HTTP bad request response and returns error.
+//
+// Supported queries: action, outcome (success or failure), actor_jp_id, target_id (ids),
+// created_from and created_to (Unix timestamps in seconds).
+func (p *queryParser) ParseAuditFilter() (*m.AuditFilter, error) {
+	filter := m.AuditFilter{}
+	var err error
+	if action := p.c.Query("action"); action != "" {
+		filter.Action = new(m.AuditAction)
+		*filter.Action = m.AuditAction(action)
+	}
+	if outcome := p.c.Query("outcome"); outcome != "" {
+		if outcome != string(m.AuditSuccess) && outcome != string(m.AuditFailure) {
+			p.logger.Debugf("the outcome %s is not valid", outcome)
+			badRequestResp(p.c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, "outcome"))
+			return nil, fmt.Errorf("the outcome is not valid")
+		}
+		filter.Outcome = new(m.AuditOutcome)
+		*filter.Outcome = m.AuditOutcome(outcome)
+	}
+	if filter.ActorJPID, err = p.parseOptionalID("actor_jp_id"); err != nil {
+		return nil, err
+	}
+	if filter.TargetID, err = p.parseOptionalID("target_id"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedFrom, err = p.parseOptionalInt("created_from"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = p.parseOptionalInt("created_to"); err != nil {
+		return nil, err
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && *filter.CreatedFrom > *filter.CreatedTo {
+		p.logger.Debugf("created_from %d is after created_to %d", *filter.CreatedFrom, *filter.CreatedTo)
+		badRequestResp(p.c, MsgBadValue, MsgInvalidTimeRange)
+		return nil, fmt.Errorf("the time range is not valid")
+	}
+	return &filter, nil
+}
+
+// Parse the cursor of pagination from the url query. If the query is absent or empty,
+// return nil. If it's invalid, sends HTTP bad request response and returns error.
+func (p *queryParser) ParseCursor(queryKey string) (*m.Cursor, error) {
+	param := p.c.Query(queryKey)
+	if param == "" {
+		return nil, nil
+	}
+	cursor, err := m.DecodeCursor(param)
+	if err != nil {
+		p.logger.Debugf("the input param %s=\"%s\" is not a valid cursor. (%s)", queryKey, param, err)
+		badRequestResp(p.c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, queryKey))
+		return nil, fmt.Errorf("the input parameter %s is not a valid cursor", queryKey)
+	}
+	return cursor, nil
+}
+
+// Parse the optional query to an int. If the query is absent, return nil. If it's
+// invalid, sends HTTP bad request response and returns error.
+func (p *queryParser) parseOptionalInt(queryKey string) (*int64, error) {
+	param, ok := p.c.GetQuery(queryKey)
+	if !ok {
+		return nil, nil
+	}
+	number, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		p.logger.Debugf("the input param %s=\"%s\" must be int but it's not. (%s)", queryKey, param, err)
+		badRequestResp(p.c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, queryKey))
+		return nil, fmt.Errorf("the input parameter %s must be int but it's not", queryKey)
+	}
+	return &number, nil
+}
+
+// Parse the optional query to an ID. If the query is absent, return nil. If it's
+// invalid or empty, sends HTTP bad request response and returns error.
+func (p *queryParser) parseOptionalID(queryKey string) (*m.ID, error) {
+	param, ok := p.c.GetQuery(queryKey)
+	if !ok {
+		return nil, nil
+	}
+	id, err := m.ID{}.FromString2(param)
+	if err != nil || id.IsNil() {
+		p.logger.Debugf("the input param %s=\"%s\" must be a non-empty id but it's not. (%v)", queryKey, param, err)
+		badRequestResp(p.c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, queryKey))
+		return nil, fmt.Errorf("the input parameter %s must be a non-empty id but it's not", queryKey)
+	}
+	return &id, nil
+}
+
+// Parse the optional query to a boolean. If the query is absent, return nil. If it's
+// invalid, sends HTTP bad request response and returns error.
+func (p *queryParser) parseOptionalBool(queryKey string) (*bool, error) {
+	param, ok := p.c.GetQuery(queryKey)
+	if !ok {
+		return nil, nil
+	}
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		p.logger.Debugf("the input param %s=\"%s\" must be boolean but it's not. (%s)", queryKey, param, err)
+		badRequestResp(p.c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, queryKey))
+		return nil, fmt.Errorf("the input parameter %s must be boolean but it's not", queryKey)
+	}
+	return &value, nil
+}
+
 // Get parsed JWT from the authentication middleware. If there's not JWT,
 // the function, sends unauthorized response (code 401) to the client and returns nil.
 func getJWT(c *gin.Context, logger l.Logger) *m.JWT {
@@ -387,3 +616,27 @@ func getJWT(c *gin.Context, logger l.Logger) *m.JWT {
 	}
 	return authInfo
 }
+
+// Return the job position of the caller. The job position in the JWT has priority and
+// if it's nil, the job position is parsed from the "jpid" url query. If there's not any
+// job position, sends bad request response to the client and returns nil.
+func getCallerJP(c *gin.Context, jwt *m.JWT, logger l.Logger) *m.ID {
+	if !jwt.JPID.IsNil() {
+		return &jwt.JPID
+	}
+	jpID, err := newQueryParser(c, logger).ParseID("jpid", nil)
+	if err != nil {
+		logger.Debugf("Failed to parse job position id: %s", err.Error())
+		return nil
+	} else if jpID.IsNil() {
+		customErrResp(c, hCBadValue, MsgBadValue, fmt.Sprintf(MsgRequiredValueC, MsgJP))
+		return nil
+	}
+	return jpID
+}
+
+// Return address and user agent of the client that sent the request. They're stored in
+// the audit log.
+func getClientInfo(c *gin.Context) m.ClientInfo {
+	return m.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	s "DMS/internal/services"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var testLogger = l.NewSLogger(l.None, nil, io.Discard)
+
+// Call the handler with a GET request to the url. If jwt isn't nil, it's set as the
+// authentication info like the authentication middleware. Return the recorded response.
+func callHandler(t *testing.T, handler gin.HandlerFunc, url string, jwt *m.JWT, params ...gin.Param) (*httptest.ResponseRecorder, HttpResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, url, nil)
+	c.Params = params
+	if jwt != nil {
+		c.Set(authInfo, jwt)
+	}
+	handler(c)
+	var resp HttpResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse the response %q: %s", recorder.Body.String(), err.Error())
+	}
+	return recorder, resp
+}
+
+// It records the cursor of the last request and returns an empty page.
+type cursorEventService struct {
+	s.EventService
+	cursor *m.Cursor
+}
+
+func (s *cursorEventService) GetNLastEventsByJPID(userID, claimedJPID m.ID, cursor *m.Cursor, limit uint64, filter *m.ListFilter) (*[]m.Event, *m.Cursor, *e.Error) {
+	s.cursor = cursor
+	return &[]m.Event{}, nil, nil
+}
+
+func TestListCursor(t *testing.T) {
+	jwt := &m.JWT{UserID: m.ID(uuid.New()), JPID: m.ID(uuid.New())}
+	eventService := &cursorEventService{}
+	eventHttp := newEventHttp(eventService, testLogger)
+	docHttp := newDocHttp(nil, testLogger)
+	userHttp := newUserHttp(nil, testLogger)
+	auditHttp := newAuditHttp(nil, testLogger)
+	handlers := map[string]gin.HandlerFunc{
+		"/events": eventHttp.GetNLastEventsByJPID,
+		"/docs":   docHttp.GetNLastDocs,
+		"/users":  userHttp.GetUsers,
+		"/audit":  auditHttp.GetAuditEvents,
+	}
+	outOfRange := base64.RawURLEncoding.EncodeToString(append([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, make([]byte, 16)...))
+	for path, handler := range handlers {
+		for name, cursor := range map[string]string{"not base64": "!", "short": "AAAA", "out of range": outOfRange} {
+			t.Run(path+" "+name, func(t *testing.T) {
+				recorder, resp := callHandler(t, handler, path+"?jpid="+jwt.JPID.String()+"&cursor="+cursor, jwt)
+				if recorder.Code != http.StatusBadRequest || resp.Message != MsgBadValue {
+					t.Errorf("expected bad request, got %d %+v", recorder.Code, resp)
+				}
+			})
+		}
+	}
+
+	t.Run("valid cursor", func(t *testing.T) {
+		cursor := m.Cursor{CreatedAt: 1700000000123456, ID: m.ID(uuid.New())}
+		recorder, resp := callHandler(t, eventHttp.GetNLastEventsByJPID,
+			"/events?jpid="+jwt.JPID.String()+"&cursor="+cursor.Encode(), jwt)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected success, got %d %+v", recorder.Code, resp)
+		}
+		if eventService.cursor == nil || *eventService.cursor != cursor {
+			t.Errorf("expected cursor %+v to be passed to the service, got %+v", cursor, eventService.cursor)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
 	s "DMS/internal/services"
@@ -23,7 +24,7 @@ func newDocHttp(docService s.DocService, logger l.Logger) DocHttp {
 
 // @Security BearerAuth
 // @Summary Create document
-// @Description Create document for specified event and current user in the current time and return its id.
+// @Description Create document for specified event and current user in the current time and return its id. Just the owner of the event and its contributors could create documents for it.
 // @Tags document
 // @Accept json
 // @Produce json
@@ -31,8 +32,8 @@ func newDocHttp(docService s.DocService, logger l.Logger) DocHttp {
 // @Success 200 {object} HttpResponse{details=idResponse} "Success creating document. Returns the document id."
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 404 {object} HttpResponse{details=string} "Not found error. The job position doesn't belongs to current user."
-// @Failure 403 {object} HttpResponse{details=string} "Forbidden error. The user is disabled."
-// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Failure 403 {object} HttpResponse{details=string} "Forbidden error. The user is disabled, the job position is not allowed to create docs or the storage quota is exceeded."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error. Also if a file is not allowed by the upload policy or its size is missing."
 // @Router /docs [post]
 func (h *DocHttp) CreateDoc(c *gin.Context) {
 	doc := m.Doc{}
@@ -44,7 +45,7 @@ func (h *DocHttp) CreateDoc(c *gin.Context) {
 		return
 	}
 
-	id, err := h.docService.CreateDoc(&doc, jwt.UserID)
+	id, err := h.docService.CreateDoc(&doc, jwt.UserID, getClientInfo(c))
 	if err == nil {
 		h.logger.Debugf("Created doc with id %s successfully", id.String())
 		successResp(c, MsgDocCreated, newIDResponse(*id))
@@ -62,6 +63,15 @@ func (h *DocHttp) CreateDoc(c *gin.Context) {
 		h.logger.Debugf("The job position %s can't create a doc for the event %s: %s",
 			doc.CreatedBy.String(), doc.EventID.String(), err.Error())
 		forbiddenErrResp(c, fmt.Sprintf(MsgCreationNotAllowC, MsgDocs), MsgEventOwnerMismatchedJP)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to create doc: %s", err.Error())
+		forbiddenErrResp(c, fmt.Sprintf(MsgCreationNotAllowC, MsgDocs), MsgNotPermission)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to create doc: %s", err.Error())
+		badRequestResp(c, MsgBadValue, MsgFileNotAllowed)
+	case s.SEQuotaExceeded:
+		h.logger.Debugf("Failed to create doc: %s", err.Error())
+		forbiddenErrResp(c, MsgQuotaExceeded, MsgReferAdmin)
 	default:
 		h.logger.Panicf("Unexpected error code %d in CreateDoc controller: %s", code, err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
@@ -122,6 +132,9 @@ func (h *DocHttp) GetNLastDocsByEventID(c *gin.Context) {
 		h.logger.Debugf("Job position with id %s is not ancestor of job position who created event with id %s: %s",
 			jPID.String(), eventID.String(), err2.Error())
 		forbiddenErrResp(c, MsgNotPermission, MsgNotAncestor)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to get docs for event %s: %s", eventID.String(), err2.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
 	default:
 		h.logger.Panicf("Unexpected error code %d in GetNLastDocsByEventID controller: %s", code, err2.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
@@ -130,14 +143,21 @@ func (h *DocHttp) GetNLastDocsByEventID(c *gin.Context) {
 
 // @Security BearerAuth
 // @Summary Get n last documents that are accessible for the user who sent the request.
-// @Description Get n last documents (according to the limit and offset values) that are accessible for the user (and one of his job positions) who sent the request. (If the job position is admin, he has access to all documents.) For example, offset = 20 and limit = 10 would return 10 records (documents 21-30).
+// @Description Get n last documents that are accessible for the user (and one of his job positions) who sent the request and match the filters. (If the job position is admin, he has access to all documents.) To get the next page, pass the returned next_cursor as the cursor query. The pages are stable even when new documents are created.
 // @Tags document
 // @Accept json
 // @Produce json
 // @Param limit query int false "Number of documents to get. Maximum is 50"
-// @Param offset query int false "Number of documents to skip"
+// @Param cursor query string false "Cursor of the page returned in the previous response. If it's empty, the first page is returned."
 // @Param jpid query string true "Job position id"
-// @Success 200 {object} HttpResponse{details=[]models.DocWithSomeDetails} "Documents"
+// @Param created_from query int false "Just items created at or after this time. (Unix timestamp in seconds)"
+// @Param created_to query int false "Just items created at or before this time. (Unix timestamp in seconds)"
+// @Param created_by query string false "Just items created by this job position"
+// @Param event_id query string false "Just items of this event"
+// @Param region_id query string false "Just items created by job positions of this region"
+// @Param has_media query bool false "Just items that have (or don't have) multimedia files"
+// @Param media_type query int false "Just items that have a multimedia file with this type" Enums(0, 1, 2)
+// @Success 200 {object} HttpResponse{details=models.DocsPage} "Documents and cursor of the next page"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 403 {object} HttpResponse{details=string} "Forbidden error. The user is not authorized to access this resource, job position doesn't belongs to the user or etc."
 // @Failure 401 {object} HttpResponse{details=string} "The user is not authorized"
@@ -154,8 +174,14 @@ func (h *DocHttp) GetNLastDocs(c *gin.Context) {
 		*limit = 1
 	}
 
-	offsetDefaultValue := uint64(0)
-	offset, _ := queryParser.ParseUInt("offset", &offsetDefaultValue)
+	cursor, err := queryParser.ParseCursor("cursor")
+	if err != nil {
+		return
+	}
+	filter, err := queryParser.ParseListFilter()
+	if err != nil {
+		return
+	}
 	jwt := getJWT(c, h.logger)
 	if jwt == nil {
 		return
@@ -169,16 +195,16 @@ func (h *DocHttp) GetNLastDocs(c *gin.Context) {
 		return
 	}
 
-	h.logger.Debugf("Getting last %d docs (with offset %d)", *limit, *offset)
-	docs, err2 := h.docService.GetNLastDocs(jwt.UserID, *jpID, *limit, *offset)
+	h.logger.Debugf("Getting last %d docs (after cursor %+v)", *limit, cursor)
+	docs, nextCursor, err2 := h.docService.GetNLastDocs(jwt.UserID, *jpID, cursor, *limit, filter)
 	if err2 == nil {
-		h.logger.Debugf("Got last %d docs (with offset %d) successfully", *limit, *offset)
-		successResp(c, MsgSuccessAction, *docs)
+		h.logger.Debugf("Got last %d docs (after cursor %+v) successfully", len(*docs), cursor)
+		successResp(c, MsgSuccessAction, m.DocsPage{Docs: *docs, NextCursor: encodeNextCursor(nextCursor)})
 		return
 	}
 	switch code := err2.GetCode(); code {
 	case s.SEDBError:
-		h.logger.Errorf("Failed to get last %d docs (with offset %d): %s", *limit, *offset, err2.Error())
+		h.logger.Errorf("Failed to get last %d docs (after cursor %+v): %s", *limit, cursor, err2.Error())
 		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
 	case s.SEJPNotMatchedUser:
 		h.logger.Debugf("Job position doesn't belong the user: %s", err2.Error())
@@ -188,3 +214,208 @@ func (h *DocHttp) GetNLastDocs(c *gin.Context) {
 		serverErrResp(c, MsgServerError, MsgTryAgain)
 	}
 }
+
+// @Security BearerAuth
+// @Summary Get document
+// @Description Get the document together with name of its event and title of the job position created it. Just the creator of the document and his ancestors could read it.
+// @Tags document
+// @Produce json
+// @Param doc_id path string true "Document id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=models.DocWithSomeDetails} "The document"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The document doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the creator of the document or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /docs/{doc_id} [get]
+func (h *DocHttp) GetDoc(c *gin.Context) {
+	docID, jpID, jwt := h.parseDocParams(c)
+	if jwt == nil {
+		return
+	}
+
+	doc, err := h.docService.GetDoc(jwt.UserID, *jpID, *docID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, doc)
+		return
+	}
+	h.handleDocAccessErr(c, err, "get doc")
+}
+
+// @Security BearerAuth
+// @Summary Edit document
+// @Description Edit context and/or multimedia files of the document. Just the creator of the document and his ancestors could edit it. The previous context and multimedia files are kept as a new version of the document. The confirmed multimedia files could just be reordered or removed by their file names and pending files are kept. New files are added by uploading them.
+// @Tags document
+// @Accept json
+// @Produce json
+// @Param doc_id path string true "Document id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Param update body models.DocUpdate true "Fields to edit. Omitted fields remain unchanged."
+// @Success 200 {object} HttpResponse{details=string} "Success editing document"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The document doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the creator of the document or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error. Also if a file is not a confirmed file of the document."
+// @Router /docs/{doc_id} [patch]
+func (h *DocHttp) UpdateDoc(c *gin.Context) {
+	docID, jpID, jwt := h.parseDocParams(c)
+	if jwt == nil {
+		return
+	}
+	update := m.DocUpdate{}
+	if err := parseValidateJSON(c, &update, h.logger); err != nil {
+		return
+	}
+
+	err := h.docService.UpdateDoc(jwt.UserID, *jpID, *docID, &update, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s edited doc %s.", jpID.String(), docID.String())
+		successResp(c, MsgDocUpdated, MsgSuccessAction)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEEmpty:
+		badRequestResp(c, MsgBadValue, MsgNothingToUpdate)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to edit doc: %s", err.Error())
+		badRequestResp(c, MsgBadValue, MsgFileNotInDoc)
+	default:
+		h.handleDocAccessErr(c, err, "edit doc")
+	}
+}
+
+// @Security BearerAuth
+// @Summary Delete document
+// @Description Delete the document and its multimedia files. Just the creator of the document and his ancestors could delete it.
+// @Tags document
+// @Produce json
+// @Param doc_id path string true "Document id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success deleting document"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The document doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the creator of the document or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /docs/{doc_id} [delete]
+func (h *DocHttp) DeleteDoc(c *gin.Context) {
+	docID, jpID, jwt := h.parseDocParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.docService.DeleteDoc(jwt.UserID, *jpID, *docID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s deleted doc %s.", jpID.String(), docID.String())
+		successResp(c, MsgDocDeleted, MsgSuccessAction)
+		return
+	}
+	h.handleDocAccessErr(c, err, "delete doc")
+}
+
+// @Security BearerAuth
+// @Summary Get versions of document
+// @Description Get previous versions of the document together with the person who replaced each version. The newest versions come first. Just the creator of the document and his ancestors could read the versions.
+// @Tags document
+// @Produce json
+// @Param doc_id path string true "Document id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.DocVersion} "Previous versions of the document"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The document doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the creator of the document or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /docs/{doc_id}/versions [get]
+func (h *DocHttp) GetDocVersions(c *gin.Context) {
+	docID, jpID, jwt := h.parseDocParams(c)
+	if jwt == nil {
+		return
+	}
+
+	versions, err := h.docService.GetDocVersions(jwt.UserID, *jpID, *docID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, versions)
+		return
+	}
+	h.handleDocAccessErr(c, err, "get versions of doc")
+}
+
+// @Security BearerAuth
+// @Summary Restore document to a version
+// @Description Replace context and multimedia files of the document with the ones in the specified version. The current state of the document is kept as a new version, so the restoring could be undone too.
+// @Tags document
+// @Produce json
+// @Param doc_id path string true "Document id"
+// @Param version path int true "Version of the document to restore"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success restoring document"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The document or the version doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the creator of the document or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /docs/{doc_id}/versions/{version}/restore [post]
+func (h *DocHttp) RestoreDocVersion(c *gin.Context) {
+	var version uint64
+	if err := newParamParser(c, h.logger).parseUInt("version", &version); err != nil {
+		return
+	}
+	docID, jpID, jwt := h.parseDocParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.docService.RestoreDocVersion(jwt.UserID, *jpID, *docID, uint(version), getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s restored doc %s to version %d.", jpID.String(), docID.String(), version)
+		successResp(c, MsgDocRestored, MsgSuccessAction)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SENotFound:
+		h.logger.Debugf("Failed to restore doc: %s", err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgDocVersion), MsgCheckInfoAgain)
+	default:
+		h.handleDocAccessErr(c, err, "restore doc")
+	}
+}
+
+// Send proper HTTP response for errors that are raised during checking access of the
+// job position to a doc. action is used in the logs.
+func (h *DocHttp) handleDocAccessErr(c *gin.Context, err *e.Error, action string) {
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SEDocNotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgDoc), MsgCheckInfoAgain)
+	case s.SENotAncestor:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgNotAncestor)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// Parse doc id (from the url path) and job position id (from the JWT or the url query) of the
+// requests related to a specific doc. If it couldn't parse them or there's not any JWT, sends proper
+// HTTP response to the client and the returned JWT would be nil.
+func (h *DocHttp) parseDocParams(c *gin.Context) (docID, jpID *m.ID, jwt *m.JWT) {
+	var err error
+	if docID, err = newParamParser(c, h.logger).parseID("doc_id", nil); err != nil {
+		return nil, nil, nil
+	}
+	if jwt = getJWT(c, h.logger); jwt == nil {
+		return nil, nil, nil
+	}
+	if jpID = getCallerJP(c, jwt, h.logger); jpID == nil {
+		return nil, nil, nil
+	}
+	return docID, jpID, jwt
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
 	s "DMS/internal/services"
@@ -31,6 +32,7 @@ func newEventHttp(event s.EventService, logger l.Logger) EventHttp {
 // @Success 200 {object} HttpResponse{details=idResponse} "Success creating event"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 404 {object} HttpResponse{details=string} "Not found error. The job position doesn't belongs to current user."
+// @Failure 403 {object} HttpResponse{details=string} "The job position is not allowed to create events"
 // @Failure 400 {object} HttpResponse{details=string} "Bad request error"
 // @Router /events [post]
 func (h *EventHttp) CreateEvent(c *gin.Context) {
@@ -43,7 +45,7 @@ func (h *EventHttp) CreateEvent(c *gin.Context) {
 		return
 	}
 
-	id, err := h.eventService.CreateEvent(event, jwt.UserID)
+	id, err := h.eventService.CreateEvent(event, jwt.UserID, getClientInfo(c))
 	if err == nil {
 		h.logger.Debugf("Created event with id %s.", id.String())
 		successResp(c, MsgEventCreated, newIDResponse(*id))
@@ -56,6 +58,9 @@ func (h *EventHttp) CreateEvent(c *gin.Context) {
 	case s.SENotFound:
 		h.logger.Debugf("Failed to create event: %s", err.Error())
 		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgJP), MsgCheckInfoAgain)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to create event: %s", err.Error())
+		forbiddenErrResp(c, fmt.Sprintf(MsgCreationNotAllowC, MsgEvent), MsgNotPermission)
 	default:
 		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
@@ -64,16 +69,24 @@ func (h *EventHttp) CreateEvent(c *gin.Context) {
 
 // @Security BearerAuth
 // @Summary Get last N events by job position id
-// @Description Get last N events by job position id.
+// @Description Get last N events by job position id that match the filters. To get the next page, pass the returned next_cursor as the cursor query. The pages are stable even when new events are created.
 // @Tags event
 // @Accept json
 // @Produce json
 // @Param jpid query string true "Job position id"
-// @Param limit query int false "Limit of events to fetch. Default is 40. Max is 100. if limit be equals 0, then return all events from offset to the end."
-// @Param offset query int false "Offset of events to fetch. Default is 0."
-// @Success 200 {object} HttpResponse{details=[]models.Event} "Success fetching events"
+// @Param limit query int false "Limit of events to fetch. Default is 40. Max is 100."
+// @Param cursor query string false "Cursor of the page returned in the previous response. If it's empty, the first page is returned."
+// @Param created_from query int false "Just items created at or after this time. (Unix timestamp in seconds)"
+// @Param created_to query int false "Just items created at or before this time. (Unix timestamp in seconds)"
+// @Param created_by query string false "Just items created by this job position"
+// @Param event_id query string false "Just this event"
+// @Param region_id query string false "Just items created by job positions of this region"
+// @Param has_media query bool false "Just items that have (or don't have) multimedia files"
+// @Param media_type query int false "Just items that have a multimedia file with this type" Enums(0, 1, 2)
+// @Success 200 {object} HttpResponse{details=models.EventsPage} "Events and cursor of the next page"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 403 {object} HttpResponse{details=string} "Jon position doesn't belong to current user."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error. e.g. the cursor or a filter is not valid."
 // @Router /events [get]
 func (h *EventHttp) GetNLastEventsByJPID(c *gin.Context) {
 	queryParser := newQueryParser(c, h.logger)
@@ -82,9 +95,17 @@ func (h *EventHttp) GetNLastEventsByJPID(c *gin.Context) {
 	maxLimit := uint64(100)
 	if *limit > maxLimit {
 		*limit = maxLimit
+	} else if *limit < 1 {
+		*limit = 1
+	}
+	cursor, err := queryParser.ParseCursor("cursor")
+	if err != nil {
+		return
+	}
+	filter, err := queryParser.ParseListFilter()
+	if err != nil {
+		return
 	}
-	offsetDefaultValue := uint64(0)
-	offset, _ := queryParser.ParseUInt("offset", &offsetDefaultValue)
 	jwt := getJWT(c, h.logger)
 	if jwt == nil {
 		return
@@ -99,11 +120,11 @@ func (h *EventHttp) GetNLastEventsByJPID(c *gin.Context) {
 		return
 	}
 
-	events, err2 := h.eventService.GetNLastEventsByJPID(jwt.UserID, *jpID, *limit, *offset)
+	events, nextCursor, err2 := h.eventService.GetNLastEventsByJPID(jwt.UserID, *jpID, cursor, *limit, filter)
 	if err2 == nil {
-		h.logger.Debugf("Fetched %d events for job position id %s. (limit: %d, offset: %d)",
-			len(*events), jpID.String(), *limit, *offset)
-		successResp(c, MsgSuccessAction, events)
+		h.logger.Debugf("Fetched %d events for job position id %s. (limit: %d, cursor: %+v)",
+			len(*events), jpID.String(), *limit, cursor)
+		successResp(c, MsgSuccessAction, m.EventsPage{Events: *events, NextCursor: encodeNextCursor(nextCursor)})
 		return
 	}
 
@@ -119,3 +140,442 @@ func (h *EventHttp) GetNLastEventsByJPID(c *gin.Context) {
 		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
 	}
 }
+
+// @Security BearerAuth
+// @Summary Approve event
+// @Description Approve (feature) the event by the specified job position of the current user. The job position must have permission to approve events and be the same as or an ancestor of the event owner.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=idResponse} "Success approving event. Returns the approval id."
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not allowed to approve the event."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id}/approval [post]
+func (h *EventHttp) ApproveEvent(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	id, err := h.eventService.ApproveEvent(jwt.UserID, *jpID, *eventID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s approved event %s.", jpID.String(), eventID.String())
+		successResp(c, MsgEventApproved, newIDResponse(*id))
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to approve event (%s)", err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to approve event: %s", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SEEventNotFound:
+		h.logger.Debugf("Failed to approve event: %s", err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgEvent), MsgCheckInfoAgain)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to approve event: %s", err.Error())
+		forbiddenErrResp(c, MsgApproveNotAllowed, MsgNotPermission)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// @Security BearerAuth
+// @Summary Revoke event approval
+// @Description Revoke the approval of the event that is made previously by the specified job position of the current user.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success revoking approval"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position has not approved the event."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id}/approval [delete]
+func (h *EventHttp) RevokeApproval(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.eventService.RevokeApproval(jwt.UserID, *jpID, *eventID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s revoked approval of event %s.", jpID.String(), eventID.String())
+		successResp(c, MsgEventApprovalRevoked, MsgSuccessAction)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to revoke event approval (%s)", err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to revoke event approval: %s", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotFound:
+		h.logger.Debugf("Failed to revoke event approval: %s", err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgApproval), MsgCheckInfoAgain)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// @Security BearerAuth
+// @Summary Get approved events
+// @Description Get last approved (featured) events created by the specified job position or his nested childs. If the job position is admin, approved events of all job positions are returned. The newest approvals come first.
+// @Tags event
+// @Produce json
+// @Param jpid query string true "Job position id"
+// @Param limit query int false "Limit of events to fetch. Default is 20. Max is 100."
+// @Param offset query int false "Offset of events to fetch. Default is 0."
+// @Param created_from query int false "Just items created at or after this time. (Unix timestamp in seconds)"
+// @Param created_to query int false "Just items created at or before this time. (Unix timestamp in seconds)"
+// @Param created_by query string false "Just items created by this job position"
+// @Param event_id query string false "Just this event"
+// @Param region_id query string false "Just items created by job positions of this region"
+// @Param has_media query bool false "Just items that have (or don't have) multimedia files"
+// @Param media_type query int false "Just items that have a multimedia file with this type" Enums(0, 1, 2)
+// @Success 200 {object} HttpResponse{details=[]models.EventWithApproval} "Success fetching approved events"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "Job position doesn't belong to current user."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/approved [get]
+func (h *EventHttp) GetApprovedEvents(c *gin.Context) {
+	queryParser := newQueryParser(c, h.logger)
+	limitDefaultValue := uint64(20)
+	limit, _ := queryParser.ParseUInt("limit", &limitDefaultValue)
+	maxLimit := uint64(100)
+	if *limit > maxLimit {
+		*limit = maxLimit
+	} else if *limit < 1 {
+		*limit = 1
+	}
+	offsetDefaultValue := uint64(0)
+	offset, _ := queryParser.ParseUInt("offset", &offsetDefaultValue)
+	filter, err := queryParser.ParseListFilter()
+	if err != nil {
+		return
+	}
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	jpID, err := queryParser.ParseID("jpid", nil)
+	if err != nil {
+		h.logger.Debugf("Failed to parse job position id: %s", err.Error())
+		return
+	} else if jpID.IsNil() {
+		customErrResp(c, hCBadValue, MsgBadValue, fmt.Sprintf(MsgRequiredValueC, MsgJP))
+		return
+	}
+
+	events, err2 := h.eventService.ListApprovedEvents(jwt.UserID, *jpID, *limit, *offset, filter)
+	if err2 == nil {
+		h.logger.Debugf("Fetched %d approved events for job position id %s. (limit: %d, offset: %d)",
+			len(*events), jpID.String(), *limit, *offset)
+		successResp(c, MsgSuccessAction, events)
+		return
+	}
+	switch code := err2.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to fetch approved events (%s)", err2.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to fetch approved events: %s", err2.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err2.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// @Security BearerAuth
+// @Summary Get event
+// @Description Get the event. Just the owner of the event, his ancestors and the ones the access list of the event grants them could read it.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=models.Event} "The event"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id} [get]
+func (h *EventHttp) GetEvent(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	event, err := h.eventService.GetEvent(jwt.UserID, *jpID, *eventID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, event)
+		return
+	}
+	h.handleEventAccessErr(c, err, "get event")
+}
+
+// @Security BearerAuth
+// @Summary Edit event
+// @Description Edit name and/or description of the event. Just the owner of the event and his ancestors could edit it. Each edit is recorded in the edit history of the event.
+// @Tags event
+// @Accept json
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Param update body models.EventUpdate true "Fields to edit. Omitted fields remain unchanged."
+// @Success 200 {object} HttpResponse{details=string} "Success editing event"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id} [patch]
+func (h *EventHttp) UpdateEvent(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+	update := m.EventUpdate{}
+	if err := parseValidateJSON(c, &update, h.logger); err != nil {
+		return
+	}
+
+	err := h.eventService.UpdateEvent(jwt.UserID, *jpID, *eventID, &update, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s edited event %s.", jpID.String(), eventID.String())
+		successResp(c, MsgEventUpdated, MsgSuccessAction)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEEmpty:
+		badRequestResp(c, MsgBadValue, MsgNothingToUpdate)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to edit event: %s", err.Error())
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgRequiredValueC, MsgEventName))
+	default:
+		h.handleEventAccessErr(c, err, "edit event")
+	}
+}
+
+// @Security BearerAuth
+// @Summary Delete event
+// @Description Delete the event and its documents. Just the owner of the event and his ancestors could delete it.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success deleting event"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id} [delete]
+func (h *EventHttp) DeleteEvent(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.eventService.DeleteEvent(jwt.UserID, *jpID, *eventID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s deleted event %s.", jpID.String(), eventID.String())
+		successResp(c, MsgEventDeleted, MsgSuccessAction)
+		return
+	}
+	h.handleEventAccessErr(c, err, "delete event")
+}
+
+// @Security BearerAuth
+// @Summary Get edit history of event
+// @Description Get all edits of the event together with the person who edited it. The newest edits come first. Just the owner of the event, his ancestors and the ones the access list of the event grants them could read the history.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.EventRevision} "Edit history of the event"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event or his ancestor."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id}/revisions [get]
+func (h *EventHttp) GetEventRevisions(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	revisions, err := h.eventService.GetEventRevisions(jwt.UserID, *jpID, *eventID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, revisions)
+		return
+	}
+	h.handleEventAccessErr(c, err, "get revisions of event")
+}
+
+// @Security BearerAuth
+// @Summary Grant access on event
+// @Description Grant read or contribute access on the event to a job position, a user or a job position with all its nested childs. Contributors could create documents for the event too. Just the owner of the event and admins could grant accesses.
+// @Tags event
+// @Accept json
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Param acl body models.EventACL true "The access. Its id, event id, granter and creation time are ignored."
+// @Success 200 {object} HttpResponse{details=idResponse} "Access granted and response id of the access"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error or the granted job position doesn't exist."
+// @Router /events/{event_id}/acl [post]
+func (h *EventHttp) GrantEventAccess(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+	acl := m.EventACL{}
+	if err := parseValidateJSON(c, &acl, h.logger); err != nil {
+		return
+	}
+
+	id, err := h.eventService.GrantEventAccess(jwt.UserID, *jpID, *eventID, &acl, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s granted %s access on event %s to %s %s.", jpID.String(),
+			acl.Access, eventID.String(), acl.GranteeType, acl.GranteeID.String())
+		successResp(c, MsgEventAccessGranted, newIDResponse(*id))
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to grant access on event: %s", err.Error())
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, MsgEventAccess))
+	default:
+		h.handleEventOwnerErr(c, err, "grant access on event")
+	}
+}
+
+// @Security BearerAuth
+// @Summary Get access list of event
+// @Description Get all accesses granted on the event, including the expired ones. The newest accesses come first. Just the owner of the event and admins could read it.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.EventACL} "Access list of the event"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id}/acl [get]
+func (h *EventHttp) GetEventACLs(c *gin.Context) {
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	acls, err := h.eventService.GetEventACLs(jwt.UserID, *jpID, *eventID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, acls)
+		return
+	}
+	h.handleEventOwnerErr(c, err, "get access list of event")
+}
+
+// @Security BearerAuth
+// @Summary Revoke access on event
+// @Description Revoke an access granted on the event. Just the owner of the event and admins could revoke accesses.
+// @Tags event
+// @Produce json
+// @Param event_id path string true "Event id"
+// @Param acl_id path string true "Access id"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success revoking access"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The event or the access doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user or is not the owner of the event."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /events/{event_id}/acl/{acl_id} [delete]
+func (h *EventHttp) RevokeEventAccess(c *gin.Context) {
+	aclID, err := newParamParser(c, h.logger).parseID("acl_id", nil)
+	if err != nil {
+		return
+	}
+	eventID, jpID, jwt := h.parseEventParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err2 := h.eventService.RevokeEventAccess(jwt.UserID, *jpID, *eventID, *aclID, getClientInfo(c))
+	if err2 == nil {
+		h.logger.Debugf("Job position %s revoked access %s on event %s.", jpID.String(), aclID.String(),
+			eventID.String())
+		successResp(c, MsgEventAccessRevoked, MsgSuccessAction)
+		return
+	}
+	switch code := err2.GetCode(); code {
+	case s.SENotFound:
+		h.logger.Debugf("Failed to revoke access on event: %s", err2.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgEventAccess), MsgCheckInfoAgain)
+	default:
+		h.handleEventOwnerErr(c, err2, "revoke access on event")
+	}
+}
+
+// Send proper HTTP response for errors of the actions that just the owner of an event
+// could do.
+func (h *EventHttp) handleEventOwnerErr(c *gin.Context, err *e.Error, action string) {
+	if err.GetCode() == s.SEEventOwnerMismatched {
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgNotEventOwner)
+		return
+	}
+	h.handleEventAccessErr(c, err, action)
+}
+
+// Send proper HTTP response for errors that are raised during checking access of the
+// job position to an event. action is used in the logs.
+func (h *EventHttp) handleEventAccessErr(c *gin.Context, err *e.Error, action string) {
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SEEventNotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgEvent), MsgCheckInfoAgain)
+	case s.SENotAncestor:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgNotAncestor)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// Parse event id (from the url path) and job position id (from the JWT or the url query) of the
+// requests related to a specific event. If it couldn't parse them or there's not any JWT, sends proper
+// HTTP response to the client and the returned JWT would be nil.
+func (h *EventHttp) parseEventParams(c *gin.Context) (eventID, jpID *m.ID, jwt *m.JWT) {
+	var err error
+	if eventID, err = newParamParser(c, h.logger).parseID("event_id", nil); err != nil {
+		return nil, nil, nil
+	}
+	if jwt = getJWT(c, h.logger); jwt == nil {
+		return nil, nil, nil
+	}
+	if jpID = getCallerJP(c, jwt, h.logger); jpID == nil {
+		return nil, nil, nil
+	}
+	return eventID, jpID, jwt
+}
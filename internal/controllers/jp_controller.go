@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
 	s "DMS/internal/services"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,13 +22,16 @@ func newJPHttp(jobPositionService s.JPService, logger l.Logger) JPHttp {
 
 // @Security BearerAuth
 // @Summary Create a new user job position
-// @Description Create a new job position for specified user. Each user job position must be created with another job position.
+// @Description Create a new job position for specified user. Each user job position must be created with another job position. A job position could have several parents, so it could be in several separate subtrees.
 // @Tags job-position
 // @Param jPWithPermission body models.UserJPWithPermission true "Job position"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
 // @Success 200 {object} HttpResponse{details=idResponse} "Job position created and response its id"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 400 {object} HttpResponse{details=string} "Bad request error"
 // @Failure 401 {object} HttpResponse{details=string} "Unauthorized access to resource"
+// @Failure 403 {object} HttpResponse{details=string} "The caller is not allowed to create job position under the parents"
+// @Failure 404 {object} HttpResponse{details=string} "A parent job position doesn't exists."
 // @Router /jps [post]
 func (h *JPHttp) CreateUserJP(c *gin.Context) {
 	jp := m.UserJPWithPermission{
@@ -37,10 +42,17 @@ func (h *JPHttp) CreateUserJP(c *gin.Context) {
 		return
 	}
 	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	callerJPID := getCallerJP(c, jwt, h.logger)
+	if callerJPID == nil {
+		return
+	}
 	jp.JobPosition.UserID = jwt.UserID
 
-	h.logger.Debugf("Got job position %+v and permission %+v, ParentID: %+v", jp.JobPosition, jp.Permission, jp.JobPosition.ParentID)
-	id, err := h.jpService.CreateUserJP(&jp.JobPosition, &jp.Permission)
+	h.logger.Debugf("Got job position %+v and permission %+v, parents: %+v", jp.JobPosition, jp.Permission, jp.JobPosition.Parents())
+	id, err := h.jpService.CreateUserJP(jwt.UserID, *callerJPID, &jp.JobPosition, &jp.Permission, getClientInfo(c))
 	if err == nil {
 		successResp(c, MsgJPCreated, newIDResponse(*id))
 		h.logger.Debugf("Created job position with id %s successfully", id.String())
@@ -54,7 +66,17 @@ func (h *JPHttp) CreateUserJP(c *gin.Context) {
 		h.logger.Errorf("Failed to create job position (%s)", err.Error())
 		serverErrResp(c, MsgSuccessAction, MsgSomeActionsFailed)
 	case s.SENotFound:
-		h.logger.Errorf("Failed to create job position (%s)", err.Error())
+		h.logger.Debugf("Failed to create job position (%s)", err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgParentJP), MsgCheckInfoAgain)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to create job position (%s)", err.Error())
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgRequiredValueC, MsgParentJP))
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to create job position (%s)", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to create job position (%s)", err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
 	default:
 		h.logger.Panicf("Unexpected error code %d: %s", code, err.Error())
 	}
@@ -62,13 +84,15 @@ func (h *JPHttp) CreateUserJP(c *gin.Context) {
 
 // @Security BearerAuth
 // @Summary Create a new job position
-// @Description Create a new job position for specified user. Each Admin job position is created without a job position and has no parent job position.
+// @Description Create a new job position for specified user. Each Admin job position is created without a job position and has no parent job position. Just admin job positions could create admin job positions. The first one is created by the bootstrap-admin command of dmsctl.
 // @Tags job-position
 // @Param jPWithPermission body models.AdminJPWithPermission true "Job position"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
 // @Success 200 {object} HttpResponse{details=idResponse} "Job position created and response its id"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 400 {object} HttpResponse{details=string} "Bad request error"
 // @Failure 401 {object} HttpResponse{details=string} "Unauthorized access to resource"
+// @Failure 403 {object} HttpResponse{details=string} "The job position is not admin"
 // @Router /jps/admin [post]
 func (h *JPHttp) CreateAdminJP(c *gin.Context) {
 	jp := m.AdminJPWithPermission{
@@ -78,8 +102,16 @@ func (h *JPHttp) CreateAdminJP(c *gin.Context) {
 	if err := parseValidateJSON(c, &jp, h.logger); err != nil {
 		return
 	}
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	callerJPID := getCallerJP(c, jwt, h.logger)
+	if callerJPID == nil {
+		return
+	}
 	h.logger.Debugf("Got job position %+v and permission %+v", jp.JobPosition, jp.Permission)
-	id, err := h.jpService.CreateAdminJP(&jp.JobPosition, &jp.Permission)
+	id, err := h.jpService.CreateAdminJP(jwt.UserID, *callerJPID, &jp.JobPosition, &jp.Permission, getClientInfo(c))
 	if err == nil {
 		successResp(c, MsgJPCreated, newIDResponse(*id))
 		h.logger.Debugf("Created job position with id %s successfully", id.String())
@@ -89,6 +121,18 @@ func (h *JPHttp) CreateAdminJP(c *gin.Context) {
 	case s.SEDBError:
 		h.logger.Errorf("Failed to create job position (%s)", err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
+	case s.SEInMemoryUpdateFailed:
+		h.logger.Errorf("Failed to create job position (%s)", err.Error())
+		serverErrResp(c, MsgSuccessAction, MsgSomeActionsFailed)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to create job position (%s)", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to create job position (%s)", err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	default:
+		h.logger.Panicf("Unexpected error code %d: %s", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
 	}
 }
 
@@ -139,3 +183,323 @@ func (h *JPHttp) GetUserJPs(c *gin.Context) {
 		h.logger.Panicf("Unexpected error code %d in GetUserJPs controller(%s)", code, err2.Error())
 	}
 }
+
+// @Security BearerAuth
+// @Summary Edit job position
+// @Description Edit title and/or region of the job position. Just admins and ancestors of the job position could edit it.
+// @Tags job-position
+// @Accept json
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param update body models.JPUpdate true "Fields to edit. Omitted fields remain unchanged."
+// @Success 200 {object} HttpResponse{details=string} "Success editing job position"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id} [patch]
+func (h *JPHttp) UpdateJP(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+	update := m.JPUpdate{}
+	if err := parseValidateJSON(c, &update, h.logger); err != nil {
+		return
+	}
+
+	err := h.jpService.UpdateJP(jwt.UserID, *callerJPID, *jpID, &update, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s edited job position %s.", callerJPID.String(), jpID.String())
+		successResp(c, MsgJPUpdated, MsgSuccessAction)
+		return
+	}
+	h.handleJPAccessErr(c, err, "edit job position")
+}
+
+// @Security BearerAuth
+// @Summary Move job position
+// @Description Change parent of the job position. The caller must be admin or an ancestor of both the job position and the new parent. The job position couldn't be moved under itself or its nested childs.
+// @Tags job-position
+// @Accept json
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param move body models.JPMove true "The new parent"
+// @Success 200 {object} HttpResponse{details=string} "Success moving job position"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not admin or an ancestor of the job positions."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error. e.g. the move makes a cycle."
+// @Router /jps/{jp_id}/move [post]
+func (h *JPHttp) MoveJP(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+	move := m.JPMove{}
+	if err := parseValidateJSON(c, &move, h.logger); err != nil {
+		return
+	}
+
+	err := h.jpService.MoveJP(jwt.UserID, *callerJPID, *jpID, move.ParentID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s moved job position %s under %s.", callerJPID.String(),
+			jpID.String(), move.ParentID.String())
+		successResp(c, MsgJPMoved, MsgSuccessAction)
+		return
+	}
+	h.handleJPAccessErr(c, err, "move job position")
+}
+
+// @Security BearerAuth
+// @Summary Disable job position
+// @Description Disable the job position, so its user couldn't use it anymore. Just admins and ancestors of the job position could disable it.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success disabling job position"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/disable [post]
+func (h *JPHttp) DisableJP(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.jpService.DisableJP(jwt.UserID, *callerJPID, *jpID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s disabled job position %s.", callerJPID.String(), jpID.String())
+		successResp(c, MsgJPDisabled, MsgSuccessAction)
+		return
+	}
+	h.handleJPAccessErr(c, err, "disable job position")
+}
+
+// @Security BearerAuth
+// @Summary Enable job position
+// @Description Enable the disabled job position. Just admins and ancestors of the job position could enable it.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success enabling job position"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/enable [post]
+func (h *JPHttp) EnableJP(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.jpService.EnableJP(jwt.UserID, *callerJPID, *jpID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s enabled job position %s.", callerJPID.String(), jpID.String())
+		successResp(c, MsgJPEnabled, MsgSuccessAction)
+		return
+	}
+	h.handleJPAccessErr(c, err, "enable job position")
+}
+
+// @Security BearerAuth
+// @Summary Delete job position
+// @Description Delete the job position and its permissions. Job positions that have childs couldn't be deleted. Just admins and ancestors of the job position could delete it.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success deleting job position"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 409 {object} HttpResponse{details=string} "The job position has childs."
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not admin or an ancestor of the job position."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id} [delete]
+func (h *JPHttp) DeleteJP(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.jpService.DeleteJP(jwt.UserID, *callerJPID, *jpID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s deleted job position %s.", callerJPID.String(), jpID.String())
+		successResp(c, MsgJPDeleted, MsgSuccessAction)
+		return
+	}
+	h.handleJPAccessErr(c, err, "delete job position")
+}
+
+// @Security BearerAuth
+// @Summary Get child job positions
+// @Description Get direct child job positions of the job position together with their users and permissions. A job position could just browse its own subtree.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.JPNode} "Child job positions"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or the job position is not in its subtree."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/children [get]
+func (h *JPHttp) GetChildJPs(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+
+	jps, err := h.jpService.GetChildJPs(jwt.UserID, *callerJPID, *jpID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, jps)
+		return
+	}
+	h.handleJPAccessErr(c, err, "get child job positions")
+}
+
+// @Security BearerAuth
+// @Summary Get descendant job positions
+// @Description Get all nested child job positions of the job position together with their users and permissions. A job position could just browse its own subtree.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.JPNode} "Nested child job positions"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or the job position is not in its subtree."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/descendants [get]
+func (h *JPHttp) GetDescendantJPs(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+
+	jps, err := h.jpService.GetDescendantJPs(jwt.UserID, *callerJPID, *jpID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, jps)
+		return
+	}
+	h.handleJPAccessErr(c, err, "get descendant job positions")
+}
+
+// @Security BearerAuth
+// @Summary Get ancestor job positions
+// @Description Get ancestors of the job position together with their users and permissions. The nearer ancestors come first. Ancestors out of the subtree of the caller job position are not returned, except the caller is admin.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.JPNode} "Ancestor job positions"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or the job position is not in its subtree."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/ancestors [get]
+func (h *JPHttp) GetAncestorJPs(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+
+	jps, err := h.jpService.GetAncestorJPs(jwt.UserID, *callerJPID, *jpID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, jps)
+		return
+	}
+	h.handleJPAccessErr(c, err, "get ancestor job positions")
+}
+
+// @Security BearerAuth
+// @Summary Get job position tree
+// @Description Get the subtree of the job position as nested job positions together with their users and permissions. A job position could just browse its own subtree.
+// @Tags job-position
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param depth query int false "Number of levels of childs in the tree. Default is 3. Max is 10."
+// @Success 200 {object} HttpResponse{details=models.JPNode} "The tree"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or the job position is not in its subtree."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/tree [get]
+func (h *JPHttp) GetJPTree(c *gin.Context) {
+	jpID, callerJPID, jwt := h.parseJPParams(c)
+	if jwt == nil {
+		return
+	}
+	depthDefaultValue := uint64(3)
+	depth, _ := newQueryParser(c, h.logger).ParseUInt("depth", &depthDefaultValue)
+	maxDepth := uint64(10)
+	if *depth > maxDepth {
+		*depth = maxDepth
+	}
+
+	tree, err := h.jpService.GetJPTree(jwt.UserID, *callerJPID, *jpID, int(*depth))
+	if err == nil {
+		successResp(c, MsgSuccessAction, tree)
+		return
+	}
+	h.handleJPAccessErr(c, err, "get job position tree")
+}
+
+// Send proper HTTP response for errors of the actions on a specific job position.
+func (h *JPHttp) handleJPAccessErr(c *gin.Context, err *e.Error, action string) {
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEInMemoryUpdateFailed:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		serverErrResp(c, MsgSuccessAction, MsgSomeActionsFailed)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgJP), MsgCheckInfoAgain)
+	case s.SENotAncestor:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgNotAncestor)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, MsgParentJP))
+	case s.SEJPHasChilds:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		conflictErrResp(c, MsgJPHasChilds, MsgRemoveChildsFirst)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// Parse job position id (from the url path) and the caller job position id (from the JWT
+// or the url query) of the requests related to a specific job position. If it couldn't
+// parse them or there's not any JWT, sends proper HTTP response to the client and the
+// returned JWT would be nil.
+func (h *JPHttp) parseJPParams(c *gin.Context) (jpID, callerJPID *m.ID, jwt *m.JWT) {
+	var err error
+	if jpID, err = newParamParser(c, h.logger).parseID("jp_id", nil); err != nil {
+		return nil, nil, nil
+	}
+	if jwt = getJWT(c, h.logger); jwt == nil {
+		return nil, nil, nil
+	}
+	if callerJPID = getCallerJP(c, jwt, h.logger); callerJPID == nil {
+		return nil, nil, nil
+	}
+	return jpID, callerJPID, jwt
+}
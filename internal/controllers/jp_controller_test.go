@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	e "DMS/internal/error"
+	m "DMS/internal/models"
+	s "DMS/internal/services"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// It records the parameters of the last browse request and returns err, if it's set.
+type browseJPService struct {
+	s.JPService
+	userID, callerJPID, jpID m.ID
+	err                      *e.Error
+}
+
+func (s *browseJPService) browse(userID, callerJPID, jpID m.ID) *e.Error {
+	s.userID, s.callerJPID, s.jpID = userID, callerJPID, jpID
+	return s.err
+}
+
+func (s *browseJPService) GetChildJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error) {
+	return &[]m.JPNode{}, s.browse(userID, callerJPID, jpID)
+}
+
+func (s *browseJPService) GetDescendantJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error) {
+	return &[]m.JPNode{}, s.browse(userID, callerJPID, jpID)
+}
+
+func (s *browseJPService) GetAncestorJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error) {
+	return &[]m.JPNode{}, s.browse(userID, callerJPID, jpID)
+}
+
+func (s *browseJPService) GetJPTree(userID, callerJPID, jpID m.ID, depth int) (*m.JPNode, *e.Error) {
+	return &m.JPNode{ID: jpID}, s.browse(userID, callerJPID, jpID)
+}
+
+func TestBrowseJPAccess(t *testing.T) {
+	userID, jwtJPID, queryJPID, jpID := m.ID(uuid.New()), m.ID(uuid.New()), m.ID(uuid.New()), m.ID(uuid.New())
+	tests := []struct {
+		name  string
+		jwt   *m.JWT
+		query string
+		jpID  string
+		err   *e.Error
+		// Expected HTTP status code
+		code int
+		// Expected caller job position passed to the service, if the request is passed.
+		callerJPID m.ID
+	}{
+		{name: "caller of the JWT", jwt: &m.JWT{UserID: userID, JPID: jwtJPID}, query: "?jpid=" + queryJPID.String(),
+			jpID: jpID.String(), code: http.StatusOK, callerJPID: jwtJPID},
+		{name: "caller of the query", jwt: &m.JWT{UserID: userID}, query: "?jpid=" + queryJPID.String(),
+			jpID: jpID.String(), code: http.StatusOK, callerJPID: queryJPID},
+		{name: "without caller", jwt: &m.JWT{UserID: userID}, jpID: jpID.String(), code: http.StatusBadRequest},
+		{name: "without JWT", query: "?jpid=" + queryJPID.String(), jpID: jpID.String(), code: http.StatusUnauthorized},
+		{name: "invalid job position", jwt: &m.JWT{UserID: userID, JPID: jwtJPID}, jpID: "1", code: http.StatusBadRequest},
+		{name: "job position out of the subtree", jwt: &m.JWT{UserID: userID, JPID: jwtJPID}, jpID: jpID.String(),
+			err: e.NewErrorP("not ancestor", s.SENotAncestor), code: http.StatusForbidden, callerJPID: jwtJPID},
+		{name: "not allowed to view the subtree", jwt: &m.JWT{UserID: userID, JPID: jwtJPID}, jpID: jpID.String(),
+			err: e.NewErrorP("not permission", s.SENotPermission), code: http.StatusForbidden, callerJPID: jwtJPID},
+		{name: "caller of another user", jwt: &m.JWT{UserID: userID, JPID: jwtJPID}, jpID: jpID.String(),
+			err: e.NewErrorP("not matched", s.SEJPNotMatchedUser), code: http.StatusForbidden, callerJPID: jwtJPID},
+		{name: "job position not found", jwt: &m.JWT{UserID: userID, JPID: jwtJPID}, jpID: jpID.String(),
+			err: e.NewErrorP("not found", s.SENotFound), code: http.StatusNotFound, callerJPID: jwtJPID},
+	}
+	for _, route := range []string{"children", "descendants", "ancestors", "tree"} {
+		for _, test := range tests {
+			t.Run(route+" "+test.name, func(t *testing.T) {
+				service := &browseJPService{err: test.err}
+				jpHttp := newJPHttp(service, testLogger)
+				handler := map[string]gin.HandlerFunc{
+					"children":    jpHttp.GetChildJPs,
+					"descendants": jpHttp.GetDescendantJPs,
+					"ancestors":   jpHttp.GetAncestorJPs,
+					"tree":        jpHttp.GetJPTree,
+				}[route]
+				recorder, resp := callHandler(t, handler, "/jps/"+test.jpID+"/"+route+test.query, test.jwt,
+					gin.Param{Key: "jp_id", Value: test.jpID})
+				if recorder.Code != test.code {
+					t.Fatalf("expected status code %d, got %d %+v", test.code, recorder.Code, resp)
+				}
+				if test.callerJPID.IsNil() {
+					if !service.callerJPID.IsNil() {
+						t.Errorf("expected the request not to be passed to the service")
+					}
+					return
+				}
+				if service.userID != userID || service.callerJPID != test.callerJPID || service.jpID != jpID {
+					t.Errorf("expected user %s, caller %s and job position %s to be passed to the service, got %s, %s and %s",
+						userID.String(), test.callerJPID.String(), jpID.String(),
+						service.userID.String(), service.callerJPID.String(), service.jpID.String())
+				}
+			})
+		}
+	}
+}
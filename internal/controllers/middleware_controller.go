@@ -5,7 +5,6 @@ import (
 	m "DMS/internal/models"
 	s "DMS/internal/services"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-contrib/cors"
@@ -15,12 +14,21 @@ import (
 type MiddlewareHttp struct {
 	logger         l.Logger
 	sessionService s.SessionService
+	corsConfig     cors.Config
 }
 
-func newMiddlewareHttp(sessionService s.SessionService, logger l.Logger) MiddlewareHttp {
+func newMiddlewareHttp(sessionService s.SessionService, allowedOrigins []string, logger l.Logger) MiddlewareHttp {
+	logger.Debugf("Allowed origins: %+v", allowedOrigins)
 	return MiddlewareHttp{
 		logger,
 		sessionService,
+		cors.Config{
+			AllowOrigins:     allowedOrigins,
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowHeaders:     []string{"Content-Type", "Authorization"},
+			AllowCredentials: true,
+			MaxAge:           12 * 60 * 60,
+		},
 	}
 }
 
@@ -32,6 +40,10 @@ func (h MiddlewareHttp) Authentication(c *gin.Context) {
 
 	params, err := h.sessionService.ValidateSessionJWT(m.Token(jwt))
 	if err == nil {
+		// Failing to record the usage shouldn't block the request.
+		if err := h.sessionService.MarkSessionUsed(params.JTI); err != nil {
+			h.logger.Errorf("Failed to update last usage of session %s (%s)", params.JTI.String(), err.Error())
+		}
 		c.Set(authInfo, params)
 		c.Next()
 		return
@@ -47,39 +59,18 @@ func (h MiddlewareHttp) Authentication(c *gin.Context) {
 	case s.SENotFound:
 		h.logger.Debugf("Failed to authenticate user in the middleware: %s", err.Error())
 		unauthorizedResp(c, MsgAuthNotFound, MsgReferAdmin)
+	case s.SEIsDisabled:
+		h.logger.Debugf("Failed to authenticate user in the middleware: %s", err.Error())
+		unauthorizedResp(c, MsgDisabledUser, MsgFixDisabledUserProblem)
 	default:
 		h.logger.Panicf("Error code %d doesn't handled. (%s)", code, err.Error())
 	}
 }
 
-var corsConfig cors.Config
-var isCorsRunned = false
-
 // Cors enables Cross-Origin Resource Sharing (CORS) headers for the given
 // request.
 func (h MiddlewareHttp) Cors(c *gin.Context) {
-	if !isCorsRunned {
-		allowedOriginsEnv := strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), " ")
-		allowedOrigins := make([]string, 0, len(allowedOriginsEnv))
-		for _, origin := range allowedOriginsEnv {
-			if strings.TrimSpace(origin) == "" {
-				continue
-			}
-			allowedOrigins = append(allowedOrigins, strings.TrimSpace(origin))
-		}
-		isCorsRunned = true
-		h.logger.Debugf("Allowed origins: %+v", allowedOrigins)
-
-		corsConfig = cors.Config{
-			AllowOrigins:     allowedOrigins,
-			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-			AllowHeaders:     []string{"Content-Type", "Authorization"},
-			AllowCredentials: true,
-			MaxAge:           12 * 60 * 60,
-		}
-	}
-
-	corsMiddleware := cors.New(corsConfig)
+	corsMiddleware := cors.New(h.corsConfig)
 	corsMiddleware(c)
 	if c.Request.Method == "OPTIONS" {
 		c.AbortWithStatus(http.StatusNoContent)
@@ -87,5 +78,3 @@ func (h MiddlewareHttp) Cors(c *gin.Context) {
 	}
 	c.Next()
 }
-
-// TODO: Create a controller that abort requests if the specified user is disabled.
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	s "DMS/internal/services"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role controller
+type RoleHttp struct {
+	roleService s.RoleService
+	logger      l.Logger
+}
+
+func newRoleHttp(roleService s.RoleService, logger l.Logger) RoleHttp {
+	return RoleHttp{roleService, logger}
+}
+
+// @Security BearerAuth
+// @Summary Create role
+// @Description Create a named role that bundles some actions. Just job positions that are allowed to manage roles could create roles.
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param role body models.Role true "Role"
+// @Success 200 {object} HttpResponse{details=idResponse} "Role created and response its id"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to manage roles."
+// @Failure 409 {object} HttpResponse{details=string} "A role with the same name exists."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /roles [post]
+func (h *RoleHttp) CreateRole(c *gin.Context) {
+	role := m.Role{}
+	if err := parseValidateJSON(c, &role, h.logger); err != nil {
+		return
+	}
+	callerJPID, jwt := h.parseCaller(c)
+	if jwt == nil {
+		return
+	}
+
+	id, err := h.roleService.CreateRole(jwt.UserID, *callerJPID, &role, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s created role %s with id %s.", callerJPID.String(), role.Name, id.String())
+		successResp(c, MsgRoleCreated, newIDResponse(*id))
+		return
+	}
+	h.handleRoleErr(c, err, "create role")
+}
+
+// @Security BearerAuth
+// @Summary Get roles
+// @Description Get all roles with their actions.
+// @Tags role
+// @Produce json
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.Role} "Roles"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /roles [get]
+func (h *RoleHttp) GetRoles(c *gin.Context) {
+	callerJPID, jwt := h.parseCaller(c)
+	if jwt == nil {
+		return
+	}
+
+	roles, err := h.roleService.GetRoles(jwt.UserID, *callerJPID)
+	if err == nil {
+		successResp(c, MsgSuccessAction, roles)
+		return
+	}
+	h.handleRoleErr(c, err, "get roles")
+}
+
+// @Security BearerAuth
+// @Summary Delete role
+// @Description Delete the role and take it from all job positions. Built-in roles couldn't be deleted.
+// @Tags role
+// @Produce json
+// @Param role_id path string true "Role id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success deleting role"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The role doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to manage roles."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error. e.g. the role is built-in."
+// @Router /roles/{role_id} [delete]
+func (h *RoleHttp) DeleteRole(c *gin.Context) {
+	roleID, err := newParamParser(c, h.logger).parseID("role_id", nil)
+	if err != nil {
+		return
+	}
+	callerJPID, jwt := h.parseCaller(c)
+	if jwt == nil {
+		return
+	}
+
+	err2 := h.roleService.DeleteRole(jwt.UserID, *callerJPID, *roleID, getClientInfo(c))
+	if err2 == nil {
+		h.logger.Debugf("Job position %s deleted role %s.", callerJPID.String(), roleID.String())
+		successResp(c, MsgRoleDeleted, MsgSuccessAction)
+		return
+	} else if err2.GetCode() == s.SEWrongParameter {
+		h.logger.Debugf("Failed to delete role: %s", err2.Error())
+		badRequestResp(c, MsgBadValue, MsgBuiltinRole)
+		return
+	}
+	h.handleRoleErr(c, err2, "delete role")
+}
+
+// @Security BearerAuth
+// @Summary Get roles of job position
+// @Description Get the roles assigned to the job position. The caller must be the job position itself or be allowed to view its subtree.
+// @Tags role
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.JPRole} "Roles of the job position"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to view the job position."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/roles [get]
+func (h *RoleHttp) GetJPRoles(c *gin.Context) {
+	jpID, err := newParamParser(c, h.logger).parseID("jp_id", nil)
+	if err != nil {
+		return
+	}
+	callerJPID, jwt := h.parseCaller(c)
+	if jwt == nil {
+		return
+	}
+
+	roles, err2 := h.roleService.GetJPRoles(jwt.UserID, *callerJPID, *jpID)
+	if err2 == nil {
+		successResp(c, MsgSuccessAction, roles)
+		return
+	}
+	h.handleRoleErr(c, err2, "get roles of job position")
+}
+
+// @Security BearerAuth
+// @Summary Assign role to job position
+// @Description Assign the role to the job position. If the role is inheritable, it applies to all nested childs of the job position too. The caller must be allowed to manage roles, be an ancestor of the job position and be allowed to do all actions of the role.
+// @Tags role
+// @Accept json
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param role body models.JPRole true "The role. Its name is ignored."
+// @Success 200 {object} HttpResponse{details=string} "Success assigning role"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position or the role doesn't exists."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user, is not allowed to manage roles of the job position or isn't allowed to do an action of the role."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/roles [post]
+func (h *RoleHttp) AssignRole(c *gin.Context) {
+	jpID, err := newParamParser(c, h.logger).parseID("jp_id", nil)
+	if err != nil {
+		return
+	}
+	jpRole := m.JPRole{}
+	if err := parseValidateJSON(c, &jpRole, h.logger); err != nil {
+		return
+	}
+	callerJPID, jwt := h.parseCaller(c)
+	if jwt == nil {
+		return
+	}
+
+	err2 := h.roleService.AssignRole(jwt.UserID, *callerJPID, *jpID, jpRole.RoleID, jpRole.IsInheritable, getClientInfo(c))
+	if err2 == nil {
+		h.logger.Debugf("Job position %s assigned role %s to %s.", callerJPID.String(),
+			jpRole.RoleID.String(), jpID.String())
+		successResp(c, MsgRoleAssigned, MsgSuccessAction)
+		return
+	}
+	h.handleRoleErr(c, err2, "assign role")
+}
+
+// @Security BearerAuth
+// @Summary Unassign role from job position
+// @Description Take the role from the job position. The caller must be allowed to manage roles and be an ancestor of the job position.
+// @Tags role
+// @Produce json
+// @Param jp_id path string true "Job position id"
+// @Param role_id path string true "Role id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success unassigning role"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The job position doesn't exists or the role is not assigned to it."
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to manage roles of the job position."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /jps/{jp_id}/roles/{role_id} [delete]
+func (h *RoleHttp) UnassignRole(c *gin.Context) {
+	paramParser := newParamParser(c, h.logger)
+	jpID, err := paramParser.parseID("jp_id", nil)
+	if err != nil {
+		return
+	}
+	roleID, err := paramParser.parseID("role_id", nil)
+	if err != nil {
+		return
+	}
+	callerJPID, jwt := h.parseCaller(c)
+	if jwt == nil {
+		return
+	}
+
+	err2 := h.roleService.UnassignRole(jwt.UserID, *callerJPID, *jpID, *roleID, getClientInfo(c))
+	if err2 == nil {
+		h.logger.Debugf("Job position %s unassigned role %s from %s.", callerJPID.String(),
+			roleID.String(), jpID.String())
+		successResp(c, MsgRoleUnassigned, MsgSuccessAction)
+		return
+	}
+	h.handleRoleErr(c, err2, "unassign role")
+}
+
+// Send proper HTTP response for errors of the actions on roles.
+func (h *RoleHttp) handleRoleErr(c *gin.Context, err *e.Error, action string) {
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	case s.SENotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgJP), MsgCheckInfoAgain)
+	case s.SERoleNotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgRole), MsgCheckInfoAgain)
+	case s.SEExists:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		conflictErrResp(c, MsgRoleExists, MsgCheckInfoAgain)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, MsgRole))
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// Parse the caller job position id (from the JWT or the url query). If it couldn't parse
+// it or there's not any JWT, sends proper HTTP response to the client and the returned
+// JWT would be nil.
+func (h *RoleHttp) parseCaller(c *gin.Context) (callerJPID *m.ID, jwt *m.JWT) {
+	if jwt = getJWT(c, h.logger); jwt == nil {
+		return nil, nil
+	}
+	if callerJPID = getCallerJP(c, jwt, h.logger); callerJPID == nil {
+		return nil, nil
+	}
+	return callerJPID, jwt
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	s "DMS/internal/services"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHttp struct {
+	searchService s.SearchService
+	logger        l.Logger
+}
+
+func newSearchHttp(searchService s.SearchService, logger l.Logger) SearchHttp {
+	return SearchHttp{
+		searchService,
+		logger,
+	}
+}
+
+// @Security BearerAuth
+// @Summary Search documents or events
+// @Description Full-text search in context of documents or name and description of events that are accessible for the job position. (If the job position is admin, he has access to all documents and events.) The results are ordered by their relevance and the matched words in the title and snippet of each result are wrapped in <mark> tags. The query supports web search syntax. e.g. "quoted phrase", "or" and "-" to exclude a word.
+// @Tags search
+// @Produce json
+// @Param q query string true "Search query"
+// @Param type query string false "Type of entities to search" Enums(doc, event) default(doc)
+// @Param limit query int false "Number of results to get. Maximum is 50"
+// @Param offset query int false "Number of results to skip"
+// @Param jpid query string false "Job position id. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=[]models.SearchResult} "Search results"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "The job position doesn't belong to current user."
+// @Failure 401 {object} HttpResponse{details=string} "The user is not authorized"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /search [get]
+func (h *SearchHttp) Search(c *gin.Context) {
+	queryParser := newQueryParser(c, h.logger)
+	limitDefaultValue := uint64(20)
+	limit, _ := queryParser.ParseUInt("limit", &limitDefaultValue)
+	maxLimit := uint64(50)
+	if *limit > maxLimit {
+		*limit = maxLimit
+	} else if *limit < 1 {
+		*limit = 1
+	}
+	offsetDefaultValue := uint64(0)
+	offset, _ := queryParser.ParseUInt("offset", &offsetDefaultValue)
+	query := c.Query("q")
+	searchType := m.SearchType(c.DefaultQuery("type", string(m.SearchDoc)))
+
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	jpID := getCallerJP(c, jwt, h.logger)
+	if jpID == nil {
+		return
+	}
+
+	results, err := h.searchService.Search(jwt.UserID, *jpID, query, searchType, *limit, *offset)
+	if err == nil {
+		successResp(c, MsgSuccessAction, *results)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEEmpty:
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgRequiredValueC, MsgSearchQuery))
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to search: %s", err.Error())
+		badRequestResp(c, MsgBadValue, fmt.Sprintf(MsgIsNotValidC, MsgSearchType))
+	case s.SEDBError:
+		h.logger.Errorf("Failed to search \"%s\": %s", query, err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Job position doesn't belong the user: %s", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	default:
+		h.logger.Panicf("Unexpected error code %d in Search controller: %s", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	}
+}
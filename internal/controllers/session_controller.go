@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
 	s "DMS/internal/services"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,33 +22,80 @@ func newSessionHttp(sessionService s.SessionService, logger l.Logger) SessionHtt
 	}
 }
 
-// @Security BearerAuth
-// @Summary Login/Create JWT with phone number only
-// @Description Login/Create JWT with phone number only.
+// @Summary Request one-time code for phone based login
+// @Description Generate a one-time code and send it to the phone number by SMS. The code expires after a few minutes and the number of requests for each phone number and each IP address is limited. If the phone number doesn't belong to any enabled user, nothing is sent but the response is the same.
+// @Tags session
+// @Accept json
+// @Produce json
+// @Param phone body models.PhoneOTPRequest true "Phone number"
+// @Success 200 {object} HttpResponse{details=string} "The code is sent"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 429 {object} HttpResponse{details=string} "Too many requests for the phone number or from the IP address"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /login/phone-based/request-otp [post]
+func (h *SessionHttp) RequestPhoneOTP(c *gin.Context) {
+	request := m.PhoneOTPRequest{}
+	if err := parseValidateJSON(c, &request, h.logger); err != nil {
+		return
+	}
+	err := h.sessionService.RequestPhoneOTP(request.PhoneNumber, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Handled one-time code request of phone %s.", request.PhoneNumber.ToString())
+		successResp(c, MsgOTPSent, MsgOTPSent)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEDBError, s.SEInternal:
+		h.logger.Errorf("Failed to send one-time code (%s)", err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	case s.SETooManyRequests:
+		h.logger.Debugf("Failed to send one-time code: %s", err.Error())
+		customErrResp(c, hCTooManyRequests, MsgTooManyRequests, MsgTryLater)
+	default:
+		h.logger.Errorf("Unexpected error code %d (%s)", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	}
+}
+
+// @Summary Login/Create JWT with phone number and one-time code
+// @Description Login/Create JWT if the one-time code sent to the phone number is correct. After some wrong codes, the code is revoked and a new one must be requested.
 // @Tags session
-// @Param phone body models.PhoneBasedLoginInfo true "Phone number"
-// @Success 200 {object} HttpResponse{details=string} "Success login and response created JWT token"
+// @Accept json
+// @Produce json
+// @Param login body models.PhoneBasedLoginInfo true "Phone number and one-time code"
+// @Success 200 {object} HttpResponse{details=models.SessionTokens} "Success login and response created access token and refresh token"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
-// @Failure 401 {object} HttpResponse{details=string} "User not found with such phone number"
+// @Failure 429 {object} HttpResponse{details=string} "Too many wrong codes"
+// @Failure 403 {object} HttpResponse{details=string} "The user is disabled"
+// @Failure 401 {object} HttpResponse{details=string} "The code is wrong or expired or user not found with such phone number"
 // @Failure 400 {object} HttpResponse{details=string} "Bad request error"
-// @Router /login/phone-based [post]
+// @Router /login/phone-based/verify [post]
 func (h *SessionHttp) PhoneBasedLogin(c *gin.Context) {
 	session := m.PhoneBasedLoginInfo{}
 	if err := parseValidateJSON(c, &session, h.logger); err != nil {
 		return
 	}
-	token, err := h.sessionService.CreateSessionJustByPhone(&session)
+	token, err := h.sessionService.CreateSessionByPhoneOTP(&session, getClientInfo(c))
 	if err == nil {
 		h.logger.Debugf("Created session with user-agent %s.", session.UserAgent)
 		successResp(c, MsgSuccessfulLogin, token)
 		return
 	}
 	switch code := err.GetCode(); code {
-	case s.SEDBError:
+	case s.SEDBError, s.SEInternal:
 		h.logger.Errorf("Failed to create session (%s)", err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
 	case s.SENotFound:
 		unauthorizedResp(c, MsgAuthNotFound, MsgReferAdmin)
+	case s.SEIsDisabled:
+		h.logger.Debugf("Failed to create session: %s", err.Error())
+		forbiddenErrResp(c, MsgDisabledUser, MsgFixDisabledUserProblem)
+	case s.SEAuthFailed:
+		h.logger.Debugf("Failed to create session: %s", err.Error())
+		unauthorizedResp(c, MsgWrongOTP, MsgCheckInfoAgain)
+	case s.SETooManyRequests:
+		h.logger.Debugf("Failed to create session: %s", err.Error())
+		customErrResp(c, hCTooManyRequests, MsgTooManyRequests, MsgRequestNewOTP)
 	case s.SEEncodingError:
 		h.logger.Debugf("Failed to create session: %s", err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
@@ -56,6 +105,47 @@ func (h *SessionHttp) PhoneBasedLogin(c *gin.Context) {
 	}
 }
 
+// @Summary Refresh session
+// @Description Issue a new access token (JWT) and a new refresh token for the session of the refresh token. Each refresh token could be used once. If a used refresh token is sent again, the whole session is revoked.
+// @Tags session
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshSessionRequest true "Refresh token"
+// @Success 200 {object} HttpResponse{details=models.SessionTokens} "New access token and refresh token"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "The user is disabled"
+// @Failure 401 {object} HttpResponse{details=string} "The refresh token is invalid, expired or used previously"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /token/refresh [post]
+func (h *SessionHttp) RefreshSession(c *gin.Context) {
+	request := m.RefreshSessionRequest{}
+	if err := parseValidateJSON(c, &request, h.logger); err != nil {
+		return
+	}
+	tokens, err := h.sessionService.RefreshSession(request.RefreshToken, getClientInfo(c))
+	if err == nil {
+		successResp(c, MsgSessionRefreshed, tokens)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEDBError, s.SEInternal:
+		h.logger.Errorf("Failed to refresh session (%s)", err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	case s.SEAuthFailed:
+		h.logger.Debugf("Failed to refresh session: %s", err.Error())
+		unauthorizedResp(c, MsgAuthFailed, MsgLoginAgain)
+	case s.SEIsDisabled:
+		h.logger.Debugf("Failed to refresh session: %s", err.Error())
+		forbiddenErrResp(c, MsgDisabledUser, MsgFixDisabledUserProblem)
+	case s.SEEncodingError:
+		h.logger.Debugf("Failed to refresh session: %s", err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	default:
+		h.logger.Errorf("Unexpected error code %d (%s)", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	}
+}
+
 // @Security BearerAuth
 // @Summary Logout
 // @Description Logout from the current session.
@@ -70,7 +160,7 @@ func (h *SessionHttp) Logout(c *gin.Context) {
 	if jwt == nil {
 		return
 	}
-	err := h.sessionService.DeleteSession(jwt)
+	err := h.sessionService.DeleteSession(jwt, getClientInfo(c))
 	if err == nil {
 		h.logger.Debugf("Deleted session with id %s.", jwt.JTI.String())
 		successResp(c, MsgSuccessfulLogout, MsgSuccessfulLogout)
@@ -91,3 +181,106 @@ func (h *SessionHttp) Logout(c *gin.Context) {
 		serverErrResp(c, MsgServerError, MsgTryAgain)
 	}
 }
+
+// @Security BearerAuth
+// @Summary Get my sessions
+// @Description Get active sessions (logged in devices) of the current user. The recently used sessions come first and the session of the current request is marked.
+// @Tags session
+// @Produce json
+// @Success 200 {object} HttpResponse{details=[]models.Session} "Active sessions"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 401 {object} HttpResponse{details=string} "Unauthorized access to resource"
+// @Router /sessions [get]
+func (h *SessionHttp) GetSessions(c *gin.Context) {
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	sessions, err := h.sessionService.GetUserSessions(jwt)
+	if err == nil {
+		successResp(c, MsgSuccessAction, sessions)
+		return
+	}
+	h.handleSessionErr(c, err, "get sessions")
+}
+
+// @Security BearerAuth
+// @Summary Revoke a session
+// @Description Revoke one of the sessions of the current user. Its tokens stop working immediately.
+// @Tags session
+// @Produce json
+// @Param session_id path string true "Session id"
+// @Success 200 {object} HttpResponse{details=string} "Success revoking session"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The session doesn't exist or doesn't belong to the current user"
+// @Failure 401 {object} HttpResponse{details=string} "Unauthorized access to resource"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /sessions/{session_id} [delete]
+func (h *SessionHttp) RevokeSession(c *gin.Context) {
+	sessionID, err := newParamParser(c, h.logger).parseID("session_id", nil)
+	if err != nil {
+		return
+	}
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	err2 := h.sessionService.RevokeSession(jwt, *sessionID, getClientInfo(c))
+	if err2 == nil {
+		h.logger.Debugf("User %s revoked session %s.", jwt.UserID.String(), sessionID.String())
+		successResp(c, MsgSessionRevoked, MsgSuccessAction)
+		return
+	}
+	h.handleSessionErr(c, err2, "revoke session")
+}
+
+// @Security BearerAuth
+// @Summary Revoke my sessions
+// @Description Revoke all sessions of the current user and response number of revoked sessions. If all_except_current is true, the session of the current request is kept.
+// @Tags session
+// @Produce json
+// @Param all_except_current query bool false "Keep the current session"
+// @Success 200 {object} HttpResponse{details=int} "Number of revoked sessions"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 401 {object} HttpResponse{details=string} "Unauthorized access to resource"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /sessions [delete]
+func (h *SessionHttp) RevokeSessions(c *gin.Context) {
+	exceptCurrent, err := newQueryParser(c, h.logger).parseOptionalBool("all_except_current")
+	if err != nil {
+		return
+	}
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	count, err2 := h.sessionService.RevokeUserSessions(jwt, exceptCurrent != nil && *exceptCurrent, getClientInfo(c))
+	if err2 == nil {
+		h.logger.Debugf("User %s revoked %d sessions.", jwt.UserID.String(), count)
+		successResp(c, MsgSessionsRevoked, count)
+		return
+	}
+	h.handleSessionErr(c, err2, "revoke sessions")
+}
+
+// Response public keys that JWTs could be verified with, in JSON Web Key Set format.
+// Each JWT has the id of its key in the "kid" header. The key that signs new JWTs comes
+// first. It's served out of the API base path, so it's not in the API documentation.
+func (h *SessionHttp) GetJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.sessionService.GetJWKS())
+}
+
+// Send proper HTTP response for errors of the actions on sessions of the current user.
+func (h *SessionHttp) handleSessionErr(c *gin.Context, err *e.Error, action string) {
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	case s.SENotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, MsgSessionNotFound, MsgCheckInfoAgain)
+	default:
+		h.logger.Errorf("Unexpected error code %d (%s)", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	s "DMS/internal/services"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header that the file-transfer service sends its shared secret in
+const serviceTokenHeader = "X-Service-Token"
+
+type UploadHttp struct {
+	filePermissionService s.FilePermissionService
+	// Shared secret of the file-transfer service. If it's empty, the callbacks are disabled.
+	callbackSecret string
+	logger         l.Logger
+}
+
+func newUploadHttp(filePermissionService s.FilePermissionService, callbackSecret string, logger l.Logger) UploadHttp {
+	return UploadHttp{
+		filePermissionService,
+		callbackSecret,
+		logger,
+	}
+}
+
+// @Summary Confirm upload
+// @Description It's called by the file-transfer service after storing an uploaded file (It's the HTTP twin of the ConfirmUpload gRPC method). The pending file of the doc with the same name is confirmed with its size, checksum and MIME type. The doc must be created by the job position of the auth token for its event. The shared secret of the file-transfer service must be sent in the X-Service-Token header.
+// @Tags upload
+// @Accept json
+// @Produce json
+// @Param X-Service-Token header string true "Shared secret of the file-transfer service"
+// @Param confirmation body models.UploadConfirmation true "Stored file"
+// @Success 200 {object} HttpResponse{details=models.MediaPath} "The confirmed file"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "Not found error. The doc doesn't exists or doesn't have such pending file."
+// @Failure 403 {object} HttpResponse{details=string} "Forbidden error. The service token is wrong, the doc doesn't belong to the auth token or the storage quota is exceeded."
+// @Failure 401 {object} HttpResponse{details=string} "The auth token is invalid"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error. Also if the file is not allowed by the upload policy."
+// @Router /uploads/confirm [post]
+func (h *UploadHttp) ConfirmUpload(c *gin.Context) {
+	token := c.GetHeader(serviceTokenHeader)
+	if h.callbackSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.callbackSecret)) != 1 {
+		h.logger.Debugf("Rejected upload confirmation with wrong service token")
+		forbiddenErrResp(c, MsgInvalidServiceToken, MsgNotPermission)
+		return
+	}
+	confirmation := m.UploadConfirmation{}
+	if err := parseValidateJSON(c, &confirmation, h.logger); err != nil {
+		return
+	}
+
+	media, err := h.filePermissionService.ConfirmUpload(&confirmation, getClientInfo(c))
+	if err == nil {
+		successResp(c, MsgUploadConfirmed, media)
+		return
+	}
+	switch code := err.GetCode(); code {
+	case s.SEInternal:
+		h.logger.Errorf("Failed to confirm upload (%s)", err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	case s.SEAuthFailed:
+		h.logger.Debugf("Failed to confirm upload: %s", err.Error())
+		unauthorizedResp(c, MsgAuthFailed, MsgLoginAgain)
+	case s.SEForbidden:
+		h.logger.Debugf("Failed to confirm upload: %s", err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgEventOwnerMismatchedJP)
+	case s.SENotFound:
+		h.logger.Debugf("Failed to confirm upload: %s", err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgDoc), MsgCheckInfoAgain)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to confirm upload: %s", err.Error())
+		badRequestResp(c, MsgBadValue, MsgFileNotAllowed)
+	default:
+		h.logger.Errorf("Unexpected error code %d (%s)", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	}
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
 	s "DMS/internal/services"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,12 +21,14 @@ func newUserHttp(userService s.UserService, logger l.Logger) UserHttp {
 
 // @Security BearerAuth
 // @Summary Create user
-// @Description Create a user and return its id. Each user must created by a job position.
+// @Description Create a user and return its id. Each user must created by a job position that is allowed to create users.
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param admin body models.User true "User"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
 // @Success 200 {object} HttpResponse{details=idResponse} "Success creating admin"
+// @Failure 403 {object} HttpResponse{details=string} "The job position is not allowed to create users"
 // @Failure 409 {object} HttpResponse{details=string} "This user exists previously or disabled"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 400 {object} HttpResponse{details=string} "Bad request error"
@@ -34,7 +38,15 @@ func (h *UserHttp) CreateUser(c *gin.Context) {
 	if err := parseValidateJSON(c, &user, h.logger); err != nil {
 		return
 	}
-	id, err := h.userService.CreateUser(user.Name, user.PhoneNumber, *user.CreatedBy)
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	callerJPID := getCallerJP(c, jwt, h.logger)
+	if callerJPID == nil {
+		return
+	}
+	id, err := h.userService.CreateUser(user.Name, user.PhoneNumber, jwt.UserID, *callerJPID, getClientInfo(c))
 	if err == nil {
 		h.logger.Debugf("Created user with id %s successfully", id.String())
 		successResp(c, MsgUserCreated, newIDResponse(*id))
@@ -48,18 +60,29 @@ func (h *UserHttp) CreateUser(c *gin.Context) {
 	case s.SEDBError:
 		h.logger.Infof("Failed to create user with phone number %s (%s)", user.PhoneNumber, err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to create user: %s", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to create user: %s", err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	default:
+		h.logger.Panicf("Unexpected error code %d: %s", code, err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
 	}
 }
 
 // @Security BearerAuth
 // @Summary Create admin
-// @Description Create admin user and return its id. Admin users are users that don't have created by any user.
+// @Description Create admin user and return its id. Admin users are users that don't have created by any user. Just admin job positions could create admins. The first admin is created by the bootstrap-admin command of dmsctl.
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param adminUser body models.AdminUser true "AdminUser"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
 // @Success 200 {object} HttpResponse{details=idResponse} "Success creating admin"
-// @Failure 409 {object} HttpResponse{details=string} "This user exists previously or disabled"
+// @Failure 403 {object} HttpResponse{details=string} "The job position is not admin"
+// @Failure 409 {object} HttpResponse{details=string} "This user exists previously"
 // @Failure 500 {object} HttpResponse{details=string} "Server or database error"
 // @Failure 400 {object} HttpResponse{details=string} "Bad request error"
 // @Router /users/admin [post]
@@ -68,18 +91,34 @@ func (h *UserHttp) CreateAdmin(c *gin.Context) {
 	if err := parseValidateJSON(c, &user, h.logger); err != nil {
 		return
 	}
-	id, err := h.userService.CreateAdmin(user.Name, user.PhoneNumber)
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	callerJPID := getCallerJP(c, jwt, h.logger)
+	if callerJPID == nil {
+		return
+	}
+	id, err := h.userService.CreateAdmin(jwt.UserID, *callerJPID, user.Name, user.PhoneNumber, getClientInfo(c))
 	if err == nil {
 		successResp(c, MsgAdminCreated, newIDResponse(*id))
 		h.logger.Debugf("Created admin with id %s successfully", id.String())
 		return
 	}
 	switch code := err.GetCode(); code {
-	case s.SEIsDisabled:
-		conflictErrResp(c, MsgDisabledUser, MsgFixDisabledUserProblem)
 	case s.SEExists:
 		conflictErrResp(c, MsgUserExists, MsgUserExistsExpanded)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to create admin (%s)", err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to create admin (%s)", err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
 	case s.SEDBError:
+		h.logger.Errorf("Failed to create admin (%s)", err.Error())
+		serverErrResp(c, MsgServerError, MsgTryAgain)
+	default:
+		h.logger.Panicf("Unexpected error code %d: %s", code, err.Error())
 		serverErrResp(c, MsgServerError, MsgTryAgain)
 	}
 }
@@ -113,3 +152,188 @@ func (h *UserHttp) GetCurrentUserInfo(c *gin.Context) {
 		serverErrResp(c, MsgServerError, MsgTryAgain)
 	}
 }
+
+// @Security BearerAuth
+// @Summary Get users
+// @Description Get last N created users. Just users created by users of the caller job position or its nested childs are returned, unless the caller be admin. To get the next page, pass the returned next_cursor as the cursor query.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param limit query int false "Limit of users to fetch. Default is 40. Max is 100."
+// @Param cursor query string false "Cursor of the page returned in the previous response. If it's empty, the first page is returned."
+// @Success 200 {object} HttpResponse{details=models.UsersPage} "Users and cursor of the next page"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 403 {object} HttpResponse{details=string} "Jon position doesn't belong to current user."
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /users [get]
+func (h *UserHttp) GetUsers(c *gin.Context) {
+	queryParser := newQueryParser(c, h.logger)
+	limitDefaultValue := uint64(40)
+	limit, _ := queryParser.ParseUInt("limit", &limitDefaultValue)
+	maxLimit := uint64(100)
+	if *limit > maxLimit {
+		*limit = maxLimit
+	} else if *limit < 1 {
+		*limit = 1
+	}
+	cursor, err := queryParser.ParseCursor("cursor")
+	if err != nil {
+		return
+	}
+	jwt := getJWT(c, h.logger)
+	if jwt == nil {
+		return
+	}
+	callerJPID := getCallerJP(c, jwt, h.logger)
+	if callerJPID == nil {
+		return
+	}
+
+	users, nextCursor, err2 := h.userService.GetUsers(jwt.UserID, *callerJPID, cursor, *limit)
+	if err2 == nil {
+		h.logger.Debugf("Fetched %d users for job position id %s. (limit: %d, cursor: %+v)",
+			len(*users), callerJPID.String(), *limit, cursor)
+		successResp(c, MsgSuccessAction, m.UsersPage{Users: *users, NextCursor: encodeNextCursor(nextCursor)})
+		return
+	}
+	h.handleUserAccessErr(c, err2, "fetch users")
+}
+
+// @Security BearerAuth
+// @Summary Edit user
+// @Description Edit name and/or phone number of the user. The caller job position must be allowed to manage users and, unless it's admin, the user must be created by a user of its subtree.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param id path string true "User id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Param update body models.UserUpdate true "Fields to edit. Omitted fields remain unchanged."
+// @Success 200 {object} HttpResponse{details=string} "Success editing user"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 409 {object} HttpResponse{details=string} "Another user with the phone number exists"
+// @Failure 404 {object} HttpResponse{details=string} "The user doesn't exist in the subtree of the caller"
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to manage users"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /users/{id} [patch]
+func (h *UserHttp) UpdateUser(c *gin.Context) {
+	userID, callerJPID, jwt := h.parseUserParams(c)
+	if jwt == nil {
+		return
+	}
+	update := m.UserUpdate{}
+	if err := parseValidateJSON(c, &update, h.logger); err != nil {
+		return
+	}
+
+	err := h.userService.UpdateUser(jwt.UserID, *callerJPID, *userID, &update, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s edited user %s.", callerJPID.String(), userID.String())
+		successResp(c, MsgUserUpdated, MsgSuccessAction)
+		return
+	}
+	h.handleUserAccessErr(c, err, "edit user")
+}
+
+// @Security BearerAuth
+// @Summary Disable user
+// @Description Disable the user and revoke all of its sessions, so it couldn't login or use the APIs anymore. The access of the caller is the same as editing the user. Users couldn't disable themselves.
+// @Tags user
+// @Produce json
+// @Param id path string true "User id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success disabling user"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The user doesn't exist in the subtree of the caller"
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to manage users"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /users/{id}/disable [post]
+func (h *UserHttp) DisableUser(c *gin.Context) {
+	userID, callerJPID, jwt := h.parseUserParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.userService.DisableUser(jwt.UserID, *callerJPID, *userID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s disabled user %s.", callerJPID.String(), userID.String())
+		successResp(c, MsgUserDisabled, MsgSuccessAction)
+		return
+	}
+	h.handleUserAccessErr(c, err, "disable user")
+}
+
+// @Security BearerAuth
+// @Summary Enable user
+// @Description Enable the disabled user. The access of the caller is the same as editing the user.
+// @Tags user
+// @Produce json
+// @Param id path string true "User id"
+// @Param jpid query string false "Job position id of the caller. It's required if the JWT doesn't contain a job position."
+// @Success 200 {object} HttpResponse{details=string} "Success enabling user"
+// @Failure 500 {object} HttpResponse{details=string} "Server or database error"
+// @Failure 404 {object} HttpResponse{details=string} "The user doesn't exist in the subtree of the caller"
+// @Failure 403 {object} HttpResponse{details=string} "The caller job position doesn't belong to current user or is not allowed to manage users"
+// @Failure 400 {object} HttpResponse{details=string} "Bad request error"
+// @Router /users/{id}/enable [post]
+func (h *UserHttp) EnableUser(c *gin.Context) {
+	userID, callerJPID, jwt := h.parseUserParams(c)
+	if jwt == nil {
+		return
+	}
+
+	err := h.userService.EnableUser(jwt.UserID, *callerJPID, *userID, getClientInfo(c))
+	if err == nil {
+		h.logger.Debugf("Job position %s enabled user %s.", callerJPID.String(), userID.String())
+		successResp(c, MsgUserEnabled, MsgSuccessAction)
+		return
+	}
+	h.handleUserAccessErr(c, err, "enable user")
+}
+
+func (h *UserHttp) handleUserAccessErr(c *gin.Context, err *e.Error, action string) {
+	switch code := err.GetCode(); code {
+	case s.SEDBError:
+		h.logger.Errorf("Failed to %s (%s)", action, err.Error())
+		customErrResp(c, hCDBError, MsgServerError, MsgTryAgain)
+	case s.SEJPNotMatchedUser:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		customErrResp(c, hCJPNotMatchedUser, MsgJPNotBelongsUser, MsgReferAdmin)
+	case s.SENotPermission:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		forbiddenErrResp(c, MsgNotPermission, MsgReferAdmin)
+	case s.SENotFound:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		notFoundResp(c, fmt.Sprintf(MsgNotFoundC, MsgUser), MsgCheckInfoAgain)
+	case s.SEExists:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		conflictErrResp(c, MsgPhoneExists, MsgCheckInfoAgain)
+	case s.SEWrongParameter:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		badRequestResp(c, MsgBadValue, MsgEmptyNameOrPhone)
+	case s.SESelfAction:
+		h.logger.Debugf("Failed to %s: %s", action, err.Error())
+		badRequestResp(c, MsgBadValue, MsgCantChangeSelf)
+	default:
+		h.logger.Panicf("Unexpected error code %d (%s)", code, err.Error())
+		customErrResp(c, hcUnexpectedError, MsgServerError, MsgTryAgain)
+	}
+}
+
+// Parse user id (from the url path) and the caller job position id (from the JWT or the
+// url query) of the requests related to a specific user. If it couldn't parse them or
+// there's not any JWT, sends proper HTTP response to the client and the returned JWT
+// would be nil.
+func (h *UserHttp) parseUserParams(c *gin.Context) (userID, callerJPID *m.ID, jwt *m.JWT) {
+	var err error
+	if userID, err = newParamParser(c, h.logger).parseID("id", nil); err != nil {
+		return nil, nil, nil
+	}
+	if jwt = getJWT(c, h.logger); jwt == nil {
+		return nil, nil, nil
+	}
+	if callerJPID = getCallerJP(c, jwt, h.logger); callerJPID == nil {
+		return nil, nil, nil
+	}
+	return userID, callerJPID, jwt
+}
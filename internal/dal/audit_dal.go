@@ -0,0 +1,114 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"fmt"
+	"time"
+)
+
+type AuditDAL interface {
+	// Append the event to the audit log.
+	CreateAuditEvent(event *m.AuditEvent) error
+	// Return some last events of the audit log (at most limit events) that match the filter
+	// and come after the cursor, together with the cursor of the last returned event. If
+	// there's not any more event, the returned cursor is nil.
+	// If jpIDs be nil, return events of all actors; otherwise just events done with one of
+	// the job positions.
+	GetAuditEvents(jpIDs *[]m.ID, cursor *m.Cursor, limit int, filter *m.AuditFilter) (*[]m.AuditEvent, *m.Cursor, error)
+}
+
+type psqlAuditDAL struct {
+	db     *db.PSQLDB
+	logger l.Logger
+}
+
+func newPsqlAuditDAL(db *db.PSQLDB, logger l.Logger) *psqlAuditDAL {
+	return &psqlAuditDAL{db, logger}
+}
+
+func (d *psqlAuditDAL) CreateAuditEvent(event *m.AuditEvent) error {
+	newEvent := db.AuditEvent{
+		ActorUserID: modelID2DBID(&event.ActorUserID),
+		ActorJPID:   modelID2DBID(&event.ActorJPID),
+		Action:      string(event.Action),
+		TargetType:  string(event.TargetType),
+		TargetID:    modelID2DBID(&event.TargetID),
+		IP:          event.IP,
+		UserAgent:   event.UserAgent,
+		Outcome:     string(event.Outcome),
+		Details:     event.Details,
+	}
+	if result := d.db.Create(&newEvent); result.Error != nil {
+		return fmt.Errorf("failed to store audit event %s: %s", event.Action, result.Error.Error())
+	}
+	return nil
+}
+
+func (d *psqlAuditDAL) GetAuditEvents(jpIDs *[]m.ID, cursor *m.Cursor, limit int, filter *m.AuditFilter) (*[]m.AuditEvent, *m.Cursor, error) {
+	query := d.db.Model(&db.AuditEvent{})
+	if jpIDs != nil {
+		query = query.Where("audit_events.actor_jp_id IN ?", *modelIDs2DBIDs(jpIDs))
+	}
+	if filter != nil {
+		query = applyAuditFilter(query, filter)
+	}
+	// Fetch one more event to find out if there are more events after this page.
+	var events []db.AuditEvent
+	result := applyCursor(query, "audit_events", cursor).Limit(limit + 1).Find(&events)
+	if result.Error != nil {
+		return nil, nil, fmt.Errorf("failed to get last %d audit events after cursor %+v: %s", limit, cursor,
+			result.Error.Error())
+	}
+
+	var nextCursor *m.Cursor
+	if len(events) > limit {
+		events = events[:limit]
+		nextCursor = newCursor(events[limit-1].CreatedAt, events[limit-1].ID)
+	}
+	modelEvents := make([]m.AuditEvent, len(events))
+	for i := range events {
+		modelEvents[i] = *dbAuditEvent2ModelAuditEvent(&events[i])
+	}
+	return &modelEvents, nextCursor, nil
+}
+
+// Apply the conditions of the filter on the query over the audit_events table.
+func applyAuditFilter(tx *db.PSQLDB, filter *m.AuditFilter) *db.PSQLDB {
+	if filter.Action != nil {
+		tx = tx.Where("audit_events.action = ?", string(*filter.Action))
+	}
+	if filter.Outcome != nil {
+		tx = tx.Where("audit_events.outcome = ?", string(*filter.Outcome))
+	}
+	if filter.ActorJPID != nil {
+		tx = tx.Where("audit_events.actor_jp_id = ?", *modelID2DBID(filter.ActorJPID))
+	}
+	if filter.TargetID != nil {
+		tx = tx.Where("audit_events.target_id = ?", *modelID2DBID(filter.TargetID))
+	}
+	if filter.CreatedFrom != nil {
+		tx = tx.Where("audit_events.created_at >= ?", time.Unix(*filter.CreatedFrom, 0))
+	}
+	if filter.CreatedTo != nil {
+		tx = tx.Where("audit_events.created_at <= ?", time.Unix(*filter.CreatedTo, 0))
+	}
+	return tx
+}
+
+func dbAuditEvent2ModelAuditEvent(event *db.AuditEvent) *m.AuditEvent {
+	return &m.AuditEvent{
+		ID:          *dbID2ModelID(&event.ID),
+		ActorUserID: *dbID2ModelID(event.ActorUserID),
+		ActorJPID:   *dbID2ModelID(event.ActorJPID),
+		Action:      m.AuditAction(event.Action),
+		TargetType:  m.AuditTargetType(event.TargetType),
+		TargetID:    *dbID2ModelID(event.TargetID),
+		IP:          event.IP,
+		UserAgent:   event.UserAgent,
+		Outcome:     m.AuditOutcome(event.Outcome),
+		Details:     event.Details,
+		CreatedAt:   event.CreatedAt.UTC().Unix(),
+	}
+}
@@ -0,0 +1,59 @@
+package dal
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetAuditEventsScope(t *testing.T) {
+	testDB := newTestDB(t)
+	auditDAL := newPsqlAuditDAL(testDB, l.NewSLogger(l.None, nil, io.Discard))
+	// The first two job positions belong to the same user.
+	userID, otherUserID := m.ID(uuid.New()), m.ID(uuid.New())
+	jpID, sameUserJPID, otherJPID := m.ID(uuid.New()), m.ID(uuid.New()), m.ID(uuid.New())
+	ids := map[string]m.ID{}
+	for name, event := range map[string]m.AuditEvent{
+		"job position":       {ActorUserID: userID, ActorJPID: jpID, Action: m.AuditJPUpdate, Outcome: m.AuditSuccess},
+		"same user":          {ActorUserID: userID, ActorJPID: sameUserJPID, Action: m.AuditJPMove, Outcome: m.AuditSuccess},
+		"other job position": {ActorUserID: otherUserID, ActorJPID: otherJPID, Action: m.AuditJPDisable, Outcome: m.AuditFailure},
+	} {
+		if err := auditDAL.CreateAuditEvent(&event); err != nil {
+			t.Fatalf("failed to create the audit event: %s", err.Error())
+		}
+		// The ids are not returned on creating, so the events are found by their actions.
+		action := event.Action
+		events, _, err := auditDAL.GetAuditEvents(nil, nil, 10, &m.AuditFilter{Action: &action})
+		if err != nil || len(*events) != 1 {
+			t.Fatalf("failed to get the created audit event: %v", err)
+		}
+		ids[name] = (*events)[0].ID
+	}
+
+	tests := []struct {
+		name     string
+		jpIDs    *[]m.ID
+		expected []string
+	}{
+		{name: "events of the job position, not of its user", jpIDs: &[]m.ID{jpID}, expected: []string{"job position"}},
+		{name: "events of the job positions", jpIDs: &[]m.ID{jpID, otherJPID},
+			expected: []string{"job position", "other job position"}},
+		{name: "events of all actors", expected: []string{"job position", "same user", "other job position"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, _, err := auditDAL.GetAuditEvents(test.jpIDs, nil, 10, nil)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			var eventIDs []m.ID
+			for _, event := range *events {
+				eventIDs = append(eventIDs, event.ID)
+			}
+			checkTestNamedIDs(t, eventIDs, ids, test.expected)
+		})
+	}
+}
@@ -8,8 +8,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
 )
 
+// PostgreSQL error code of violating a unique constraint
+const uniqueViolationCode = "23505"
+
+// Return true if the error is caused by violating a unique constraint or index.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
 // If input be nil, return nil
 func dbID2ModelID(id *db.ID) *m.ID {
 	if id == nil {
@@ -71,29 +84,78 @@ func dbPhone2ModelPhone(phone string) m.PhoneNumber {
 	return m.PhoneNumber(phone)
 }
 
+// Apply the time range, creator and region conditions of the filter on the query over
+// the table. If filter be nil, return the query itself.
+func applyListFilter(tx *db.PSQLDB, table string, filter *m.ListFilter) *db.PSQLDB {
+	if filter == nil {
+		return tx
+	}
+	if filter.CreatedFrom != nil {
+		tx = tx.Where(table+".created_at >= ?", time.Unix(*filter.CreatedFrom, 0))
+	}
+	if filter.CreatedTo != nil {
+		tx = tx.Where(table+".created_at <= ?", time.Unix(*filter.CreatedTo, 0))
+	}
+	if filter.CreatedBy != nil {
+		tx = tx.Where(table+".created_by_id = ?", *modelID2DBID(filter.CreatedBy))
+	}
+	if filter.RegionID != nil {
+		tx = tx.Where(table+".created_by_id IN (?)", tx.Session(&gorm.Session{NewDB: true}).
+			Model(&db.JobPosition{}).Select("id").
+			Where(&db.JobPosition{RegionID: *modelID2DBID(filter.RegionID)}))
+	}
+	return tx
+}
+
+// Order the query over the table by creation time (newest first) and just keep rows
+// after the cursor. If cursor be nil, keep all rows.
+func applyCursor(tx *db.PSQLDB, table string, cursor *m.Cursor) *db.PSQLDB {
+	if cursor != nil {
+		tx = tx.Where("("+table+".created_at, "+table+".id) < (?, ?)",
+			time.UnixMicro(cursor.CreatedAt), *modelID2DBID(&cursor.ID))
+	}
+	return tx.Order(table + ".created_at desc").Order(table + ".id desc")
+}
+
+// Create cursor of a row with the given creation time and id
+func newCursor(createdAt time.Time, id db.ID) *m.Cursor {
+	return &m.Cursor{
+		CreatedAt: createdAt.UnixMicro(),
+		ID:        *dbID2ModelID(&id),
+	}
+}
+
 // DAL is a data access layer interface
 type DAL struct {
-	User       UserDAL
-	Doc        DocDAL
-	Event      EventDAL
-	JP         JPDAL
-	Permission PermissionDAL
-	Session    SessionDAL
+	User     UserDAL
+	Doc      DocDAL
+	Event    EventDAL
+	EventACL EventACLDAL
+	JP       JPDAL
+	Role     RoleDAL
+	Session  SessionDAL
+	Search   SearchDAL
+	Audit    AuditDAL
+	Dump     DumpDAL
 }
 
 // Connect to the database and implement DAL for PostgreSQL. The first argument is
 // connection details of psql database.
-// If autoMigrate be true, run auto migration schema to database
-func NewPostgresDAL(ConnDetails db.PsqlConnDetails, cache InMemoryDAL, logger l.Logger, autoMigrate bool) DAL {
+// If migrate be true, apply the migrations that are not applied yet to the database.
+func NewPostgresDAL(ConnDetails db.PsqlConnDetails, cache InMemoryDAL, logger l.Logger, migrate bool) DAL {
 	c := initCache(cache, logger)
-	db := db.NewPsqlConn(&ConnDetails, autoMigrate, logger)
+	db := db.NewPsqlConn(&ConnDetails, migrate, logger)
 	return DAL{
-		User:       newPsqlUserDAL(&db, logger),
-		Doc:        newPsqlDocDAL(&db, c, logger),
-		Event:      newPsqlEventDAL(&db, c, logger),
-		JP:         newPsqlJPDAL(&db, c, logger),
-		Permission: newPsqlPermissionDAL(&db, logger),
-		Session:    newPsqlSessionDAL(&db, logger),
+		User:     newPsqlUserDAL(&db, logger),
+		Doc:      newPsqlDocDAL(&db, c, logger),
+		Event:    newPsqlEventDAL(&db, c, logger),
+		EventACL: newPsqlEventACLDAL(&db, logger),
+		JP:       newPsqlJPDAL(&db, c, logger),
+		Role:     newPsqlRoleDAL(&db, logger),
+		Session:  newPsqlSessionDAL(&db, c, logger),
+		Search:   newPsqlSearchDAL(&db, logger),
+		Audit:    newPsqlAuditDAL(&db, logger),
+		Dump:     newPsqlDumpDAL(&db, logger),
 	}
 }
 
@@ -162,6 +224,17 @@ func (c *cache) write(key string, value any) error {
 	return nil
 }
 
+// Delete the key from the cache. If deleted successfully, return true, otherwise return false.
+// If an error occurs, the method will handle it itself.
+func (c *cache) delete(key string) bool {
+	if err := c.cache.Delete(key); err != nil {
+		c.logger.Errorf("Can't delete an entity with key \"%s\" from cache: %s", key, err.Error())
+		return false
+	}
+	c.logger.Debugf("Successfully deleted an entity with key \"%s\" from cache", key)
+	return true
+}
+
 // Set the value of the key. If set successfully, return true, otherwise return false.
 // If an error occurs, the method will handle it itself.
 func (c *cache) set(key string, value any) bool {
@@ -172,3 +245,18 @@ func (c *cache) set(key string, value any) bool {
 	c.logger.Debugf("Successfully write an entity with key \"%s\" to cache", key)
 	return true
 }
+
+// Set the value of the key that expires after the given duration. If set successfully,
+// return true, otherwise return false. If an error occurs, the method will handle it itself.
+func (c *cache) setWithExpire(key string, value any, expire time.Duration) bool {
+	stringVal, err := json.Marshal(value)
+	if err == nil {
+		err = c.cache.SetWithExpire(key, string(stringVal), expire)
+	}
+	if err != nil {
+		c.logger.Errorf("Can't write an entity with key \"%s\" to cache: %s", key, err.Error())
+		return false
+	}
+	c.logger.Debugf("Successfully write an entity with key \"%s\" to cache", key)
+	return true
+}
@@ -0,0 +1,249 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Name of the environment variable of the PostgreSQL connection string used by the
+// database tests. (e.g. "host=localhost user=dms password=dms dbname=dms_test") Each test
+// gets a new schema of the database and the schema is dropped at the end of the test.
+// If it's not set, the database tests are skipped.
+const testPsqlDSNEnv = "DMS_TEST_PSQL_DSN"
+
+// Open a database with the given connection config. It's closed at the end of the test.
+func openTestDB(t *testing.T, config *pgx.ConnConfig) *db.PSQLDB {
+	t.Helper()
+	testDB, err := gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*config)}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open the test database: %s", err.Error())
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := testDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return testDB
+}
+
+// Return the test database in a new schema that all migrations are applied to it.
+func newTestDB(t *testing.T) *db.PSQLDB {
+	t.Helper()
+	dsn := os.Getenv(testPsqlDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, so the database tests are skipped", testPsqlDSNEnv)
+	}
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %s", testPsqlDSNEnv, err.Error())
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	adminDB := openTestDB(t, config.Copy())
+	// The extension is created in the public schema, so dropping the test schema doesn't
+	// drop it.
+	if err := adminDB.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp" SCHEMA public`).Error; err != nil {
+		t.Fatalf("failed to create uuid-ossp extension: %s", err.Error())
+	}
+	if err := adminDB.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("failed to create schema %s: %s", schema, err.Error())
+	}
+	t.Cleanup(func() {
+		if err := adminDB.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("failed to drop schema %s: %s", schema, err.Error())
+		}
+	})
+
+	config.RuntimeParams["search_path"] = schema + ", public"
+	testDB := openTestDB(t, config)
+	if _, err := db.MigrateUp(testDB, l.NewSLogger(l.None, nil, io.Discard)); err != nil {
+		t.Fatalf("failed to migrate the test database: %s", err.Error())
+	}
+	return testDB
+}
+
+// Create a user with a job position in the test database and return id of the job
+// position. parentID is the first parent of the job position. (nil for admins)
+func createTestJP(t *testing.T, testDB *db.PSQLDB, parentID *m.ID) m.ID {
+	t.Helper()
+	user := db.User{Name: "user", PhoneNumber: "917" + strings.ReplaceAll(uuid.NewString(), "-", "")[:7]}
+	if err := testDB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create the user: %s", err.Error())
+	}
+	jp := db.JobPosition{UserID: user.ID, Title: "job position", ParentID: modelID2DBID(parentID)}
+	if err := testDB.Create(&jp).Error; err != nil {
+		t.Fatalf("failed to create the job position: %s", err.Error())
+	}
+	return *dbID2ModelID(&jp.ID)
+}
+
+// It keeps the values in memory and ignores their expiration.
+type memCache struct {
+	InMemoryDAL
+	values map[string]string
+}
+
+func newTestCache() *cache {
+	return initCache(&memCache{values: map[string]string{}}, l.NewSLogger(l.None, nil, io.Discard))
+}
+
+func (c *memCache) Get(key string) (*string, error) {
+	if value, ok := c.values[key]; ok {
+		return &value, nil
+	}
+	return nil, nil
+}
+
+func (c *memCache) Set(key, value string) error {
+	c.values[key] = value
+	return nil
+}
+
+func (c *memCache) SetWithExpire(key, value string, expire time.Duration) error {
+	return c.Set(key, value)
+}
+
+func (c *memCache) Delete(key string) error {
+	delete(c.values, key)
+	return nil
+}
+
+// Set creation time of the rows of the table to the given time, so their order depends
+// on their ids.
+func setTestCreatedAt(t *testing.T, testDB *db.PSQLDB, table string, createdAt time.Time, ids ...m.ID) {
+	t.Helper()
+	if err := testDB.Table(table).Where("id IN ?", *modelIDs2DBIDs(&ids)).
+		Update("created_at", createdAt).Error; err != nil {
+		t.Fatalf("failed to set creation time of %s: %s", table, err.Error())
+	}
+}
+
+// Get all pages of a list with the given page sizes and check the ids of the pages
+// together are the expected ids in order, without any duplicate or gap. getPage returns
+// ids of the page after the cursor and the cursor of the next page.
+func checkTestPages(t *testing.T, expected []m.ID, getPage func(cursor *m.Cursor, limit int) ([]m.ID, *m.Cursor)) {
+	t.Helper()
+	for _, limit := range []int{1, 2, len(expected)} {
+		var ids []m.ID
+		var cursor *m.Cursor
+		for page := 0; page <= len(expected); page++ {
+			pageIDs, nextCursor := getPage(cursor, limit)
+			if len(pageIDs) > limit {
+				t.Fatalf("expected at most %d items in a page, got %d", limit, len(pageIDs))
+			}
+			ids = append(ids, pageIDs...)
+			if nextCursor == nil {
+				break
+			}
+			cursor = nextCursor
+		}
+		if len(ids) != len(expected) {
+			t.Fatalf("expected %d items with limit %d, got %d", len(expected), limit, len(ids))
+		}
+		for i := range expected {
+			if ids[i] != expected[i] {
+				t.Errorf("expected item %s at %d with limit %d, got %s", expected[i].String(), i, limit, ids[i].String())
+			}
+		}
+	}
+}
+
+// Return the ids in order of the keyset pagination of rows with the same creation time.
+// (The greater ids come first)
+func sortTestIDsDesc(ids []m.ID) []m.ID {
+	sorted := slices.Clone(ids)
+	slices.SortFunc(sorted, func(a, b m.ID) int { return strings.Compare(b.String(), a.String()) })
+	return sorted
+}
+
+// Rows of the test database to check the list filters
+type testFilterData struct {
+	// Ids of the events and docs by their names
+	ids map[string]m.ID
+	// The creator of the image event and the old event
+	adminID m.ID
+	// The creator of the video event and the text event, that is in the region
+	memberID m.ID
+	regionID m.ID
+	// The old event and its doc are created before it and the others after it.
+	createdBefore time.Time
+}
+
+// Create the events below with a doc in each of them. The docs are named after their
+// events, e.g. "image doc".
+//
+//	old:   created two days ago by the admin, without any file
+//	image: created by the admin with an image
+//	video: created by the member with a video
+//	text:  created by the member without any file
+func createTestFilterData(t *testing.T, testDB *db.PSQLDB) *testFilterData {
+	t.Helper()
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), testLogger)
+	docDAL := newPsqlDocDAL(testDB, newTestCache(), testLogger)
+	data := &testFilterData{ids: map[string]m.ID{}, regionID: m.ID(uuid.New())}
+	data.adminID = createTestJP(t, testDB, nil)
+	data.memberID = createTestJP(t, testDB, &data.adminID)
+	if err := testDB.Model(&db.JobPosition{}).Where("id = ?", *modelID2DBID(&data.memberID)).
+		Update("region_id", *modelID2DBID(&data.regionID)).Error; err != nil {
+		t.Fatalf("failed to set region of the job position: %s", err.Error())
+	}
+
+	for _, event := range []struct {
+		name      string
+		createdBy m.ID
+		paths     []m.MediaPath
+	}{
+		{name: "old", createdBy: data.adminID},
+		{name: "image", createdBy: data.adminID, paths: []m.MediaPath{{Type: m.MediaImage, Src: "docs/a.jpg", FileName: "a.jpg", Size: 20}}},
+		{name: "video", createdBy: data.memberID, paths: []m.MediaPath{{Type: m.MediaVideo, Src: "docs/b.mp4", FileName: "b.mp4", Size: 40}}},
+		{name: "text", createdBy: data.memberID},
+	} {
+		eventID, err := eventDAL.CreateEvent(&m.Event{Name: event.name, CreatedBy: event.createdBy})
+		if err != nil {
+			t.Fatalf("failed to create the event: %s", err.Error())
+		}
+		docID, err := docDAL.CreateDoc(&m.Doc{EventID: *eventID, CreatedBy: event.createdBy, Paths: event.paths})
+		if err != nil {
+			t.Fatalf("failed to create the doc: %s", err.Error())
+		}
+		data.ids[event.name], data.ids[event.name+" doc"] = *eventID, *docID
+	}
+	data.createdBefore = time.Now().Add(-24 * time.Hour)
+	oldCreatedAt := data.createdBefore.Add(-24 * time.Hour)
+	setTestCreatedAt(t, testDB, "events", oldCreatedAt, data.ids["old"])
+	setTestCreatedAt(t, testDB, "docs", oldCreatedAt, data.ids["old doc"])
+	return data
+}
+
+// Check the ids are the ids of the expected names in any order.
+func checkTestNamedIDs(t *testing.T, ids []m.ID, namedIDs map[string]m.ID, expected []string) {
+	t.Helper()
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for name, namedID := range namedIDs {
+			if id == namedID {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	expected = slices.Sorted(slices.Values(expected))
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
@@ -2,12 +2,14 @@ package dal
 
 import (
 	"DMS/internal/db"
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"time"
 
-	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DocDAL interface {
@@ -15,14 +17,50 @@ type DocDAL interface {
 	CreateDoc(doc *m.Doc) (*m.ID, error)
 	// Get n "last" docs by the event id.
 	GetNLastDocByEventID(eventID m.ID, n int) (*[]m.Doc, error)
-	// Get some last docs (specified by offset and limit) created by the job position id
-	// If both result and error be nil, means that there are no docs created by the job position.
-	GetNLastDocsByJPID(jpID m.ID, offset, limit int) (*[]m.DocWithSomeDetails, error)
-	// Return some last docs (specified by offset and limit)
-	GetNLastDocs(limit, offset int) (*[]m.DocWithSomeDetails, error)
+	// Return some last docs (at most limit docs) created by one of the given job positions
+	// that match the filter and come after the cursor, together with the cursor of the last
+	// returned doc. If there's not any more doc, the returned cursor is nil.
+	// If jpIDs be nil, return docs of all job positions. If cursor be nil, start from the
+	// newest doc. If filter be nil, no filter is applied.
+	GetNLastDocs(jpIDs *[]m.ID, cursor *m.Cursor, limit int, filter *m.ListFilter) (*[]m.DocWithSomeDetails, *m.Cursor, error)
 	// Get latest created documents of event with event_id by user_id. Then return that
 	// document together with the name of event and user.
 	GetLastEventDocByUserID(event_id m.ID, user_id m.ID) (doc *m.Doc, event_name string, user_name string, err error)
+	// Return the doc with its multimedia files. If both doc and error be nil, means
+	// there's not any doc with the given id.
+	GetDocByID(docID m.ID) (*m.Doc, error)
+	// Return the doc with its multimedia files together with name of its event and title
+	// of the job position created it. If both doc and error be nil, means there's not any
+	// doc with the given id or its event is deleted.
+	GetDocWithDetailsByID(docID m.ID) (*m.DocWithSomeDetails, error)
+	// Store the current context and multimedia files of the doc as a new version edited
+	// by the given job position and then apply the update on the doc. Pending files of
+	// the update that are confirmed previously in the doc remain confirmed. If there's not
+	// any doc with the given id, return (false, nil).
+	UpdateDoc(docID, editorJPID m.ID, update *m.DocUpdate) (bool, error)
+	// Soft delete the doc and its multimedia files. If there's not any doc with the
+	// given id, return (false, nil).
+	DeleteDoc(docID m.ID) (bool, error)
+	// Return all previous versions of the doc. The newest versions come first.
+	GetDocVersions(docID m.ID) (*[]m.DocVersion, error)
+	// Return the specified version of the doc. If both version and error be nil, means
+	// there's not such version.
+	GetDocVersion(docID m.ID, version uint) (*m.DocVersion, error)
+	// Return id of the event of the doc, if the doc has a confirmed multimedia file with
+	// the file name. If both id and error be nil, means there's not such file or doc.
+	GetMediaEventID(docID m.ID, fileName string) (*m.ID, error)
+	// Mark the pending multimedia file of the doc with the same file name as confirmed and
+	// set its details from the given file. If the quotas are not zero, the confirmed files
+	// of the event and the job position created the doc together with this file must not
+	// exceed them; otherwise e.ErrQuotaExceeded is returned. If the doc doesn't have such
+	// pending file, return (false, nil).
+	ConfirmMedia(docID m.ID, media *m.MediaPath, eventQuotaKB, jpQuotaKB uint64) (bool, error)
+	// Return total size of the confirmed multimedia files of the docs of the event in
+	// Kbytes.
+	GetEventMediaSize(eventID m.ID) (uint64, error)
+	// Return total size of the confirmed multimedia files of the docs created by the job
+	// position in Kbytes.
+	GetJPMediaSize(jpID m.ID) (uint64, error)
 }
 
 const (
@@ -71,72 +109,300 @@ func (d *psqlDocDAL) GetNLastDocByEventID(eventID m.ID, n int) (*[]m.Doc, error)
 	return dbDocs2modelDocs(docs, d.logger), nil
 }
 
-func (d *psqlDocDAL) GetNLastDocsByJPID(jpID m.ID, offset, limit int) (*[]m.DocWithSomeDetails, error) {
-	// var docs *[]db.Doc
+func (d *psqlDocDAL) GetNLastDocs(jpIDs *[]m.ID, cursor *m.Cursor, limit int, filter *m.ListFilter) (*[]m.DocWithSomeDetails, *m.Cursor, error) {
 	var docs []struct {
 		db.Doc
 		EventName string
+		JPName    string
 	}
-	result := d.db.Model(&db.Doc{}).
-		Select("docs.*, events.name as event_name").
-		Joins("INNER JOIN events ON docs.event_id = events.id").
-		Order("docs.created_at desc").Offset(offset).Limit(limit).Where(
-		&db.Doc{CreatedByID: *modelID2DBID(&jpID)},
-	).Find(&docs)
-	// result := d.db.Order("created_at desc").Offset(offset).Limit(limit).Where(
-	// 	&db.Doc{CreatedByID: *modelID2DBID(&jpID)},
-	// ).Find(&docs)
 
+	query := d.db.Model(&db.Doc{}).
+		Select("docs.*, events.name as event_name, job_positions.title as jp_name").
+		Joins("INNER JOIN events ON docs.event_id = events.id").
+		Joins("INNER JOIN job_positions ON docs.created_by_id = job_positions.id")
+	if jpIDs != nil {
+		query = query.Where("docs.created_by_id IN ?", *modelIDs2DBIDs(jpIDs))
+	}
+	// Fetch one more doc to find out if there are more docs after this page.
+	result := applyCursor(d.applyDocFilter(query, filter), "docs", cursor).Limit(limit + 1).Find(&docs)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get last %d with offset %d docs by jp-id %s: %s", limit, offset, jpID.String(), result.Error.Error())
+		return nil, nil, fmt.Errorf("failed to get last %d docs after cursor %+v: %s", limit, cursor, result.Error.Error())
 	}
 
-	modelDocs := make([]m.DocWithSomeDetails, 0)
+	var nextCursor *m.Cursor
+	if len(docs) > limit {
+		docs = docs[:limit]
+		nextCursor = newCursor(docs[limit-1].CreatedAt, docs[limit-1].ID)
+	}
+	modelDocs := make([]m.DocWithSomeDetails, 0, len(docs))
 	for _, doc := range docs {
 		modelDocs = append(modelDocs, m.DocWithSomeDetails{
 			Doc:       *dbDoc2modelDoc(&doc.Doc, d.logger),
 			EventName: doc.EventName,
+			JPName:    doc.JPName,
 		})
 	}
-	return &modelDocs, nil
+	return &modelDocs, nextCursor, nil
 }
 
-func (d *psqlDocDAL) GetNLastDocs(limit, offset int) (*[]m.DocWithSomeDetails, error) {
-	// var docs []db.Doc
-	var docs []struct {
-		db.Doc
-		EventName string `json:"event_name"`
-		JPName    string `json:"jp_name"`
+func (d *psqlDocDAL) GetLastEventDocByUserID(event_id m.ID, user_id m.ID) (doc *m.Doc, event_name string, user_name string, err error) {
+	return nil, "", "", nil
+}
+
+func (d *psqlDocDAL) GetDocByID(docID m.ID) (*m.Doc, error) {
+	var doc db.Doc
+	result := d.db.Preload("Multimedia").
+		Where(&db.Doc{BaseModel: db.BaseModel{ID: *modelID2DBID(&docID)}}).
+		Limit(1).Find(&doc)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get doc by id %s: %s", docID.String(), result.Error.Error())
+	} else if result.RowsAffected < 1 {
+		return nil, nil
 	}
+	return dbDoc2modelDoc(&doc, d.logger), nil
+}
 
-	result := d.db.Model(&db.Doc{}).
-		Select("docs.*, events.name as event_name, job_positions.title as jp_name").
-		Joins("INNER JOIN events ON docs.event_id = events.id").
+func (d *psqlDocDAL) GetDocWithDetailsByID(docID m.ID) (*m.DocWithSomeDetails, error) {
+	doc, err := d.GetDocByID(docID)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+
+	var details docDetails
+	result := d.docDetailsQuery(docID).Scan(&details)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get details of doc-id %s: %s", docID.String(), result.Error.Error())
+	} else if result.RowsAffected < 1 {
+		return nil, nil
+	}
+	return &m.DocWithSomeDetails{
+		Doc:       *doc,
+		EventName: details.EventName,
+		JPName:    details.JPName,
+	}, nil
+}
+
+// Name of the event and title of the creator of a doc
+type docDetails struct {
+	EventName string
+	JPName    string
+}
+
+// Return the query of details of the doc. Docs of the deleted events are not found.
+func (d *psqlDocDAL) docDetailsQuery(docID m.ID) *db.PSQLDB {
+	return d.db.Model(&db.Doc{}).
+		Select("events.name as event_name, job_positions.title as jp_name").
+		Joins("INNER JOIN events ON docs.event_id = events.id AND events.deleted_at IS NULL").
 		Joins("INNER JOIN job_positions ON docs.created_by_id = job_positions.id").
-		Order("docs.created_at desc").Offset(offset).Limit(limit).Find(&docs)
-	// result := d.db.Order("created_at desc").Offset(offset).Limit(limit).Find(&docs)
+		Where("docs.id = ?", *modelID2DBID(&docID)).
+		Limit(1)
+}
+
+func (d *psqlDocDAL) UpdateDoc(docID, editorJPID m.ID, update *m.DocUpdate) (bool, error) {
+	isFound := false
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		var doc db.Doc
+		// Lock the doc until the end of the transaction, so concurrent edits of the doc
+		// are applied one by one and each of them gets the next version number. The
+		// unique index of the version numbers rejects the duplicates anyway.
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Multimedia").
+			Where(&db.Doc{BaseModel: db.BaseModel{ID: *modelID2DBID(&docID)}}).
+			Limit(1).Find(&doc)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isFound = true
+
+		var lastVersion uint
+		if err := tx.Model(&db.DocVersion{}).Where(&db.DocVersion{DocID: doc.ID}).
+			Select("COALESCE(MAX(version), 0)").Scan(&lastVersion).Error; err != nil {
+			return err
+		}
+		paths := *dbMultimedias2ModelMultimedias(doc.Multimedia, d.logger)
+		if paths == nil {
+			paths = []m.MediaPath{}
+		}
+		multimedia, err := json.Marshal(paths)
+		if err != nil {
+			return fmt.Errorf("failed to encode multimedia files of the doc: %s", err.Error())
+		}
+		version := db.DocVersion{
+			DocID:      doc.ID,
+			Version:    lastVersion + 1,
+			EditedByID: *modelID2DBID(&editorJPID),
+			Context:    doc.Context,
+			Multimedia: string(multimedia),
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+
+		if update.Context != nil {
+			if err := tx.Model(&doc).Update("context", *update.Context).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&doc).Update("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if update.Paths != nil {
+			if err := tx.Where(&db.Multimedia{DocID: doc.ID}).Delete(&db.Multimedia{}).Error; err != nil {
+				return err
+			}
+			newMultimedia := *modelMultimedias2DBMultimedias(update.Paths, d.logger)
+			keepConfirmedMedia(doc.Multimedia, newMultimedia)
+			for i := range newMultimedia {
+				newMultimedia[i].DocID = doc.ID
+			}
+			if len(newMultimedia) > 0 {
+				if err := tx.Create(&newMultimedia).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update doc-id %s by job-position-id %s: %s",
+			docID.String(), editorJPID.String(), err.Error())
+	}
+	return isFound, nil
+}
+
+// Keep the confirmed files of the old multimedia files confirmed in the new ones. Each
+// pending file of newMultimedia that has a confirmed file with the same name in
+// oldMultimedia, takes the details and the status of the confirmed one.
+func keepConfirmedMedia(oldMultimedia *[]db.Multimedia, newMultimedia []db.Multimedia) {
+	confirmed := make(map[string]db.Multimedia)
+	if oldMultimedia != nil {
+		for _, media := range *oldMultimedia {
+			if media.Status == string(m.MediaConfirmed) {
+				confirmed[media.FileName] = media
+			}
+		}
+	}
+	for i := range newMultimedia {
+		if old, ok := confirmed[newMultimedia[i].FileName]; ok &&
+			newMultimedia[i].Status == string(m.MediaPending) {
+			newMultimedia[i].Src = old.Src
+			newMultimedia[i].Size = old.Size
+			newMultimedia[i].Checksum = old.Checksum
+			newMultimedia[i].MimeType = old.MimeType
+			newMultimedia[i].Status = old.Status
+		}
+	}
+}
+
+func (d *psqlDocDAL) DeleteDoc(docID m.ID) (bool, error) {
+	isDeleted := false
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		result := tx.Where(&db.Doc{BaseModel: db.BaseModel{ID: *modelID2DBID(&docID)}}).
+			Delete(&db.Doc{})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isDeleted = true
+		return tx.Where(&db.Multimedia{DocID: *modelID2DBID(&docID)}).Delete(&db.Multimedia{}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete doc-id %s: %s", docID.String(), err.Error())
+	}
+	return isDeleted, nil
+}
+
+func (d *psqlDocDAL) GetDocVersions(docID m.ID) (*[]m.DocVersion, error) {
+	var versions []docVersionRow
+	result := d.docVersionsQuery(docID).Order("doc_versions.version desc").Find(&versions)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
+		return nil, fmt.Errorf("failed to get versions of doc-id %s: %s", docID.String(), result.Error.Error())
+	}
+
+	modelVersions := make([]m.DocVersion, 0, len(versions))
+	for _, version := range versions {
+		modelVersion, err := version.toModel()
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to get last %d with offset %d docs: %s", limit, limit, result.Error.Error())
+		modelVersions = append(modelVersions, *modelVersion)
 	}
+	return &modelVersions, nil
+}
 
-	modelDocs := make([]m.DocWithSomeDetails, 0)
-	for _, doc := range docs {
-		modelDocs = append(modelDocs, m.DocWithSomeDetails{
-			Doc:       *dbDoc2modelDoc(&doc.Doc, d.logger),
-			EventName: doc.EventName,
-		})
+func (d *psqlDocDAL) GetDocVersion(docID m.ID, version uint) (*m.DocVersion, error) {
+	var versions []docVersionRow
+	result := d.docVersionsQuery(docID).Where("doc_versions.version = ?", version).Limit(1).Find(&versions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get version %d of doc-id %s: %s", version, docID.String(), result.Error.Error())
+	} else if len(versions) == 0 {
+		return nil, nil
 	}
-	return &modelDocs, nil
+	return versions[0].toModel()
 }
 
-func (d *psqlDocDAL) GetLastEventDocByUserID(event_id m.ID, user_id m.ID) (doc *m.Doc, event_name string, user_name string, err error) {
-	return nil, "", "", nil
+// A row of the doc versions query
+type docVersionRow struct {
+	db.DocVersion
+	EditorName string
+}
+
+func (r *docVersionRow) toModel() (*m.DocVersion, error) {
+	paths := make([]m.MediaPath, 0)
+	if err := json.Unmarshal([]byte(r.Multimedia), &paths); err != nil {
+		return nil, fmt.Errorf("failed to decode multimedia files of version %d of doc-id %s: %s",
+			r.Version, r.DocID.ToString(), err.Error())
+	}
+	return &m.DocVersion{
+		DocID:      *dbID2ModelID(&r.DocID),
+		Version:    r.Version,
+		Context:    r.Context,
+		Paths:      paths,
+		EditedBy:   *dbID2ModelID(&r.EditedByID),
+		EditorName: r.EditorName,
+		CreatedAt:  r.CreatedAt.UTC().Unix(),
+	}, nil
+}
+
+// Return a query over versions of the doc together with name of the editors.
+func (d *psqlDocDAL) docVersionsQuery(docID m.ID) *db.PSQLDB {
+	return d.db.Model(&db.DocVersion{}).
+		Select("doc_versions.*, users.name AS editor_name").
+		Joins("LEFT JOIN job_positions ON job_positions.id = doc_versions.edited_by_id").
+		Joins("LEFT JOIN users ON users.id = job_positions.user_id").
+		Where("doc_versions.doc_id = ?", *modelID2DBID(&docID))
+}
+
+// Apply the filter on the query over the docs table. If filter be nil, return the
+// query itself.
+func (d *psqlDocDAL) applyDocFilter(tx *db.PSQLDB, filter *m.ListFilter) *db.PSQLDB {
+	tx = applyListFilter(tx, "docs", filter)
+	if filter == nil {
+		return tx
+	}
+	if filter.EventID != nil {
+		tx = tx.Where("docs.event_id = ?", *modelID2DBID(filter.EventID))
+	}
+	return applyMediaFilter(tx, "SELECT 1 FROM multimedia WHERE multimedia.doc_id = docs.id "+
+		"AND multimedia.deleted_at IS NULL", filter, d.logger)
+}
+
+// Apply the media conditions of the filter on the query. mediaQuery is a query that
+// selects the multimedia files related to each row of the query.
+func applyMediaFilter(tx *db.PSQLDB, mediaQuery string, filter *m.ListFilter, logger l.Logger) *db.PSQLDB {
+	if filter.HasMedia != nil {
+		if *filter.HasMedia {
+			tx = tx.Where("EXISTS (" + mediaQuery + ")")
+		} else {
+			tx = tx.Where("NOT EXISTS (" + mediaQuery + ")")
+		}
+	}
+	if filter.MediaType != nil {
+		tx = tx.Where("EXISTS ("+mediaQuery+" AND multimedia.type = ?)",
+			modelMediaType2DBMediaType(*filter.MediaType, logger))
+	}
+	return tx
 }
 
 func modelMediaType2DBMediaType(media m.MediaType, logger l.Logger) db.MediaType {
@@ -167,11 +433,131 @@ func dbMediaType2ModelMediaType(media db.MediaType, logger l.Logger) m.MediaType
 	return m.MediaImage
 }
 
+func (d *psqlDocDAL) GetMediaEventID(docID m.ID, fileName string) (*m.ID, error) {
+	var eventIDs []db.ID
+	result := d.db.Model(&db.Multimedia{}).
+		Joins("INNER JOIN docs ON docs.id = multimedia.doc_id AND docs.deleted_at IS NULL").
+		Where("multimedia.doc_id = ? AND multimedia.file_name = ? AND multimedia.status = ?",
+			*modelID2DBID(&docID), fileName, string(m.MediaConfirmed)).
+		Limit(1).Pluck("docs.event_id", &eventIDs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get event of file %s of doc-id %s: %s", fileName, docID.String(),
+			result.Error.Error())
+	} else if len(eventIDs) == 0 {
+		return nil, nil
+	}
+	return dbID2ModelID(&eventIDs[0]), nil
+}
+
+func (d *psqlDocDAL) ConfirmMedia(docID m.ID, media *m.MediaPath, eventQuotaKB, jpQuotaKB uint64) (bool, error) {
+	isFound := false
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		var doc db.Doc
+		result := tx.Where(&db.Doc{BaseModel: db.BaseModel{ID: *modelID2DBID(&docID)}}).Limit(1).Find(&doc)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+
+		// Confirmations of the same event or job position are serialized, so the quotas
+		// couldn't be exceeded by concurrent confirmations. The event lock is always taken
+		// first to avoid deadlocks.
+		quotas := []struct {
+			quota     uint64
+			lockKey   string
+			condition string
+			arg       db.ID
+		}{
+			{eventQuotaKB, "media-quota:event:" + doc.EventID.ToString(), "docs.event_id = ?", doc.EventID},
+			{jpQuotaKB, "media-quota:jp:" + doc.CreatedByID.ToString(), "docs.created_by_id = ?", doc.CreatedByID},
+		}
+		for _, q := range quotas {
+			if q.quota == 0 {
+				continue
+			}
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", q.lockKey).Error; err != nil {
+				return err
+			}
+		}
+
+		var pending db.Multimedia
+		result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(&db.Multimedia{DocID: doc.ID, FileName: media.FileName, Status: string(m.MediaPending)}).
+			Limit(1).Find(&pending)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isFound = true
+
+		for _, q := range quotas {
+			if q.quota == 0 {
+				continue
+			}
+			used, err := mediaSize(tx, q.condition, q.arg)
+			if err != nil {
+				return err
+			} else if used+media.Size > q.quota {
+				return fmt.Errorf("%w: %d KB of %d KB is used", e.ErrQuotaExceeded, used, q.quota)
+			}
+		}
+
+		confirmed := modelMultimedia2DBMultimedia(media, d.logger)
+		return tx.Model(&pending).Updates(map[string]any{
+			"type":      confirmed.Type,
+			"src":       confirmed.Src,
+			"size":      confirmed.Size,
+			"checksum":  confirmed.Checksum,
+			"mime_type": confirmed.MimeType,
+			"status":    string(m.MediaConfirmed),
+		}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm file %s of doc-id %s: %w", media.FileName, docID.String(), err)
+	}
+	return isFound, nil
+}
+
+func (d *psqlDocDAL) GetEventMediaSize(eventID m.ID) (uint64, error) {
+	size, err := mediaSize(d.db, "docs.event_id = ?", *modelID2DBID(&eventID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get size of files of event %s: %s", eventID.String(), err.Error())
+	}
+	return size, nil
+}
+
+func (d *psqlDocDAL) GetJPMediaSize(jpID m.ID) (uint64, error) {
+	size, err := mediaSize(d.db, "docs.created_by_id = ?", *modelID2DBID(&jpID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get size of files of job position %s: %s", jpID.String(), err.Error())
+	}
+	return size, nil
+}
+
+// Return total size of the confirmed multimedia files of the docs that match the
+// condition. Pending files are not stored yet and their sizes are just declared by the
+// clients, so they're not counted.
+func mediaSize(tx *db.PSQLDB, docCondition string, args ...any) (uint64, error) {
+	var size uint64
+	result := tx.Model(&db.Multimedia{}).
+		Joins("INNER JOIN docs ON docs.id = multimedia.doc_id AND docs.deleted_at IS NULL").
+		Where("multimedia.status = ?", string(m.MediaConfirmed)).
+		Where(docCondition, args...).
+		Select("COALESCE(SUM(multimedia.size), 0)").Scan(&size)
+	return size, result.Error
+}
+
 func modelMultimedia2DBMultimedia(m *m.MediaPath, logger l.Logger) *db.Multimedia {
 	return &db.Multimedia{
 		Type:     modelMediaType2DBMediaType(m.Type, logger),
 		Src:      m.Src,
 		FileName: m.FileName,
+		Size:     m.Size,
+		Checksum: m.Checksum,
+		MimeType: m.MimeType,
+		Status:   string(m.Status),
 	}
 }
 
@@ -180,6 +566,10 @@ func dbMultimedia2ModelMultimedia(mum *db.Multimedia, logger l.Logger) *m.MediaP
 		Type:     dbMediaType2ModelMediaType(mum.Type, logger),
 		Src:      mum.Src,
 		FileName: mum.FileName,
+		Size:     mum.Size,
+		Checksum: mum.Checksum,
+		MimeType: mum.MimeType,
+		Status:   m.MediaStatus(mum.Status),
 	}
 }
 
@@ -210,6 +600,7 @@ func dbDoc2modelDoc(doc *db.Doc, logger l.Logger) *m.Doc {
 		EventID:   *dbID2ModelID(&doc.EventID),
 		Context:   doc.Context,
 		Paths:     *dbMultimedias2ModelMultimedias(doc.Multimedia, logger),
+		CreatedAt: doc.CreatedAt.UTC().Unix(),
 	}
 }
 
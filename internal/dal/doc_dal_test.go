@@ -0,0 +1,226 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestKeepConfirmedMedia(t *testing.T) {
+	confirmed := db.Multimedia{FileName: "a.jpg", Src: "events/a.jpg", Size: 20, Checksum: "sha256:a",
+		MimeType: "image/jpeg", Status: string(m.MediaConfirmed)}
+	oldMultimedia := []db.Multimedia{
+		confirmed,
+		{FileName: "b.jpg", Src: "b.jpg", Status: string(m.MediaPending)},
+	}
+
+	tests := []struct {
+		name          string
+		oldMultimedia *[]db.Multimedia
+		newMultimedia []db.Multimedia
+		expected      []db.Multimedia
+	}{
+		{
+			name:          "pending file remains confirmed",
+			oldMultimedia: &oldMultimedia,
+			newMultimedia: []db.Multimedia{{FileName: "a.jpg", Src: "a.jpg", Status: string(m.MediaPending)}},
+			expected:      []db.Multimedia{confirmed},
+		},
+		{
+			name:          "pending file of a pending file remains pending",
+			oldMultimedia: &oldMultimedia,
+			newMultimedia: []db.Multimedia{{FileName: "b.jpg", Src: "b.jpg", Status: string(m.MediaPending)}},
+			expected:      []db.Multimedia{{FileName: "b.jpg", Src: "b.jpg", Status: string(m.MediaPending)}},
+		},
+		{
+			name:          "new file remains pending",
+			oldMultimedia: &oldMultimedia,
+			newMultimedia: []db.Multimedia{{FileName: "c.jpg", Src: "c.jpg", Status: string(m.MediaPending)}},
+			expected:      []db.Multimedia{{FileName: "c.jpg", Src: "c.jpg", Status: string(m.MediaPending)}},
+		},
+		{
+			name:          "doc without files",
+			newMultimedia: []db.Multimedia{{FileName: "a.jpg", Src: "a.jpg", Status: string(m.MediaPending)}},
+			expected:      []db.Multimedia{{FileName: "a.jpg", Src: "a.jpg", Status: string(m.MediaPending)}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keepConfirmedMedia(test.oldMultimedia, test.newMultimedia)
+			if fmt.Sprint(test.newMultimedia) != fmt.Sprint(test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, test.newMultimedia)
+			}
+		})
+	}
+}
+
+func TestMediaSizeCountsConfirmedFiles(t *testing.T) {
+	testDB := newTestDB(t)
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), testLogger)
+	docDAL := newPsqlDocDAL(testDB, newTestCache(), testLogger)
+	jpID := createTestJP(t, testDB, nil)
+	var eventIDs []m.ID
+	for i := 0; i < 2; i++ {
+		eventID, err := eventDAL.CreateEvent(&m.Event{Name: "event", CreatedBy: jpID})
+		if err != nil {
+			t.Fatalf("failed to create the event: %s", err.Error())
+		}
+		eventIDs = append(eventIDs, *eventID)
+	}
+	docID, err := docDAL.CreateDoc(&m.Doc{EventID: eventIDs[0], CreatedBy: jpID, Paths: []m.MediaPath{
+		{Type: m.MediaImage, Src: "docs/a.jpg", FileName: "a.jpg", Size: 100, Status: m.MediaPending},
+		{Type: m.MediaImage, Src: "docs/b.jpg", FileName: "b.jpg", Size: 30, Status: m.MediaPending},
+	}})
+	if err != nil {
+		t.Fatalf("failed to create the doc: %s", err.Error())
+	}
+	// Files of the other event are counted just for the job position.
+	if _, err := docDAL.CreateDoc(&m.Doc{EventID: eventIDs[1], CreatedBy: jpID, Paths: []m.MediaPath{
+		{Type: m.MediaVideo, Src: "docs/c.mp4", FileName: "c.mp4", Size: 50, Status: m.MediaConfirmed},
+	}}); err != nil {
+		t.Fatalf("failed to create the doc: %s", err.Error())
+	}
+
+	checkSizes := func(eventSize, jpSize uint64) {
+		t.Helper()
+		if size, err := docDAL.GetEventMediaSize(eventIDs[0]); err != nil || size != eventSize {
+			t.Errorf("expected %d KB files of the event, got %d (%v)", eventSize, size, err)
+		}
+		if size, err := docDAL.GetJPMediaSize(jpID); err != nil || size != jpSize {
+			t.Errorf("expected %d KB files of the job position, got %d (%v)", jpSize, size, err)
+		}
+	}
+	checkSizes(0, 50)
+	isFound, err := docDAL.ConfirmMedia(*docID,
+		&m.MediaPath{Type: m.MediaImage, Src: "docs/b.jpg", FileName: "b.jpg", Size: 30}, 100, 100)
+	if err != nil || !isFound {
+		t.Fatalf("failed to confirm the file: %v", err)
+	}
+	checkSizes(30, 80)
+	// 30 KB of the event quota is used, so the 100 KB file couldn't be confirmed.
+	if _, err := docDAL.ConfirmMedia(*docID,
+		&m.MediaPath{Type: m.MediaImage, Src: "docs/a.jpg", FileName: "a.jpg", Size: 100}, 100, 0); !errors.Is(err, e.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error, got %v", err)
+	}
+	checkSizes(30, 80)
+}
+
+func TestDocDetailsSkipsDeletedEvents(t *testing.T) {
+	testDB := newTestDB(t)
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), testLogger)
+	docDAL := newPsqlDocDAL(testDB, newTestCache(), testLogger)
+	jpID := createTestJP(t, testDB, nil)
+	docIDs := map[string]m.ID{}
+	for _, name := range []string{"kept", "deleted"} {
+		eventID, err := eventDAL.CreateEvent(&m.Event{Name: name, CreatedBy: jpID})
+		if err != nil {
+			t.Fatalf("failed to create the event: %s", err.Error())
+		}
+		docID, err := docDAL.CreateDoc(&m.Doc{EventID: *eventID, CreatedBy: jpID})
+		if err != nil {
+			t.Fatalf("failed to create the doc: %s", err.Error())
+		}
+		docIDs[name] = *docID
+		if name == "deleted" {
+			if _, err := eventDAL.DeleteEvent(*eventID); err != nil {
+				t.Fatalf("failed to delete the event: %s", err.Error())
+			}
+		}
+	}
+
+	doc, err := docDAL.GetDocWithDetailsByID(docIDs["kept"])
+	if err != nil || doc == nil {
+		t.Fatalf("failed to get the doc: %v", err)
+	}
+	if doc.ID != docIDs["kept"] || doc.EventName != "kept" || doc.JPName != "job position" {
+		t.Errorf("expected the doc with its event name and job position title, got %+v", *doc)
+	}
+	if doc, err := docDAL.GetDocWithDetailsByID(docIDs["deleted"]); err != nil || doc != nil {
+		t.Errorf("expected the doc of the deleted event not to be found, got %+v (%v)", doc, err)
+	}
+}
+
+func TestGetNLastDocsPages(t *testing.T) {
+	testDB := newTestDB(t)
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), testLogger)
+	docDAL := newPsqlDocDAL(testDB, newTestCache(), testLogger)
+	jpID := createTestJP(t, testDB, nil)
+	eventID, err := eventDAL.CreateEvent(&m.Event{Name: "event", CreatedBy: jpID})
+	if err != nil {
+		t.Fatalf("failed to create the event: %s", err.Error())
+	}
+	var ids []m.ID
+	for i := 0; i < 5; i++ {
+		docID, err := docDAL.CreateDoc(&m.Doc{CreatedBy: jpID, EventID: *eventID})
+		if err != nil {
+			t.Fatalf("failed to create the doc: %s", err.Error())
+		}
+		ids = append(ids, *docID)
+	}
+	// The first doc is the oldest one and the others are created at the same time.
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	setTestCreatedAt(t, testDB, "docs", createdAt.Add(-time.Minute), ids[0])
+	setTestCreatedAt(t, testDB, "docs", createdAt, ids[1:]...)
+	expected := append(sortTestIDsDesc(ids[1:]), ids[0])
+
+	checkTestPages(t, expected, func(cursor *m.Cursor, limit int) ([]m.ID, *m.Cursor) {
+		docs, nextCursor, err := docDAL.GetNLastDocs(nil, cursor, limit, nil)
+		if err != nil {
+			t.Fatalf("failed to get the docs: %s", err.Error())
+		}
+		var pageIDs []m.ID
+		for _, doc := range *docs {
+			pageIDs = append(pageIDs, doc.ID)
+		}
+		return pageIDs, nextCursor
+	})
+}
+
+func TestGetNLastDocsFilter(t *testing.T) {
+	testDB := newTestDB(t)
+	data := createTestFilterData(t, testDB)
+	docDAL := newPsqlDocDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	createdBefore := data.createdBefore.Unix()
+	imageEventID, hasMedia, hasNotMedia := data.ids["image"], true, false
+	imageType, videoType := m.MediaImage, m.MediaVideo
+
+	tests := []struct {
+		name     string
+		filter   m.ListFilter
+		expected []string
+	}{
+		{name: "created from", filter: m.ListFilter{CreatedFrom: &createdBefore},
+			expected: []string{"image doc", "video doc", "text doc"}},
+		{name: "created to", filter: m.ListFilter{CreatedTo: &createdBefore}, expected: []string{"old doc"}},
+		{name: "created by", filter: m.ListFilter{CreatedBy: &data.memberID}, expected: []string{"video doc", "text doc"}},
+		{name: "event", filter: m.ListFilter{EventID: &imageEventID}, expected: []string{"image doc"}},
+		{name: "region", filter: m.ListFilter{RegionID: &data.regionID}, expected: []string{"video doc", "text doc"}},
+		{name: "with media", filter: m.ListFilter{HasMedia: &hasMedia}, expected: []string{"image doc", "video doc"}},
+		{name: "without media", filter: m.ListFilter{HasMedia: &hasNotMedia}, expected: []string{"old doc", "text doc"}},
+		{name: "media type", filter: m.ListFilter{MediaType: &imageType}, expected: []string{"image doc"}},
+		{name: "all filters", filter: m.ListFilter{CreatedFrom: &createdBefore, CreatedBy: &data.memberID,
+			RegionID: &data.regionID, MediaType: &videoType}, expected: []string{"video doc"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			docs, _, err := docDAL.GetNLastDocs(nil, nil, 10, &test.filter)
+			if err != nil {
+				t.Fatalf("failed to get the docs: %s", err.Error())
+			}
+			var ids []m.ID
+			for _, doc := range *docs {
+				ids = append(ids, doc.ID)
+			}
+			checkTestNamedIDs(t, ids, data.ids, test.expected)
+		})
+	}
+}
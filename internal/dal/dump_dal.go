@@ -0,0 +1,231 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm/clause"
+)
+
+type DumpDAL interface {
+	// Write rows of all tables, except sessions and their refresh tokens, to w as JSON
+	// lines. Soft deleted rows are exported too and each row comes after the rows it
+	// references. Return number of exported rows.
+	Export(w io.Writer) (int, error)
+	// Insert the rows exported by Export in a transaction and return number of inserted
+	// rows. Rows that exist previously are skipped. Roles with the same name of an existing
+	// role (e.g. built-in roles) are mapped to the existing one.
+	Import(r io.Reader) (int, error)
+}
+
+// A line of the exported data
+type dumpRecord struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// A table of the exported data. query returns rows of the table in the exported order.
+type dumpTable struct {
+	name   string
+	newRow func() any
+	query  func(tx *db.PSQLDB) *db.PSQLDB
+}
+
+// Tables of the exported data. They're ordered so that each table comes after the tables
+// it references.
+var dumpTables = []dumpTable{
+	{"users", func() any { return &db.User{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return orderByReference(tx, "users", "created_by_id")
+	}},
+	{"job_positions", func() any { return &db.JobPosition{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return orderByReference(tx, "job_positions", "parent_id")
+	}},
+	{"jp_permissions", func() any { return &db.JPPermission{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.JPPermission{}).Order("created_at")
+	}},
+	{"jp_edges", func() any { return &db.JPEdge{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Model(&db.JPEdge{}).Order("created_at")
+	}},
+	{"roles", func() any { return &db.Role{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.Role{}).Order("created_at")
+	}},
+	{"role_permissions", func() any { return &db.RolePermission{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Model(&db.RolePermission{}).Order("role_id, action")
+	}},
+	{"jp_roles", func() any { return &db.JPRole{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Model(&db.JPRole{}).Order("created_at")
+	}},
+	{"events", func() any { return &db.Event{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.Event{}).Omit("search_vector").Order("created_at")
+	}},
+	{"event_revisions", func() any { return &db.EventRevision{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.EventRevision{}).Order("created_at")
+	}},
+	{"event_approvals", func() any { return &db.EventApproval{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.EventApproval{}).Order("created_at")
+	}},
+	{"event_acls", func() any { return &db.EventACL{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.EventACL{}).Order("created_at")
+	}},
+	{"docs", func() any { return &db.Doc{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.Doc{}).Omit("search_vector").Order("created_at")
+	}},
+	{"doc_versions", func() any { return &db.DocVersion{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.DocVersion{}).Order("created_at")
+	}},
+	{"multimedia", func() any { return &db.Multimedia{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Unscoped().Model(&db.Multimedia{}).Order("created_at")
+	}},
+	{"audit_events", func() any { return &db.AuditEvent{} }, func(tx *db.PSQLDB) *db.PSQLDB {
+		return tx.Model(&db.AuditEvent{}).Order("created_at")
+	}},
+}
+
+// Return all rows of a self-referencing table, ordered so that each row comes after the
+// row it references by the column refColumn.
+func orderByReference(tx *db.PSQLDB, table, refColumn string) *db.PSQLDB {
+	return tx.Raw(fmt.Sprintf(`WITH RECURSIVE ordered AS (
+			SELECT id, 0 AS depth FROM %[1]s WHERE %[2]s IS NULL
+			UNION ALL
+			SELECT t.id, ordered.depth + 1 FROM %[1]s t JOIN ordered ON t.%[2]s = ordered.id
+		)
+		SELECT %[1]s.* FROM %[1]s JOIN ordered USING (id) ORDER BY ordered.depth, %[1]s.created_at`,
+		table, refColumn))
+}
+
+type psqlDumpDAL struct {
+	db     *db.PSQLDB
+	logger l.Logger
+}
+
+func newPsqlDumpDAL(db *db.PSQLDB, logger l.Logger) *psqlDumpDAL {
+	return &psqlDumpDAL{db, logger}
+}
+
+// All tables are read in a read-only transaction, so the exported data is a consistent
+// snapshot of the database.
+func (d *psqlDumpDAL) Export(w io.Writer) (int, error) {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		for _, table := range dumpTables {
+			n, err := d.exportTable(tx, encoder, &table)
+			count += n
+			if err != nil {
+				return fmt.Errorf("failed to export table %s: %s", table.name, err.Error())
+			}
+			d.logger.Debugf("Exported %d rows of table %s", n, table.name)
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	return count, err
+}
+
+func (d *psqlDumpDAL) exportTable(tx *db.PSQLDB, encoder *json.Encoder, table *dumpTable) (int, error) {
+	rows, err := table.query(tx).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	count := 0
+	for rows.Next() {
+		row := table.newRow()
+		if err := tx.ScanRows(rows, row); err != nil {
+			return count, err
+		}
+		if err := writeDumpRecord(encoder, table.name, row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// Write the row of the table as a line of the exported data.
+func writeDumpRecord(encoder *json.Encoder, table string, row any) error {
+	rowJSON, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(dumpRecord{table, rowJSON})
+}
+
+// Read lines of the exported data and call fc with the table and the row of each line in
+// order. Reading is stopped on the first error of fc and the error is returned.
+func readDumpRecords(r io.Reader, fc func(line int, table string, row any) error) error {
+	tables := make(map[string]*dumpTable, len(dumpTables))
+	for i := range dumpTables {
+		tables[dumpTables[i].name] = &dumpTables[i]
+	}
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var record dumpRecord
+		if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid record %d: %s", line, err.Error())
+		}
+		table, isFound := tables[record.Table]
+		if !isFound {
+			return fmt.Errorf("unknown table %s in record %d", record.Table, line)
+		}
+		row := table.newRow()
+		if err := json.Unmarshal(record.Row, row); err != nil {
+			return fmt.Errorf("invalid row of table %s in record %d: %s", record.Table, line, err.Error())
+		}
+		if err := fc(line, record.Table, row); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *psqlDumpDAL) Import(r io.Reader) (int, error) {
+	count := 0
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		// Map ids of the imported roles to ids of the existing roles with the same name
+		roleIDs := make(map[db.ID]db.ID)
+		return readDumpRecords(r, func(line int, table string, row any) error {
+			isInserted, err := d.importRow(tx, row, roleIDs)
+			if err != nil {
+				return fmt.Errorf("failed to import record %d into table %s: %s", line, table, err.Error())
+			} else if isInserted {
+				count++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Insert the row if it doesn't exist and return true if it's inserted.
+func (d *psqlDumpDAL) importRow(tx *db.PSQLDB, row any, roleIDs map[db.ID]db.ID) (bool, error) {
+	switch r := row.(type) {
+	case *db.Role:
+		var existing db.Role
+		result := tx.Unscoped().Where("name = ?", r.Name).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return false, result.Error
+		} else if result.RowsAffected > 0 {
+			roleIDs[r.ID] = existing.ID
+			return false, nil
+		}
+	case *db.RolePermission:
+		if id, isMapped := roleIDs[r.RoleID]; isMapped {
+			r.RoleID = id
+		}
+	case *db.JPRole:
+		if id, isMapped := roleIDs[r.RoleID]; isMapped {
+			r.RoleID = id
+		}
+	}
+	result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(row)
+	return result.RowsAffected > 0, result.Error
+}
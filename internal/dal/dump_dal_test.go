@@ -0,0 +1,102 @@
+package dal
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set all fields of the value to non-zero values, so losing any of them in the exported
+// data is detected. Associations and generated columns are not exported, so they're
+// skipped.
+func fillDumpRow(t *testing.T, value reflect.Value) {
+	t.Helper()
+	switch value.Kind() {
+	case reflect.Pointer:
+		value.Set(reflect.New(value.Type().Elem()))
+		fillDumpRow(t, value.Elem())
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			value.Set(reflect.ValueOf(time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)))
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			gormTag := field.Tag.Get("gorm")
+			if field.IsExported() && !strings.Contains(gormTag, "foreignKey") && !strings.Contains(gormTag, "->:false") {
+				fillDumpRow(t, value.Field(i))
+			}
+		}
+	case reflect.Array:
+		id := uuid.New()
+		reflect.Copy(value, reflect.ValueOf(id[:]))
+	case reflect.Slice:
+		value.Set(reflect.MakeSlice(value.Type(), 1, 1))
+		fillDumpRow(t, value.Index(0))
+	case reflect.String:
+		value.SetString("value")
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(1)
+	default:
+		t.Fatalf("unexpected kind %s of the exported rows", value.Kind())
+	}
+}
+
+func TestDumpRecordsRoundTrip(t *testing.T) {
+	rows := make([]any, len(dumpTables))
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for i, table := range dumpTables {
+		rows[i] = table.newRow()
+		fillDumpRow(t, reflect.ValueOf(rows[i]).Elem())
+		if err := writeDumpRecord(encoder, table.name, rows[i]); err != nil {
+			t.Fatalf("failed to write row of table %s: %s", table.name, err.Error())
+		}
+	}
+
+	count := 0
+	err := readDumpRecords(&buffer, func(line int, table string, row any) error {
+		if table != dumpTables[count].name {
+			t.Errorf("expected table %s in record %d, got %s", dumpTables[count].name, line, table)
+		} else if !reflect.DeepEqual(row, rows[count]) {
+			t.Errorf("row of table %s is changed:\nexported %+v\nimported %+v", table, rows[count], row)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if count != len(dumpTables) {
+		t.Errorf("expected %d records, got %d", len(dumpTables), count)
+	}
+}
+
+func TestReadInvalidDumpRecords(t *testing.T) {
+	tests := []struct {
+		name, data string
+		// Expected part of the error
+		err string
+	}{
+		{name: "unknown table", data: `{"table":"sessions","row":{}}`, err: "unknown table sessions in record 1"},
+		{name: "invalid json", data: `{"table":"users","row":{}}` + "\n{", err: "invalid record 2"},
+		{name: "invalid row", data: `{"table":"users","row":{"ID":"id"}}`, err: "invalid row of table users"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := readDumpRecords(strings.NewReader(test.data), func(int, string, any) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
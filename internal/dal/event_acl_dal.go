@@ -0,0 +1,117 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"fmt"
+	"time"
+)
+
+type EventACLDAL interface {
+	// Add the entry to the access control list of its event and return its id.
+	CreateEventACL(acl *m.EventACL) (*m.ID, error)
+	// Return all entries of the access control list of the event, including the expired
+	// ones. The newest entries come first.
+	GetEventACLs(eventID m.ID) (*[]m.EventACL, error)
+	// Revoke the entry of the access control list of the event. If there's not any such
+	// entry, return (false, nil).
+	DeleteEventACL(eventID, aclID m.ID) (bool, error)
+	// Return true if there's an entry of the access control list of the event, that is
+	// not expired and grants one of the accesses to the user, the job position jpID or
+	// one of subtreeRootIDs. subtreeRootIDs are the job positions that jpID is in their
+	// subtree.
+	HasEventAccess(eventID, userID, jpID m.ID, subtreeRootIDs []m.ID, accesses []m.EventAccess) (bool, error)
+}
+
+type psqlEventACLDAL struct {
+	db     *db.PSQLDB
+	logger l.Logger
+}
+
+func newPsqlEventACLDAL(db *db.PSQLDB, logger l.Logger) *psqlEventACLDAL {
+	return &psqlEventACLDAL{db, logger}
+}
+
+func (d *psqlEventACLDAL) CreateEventACL(acl *m.EventACL) (*m.ID, error) {
+	newACL := db.EventACL{
+		EventID:     *modelID2DBID(&acl.EventID),
+		GranteeType: string(acl.GranteeType),
+		GranteeID:   *modelID2DBID(&acl.GranteeID),
+		Access:      string(acl.Access),
+		ExpiresAt:   acl.ExpiresAt,
+		GrantedByID: *modelID2DBID(&acl.GrantedBy),
+	}
+	if result := d.db.Create(&newACL); result.Error != nil {
+		return nil, fmt.Errorf("failed to grant access on event %s: %s", acl.EventID.String(),
+			result.Error.Error())
+	}
+	return dbID2ModelID(&newACL.ID), nil
+}
+
+func (d *psqlEventACLDAL) GetEventACLs(eventID m.ID) (*[]m.EventACL, error) {
+	var acls []db.EventACL
+	result := d.db.Where(&db.EventACL{EventID: *modelID2DBID(&eventID)}).
+		Order("created_at desc").Find(&acls)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get access list of event %s: %s", eventID.String(),
+			result.Error.Error())
+	}
+	modelACLs := make([]m.EventACL, len(acls))
+	for i := range acls {
+		modelACLs[i] = *dbEventACL2ModelEventACL(&acls[i])
+	}
+	return &modelACLs, nil
+}
+
+func (d *psqlEventACLDAL) DeleteEventACL(eventID, aclID m.ID) (bool, error) {
+	result := d.db.Where(&db.EventACL{
+		BaseModel: db.BaseModel{ID: *modelID2DBID(&aclID)},
+		EventID:   *modelID2DBID(&eventID),
+	}).Delete(&db.EventACL{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke access %s on event %s: %s", aclID.String(),
+			eventID.String(), result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *psqlEventACLDAL) HasEventAccess(eventID, userID, jpID m.ID, subtreeRootIDs []m.ID, accesses []m.EventAccess) (bool, error) {
+	accessNames := make([]string, len(accesses))
+	for i := range accesses {
+		accessNames[i] = string(accesses[i])
+	}
+	query := d.db.Model(&db.EventACL{}).
+		Where(&db.EventACL{EventID: *modelID2DBID(&eventID)}).
+		Where("access IN ?", accessNames).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC().Unix())
+	if len(subtreeRootIDs) > 0 {
+		query = query.Where(`((grantee_type = ? AND grantee_id = ?) OR (grantee_type = ? AND grantee_id = ?)
+			OR (grantee_type = ? AND grantee_id IN ?))`,
+			string(m.GranteeJP), *modelID2DBID(&jpID), string(m.GranteeUser), *modelID2DBID(&userID),
+			string(m.GranteeSubtree), *modelIDs2DBIDs(&subtreeRootIDs))
+	} else {
+		query = query.Where("((grantee_type = ? AND grantee_id = ?) OR (grantee_type = ? AND grantee_id = ?))",
+			string(m.GranteeJP), *modelID2DBID(&jpID), string(m.GranteeUser), *modelID2DBID(&userID))
+	}
+
+	var count int64
+	if result := query.Count(&count); result.Error != nil {
+		return false, fmt.Errorf("failed to check access of job position %s on event %s: %s",
+			jpID.String(), eventID.String(), result.Error.Error())
+	}
+	return count > 0, nil
+}
+
+func dbEventACL2ModelEventACL(acl *db.EventACL) *m.EventACL {
+	return &m.EventACL{
+		ID:          *dbID2ModelID(&acl.ID),
+		EventID:     *dbID2ModelID(&acl.EventID),
+		GranteeType: m.GranteeType(acl.GranteeType),
+		GranteeID:   *dbID2ModelID(&acl.GranteeID),
+		Access:      m.EventAccess(acl.Access),
+		ExpiresAt:   acl.ExpiresAt,
+		GrantedBy:   *dbID2ModelID(&acl.GrantedByID),
+		CreatedAt:   acl.CreatedAt.UTC().Unix(),
+	}
+}
@@ -0,0 +1,75 @@
+package dal
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHasEventAccessSkipsExpiredEntries(t *testing.T) {
+	testDB := newTestDB(t)
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), testLogger)
+	aclDAL := newPsqlEventACLDAL(testDB, testLogger)
+	adminID := createTestJP(t, testDB, nil)
+	eventID, err := eventDAL.CreateEvent(&m.Event{Name: "event", CreatedBy: adminID})
+	if err != nil {
+		t.Fatalf("failed to create the event: %s", err.Error())
+	}
+	jpID, userID, rootID, revokedJPID := m.ID(uuid.New()), m.ID(uuid.New()), m.ID(uuid.New()), m.ID(uuid.New())
+	expired, notExpired := time.Now().Add(-time.Hour).Unix(), time.Now().Add(time.Hour).Unix()
+	for _, acl := range []m.EventACL{
+		{GranteeType: m.GranteeJP, GranteeID: jpID, Access: m.EventAccessRead},
+		{GranteeType: m.GranteeUser, GranteeID: userID, Access: m.EventAccessRead, ExpiresAt: &expired},
+		{GranteeType: m.GranteeSubtree, GranteeID: rootID, Access: m.EventAccessContribute, ExpiresAt: &notExpired},
+		{GranteeType: m.GranteeJP, GranteeID: revokedJPID, Access: m.EventAccessRead},
+	} {
+		acl.EventID, acl.GrantedBy = *eventID, adminID
+		aclID, err := aclDAL.CreateEventACL(&acl)
+		if err != nil {
+			t.Fatalf("failed to grant the access: %s", err.Error())
+		}
+		if acl.GranteeID == revokedJPID {
+			if isDeleted, err := aclDAL.DeleteEventACL(*eventID, *aclID); err != nil || !isDeleted {
+				t.Fatalf("failed to revoke the access: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name           string
+		userID, jpID   m.ID
+		subtreeRootIDs []m.ID
+		accesses       []m.EventAccess
+		expected       bool
+	}{
+		{name: "access of the job position", userID: m.ID(uuid.New()), jpID: jpID,
+			accesses: []m.EventAccess{m.EventAccessRead}, expected: true},
+		{name: "another access of the job position", userID: m.ID(uuid.New()), jpID: jpID,
+			accesses: []m.EventAccess{m.EventAccessContribute}},
+		{name: "expired access of the user", userID: userID, jpID: m.ID(uuid.New()),
+			accesses: []m.EventAccess{m.EventAccessRead}},
+		{name: "access of the subtree not expired", userID: m.ID(uuid.New()), jpID: m.ID(uuid.New()),
+			subtreeRootIDs: []m.ID{m.ID(uuid.New()), rootID},
+			accesses:       []m.EventAccess{m.EventAccessRead, m.EventAccessContribute}, expected: true},
+		{name: "access of the subtree without its roots", userID: m.ID(uuid.New()), jpID: rootID,
+			accesses: []m.EventAccess{m.EventAccessContribute}},
+		{name: "revoked access", userID: m.ID(uuid.New()), jpID: revokedJPID,
+			accesses: []m.EventAccess{m.EventAccessRead}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hasAccess, err := aclDAL.HasEventAccess(*eventID, test.userID, test.jpID, test.subtreeRootIDs, test.accesses)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if hasAccess != test.expected {
+				t.Errorf("expected access %v, got %v", test.expected, hasAccess)
+			}
+		})
+	}
+}
@@ -7,22 +7,47 @@ import (
 	m "DMS/internal/models"
 	"errors"
 	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 type EventDAL interface {
 	// Create event and return its id.
 	CreateEvent(event *m.Event) (*m.ID, error)
-	// Return some last events created by the job position id.
-	// If limit be equals -1, then return all events from offset to the end.
-	GetNLastEventsByJPID(jPID m.ID, limit, offset int) (*[]m.Event, error)
 	GetLastApprovedEventByUserID(id m.ID) (*m.Event, *m.ApprovedEvent, error)
 	// Return all created events by job position id.
 	GetAllCreatedEventsByJPID(jPID m.ID) (*[]m.Event, error)
 	// Return event by its id. If no error occurs and the returned event is nil, then
 	// there is no corresponding event with that id.
 	GetEventByID(eventID m.ID) (*m.Event, error)
-	// Return some last events (specified by offset and limit)
-	GetNLastEvents(limit, offset int) (*[]m.Event, error)
+	// Return some last events (at most limit events) created by one of the given job
+	// positions that match the filter and come after the cursor, together with the cursor
+	// of the last returned event. If there's not any more event, the returned cursor is nil.
+	// If jpIDs be nil, return events of all job positions. If cursor be nil, start from
+	// the newest event. If filter be nil, no filter is applied.
+	GetNLastEvents(jpIDs *[]m.ID, cursor *m.Cursor, limit int, filter *m.ListFilter) (*[]m.Event, *m.Cursor, error)
+	// Approve the event by the job position and return id of the approval. If the job
+	// position has approved the event previously, return id of the previous approval.
+	ApproveEvent(eventID, jpID m.ID) (*m.ID, error)
+	// Revoke the approval of the event by the job position. If there's not any active
+	// approval of the event by the job position, return (false, nil).
+	RevokeApproval(eventID, jpID m.ID) (bool, error)
+	// Return some last approved events (specified by offset and limit) that are created
+	// by one of the given job positions and match the filter. If jpIDs be nil, return
+	// approved events of all job positions. If filter be nil, no filter is applied. The
+	// newest approvals come first.
+	GetNLastApprovedEvents(jpIDs *[]m.ID, limit, offset int, filter *m.ListFilter) (*[]m.EventWithApproval, error)
+	// Apply the update on the event and record values of the event before and after the
+	// update as a revision edited by the given job position. If the update doesn't change
+	// the event, nothing is written. If there's not any event with the given id, return
+	// (false, nil).
+	UpdateEvent(eventID, editorJPID m.ID, update *m.EventUpdate) (bool, error)
+	// Soft delete the event and its docs. If there's not any event with the given id,
+	// return (false, nil).
+	DeleteEvent(eventID m.ID) (bool, error)
+	// Return all revisions of the event. The newest revisions come first.
+	GetEventRevisions(eventID m.ID) (*[]m.EventRevision, error)
 }
 
 func (c cacheKey) eventByIDKey(eventID m.ID) string {
@@ -59,28 +84,35 @@ func (d *psqlEventDAL) CreateEvent(event *m.Event) (*m.ID, error) {
 	return dbID2ModelID(&newEvent.ID), nil
 }
 
-func (d *psqlEventDAL) GetNLastEventsByJPID(jPID m.ID, limit, offset int) (*[]m.Event, error) {
+// Return the last approved event created by one of the job positions of the user. If both
+// event and error be nil, means the user doesn't have any approved event.
+func (d *psqlEventDAL) GetLastApprovedEventByUserID(id m.ID) (*m.Event, *m.ApprovedEvent, error) {
+	var events []approvedEventRow
+	result := d.approvedEventsQuery().
+		Joins("INNER JOIN job_positions AS owners ON owners.id = events.created_by_id").
+		Where("owners.user_id = ?", *modelID2DBID(&id)).
+		Limit(1).Find(&events)
+	if result.Error != nil {
+		return nil, nil, fmt.Errorf("failed to get last approved event of user-id %s: %s", id.String(), result.Error.Error())
+	} else if len(events) == 0 {
+		return nil, nil, nil
+	}
+	approved := events[0].toModel()
+	return &approved.Event, &approved.Approval, nil
+}
+
+func (d *psqlEventDAL) GetAllCreatedEventsByJPID(jpID m.ID) (*[]m.Event, error) {
 	var events *[]db.Event
-	result := d.db.Order("created_at desc").Limit(int(limit)).Offset(int(offset)).Where(&db.Event{
-		CreatedByID: *modelID2DBID(&jPID),
+	result := d.db.Order("created_at desc").Where(&db.Event{
+		CreatedByID: *modelID2DBID(&jpID),
 	}).Find(&events)
-
 	if result.Error != nil {
-		d.logger.Debugf("Failed to get %s events for job-position-id %d (%s)", limit, jPID.String(), result.Error.Error())
+		d.logger.Debugf("Failed to get events of job-position-id %s (%s)", jpID.String(), result.Error.Error())
 		return nil, result.Error
 	}
 	return dbEvents2ModelEvents(events), nil
 }
 
-func (d *psqlEventDAL) GetLastApprovedEventByUserID(id m.ID) (*m.Event, *m.ApprovedEvent, error) {
-	d.logger.Panicf("GetLastApprovedEventByUserID not implemented yet")
-	return nil, nil, nil
-}
-
-func (d *psqlEventDAL) GetAllCreatedEventsByJPID(jpID m.ID) (*[]m.Event, error) {
-	return d.GetNLastEventsByJPID(jpID, -1, 0)
-}
-
 // TODO: Test it with wrong id to know if it returns nil
 func (d *psqlEventDAL) GetEventByID(eventID m.ID) (*m.Event, error) {
 	var cacheKey = ck.eventByIDKey(eventID)
@@ -98,6 +130,8 @@ func (d *psqlEventDAL) GetEventByID(eventID m.ID) (*m.Event, error) {
 	}).Find(&dbEvent)
 	if result.Error != nil {
 		return nil, result.Error
+	} else if result.RowsAffected < 1 {
+		return nil, nil
 	}
 	event = *dbEvent2ModelEvent(&dbEvent)
 	if err := d.cache.write(cacheKey, event); err != nil {
@@ -106,30 +140,257 @@ func (d *psqlEventDAL) GetEventByID(eventID m.ID) (*m.Event, error) {
 	return &event, nil
 }
 
-func (d *psqlEventDAL) GetNLastEvents(limit, offset int) (*[]m.Event, error) {
-	var events *[]db.Event
-	result := d.db.Order("created_at desc").Offset(offset).Limit(limit).Find(&events)
+func (d *psqlEventDAL) GetNLastEvents(jpIDs *[]m.ID, cursor *m.Cursor, limit int, filter *m.ListFilter) (*[]m.Event, *m.Cursor, error) {
+	query := d.applyEventFilter(d.db.Model(&db.Event{}), filter)
+	if jpIDs != nil {
+		query = query.Where("events.created_by_id IN ?", *modelIDs2DBIDs(jpIDs))
+	}
+	var events []db.Event
+	// Fetch one more event to find out if there are more events after this page.
+	result := applyCursor(query, "events", cursor).Limit(limit + 1).Find(&events)
 	if result.Error != nil {
-		d.logger.Debugf("Failed to get %s events (%s)", limit, result.Error.Error())
-		return nil, result.Error
+		return nil, nil, fmt.Errorf("failed to get last %d events after cursor %+v: %s", limit, cursor, result.Error.Error())
 	}
-	return dbEvents2ModelEvents(events), nil
+
+	var nextCursor *m.Cursor
+	if len(events) > limit {
+		events = events[:limit]
+		nextCursor = newCursor(events[limit-1].CreatedAt, events[limit-1].ID)
+	}
+	return dbEvents2ModelEvents(&events), nextCursor, nil
+}
+
+func (d *psqlEventDAL) ApproveEvent(eventID, jpID m.ID) (*m.ID, error) {
+	approval := db.EventApproval{
+		EventID:      *modelID2DBID(&eventID),
+		ApprovedByID: *modelID2DBID(&jpID),
+	}
+	// The unique index of active approvals keeps one approval of the event by the job
+	// position, even if it's approved concurrently.
+	result := d.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "event_id"}, {Name: "approved_by_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(&approval)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to approve event-id %s by job-position-id %s: %s",
+			eventID.String(), jpID.String(), result.Error.Error())
+	} else if result.RowsAffected >= 1 {
+		return dbID2ModelID(&approval.ID), nil
+	}
+
+	// The event is approved previously by the job position.
+	approval = db.EventApproval{}
+	result = d.db.Where(&db.EventApproval{
+		EventID:      *modelID2DBID(&eventID),
+		ApprovedByID: *modelID2DBID(&jpID),
+	}).Limit(1).Find(&approval)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get previous approval of event-id %s by job-position-id %s: %s",
+			eventID.String(), jpID.String(), result.Error.Error())
+	} else if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("previous approval of event-id %s by job-position-id %s is revoked concurrently",
+			eventID.String(), jpID.String())
+	}
+	return dbID2ModelID(&approval.ID), nil
+}
+
+func (d *psqlEventDAL) RevokeApproval(eventID, jpID m.ID) (bool, error) {
+	result := d.db.Where(&db.EventApproval{
+		EventID:      *modelID2DBID(&eventID),
+		ApprovedByID: *modelID2DBID(&jpID),
+	}).Delete(&db.EventApproval{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke approval of event-id %s by job-position-id %s: %s",
+			eventID.String(), jpID.String(), result.Error.Error())
+	}
+	return result.RowsAffected >= 1, nil
 }
 
+func (d *psqlEventDAL) GetNLastApprovedEvents(jpIDs *[]m.ID, limit, offset int, filter *m.ListFilter) (*[]m.EventWithApproval, error) {
+	query := d.applyEventFilter(d.approvedEventsQuery(), filter)
+	if jpIDs != nil {
+		query = query.Where("events.created_by_id IN ?", *modelIDs2DBIDs(jpIDs))
+	}
+	var events []approvedEventRow
+	result := query.Offset(offset).Limit(limit).Find(&events)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get last %d approved events with offset %d: %s", limit, offset, result.Error.Error())
+	}
+
+	approvedEvents := make([]m.EventWithApproval, 0, len(events))
+	for _, event := range events {
+		approvedEvents = append(approvedEvents, *event.toModel())
+	}
+	return &approvedEvents, nil
+}
+
+// Apply the filter on the query over the events table. If filter be nil, return the
+// query itself.
+func (d *psqlEventDAL) applyEventFilter(tx *db.PSQLDB, filter *m.ListFilter) *db.PSQLDB {
+	tx = applyListFilter(tx, "events", filter)
+	if filter == nil {
+		return tx
+	}
+	if filter.EventID != nil {
+		tx = tx.Where("events.id = ?", *modelID2DBID(filter.EventID))
+	}
+	return applyMediaFilter(tx, "SELECT 1 FROM docs INNER JOIN multimedia ON multimedia.doc_id = docs.id "+
+		"WHERE docs.event_id = events.id AND docs.deleted_at IS NULL AND multimedia.deleted_at IS NULL",
+		filter, d.logger)
+}
+
+// A row of the approved events query
+type approvedEventRow struct {
+	db.Event
+	ApprovedAt   time.Time
+	ApprovedByID db.ID
+	ApproverName string
+}
+
+func (r *approvedEventRow) toModel() *m.EventWithApproval {
+	event := dbEvent2ModelEvent(&r.Event)
+	return &m.EventWithApproval{
+		Event: *event,
+		Approval: m.ApprovedEvent{
+			EventID:        event.ID,
+			ApprovedByJPID: *dbID2ModelID(&r.ApprovedByID),
+			ApprovedBy:     r.ApproverName,
+			CreatedAt:      r.ApprovedAt.UTC(),
+		},
+	}
+}
+
+// Return a query over active approvals of not deleted events together with name of
+// the approvers. The newest approvals come first.
+func (d *psqlEventDAL) approvedEventsQuery() *db.PSQLDB {
+	return d.db.Model(&db.EventApproval{}).
+		Select("events.*, event_approvals.created_at AS approved_at, " +
+			"event_approvals.approved_by_id, users.name AS approver_name").
+		Joins("INNER JOIN events ON events.id = event_approvals.event_id AND events.deleted_at IS NULL").
+		Joins("INNER JOIN job_positions ON job_positions.id = event_approvals.approved_by_id").
+		Joins("INNER JOIN users ON users.id = job_positions.user_id").
+		Order("event_approvals.created_at desc")
+}
+
+func (d *psqlEventDAL) UpdateEvent(eventID, editorJPID m.ID, update *m.EventUpdate) (bool, error) {
+	isFound := false
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		var event db.Event
+		result := tx.Where(&db.Event{BaseModel: db.BaseModel{ID: *modelID2DBID(&eventID)}}).
+			Limit(1).Find(&event)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isFound = true
+
+		revision := db.EventRevision{
+			EventID:        event.ID,
+			EditedByID:     *modelID2DBID(&editorJPID),
+			OldName:        event.Name,
+			NewName:        event.Name,
+			OldDescription: event.Description,
+			NewDescription: event.Description,
+		}
+		if update.Name != nil {
+			revision.NewName = *update.Name
+		}
+		if update.Description != nil {
+			revision.NewDescription = *update.Description
+		}
+		if revision.NewName == revision.OldName && revision.NewDescription == revision.OldDescription {
+			return nil
+		}
+		result = tx.Model(&event).Updates(map[string]any{
+			"name":        revision.NewName,
+			"description": revision.NewDescription,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(&revision).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update event-id %s by job-position-id %s: %s",
+			eventID.String(), editorJPID.String(), err.Error())
+	}
+	d.cache.delete(ck.eventByIDKey(eventID))
+	return isFound, nil
+}
+
+func (d *psqlEventDAL) DeleteEvent(eventID m.ID) (bool, error) {
+	isDeleted := false
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		result := tx.Where(&db.Event{BaseModel: db.BaseModel{ID: *modelID2DBID(&eventID)}}).
+			Delete(&db.Event{})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isDeleted = true
+		return tx.Where(&db.Doc{EventID: *modelID2DBID(&eventID)}).Delete(&db.Doc{}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete event-id %s: %s", eventID.String(), err.Error())
+	}
+	d.cache.delete(ck.eventByIDKey(eventID))
+	return isDeleted, nil
+}
+
+func (d *psqlEventDAL) GetEventRevisions(eventID m.ID) (*[]m.EventRevision, error) {
+	var revisions []struct {
+		db.EventRevision
+		EditorName string
+	}
+	result := d.db.Model(&db.EventRevision{}).
+		Select("event_revisions.*, users.name AS editor_name").
+		Joins("LEFT JOIN job_positions ON job_positions.id = event_revisions.edited_by_id").
+		Joins("LEFT JOIN users ON users.id = job_positions.user_id").
+		Where("event_revisions.event_id = ?", *modelID2DBID(&eventID)).
+		Order("event_revisions.created_at desc").Find(&revisions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get revisions of event-id %s: %s", eventID.String(), result.Error.Error())
+	}
+
+	modelRevisions := make([]m.EventRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		modelRevisions = append(modelRevisions, m.EventRevision{
+			ID:             *dbID2ModelID(&revision.ID),
+			EventID:        *dbID2ModelID(&revision.EventID),
+			EditedBy:       *dbID2ModelID(&revision.EditedByID),
+			EditorName:     revision.EditorName,
+			OldName:        revision.OldName,
+			NewName:        revision.NewName,
+			OldDescription: revision.OldDescription,
+			NewDescription: revision.NewDescription,
+			CreatedAt:      revision.CreatedAt.UTC().Unix(),
+		})
+	}
+	return &modelRevisions, nil
+}
+
+// If the event has never been updated, UpdatedAt of the returned event would be nil.
 func dbEvent2ModelEvent(event *db.Event) *m.Event {
-	updatedAt := event.UpdatedAt.UTC().Unix()
+	var updatedAt *int64
+	if !event.UpdatedAt.Equal(event.CreatedAt) {
+		updated := event.UpdatedAt.UTC().Unix()
+		updatedAt = &updated
+	}
 	return &m.Event{
 		ID:          *dbID2ModelID(&event.ID),
 		Name:        event.Name,
 		CreatedBy:   *dbID2ModelID(&event.CreatedByID),
 		Description: event.Description,
 		CreatedAt:   event.CreatedAt.UTC().Unix(),
-		UpdatedAt:   &updatedAt,
+		UpdatedAt:   updatedAt,
 	}
 }
 
 func dbEvents2ModelEvents(events *[]db.Event) *[]m.Event {
-	var result []m.Event
+	result := make([]m.Event, 0, len(*events))
 	for _, event := range *events {
 		result = append(result, *dbEvent2ModelEvent(&event))
 	}
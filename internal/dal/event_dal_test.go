@@ -0,0 +1,243 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestApproveEvent(t *testing.T) {
+	testDB := newTestDB(t)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	ownerID := createTestJP(t, testDB, nil)
+	approverID := createTestJP(t, testDB, &ownerID)
+	eventID, err := eventDAL.CreateEvent(&m.Event{Name: "event", CreatedBy: ownerID})
+	if err != nil {
+		t.Fatalf("failed to create the event: %s", err.Error())
+	}
+
+	approvalID, err := eventDAL.ApproveEvent(*eventID, approverID)
+	if err != nil {
+		t.Fatalf("failed to approve the event: %s", err.Error())
+	}
+	if againID, err := eventDAL.ApproveEvent(*eventID, approverID); err != nil || *againID != *approvalID {
+		t.Errorf("expected approving again to return the previous approval %s, got %v (%v)",
+			approvalID.String(), againID, err)
+	}
+	var count int64
+	if err := testDB.Model(&db.EventApproval{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("expected one approval, got %d (%v)", count, err)
+	}
+
+	if isRevoked, err := eventDAL.RevokeApproval(*eventID, approverID); err != nil || !isRevoked {
+		t.Fatalf("failed to revoke the approval: %v", err)
+	}
+	if isRevoked, err := eventDAL.RevokeApproval(*eventID, approverID); err != nil || isRevoked {
+		t.Errorf("expected the revoked approval not to be revoked again, got %v (%v)", isRevoked, err)
+	}
+	if newID, err := eventDAL.ApproveEvent(*eventID, approverID); err != nil || *newID == *approvalID {
+		t.Errorf("expected a new approval after revoking the previous one, got %v (%v)", newID, err)
+	}
+}
+
+func TestGetNLastApprovedEvents(t *testing.T) {
+	testDB := newTestDB(t)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	adminID := createTestJP(t, testDB, nil)
+	ownerID := createTestJP(t, testDB, &adminID)
+	otherID := createTestJP(t, testDB, &adminID)
+	events := map[string]m.ID{}
+	for _, event := range []m.Event{
+		{Name: "first", CreatedBy: ownerID},
+		{Name: "second", CreatedBy: ownerID},
+		{Name: "other", CreatedBy: otherID},
+		{Name: "not approved", CreatedBy: ownerID},
+		{Name: "revoked", CreatedBy: ownerID},
+		{Name: "deleted", CreatedBy: ownerID},
+	} {
+		eventID, err := eventDAL.CreateEvent(&event)
+		if err != nil {
+			t.Fatalf("failed to create the event: %s", err.Error())
+		}
+		events[event.Name] = *eventID
+		if event.Name == "not approved" {
+			continue
+		}
+		if _, err := eventDAL.ApproveEvent(*eventID, adminID); err != nil {
+			t.Fatalf("failed to approve the event: %s", err.Error())
+		}
+	}
+	if _, err := eventDAL.RevokeApproval(events["revoked"], adminID); err != nil {
+		t.Fatalf("failed to revoke the approval: %s", err.Error())
+	}
+	if _, err := eventDAL.DeleteEvent(events["deleted"]); err != nil {
+		t.Fatalf("failed to delete the event: %s", err.Error())
+	}
+
+	tests := []struct {
+		name   string
+		jpIDs  *[]m.ID
+		filter *m.ListFilter
+		limit  int
+		offset int
+		// Names of the expected events. The newest approvals come first.
+		expected []string
+	}{
+		{name: "all job positions", limit: 10, expected: []string{"other", "second", "first"}},
+		{name: "given job positions", jpIDs: &[]m.ID{ownerID}, limit: 10, expected: []string{"second", "first"}},
+		{name: "filter", filter: &m.ListFilter{CreatedBy: &otherID}, limit: 10, expected: []string{"other"}},
+		{name: "limit and offset", limit: 1, offset: 1, expected: []string{"second"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			approvedEvents, err := eventDAL.GetNLastApprovedEvents(test.jpIDs, test.limit, test.offset, test.filter)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if len(*approvedEvents) != len(test.expected) {
+				t.Fatalf("expected events %v, got %+v", test.expected, *approvedEvents)
+			}
+			for i, name := range test.expected {
+				event := (*approvedEvents)[i]
+				if event.ID != events[name] || event.Approval.ApprovedByJPID != adminID || event.Approval.ApprovedBy != "user" {
+					t.Errorf("expected event %s approved by the admin at %d, got %+v", name, i, event)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateEventRevisions(t *testing.T) {
+	testDB := newTestDB(t)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	jpID := createTestJP(t, testDB, nil)
+	eventID, err := eventDAL.CreateEvent(&m.Event{Name: "event", Description: "description", CreatedBy: jpID})
+	if err != nil {
+		t.Fatalf("failed to create the event: %s", err.Error())
+	}
+
+	name, description := "edited", "description"
+	updates := []m.EventUpdate{{Name: &name}, {Name: &name, Description: &description}, {Description: &description}}
+	for _, update := range updates {
+		if isFound, err := eventDAL.UpdateEvent(*eventID, jpID, &update); err != nil || !isFound {
+			t.Fatalf("failed to update the event: %v", err)
+		}
+	}
+	revisions, err := eventDAL.GetEventRevisions(*eventID)
+	if err != nil {
+		t.Fatalf("failed to get revisions of the event: %s", err.Error())
+	}
+	if len(*revisions) != 1 || (*revisions)[0].OldName != "event" || (*revisions)[0].NewName != name {
+		t.Errorf("expected just the revision of the changed name, got %+v", *revisions)
+	}
+	if event, err := eventDAL.GetEventByID(*eventID); err != nil || event == nil || event.Name != name {
+		t.Errorf("expected the edited event, got %+v (%v)", event, err)
+	}
+}
+
+func TestDeleteEventHidesIt(t *testing.T) {
+	testDB := newTestDB(t)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	jpID := createTestJP(t, testDB, nil)
+	keptID, err := eventDAL.CreateEvent(&m.Event{Name: "kept", CreatedBy: jpID})
+	if err != nil {
+		t.Fatalf("failed to create the event: %s", err.Error())
+	}
+	deletedID, err := eventDAL.CreateEvent(&m.Event{Name: "deleted", CreatedBy: jpID})
+	if err != nil {
+		t.Fatalf("failed to create the event: %s", err.Error())
+	}
+	if _, err := eventDAL.GetEventByID(*deletedID); err != nil {
+		t.Fatalf("failed to get the event: %s", err.Error())
+	}
+
+	if isDeleted, err := eventDAL.DeleteEvent(*deletedID); err != nil || !isDeleted {
+		t.Fatalf("failed to delete the event: %v", err)
+	}
+	if event, err := eventDAL.GetEventByID(*deletedID); err != nil || event != nil {
+		t.Errorf("expected the deleted event not to be found, got %+v (%v)", event, err)
+	}
+	for _, jpIDs := range []*[]m.ID{nil, {jpID}} {
+		events, _, err := eventDAL.GetNLastEvents(jpIDs, nil, 10, nil)
+		if err != nil {
+			t.Fatalf("failed to get the events: %s", err.Error())
+		}
+		if len(*events) != 1 || (*events)[0].ID != *keptID {
+			t.Errorf("expected just the kept event in the feed, got %+v", *events)
+		}
+	}
+	if isDeleted, err := eventDAL.DeleteEvent(*deletedID); err != nil || isDeleted {
+		t.Errorf("expected the deleted event not to be deleted again, got %v (%v)", isDeleted, err)
+	}
+}
+
+func TestGetNLastEventsPages(t *testing.T) {
+	testDB := newTestDB(t)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	jpID := createTestJP(t, testDB, nil)
+	var ids []m.ID
+	for i := 0; i < 5; i++ {
+		eventID, err := eventDAL.CreateEvent(&m.Event{Name: "event", CreatedBy: jpID})
+		if err != nil {
+			t.Fatalf("failed to create the event: %s", err.Error())
+		}
+		ids = append(ids, *eventID)
+	}
+	// The last event is the newest one and the others are created at the same time.
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	setTestCreatedAt(t, testDB, "events", createdAt, ids[:4]...)
+	setTestCreatedAt(t, testDB, "events", createdAt.Add(time.Minute), ids[4])
+	expected := append([]m.ID{ids[4]}, sortTestIDsDesc(ids[:4])...)
+
+	checkTestPages(t, expected, func(cursor *m.Cursor, limit int) ([]m.ID, *m.Cursor) {
+		events, nextCursor, err := eventDAL.GetNLastEvents(&[]m.ID{jpID}, cursor, limit, nil)
+		if err != nil {
+			t.Fatalf("failed to get the events: %s", err.Error())
+		}
+		var pageIDs []m.ID
+		for _, event := range *events {
+			pageIDs = append(pageIDs, event.ID)
+		}
+		return pageIDs, nextCursor
+	})
+}
+
+func TestGetNLastEventsFilter(t *testing.T) {
+	testDB := newTestDB(t)
+	data := createTestFilterData(t, testDB)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+	createdBefore := data.createdBefore.Unix()
+	imageEventID, hasMedia, hasNotMedia := data.ids["image"], true, false
+	videoType := m.MediaVideo
+
+	tests := []struct {
+		name     string
+		filter   m.ListFilter
+		expected []string
+	}{
+		{name: "created from", filter: m.ListFilter{CreatedFrom: &createdBefore}, expected: []string{"image", "video", "text"}},
+		{name: "created to", filter: m.ListFilter{CreatedTo: &createdBefore}, expected: []string{"old"}},
+		{name: "created by", filter: m.ListFilter{CreatedBy: &data.memberID}, expected: []string{"video", "text"}},
+		{name: "event", filter: m.ListFilter{EventID: &imageEventID}, expected: []string{"image"}},
+		{name: "region", filter: m.ListFilter{RegionID: &data.regionID}, expected: []string{"video", "text"}},
+		{name: "with media", filter: m.ListFilter{HasMedia: &hasMedia}, expected: []string{"image", "video"}},
+		{name: "without media", filter: m.ListFilter{HasMedia: &hasNotMedia}, expected: []string{"old", "text"}},
+		{name: "media type", filter: m.ListFilter{MediaType: &videoType}, expected: []string{"video"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, _, err := eventDAL.GetNLastEvents(&[]m.ID{data.adminID, data.memberID}, nil, 10, &test.filter)
+			if err != nil {
+				t.Fatalf("failed to get the events: %s", err.Error())
+			}
+			var ids []m.ID
+			for _, event := range *events {
+				ids = append(ids, event.ID)
+			}
+			checkTestNamedIDs(t, ids, data.ids, test.expected)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	l "DMS/internal/logger"
 	"context"
 	"io"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -18,7 +19,16 @@ type InMemoryDAL interface {
 	// If both returned string and error be nil, means there's not such key
 	Get(key string) (*string, error)
 	Set(key, value string) error
+	// Set the key-value that expires after the given duration.
+	SetWithExpire(key, value string, expire time.Duration) error
+	// Increment the integer value of the key by one and return the new value. If there's
+	// not such key, it's created with value 1 and expires after the given duration.
+	Increment(key string, expire time.Duration) (int64, error)
 	Delete(key string) error
+	// Delete the key if its value equals to the given value and return true if it's
+	// deleted. Checking and deleting is done atomically, so only one of the concurrent
+	// callers could delete the key.
+	DeleteIfEqual(key, value string) (bool, error)
 	// Clear the key-values in im-memory cache that their keys match the pattern.
 	Clear(pattern string) error
 	// Returns the number of keys that match the pattern.
@@ -45,6 +55,10 @@ func (r *redisInMemoeyDAL) Delete(key string) error {
 	return r.db.Delete(key)
 }
 
+func (r *redisInMemoeyDAL) DeleteIfEqual(key, value string) (bool, error) {
+	return r.db.DeleteIfEqual(key, value)
+}
+
 func (r *redisInMemoeyDAL) Get(key string) (*string, error) {
 	val, err := r.db.Get(key)
 	if err == redis.Nil {
@@ -57,6 +71,14 @@ func (r *redisInMemoeyDAL) Set(key string, value string) error {
 	return r.db.Set(key, value)
 }
 
+func (r *redisInMemoeyDAL) SetWithExpire(key, value string, expire time.Duration) error {
+	return r.db.SetWithExpire(key, value, expire)
+}
+
+func (r *redisInMemoeyDAL) Increment(key string, expire time.Duration) (int64, error) {
+	return r.db.Increment(key, expire)
+}
+
 func (r *redisInMemoeyDAL) Size(pattern string) (int, error) {
 	return r.db.Size(pattern)
 }
@@ -14,22 +14,50 @@ import (
 )
 
 type JPDAL interface {
-	// Create a job position and its permissions for specified user and return job position id
+	// Create a job position for specified user, assign the built-in roles equivalent to the
+	// permission to it and return job position id
 	CreateUserJPWithPermissions(jp *m.UserJobPosition, permission *m.Permission) (*m.ID, error)
-	// Create an admin job position and its permissions for specified user and return job position id
+	// Create an admin job position for specified user, assign the built-in roles equivalent
+	// to the permission to it and return job position id
 	CreateAdminJPWithPermissions(jp *m.AdminJobPosition, permission *m.Permission) (*m.ID, error)
 	// Create a job position for specified user id and return its id
 	CreateUserJP(jp *m.UserJobPosition) (*m.ID, error)
 	// Create a admin job position for specified user id and return its id
 	CreateAdminJP(jp *m.AdminJobPosition) (*m.ID, error)
-	// Create Permission for specified job position and return its id
-	CreatePermission(JPID m.ID, permission *m.Permission) (*m.ID, error)
 	// Get all job positions of the specified user
 	// If both array and error be nil, it means there's not any matched job position.
 	GetJPsByUser(user *m.User) (*[]m.UserJobPosition, error)
-	// Return true if a job position with given ID belongs to a user with given ID.
+	// Return true if a job position with given ID belongs to a user with given ID and
+	// the job position is not disabled.
 	IsExistsUserWithJP(userID, jpID m.ID) (bool, error)
+	// Get the job position with given id. If both returned values be nil, it means the job
+	// position is not found.
+	GetJPByID(jpID m.ID) (*m.UserJobPosition, error)
+	// Update the given fields of the job position. Return false if the job position is
+	// not found.
+	UpdateJP(jpID m.ID, update *m.JPUpdate) (bool, error)
+	// Change parent of the job position. Return false if the job position is not found.
+	MoveJP(jpID, newParentID m.ID) (bool, error)
+	// Disable or enable the job position. Return false if the job position is not found.
+	SetJPDisability(jpID m.ID, isDisabled bool) (bool, error)
+	// Soft delete the job position and unassign its roles. Return false if the job position
+	// is not found.
+	DeleteJP(jpID m.ID) (bool, error)
+	// Get the job positions with given ids together with their user names. Roles of the
+	// job positions are not filled.
+	// Job positions that are not found are not in the result and the order of the result
+	// is not specified.
+	GetJPNodes(jpIDs []m.ID) (*[]m.JPNode, error)
 	GetAllJPCount() (uint64, error)
+	// Return true if there's any admin job position. (a job position without parent)
+	IsExistsAdminJP() (bool, error)
+	// Create the first admin with its admin job position jp and assign the built-in roles
+	// equivalent to the permission to the job position, all in a transaction. Concurrent
+	// calls are serialized, so just one of them creates the admin. If there's any admin
+	// job position, nothing is created and all returned values are nil. If a user with the
+	// phone number exists and it isn't created by another user (an admin), the job
+	// position is created for it; otherwise an error wrapping ErrExists is returned.
+	BootstrapAdmin(name string, phone m.PhoneNumber, jp *m.AdminJobPosition, permission *m.Permission) (userID, jpID *m.ID, err error)
 	getSomeJPIDs(limit, offset int) (*[]JPEdge, error)
 	// Return an iterator over job position details. (their ids and their parents)
 	// limit is the batch size of the job positions fetched from the db.
@@ -51,13 +79,31 @@ func newPsqlJPDAL(db *db.PSQLDB, cache *cache, logger l.Logger) *psqlJPDAL {
 }
 
 func (d *psqlJPDAL) CreateUserJP(jp *m.UserJobPosition) (*m.ID, error) {
+	var jpID *m.ID
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		var err error
+		jpID, err = d.createUserJP(tx, jp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jpID, nil
+}
+
+// Create the job position and its edges to all of its parents using the given transaction.
+func (d *psqlJPDAL) createUserJP(tx *db.PSQLDB, jp *m.UserJobPosition) (*m.ID, error) {
+	parents := jp.Parents()
+	if len(parents) == 0 {
+		return nil, e.NewSError("job position must have at least one parent")
+	}
 	newJP := db.JobPosition{
 		UserID:   *modelID2DBID(&jp.UserID),
 		Title:    jp.Title,
 		RegionID: *modelID2DBID(&jp.RegionID),
-		ParentID: modelID2DBID(&jp.ParentID),
+		ParentID: modelID2DBID(&parents[0]),
 	}
-	result := d.db.Create(&newJP)
+	result := tx.Create(&newJP)
 
 	if result.Error != nil {
 		d.logger.Debugf("Failed to create job position for user-id %s (%s)", newJP.UserID.ToString(), result.Error.Error())
@@ -67,17 +113,30 @@ func (d *psqlJPDAL) CreateUserJP(jp *m.UserJobPosition) (*m.ID, error) {
         created are %d"`, newJP.UserID.ToString(), result.RowsAffected)
 		return nil, e.NewSError("couldn't create job position")
 	}
+
+	edges := make([]db.JPEdge, len(parents))
+	for i := range parents {
+		edges[i] = db.JPEdge{JpID: newJP.ID, ParentID: *modelID2DBID(&parents[i])}
+	}
+	if err := tx.Create(&edges).Error; err != nil {
+		d.logger.Debugf("Failed to create edges of job position-id %s (%s)", newJP.ID.ToString(), err.Error())
+		return nil, err
+	}
 	return dbID2ModelID(&newJP.ID), nil
 }
 
 func (d *psqlJPDAL) CreateAdminJP(jp *m.AdminJobPosition) (*m.ID, error) {
+	return d.createAdminJP(d.db, jp)
+}
+
+func (d *psqlJPDAL) createAdminJP(tx *db.PSQLDB, jp *m.AdminJobPosition) (*m.ID, error) {
 	newJP := db.JobPosition{
 		UserID:   *modelID2DBID(&jp.UserID),
 		Title:    jp.Title,
 		RegionID: *modelID2DBID(&jp.RegionID),
 		ParentID: nil,
 	}
-	result := d.db.Create(&newJP)
+	result := tx.Create(&newJP)
 
 	if result.Error != nil {
 		d.logger.Debugf("Failed to create job position for user-id %s (%s)", newJP.UserID.ToString(), result.Error.Error())
@@ -90,37 +149,15 @@ func (d *psqlJPDAL) CreateAdminJP(jp *m.AdminJobPosition) (*m.ID, error) {
 	return dbID2ModelID(&newJP.ID), nil
 }
 
-func (d *psqlJPDAL) CreatePermission(JPID m.ID, permission *m.Permission) (*m.ID, error) {
-	newPermission := db.JPPermission{
-		JpID:            *modelID2DBID(&JPID),
-		IsAllowCreateJP: permission.IsAllowCreateJP,
-	}
-	result := d.db.Create(&newPermission)
-
-	if result.Error != nil {
-		d.logger.Debugf("Failed to create permission for job position-id %s (%s)", newPermission.JpID.ToString(), result.Error.Error())
-		return nil, result.Error
-	} else if result.RowsAffected < 1 {
-		d.logger.Debugf(`It seems can't create permission for job position-id %s. Total rows 
-        created are %d"`, newPermission.JpID.ToString(), result.RowsAffected)
-		return nil, e.NewSError("couldn't create permission")
-	}
-	return dbID2ModelID(&newPermission.ID), nil
-}
-
 func (d *psqlJPDAL) CreateUserJPWithPermissions(jp *m.UserJobPosition, permission *m.Permission) (*m.ID, error) {
 	var jpID *m.ID
 	result := d.db.Transaction(func(tx *db.PSQLDB) error {
 		var err error
-		jpID, err = d.CreateUserJP(jp)
-		if err != nil {
-			return err
-		}
-		_, err = d.CreatePermission(*jpID, permission)
+		jpID, err = d.createUserJP(tx, jp)
 		if err != nil {
 			return err
 		}
-		return nil
+		return assignPermissionRoles(tx, *jpID, permission)
 	})
 
 	if result != nil {
@@ -134,15 +171,11 @@ func (d *psqlJPDAL) CreateAdminJPWithPermissions(jp *m.AdminJobPosition, permiss
 	var jpID *m.ID
 	result := d.db.Transaction(func(tx *db.PSQLDB) error {
 		var err error
-		jpID, err = d.CreateAdminJP(jp)
-		if err != nil {
-			return err
-		}
-		_, err = d.CreatePermission(*jpID, permission)
+		jpID, err = d.createAdminJP(tx, jp)
 		if err != nil {
 			return err
 		}
-		return nil
+		return assignPermissionRoles(tx, *jpID, permission)
 	})
 
 	if result != nil {
@@ -175,9 +208,69 @@ func (d *psqlJPDAL) GetJPsByUser(user *m.User) (*[]m.UserJobPosition, error) {
 	}
 
 	modelJPs := dbJPs2ModelJPs(jps)
+	if err := d.setJPsParents(modelJPs); err != nil {
+		return nil, err
+	}
 	return &modelJPs, nil
 }
 
+func (d *psqlJPDAL) IsExistsAdminJP() (bool, error) {
+	return isExistsAdminJP(d.db)
+}
+
+func isExistsAdminJP(tx *db.PSQLDB) (bool, error) {
+	var count int64
+	if err := tx.Model(&db.JobPosition{}).Where("parent_id IS NULL").Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count admin job positions: %s", err.Error())
+	}
+	return count > 0, nil
+}
+
+// Key of the advisory lock that serializes bootstrapping the first admin
+const bootstrapAdminLockKey = "bootstrap-admin"
+
+func (d *psqlJPDAL) BootstrapAdmin(name string, phone m.PhoneNumber, jp *m.AdminJobPosition, permission *m.Permission) (*m.ID, *m.ID, error) {
+	var userID, jpID *m.ID
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		// The lock is held until the end of the transaction, so the next bootstrap sees the
+		// created admin job position.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", bootstrapAdminLockKey).Error; err != nil {
+			return err
+		}
+		if isExists, err := isExistsAdminJP(tx); err != nil || isExists {
+			return err
+		}
+
+		var user db.User
+		result := tx.Where(&db.User{PhoneNumber: phone.ToString()}).Limit(1).Find(&user)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			user = db.User{Name: name, PhoneNumber: phone.ToString(), IsDisabled: db.IsNotDisabled}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		} else if user.CreatedByID != nil {
+			return fmt.Errorf("the user with phone number %s is not admin: %w", phone.ToString(), e.ErrExists)
+		}
+
+		jp.UserID = *dbID2ModelID(&user.ID)
+		newJPID, err := d.createAdminJP(tx, jp)
+		if err != nil {
+			return err
+		}
+		if err := assignPermissionRoles(tx, *newJPID, permission); err != nil {
+			return err
+		}
+		userID, jpID = &jp.UserID, newJPID
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to bootstrap admin: %w", err)
+	}
+	return userID, jpID, nil
+}
+
 func (d *psqlJPDAL) IsExistsUserWithJP(userID, jpID m.ID) (bool, error) {
 	cacheKey := ck.userHasJPKey(userID, jpID)
 	isExists := false
@@ -187,7 +280,8 @@ func (d *psqlJPDAL) IsExistsUserWithJP(userID, jpID m.ID) (bool, error) {
 	}
 
 	var jp db.JobPosition
-	result := d.db.Where("user_id = ? AND id = ?", userID, jpID).Limit(1).Find(&jp)
+	result := d.db.Where("user_id = ? AND id = ? AND is_disabled = ?", userID, jpID, db.IsNotDisabled).
+		Limit(1).Find(&jp)
 	if result.Error != nil {
 		return false, fmt.Errorf("failed to check if user with id %s has job position with id %s: %s",
 			userID.String(), jpID.String(), result.Error.Error())
@@ -200,22 +294,221 @@ func (d *psqlJPDAL) IsExistsUserWithJP(userID, jpID m.ID) (bool, error) {
 	}
 }
 
+func (d *psqlJPDAL) GetJPByID(jpID m.ID) (*m.UserJobPosition, error) {
+	jp, err := d.getJPByID(d.db, jpID)
+	if err != nil || jp == nil {
+		return nil, err
+	}
+	modelJP := dbJP2ModelJP(jp)
+	if err := d.setJPsParents([]m.UserJobPosition{*modelJP}); err != nil {
+		return nil, err
+	}
+	return modelJP, nil
+}
+
+// Return nil if the job position is not found.
+func (d *psqlJPDAL) getJPByID(tx *db.PSQLDB, jpID m.ID) (*db.JobPosition, error) {
+	var jp db.JobPosition
+	result := tx.Where(&db.JobPosition{BaseModel: db.BaseModel{ID: *modelID2DBID(&jpID)}}).
+		Limit(1).Find(&jp)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get job position with id %s: %s", jpID.String(), result.Error.Error())
+	} else if result.RowsAffected < 1 {
+		return nil, nil
+	}
+	return &jp, nil
+}
+
+func (d *psqlJPDAL) UpdateJP(jpID m.ID, update *m.JPUpdate) (bool, error) {
+	fields := map[string]any{}
+	if update.Title != nil {
+		fields["title"] = *update.Title
+	}
+	if update.RegionID != nil {
+		fields["region_id"] = *modelID2DBID(update.RegionID)
+	}
+	if len(fields) == 0 {
+		jp, err := d.getJPByID(d.db, jpID)
+		return jp != nil, err
+	}
+	return d.updateJP(d.db, jpID, fields)
+}
+
+// All of the current parents of the job position are replaced with the new parent.
+func (d *psqlJPDAL) MoveJP(jpID, newParentID m.ID) (bool, error) {
+	isFound := false
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		var err error
+		isFound, err = d.updateJP(tx, jpID, map[string]any{"parent_id": *modelID2DBID(&newParentID)})
+		if err != nil || !isFound {
+			return err
+		}
+		if err := tx.Where(&db.JPEdge{JpID: *modelID2DBID(&jpID)}).Delete(&db.JPEdge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&db.JPEdge{JpID: *modelID2DBID(&jpID), ParentID: *modelID2DBID(&newParentID)}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move job position with id %s: %s", jpID.String(), err.Error())
+	}
+	return isFound, nil
+}
+
+func (d *psqlJPDAL) SetJPDisability(jpID m.ID, isDisabled bool) (bool, error) {
+	disability := db.IsNotDisabled
+	if isDisabled {
+		disability = db.IsDisabled
+	}
+	jp, err := d.getJPByID(d.db, jpID)
+	if err != nil || jp == nil {
+		return false, err
+	}
+	isFound, err := d.updateJP(d.db, jpID, map[string]any{"is_disabled": disability})
+	if isFound {
+		d.cache.delete(ck.userHasJPKey(*dbID2ModelID(&jp.UserID), jpID))
+	}
+	return isFound, err
+}
+
+func (d *psqlJPDAL) updateJP(tx *db.PSQLDB, jpID m.ID, fields map[string]any) (bool, error) {
+	result := tx.Model(&db.JobPosition{}).
+		Where(&db.JobPosition{BaseModel: db.BaseModel{ID: *modelID2DBID(&jpID)}}).
+		Updates(fields)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update job position with id %s: %s", jpID.String(), result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *psqlJPDAL) DeleteJP(jpID m.ID) (bool, error) {
+	var jp *db.JobPosition
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		var err error
+		jp, err = d.getJPByID(tx, jpID)
+		if err != nil || jp == nil {
+			return err
+		}
+		if err := tx.Delete(jp).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(&db.JPEdge{JpID: jp.ID}).Delete(&db.JPEdge{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(&db.JPRole{JpID: jp.ID}).Delete(&db.JPRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(&db.JPPermission{JpID: jp.ID}).Delete(&db.JPPermission{}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete job position with id %s: %s", jpID.String(), err.Error())
+	} else if jp == nil {
+		return false, nil
+	}
+	d.cache.delete(ck.userHasJPKey(*dbID2ModelID(&jp.UserID), jpID))
+	return true, nil
+}
+
+type jpNodeRow struct {
+	db.JobPosition
+	UserName string
+}
+
+func (d *psqlJPDAL) GetJPNodes(jpIDs []m.ID) (*[]m.JPNode, error) {
+	nodes := make([]m.JPNode, 0, len(jpIDs))
+	if len(jpIDs) == 0 {
+		return &nodes, nil
+	}
+	var rows []jpNodeRow
+	result := d.db.Model(&db.JobPosition{}).
+		Select("job_positions.*, users.name AS user_name").
+		Joins("LEFT JOIN users ON users.id = job_positions.user_id").
+		Where("job_positions.id IN ?", *modelIDs2DBIDs(&jpIDs)).
+		Find(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get details of %d job positions: %s", len(jpIDs), result.Error.Error())
+	}
+
+	parents, err := d.getJPsParents(*modelIDs2DBIDs(&jpIDs))
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		jp := dbJP2ModelJP(&rows[i].JobPosition)
+		nodes = append(nodes, m.JPNode{
+			ID:         jp.ID,
+			Title:      jp.Title,
+			UserID:     jp.UserID,
+			UserName:   rows[i].UserName,
+			RegionID:   jp.RegionID,
+			ParentIDs:  parents[jp.ID],
+			IsDisabled: jp.IsDisabled,
+		})
+	}
+	return &nodes, nil
+}
+
 type JPEdge struct {
 	JP m.ID
 	// If jp doesn't have any parent, the parent would be NilID
 	Parent m.ID
 }
 
+// Return all parents of the given job positions. Job positions without any parent are
+// not in the result.
+func (d *psqlJPDAL) getJPsParents(jpIDs []db.ID) (map[m.ID][]m.ID, error) {
+	var edges []db.JPEdge
+	result := d.db.Where("jp_id IN ?", jpIDs).Order("created_at, parent_id").Find(&edges)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get parents of %d job positions: %s", len(jpIDs), result.Error.Error())
+	}
+	parents := make(map[m.ID][]m.ID)
+	for i := range edges {
+		jpID := *dbID2ModelID(&edges[i].JpID)
+		parents[jpID] = append(parents[jpID], *dbID2ModelID(&edges[i].ParentID))
+	}
+	return parents, nil
+}
+
+// Fill ParentIDs of the given job positions.
+func (d *psqlJPDAL) setJPsParents(jps []m.UserJobPosition) error {
+	if len(jps) == 0 {
+		return nil
+	}
+	jpIDs := make([]db.ID, len(jps))
+	for i := range jps {
+		jpIDs[i] = *modelID2DBID(&jps[i].ID)
+	}
+	parents, err := d.getJPsParents(jpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range jps {
+		jps[i].ParentIDs = parents[jps[i].ID]
+	}
+	return nil
+}
+
+type jpEdgeRow struct {
+	JpID     db.ID
+	ParentID *db.ID
+}
+
+// Each job position could have several edges and one edge is returned for each of them.
+// Job positions without any parent have one edge with NilID parent.
 func (d *psqlJPDAL) getSomeJPIDs(limit, offset int) (*[]JPEdge, error) {
-	list := []db.JobPosition{}
-	result := d.db.Limit(limit).Offset(offset).Select("id", "parent_id").Find(&list)
+	list := []jpEdgeRow{}
+	result := d.db.Model(&db.JobPosition{}).
+		Select("job_positions.id AS jp_id, jp_edges.parent_id").
+		Joins("LEFT JOIN jp_edges ON jp_edges.jp_id = job_positions.id").
+		Order("job_positions.id, jp_edges.parent_id").
+		Limit(limit).Offset(offset).Find(&list)
 
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	jpIDs := make([]JPEdge, len(list))
 	for i := range list {
-		jpIDs[i].JP = *dbID2ModelID(&list[i].ID)
+		jpIDs[i].JP = *dbID2ModelID(&list[i].JpID)
 		if list[i].ParentID != nil {
 			jpIDs[i].Parent = *dbID2ModelID(list[i].ParentID)
 		}
@@ -252,7 +545,7 @@ func (jp *jpsEdgeIter) Next() (JPEdge, bool) {
 			jp.jpStack.Push(jPos)
 		}
 	}
-	return jp.jpStack.Pop(), jp.jpStack.Size() > 0
+	return jp.jpStack.Pop(), true
 }
 func (d *psqlJPDAL) GetJPEdgeIter(limit int) common.Iterator[JPEdge] {
 	mutex := sync.Mutex{}
@@ -277,11 +570,12 @@ func dbJP2ModelJP(jp *db.JobPosition) *m.UserJobPosition {
 
 	return &m.UserJobPosition{
 		CommonJobPosition: m.CommonJobPosition{
-			ID:        *dbID2ModelID(&jp.ID),
-			UserID:    *dbID2ModelID(&jp.UserID),
-			Title:     jp.Title,
-			RegionID:  *dbID2ModelID(&jp.RegionID),
-			CreatedAt: jp.CreatedAt.UTC().Unix(),
+			ID:         *dbID2ModelID(&jp.ID),
+			UserID:     *dbID2ModelID(&jp.UserID),
+			Title:      jp.Title,
+			RegionID:   *dbID2ModelID(&jp.RegionID),
+			CreatedAt:  jp.CreatedAt.UTC().Unix(),
+			IsDisabled: jp.IsDisabled == db.IsDisabled,
 		},
 		ParentID: mParentID,
 	}
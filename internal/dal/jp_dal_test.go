@@ -0,0 +1,79 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// Roll back the migrations of the test database down to the given one, so the test
+// database has the schema before applying it.
+func rollbackTestDB(t *testing.T, testDB *db.PSQLDB, name string) {
+	t.Helper()
+	statuses, err := db.GetMigrationStatus(testDB)
+	if err != nil {
+		t.Fatalf("failed to get status of the migrations: %s", err.Error())
+	}
+	steps := -1
+	for i, status := range statuses {
+		if status.Name == name {
+			steps = len(statuses) - i
+		}
+	}
+	if steps < 0 {
+		t.Fatalf("migration %s not found", name)
+	}
+	if _, err := db.MigrateDown(testDB, steps, l.NewSLogger(l.None, nil, io.Discard)); err != nil {
+		t.Fatalf("failed to roll back the migrations: %s", err.Error())
+	}
+}
+
+func TestIsExistsUserWithJPCreatedBeforeDisability(t *testing.T) {
+	tests := []struct {
+		name string
+		// It changes the schema before creating the job position.
+		alter string
+	}{
+		{name: "job position created before the column"},
+		{name: "column added by the auto migration", alter: "ALTER TABLE job_positions ADD COLUMN is_disabled smallint"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testDB := newTestDB(t)
+			rollbackTestDB(t, testDB, "jp_disability")
+			if test.alter != "" {
+				if err := testDB.Exec(test.alter).Error; err != nil {
+					t.Fatalf("failed to alter the schema: %s", err.Error())
+				}
+			}
+			userID, jpID := m.ID(uuid.New()), m.ID(uuid.New())
+			err := testDB.Exec("INSERT INTO users (id, created_at, updated_at, name, phone_number, is_disabled) "+
+				"VALUES (?, now(), now(), 'admin', '9170000001', 0)", *modelID2DBID(&userID)).Error
+			if err == nil {
+				err = testDB.Exec("INSERT INTO job_positions (id, created_at, updated_at, user_id, title) "+
+					"VALUES (?, now(), now(), ?, 'Admin')", *modelID2DBID(&jpID), *modelID2DBID(&userID)).Error
+			}
+			if err != nil {
+				t.Fatalf("failed to create the job position: %s", err.Error())
+			}
+			if _, err := db.MigrateUp(testDB, l.NewSLogger(l.None, nil, io.Discard)); err != nil {
+				t.Fatalf("failed to migrate the test database: %s", err.Error())
+			}
+
+			jpDAL := newPsqlJPDAL(testDB, newTestCache(), l.NewSLogger(l.None, nil, io.Discard))
+			if isExists, err := jpDAL.IsExistsUserWithJP(userID, jpID); err != nil || !isExists {
+				t.Fatalf("expected the job position to be enabled, got %v (%v)", isExists, err)
+			}
+			if isFound, err := jpDAL.SetJPDisability(jpID, true); err != nil || !isFound {
+				t.Fatalf("failed to disable the job position: %v", err)
+			}
+			if isExists, err := jpDAL.IsExistsUserWithJP(userID, jpID); err != nil || isExists {
+				t.Errorf("expected the disabled job position not to be found, got %v (%v)", isExists, err)
+			}
+		})
+	}
+}
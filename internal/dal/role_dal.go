@@ -0,0 +1,229 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"fmt"
+)
+
+type RoleDAL interface {
+	// Create a role with its actions and return its id.
+	CreateRole(role *m.Role) (*m.ID, error)
+	// Get all roles with their actions.
+	GetRoles() (*[]m.Role, error)
+	// Get the role with given id. If both returned values be nil, it means the role is
+	// not found.
+	GetRoleByID(roleID m.ID) (*m.Role, error)
+	// Delete the role permanently and unassign it from all job positions. Return false if
+	// the role is not found.
+	DeleteRole(roleID m.ID) (bool, error)
+	// Assign the role to the job position. If it's assigned previously, just its
+	// inheritability is updated.
+	AssignRole(jpID, roleID m.ID, isInheritable bool) error
+	// Unassign the role from the job position. Return false if the role is not assigned
+	// to the job position.
+	UnassignRole(jpID, roleID m.ID) (bool, error)
+	// Get the roles assigned to each of the given job positions. Job positions without
+	// any role are not in the result.
+	GetJPsRoles(jpIDs []m.ID) (map[m.ID][]m.JPRole, error)
+	// Return true if one of the roles assigned to the job position or one of the
+	// inheritable roles assigned to its ancestors allows the action.
+	HasAction(jpID m.ID, ancestorIDs []m.ID, action m.Action) (bool, error)
+}
+
+type psqlRoleDAL struct {
+	db     *db.PSQLDB
+	logger l.Logger
+}
+
+func newPsqlRoleDAL(db *db.PSQLDB, logger l.Logger) *psqlRoleDAL {
+	return &psqlRoleDAL{db, logger}
+}
+
+func (d *psqlRoleDAL) CreateRole(role *m.Role) (*m.ID, error) {
+	newRole := db.Role{Name: role.Name, Description: role.Description}
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		if err := tx.Create(&newRole).Error; err != nil {
+			return err
+		}
+		return d.createRolePermissions(tx, newRole.ID, role.Actions)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role %s: %s", role.Name, err.Error())
+	}
+	return dbID2ModelID(&newRole.ID), nil
+}
+
+func (d *psqlRoleDAL) createRolePermissions(tx *db.PSQLDB, roleID db.ID, actions []m.Action) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	permissions := make([]db.RolePermission, len(actions))
+	for i := range actions {
+		permissions[i] = db.RolePermission{RoleID: roleID, Action: string(actions[i])}
+	}
+	return tx.Create(&permissions).Error
+}
+
+func (d *psqlRoleDAL) GetRoles() (*[]m.Role, error) {
+	var roles []db.Role
+	result := d.db.Preload("RolePermission").Order("name").Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get roles: %s", result.Error.Error())
+	}
+	modelRoles := make([]m.Role, len(roles))
+	for i := range roles {
+		modelRoles[i] = *dbRole2ModelRole(&roles[i])
+	}
+	return &modelRoles, nil
+}
+
+func (d *psqlRoleDAL) GetRoleByID(roleID m.ID) (*m.Role, error) {
+	var role db.Role
+	result := d.db.Preload("RolePermission").
+		Where(&db.Role{BaseModel: db.BaseModel{ID: *modelID2DBID(&roleID)}}).
+		Limit(1).Find(&role)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get role with id %s: %s", roleID.String(), result.Error.Error())
+	} else if result.RowsAffected < 1 {
+		return nil, nil
+	}
+	return dbRole2ModelRole(&role), nil
+}
+
+func (d *psqlRoleDAL) DeleteRole(roleID m.ID) (bool, error) {
+	isDeleted := false
+	dbRoleID := *modelID2DBID(&roleID)
+	err := d.db.Transaction(func(tx *db.PSQLDB) error {
+		// Roles are deleted permanently so their names could be used again.
+		result := tx.Unscoped().Where(&db.Role{BaseModel: db.BaseModel{ID: dbRoleID}}).Delete(&db.Role{})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isDeleted = true
+		if err := tx.Where(&db.JPRole{RoleID: dbRoleID}).Delete(&db.JPRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(&db.RolePermission{RoleID: dbRoleID}).Delete(&db.RolePermission{}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete role with id %s: %s", roleID.String(), err.Error())
+	}
+	return isDeleted, nil
+}
+
+func (d *psqlRoleDAL) AssignRole(jpID, roleID m.ID, isInheritable bool) error {
+	result := d.db.Exec(`INSERT INTO jp_roles (jp_id, role_id, is_inheritable, created_at)
+		VALUES (?, ?, ?, now())
+		ON CONFLICT (jp_id, role_id) DO UPDATE SET is_inheritable = EXCLUDED.is_inheritable`,
+		*modelID2DBID(&jpID), *modelID2DBID(&roleID), isInheritable)
+	if result.Error != nil {
+		return fmt.Errorf("failed to assign role %s to job position %s: %s", roleID.String(),
+			jpID.String(), result.Error.Error())
+	}
+	return nil
+}
+
+func (d *psqlRoleDAL) UnassignRole(jpID, roleID m.ID) (bool, error) {
+	result := d.db.Where(&db.JPRole{JpID: *modelID2DBID(&jpID), RoleID: *modelID2DBID(&roleID)}).
+		Delete(&db.JPRole{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to unassign role %s from job position %s: %s", roleID.String(),
+			jpID.String(), result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+type jpRoleRow struct {
+	db.JPRole
+	Name string
+}
+
+func (d *psqlRoleDAL) GetJPsRoles(jpIDs []m.ID) (map[m.ID][]m.JPRole, error) {
+	roles := make(map[m.ID][]m.JPRole)
+	if len(jpIDs) == 0 {
+		return roles, nil
+	}
+	var rows []jpRoleRow
+	result := d.db.Model(&db.JPRole{}).
+		Select("jp_roles.*, roles.name").
+		Joins("JOIN roles ON roles.id = jp_roles.role_id AND roles.deleted_at IS NULL").
+		Where("jp_roles.jp_id IN ?", *modelIDs2DBIDs(&jpIDs)).
+		Order("roles.name").
+		Find(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get roles of %d job positions: %s", len(jpIDs), result.Error.Error())
+	}
+	for i := range rows {
+		jpID := *dbID2ModelID(&rows[i].JpID)
+		roles[jpID] = append(roles[jpID], m.JPRole{
+			RoleID:        *dbID2ModelID(&rows[i].RoleID),
+			Name:          rows[i].Name,
+			IsInheritable: rows[i].IsInheritable,
+		})
+	}
+	return roles, nil
+}
+
+func (d *psqlRoleDAL) HasAction(jpID m.ID, ancestorIDs []m.ID, action m.Action) (bool, error) {
+	query := d.db.Model(&db.JPRole{}).
+		Joins("JOIN roles ON roles.id = jp_roles.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN role_permissions ON role_permissions.role_id = jp_roles.role_id").
+		Where("role_permissions.action = ?", string(action))
+	if len(ancestorIDs) > 0 {
+		query = query.Where("jp_roles.jp_id = ? OR (jp_roles.is_inheritable AND jp_roles.jp_id IN ?)",
+			*modelID2DBID(&jpID), *modelIDs2DBIDs(&ancestorIDs))
+	} else {
+		query = query.Where("jp_roles.jp_id = ?", *modelID2DBID(&jpID))
+	}
+
+	var jpRole db.JPRole
+	result := query.Select("jp_roles.*").Limit(1).Find(&jpRole)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check if job position %s is allowed to %s: %s", jpID.String(),
+			action, result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Assign the built-in roles equivalent to the given permission to the job position.
+// Each job position has the member role.
+func assignPermissionRoles(tx *db.PSQLDB, jpID m.ID, permission *m.Permission) error {
+	roleNames := []string{db.RoleMember}
+	if permission.IsAllowCreateJP {
+		roleNames = append(roleNames, db.RoleJPManager)
+	}
+	if permission.IsAllowApproveEvent {
+		roleNames = append(roleNames, db.RoleEventApprover)
+	}
+	var roles []db.Role
+	if err := tx.Where("name IN ?", roleNames).Find(&roles).Error; err != nil {
+		return err
+	} else if len(roles) != len(roleNames) {
+		return e.NewSError(fmt.Sprintf("some of the built-in roles %v not found", roleNames))
+	}
+
+	jpRoles := make([]db.JPRole, len(roles))
+	for i := range roles {
+		jpRoles[i] = db.JPRole{JpID: *modelID2DBID(&jpID), RoleID: roles[i].ID}
+	}
+	return tx.Create(&jpRoles).Error
+}
+
+func dbRole2ModelRole(role *db.Role) *m.Role {
+	actions := make([]m.Action, len(role.RolePermission))
+	for i := range role.RolePermission {
+		actions[i] = m.Action(role.RolePermission[i].Action)
+	}
+	return &m.Role{
+		ID:          *dbID2ModelID(&role.ID),
+		Name:        role.Name,
+		Description: role.Description,
+		Actions:     actions,
+		IsBuiltin:   role.IsBuiltin,
+	}
+}
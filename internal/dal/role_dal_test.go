@@ -0,0 +1,64 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"testing"
+)
+
+func TestHasAction(t *testing.T) {
+	testDB := newTestDB(t)
+	roleDAL := newPsqlRoleDAL(testDB, l.NewSLogger(l.None, nil, io.Discard))
+	parentID := createTestJP(t, testDB, nil)
+	childID := createTestJP(t, testDB, &parentID)
+	assign := func(name string, jpID m.ID, isInheritable bool, actions ...m.Action) m.ID {
+		t.Helper()
+		roleID, err := roleDAL.CreateRole(&m.Role{Name: name, Actions: actions})
+		if err != nil {
+			t.Fatalf("failed to create the role: %s", err.Error())
+		}
+		if err := roleDAL.AssignRole(jpID, *roleID, isInheritable); err != nil {
+			t.Fatalf("failed to assign the role: %s", err.Error())
+		}
+		return *roleID
+	}
+	assign("writer", childID, false, m.ActionCreateDoc)
+	assign("approver", parentID, true, m.ActionApproveEvent, m.ActionViewSubtree)
+	assign("manager", parentID, false, m.ActionManageJP)
+	// Roles are deleted permanently by the DAL, but the soft deleted roles must be skipped too.
+	removedID := assign("removed", childID, false, m.ActionCreateEvent)
+	if err := testDB.Delete(&db.Role{BaseModel: db.BaseModel{ID: *modelID2DBID(&removedID)}}).Error; err != nil {
+		t.Fatalf("failed to delete the role: %s", err.Error())
+	}
+
+	tests := []struct {
+		name        string
+		jpID        m.ID
+		ancestorIDs []m.ID
+		action      m.Action
+		expected    bool
+	}{
+		{name: "role of the job position", jpID: childID, action: m.ActionCreateDoc, expected: true},
+		{name: "action of another role", jpID: parentID, action: m.ActionCreateDoc},
+		{name: "inheritable role of the ancestor", jpID: childID, ancestorIDs: []m.ID{parentID},
+			action: m.ActionViewSubtree, expected: true},
+		{name: "role of the ancestor without ancestors", jpID: childID, action: m.ActionViewSubtree},
+		{name: "not inheritable role of the ancestor", jpID: childID, ancestorIDs: []m.ID{parentID},
+			action: m.ActionManageJP},
+		{name: "not inheritable role of itself", jpID: parentID, action: m.ActionManageJP, expected: true},
+		{name: "deleted role", jpID: childID, ancestorIDs: []m.ID{parentID}, action: m.ActionCreateEvent},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hasAction, err := roleDAL.HasAction(test.jpID, test.ancestorIDs, test.action)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if hasAction != test.expected {
+				t.Errorf("expected allowed to %s to be %v, got %v", test.action, test.expected, hasAction)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"fmt"
+	"time"
+)
+
+type SearchDAL interface {
+	// Search the query in context of docs and return the matched docs ordered by their
+	// relevance. If jpIDs isn't nil, just docs created by one of the given job positions
+	// are returned.
+	SearchDocs(query string, jpIDs *[]m.ID, limit, offset int) (*[]m.SearchResult, error)
+	// Search the query in name and description of events and return the matched events
+	// ordered by their relevance. If jpIDs isn't nil, just events created by one of the
+	// given job positions are returned.
+	SearchEvents(query string, jpIDs *[]m.ID, limit, offset int) (*[]m.SearchResult, error)
+}
+
+// Options of ts_headline function to generate snippets of the search results
+const (
+	snippetOptions   = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2"
+	highlightOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+)
+
+type psqlSearchDAL struct {
+	db     *db.PSQLDB
+	logger l.Logger
+}
+
+func newPsqlSearchDAL(db *db.PSQLDB, logger l.Logger) SearchDAL {
+	return &psqlSearchDAL{db, logger}
+}
+
+func (d *psqlSearchDAL) SearchDocs(query string, jpIDs *[]m.ID, limit, offset int) (*[]m.SearchResult, error) {
+	tx := d.db.Model(&db.Doc{}).
+		Select(fmt.Sprintf(`docs.id, docs.event_id, docs.created_by_id, docs.created_at,
+			ts_headline('%[1]s', %[4]s, search_query, '%[2]s') AS title,
+			ts_headline('%[1]s', %[5]s, search_query, '%[3]s') AS snippet,
+			ts_rank(docs.search_vector, search_query) AS rank`,
+			db.SearchConfig, highlightOptions, snippetOptions,
+			escapeHTML("fa_normalize(events.name)"), escapeHTML("fa_normalize(docs.context)"))).
+		Joins("INNER JOIN events ON docs.event_id = events.id AND events.deleted_at IS NULL").
+		Joins(searchQueryJoin(), query).
+		Where("docs.search_vector @@ search_query")
+	if jpIDs != nil {
+		tx = tx.Where("docs.created_by_id IN ?", *modelIDs2DBIDs(jpIDs))
+	}
+
+	var rows []searchResultRow
+	result := tx.Order("rank desc, docs.created_at desc").Offset(offset).Limit(limit).Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to search \"%s\" in docs (limit: %d, offset: %d): %s",
+			query, limit, offset, result.Error.Error())
+	}
+	return searchResultRows2Model(rows, m.SearchDoc), nil
+}
+
+func (d *psqlSearchDAL) SearchEvents(query string, jpIDs *[]m.ID, limit, offset int) (*[]m.SearchResult, error) {
+	tx := d.db.Model(&db.Event{}).
+		Select(fmt.Sprintf(`events.id, events.id AS event_id, events.created_by_id, events.created_at,
+			ts_headline('%[1]s', %[4]s, search_query, '%[2]s') AS title,
+			ts_headline('%[1]s', %[5]s, search_query, '%[3]s') AS snippet,
+			ts_rank(events.search_vector, search_query) AS rank`,
+			db.SearchConfig, highlightOptions, snippetOptions,
+			escapeHTML("fa_normalize(events.name)"), escapeHTML("fa_normalize(events.description)"))).
+		Joins(searchQueryJoin(), query).
+		Where("events.search_vector @@ search_query")
+	if jpIDs != nil {
+		tx = tx.Where("events.created_by_id IN ?", *modelIDs2DBIDs(jpIDs))
+	}
+
+	var rows []searchResultRow
+	result := tx.Order("rank desc, events.created_at desc").Offset(offset).Limit(limit).Scan(&rows)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to search \"%s\" in events (limit: %d, offset: %d): %s",
+			query, limit, offset, result.Error.Error())
+	}
+	return searchResultRows2Model(rows, m.SearchEvent), nil
+}
+
+// Return a join clause that parses the search query (passed as its argument) to a
+// tsquery named "search_query". The query supports web search syntax. e.g. quoted
+// phrases, "or" and "-" to exclude a word.
+func searchQueryJoin() string {
+	return fmt.Sprintf("CROSS JOIN websearch_to_tsquery('%s', fa_normalize(?)) AS search_query", db.SearchConfig)
+}
+
+// Return an SQL expression that escapes the HTML special characters of the text
+// expression. Snippets are generated from the escaped text, so the <mark> tags added by
+// ts_headline are the only tags of them and clients could render them as HTML.
+func escapeHTML(expression string) string {
+	return "replace(replace(replace(replace(replace(" + expression +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// A row of the search queries
+type searchResultRow struct {
+	ID          db.ID
+	EventID     db.ID
+	CreatedByID db.ID
+	CreatedAt   time.Time
+	Title       string
+	Snippet     string
+	Rank        float32
+}
+
+func searchResultRows2Model(rows []searchResultRow, searchType m.SearchType) *[]m.SearchResult {
+	results := make([]m.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, m.SearchResult{
+			Type:      searchType,
+			ID:        *dbID2ModelID(&row.ID),
+			EventID:   *dbID2ModelID(&row.EventID),
+			Title:     row.Title,
+			Snippet:   row.Snippet,
+			Rank:      row.Rank,
+			CreatedBy: *dbID2ModelID(&row.CreatedByID),
+			CreatedAt: row.CreatedAt.UTC().Unix(),
+		})
+	}
+	return &results
+}
@@ -0,0 +1,112 @@
+package dal
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	testDB := newTestDB(t)
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	eventDAL := newPsqlEventDAL(testDB, newTestCache(), testLogger)
+	docDAL := newPsqlDocDAL(testDB, newTestCache(), testLogger)
+	searchDAL := newPsqlSearchDAL(testDB, testLogger)
+	adminID := createTestJP(t, testDB, nil)
+	ownerID := createTestJP(t, testDB, &adminID)
+	otherID := createTestJP(t, testDB, &adminID)
+
+	ids := map[string]m.ID{}
+	for name, event := range map[string]m.Event{
+		"party":   {Name: "garden party", Description: "<b>tea</b> & cake", CreatedBy: ownerID},
+		"book":    {Name: "كتاب", Description: "meeting notes", CreatedBy: otherID},
+		"deleted": {Name: "garden tools", CreatedBy: ownerID},
+	} {
+		eventID, err := eventDAL.CreateEvent(&event)
+		if err != nil {
+			t.Fatalf("failed to create the event: %s", err.Error())
+		}
+		ids[name] = *eventID
+	}
+	for name, doc := range map[string]struct {
+		event     string
+		createdBy m.ID
+		context   string
+	}{
+		"party doc":   {event: "party", createdBy: ownerID, context: "planting roses in the garden"},
+		"book doc":    {event: "book", createdBy: otherID, context: "the garden of the book"},
+		"deleted doc": {event: "deleted", createdBy: ownerID, context: "garden tools are sold"},
+	} {
+		docID, err := docDAL.CreateDoc(&m.Doc{EventID: ids[doc.event], CreatedBy: doc.createdBy, Context: &doc.context})
+		if err != nil {
+			t.Fatalf("failed to create the doc: %s", err.Error())
+		}
+		ids[name] = *docID
+	}
+	if _, err := eventDAL.DeleteEvent(ids["deleted"]); err != nil {
+		t.Fatalf("failed to delete the event: %s", err.Error())
+	}
+
+	tests := []struct {
+		name   string
+		search func(query string, jpIDs *[]m.ID, limit, offset int) (*[]m.SearchResult, error)
+		query  string
+		jpIDs  *[]m.ID
+		// Names of the expected results in any order
+		expected []string
+	}{
+		{name: "events not deleted", search: searchDAL.SearchEvents, query: "garden", expected: []string{"party"}},
+		{name: "description of events", search: searchDAL.SearchEvents, query: "meeting", expected: []string{"book"}},
+		{name: "normalized Persian letters", search: searchDAL.SearchEvents, query: "کتاب", expected: []string{"book"}},
+		{name: "events of the job positions", search: searchDAL.SearchEvents, query: "meeting", jpIDs: &[]m.ID{ownerID}},
+		{name: "docs of the events not deleted", search: searchDAL.SearchDocs, query: "garden",
+			expected: []string{"party doc", "book doc"}},
+		{name: "docs of the job positions", search: searchDAL.SearchDocs, query: "garden", jpIDs: &[]m.ID{otherID},
+			expected: []string{"book doc"}},
+		{name: "excluded word", search: searchDAL.SearchDocs, query: "garden -roses", expected: []string{"book doc"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := test.search(test.query, test.jpIDs, 10, 0)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if len(*results) != len(test.expected) {
+				t.Fatalf("expected results %v, got %+v", test.expected, *results)
+			}
+			for _, name := range test.expected {
+				isFound := false
+				for _, result := range *results {
+					isFound = isFound || result.ID == ids[name]
+				}
+				if !isFound {
+					t.Errorf("expected result %s, got %+v", name, *results)
+				}
+			}
+		})
+	}
+
+	t.Run("snippets are escaped and highlighted", func(t *testing.T) {
+		results, err := searchDAL.SearchEvents("tea", nil, 10, 0)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if len(*results) != 1 {
+			t.Fatalf("expected one result, got %+v", *results)
+		}
+		snippet := (*results)[0].Snippet
+		if strings.Contains(snippet, "<b>") || !strings.Contains(snippet, "&lt;b&gt;") ||
+			!strings.Contains(snippet, "<mark>tea</mark>") {
+			t.Errorf("expected escaped snippet with the highlighted word, got %q", snippet)
+		}
+		docResults, err := searchDAL.SearchDocs("roses", nil, 10, 0)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if len(*docResults) != 1 || (*docResults)[0].EventID != ids["party"] || (*docResults)[0].Title != "garden party" {
+			t.Errorf("expected the doc with name of its event as the title, got %+v", *docResults)
+		}
+	})
+}
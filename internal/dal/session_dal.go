@@ -2,37 +2,92 @@ package dal
 
 import (
 	"DMS/internal/db"
+	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
+	"errors"
 	"fmt"
+	"time"
 )
 
 type SessionDAL interface {
 	// Create a login for specified user and return its id
 	CreateSession(loginInfo *m.Session) (*m.ID, error)
-	// Delete a session by sessionID
+	// Delete a session by sessionID together with all its refresh tokens.
 	// If the session was successfully deleted, return (true, nil). If an error occurred, return (false, error).
 	// and if the session was previously deactivated/deleted or it does not exist, return (false, nill).
+	// If the session is deleted but it couldn't be marked as revoked in the cache, an
+	// error is returned too, so the caller doesn't assume its tokens stopped working.
 	DeleteSession(sessionID m.ID) (bool, error)
 	// Returns true if the id of the user who owns the specified session matches the claimed user id.
 	IsMatchSessionUserID(sessionID, claimedUserID m.ID) (bool, error)
-	// Return fetched session
+	// Return fetched session. If the session is deleted or doesn't exist, return (nil, nil).
+	// Sessions are cached for a short time and deleted sessions are marked as revoked in
+	// the cache, so a stale cached copy is never returned after deletion.
 	GetSessionByID(sessionID m.ID) (*m.Session, error)
+	// Return sessions of the user that are not deleted and not expired at the time now.
+	// The recently used sessions come first.
+	GetUserSessions(userID m.ID, now int64) (*[]m.Session, error)
+	// Delete all sessions of the user, except the session exceptSessionID, together with
+	// their refresh tokens and return number of deleted sessions. If exceptSessionID is
+	// NilID, delete all of them. Like DeleteSession, it fails if the deleted sessions
+	// couldn't be marked as revoked in the cache.
+	DeleteUserSessions(userID, exceptSessionID m.ID) (int64, error)
+	// Update expiration time and last usage time of the session. If there's not such
+	// session, return (false, nil).
+	UpdateSession(session *m.Session) (bool, error)
+	// Set last usage time of the session to usedAt, if its previous value is before
+	// usedAt-minInterval. So a session that is used frequently is not updated on each usage.
+	// Each update is marked in the cache for minInterval seconds and the database is not
+	// queried while the mark exists.
+	UpdateSessionLastUsage(sessionID m.ID, usedAt, minInterval int64) error
+	// Store hash of a new refresh token for the session and return its id.
+	CreateRefreshToken(sessionID m.ID, tokenHash string, expiredAt int64) (*m.ID, error)
+	// Return the refresh token with the hash. If there's not such token, return (nil, nil).
+	GetRefreshTokenByHash(tokenHash string) (*m.RefreshToken, error)
+	// Mark the refresh token as used, store the new refresh token for its session and
+	// update the session. If the token is used previously (even concurrently), nothing is
+	// changed and return (false, nil).
+	RotateRefreshToken(tokenID m.ID, newTokenHash string, session *m.Session) (bool, error)
 }
 
+func (c cacheKey) sessionByIDKey(sessionID m.ID) string {
+	return fmt.Sprintf("session:id:%s", sessionID.String())
+}
+
+func (c cacheKey) revokedSessionKey(sessionID m.ID) string {
+	return fmt.Sprintf("session:revoked:%s", sessionID.String())
+}
+
+func (c cacheKey) sessionUsedKey(sessionID m.ID) string {
+	return fmt.Sprintf("session:used:%s", sessionID.String())
+}
+
+// Sessions are cached just for this duration, so even if invalidating the cache fails, a
+// revoked session couldn't be used longer than it. It must not exceed lifetime of access
+// tokens.
+const sessionCacheExpire = time.Minute
+
+// A revoked session is marked in the cache for this duration. It outlives any copy of the
+// session cached by a read that raced with the deletion.
+const revokedSessionExpire = 2 * sessionCacheExpire
+
 type psqlSessionDAL struct {
 	db     *db.PSQLDB
+	cache  *cache
 	logger l.Logger
 }
 
-func newPsqlSessionDAL(db *db.PSQLDB, logger l.Logger) *psqlSessionDAL {
-	return &psqlSessionDAL{db, logger}
+func newPsqlSessionDAL(db *db.PSQLDB, cache *cache, logger l.Logger) *psqlSessionDAL {
+	return &psqlSessionDAL{db, cache, logger}
 }
 
 func (p *psqlSessionDAL) CreateSession(loginInfo *m.Session) (*m.ID, error) {
 	session := db.Session{
-		UserID:    *modelID2DBID(&loginInfo.UserID),
-		UserAgent: loginInfo.UserAgent,
+		UserID:      *modelID2DBID(&loginInfo.UserID),
+		UserAgent:   loginInfo.UserAgent,
+		ExpiredAt:   loginInfo.ExpiredAt,
+		LastUsageAt: loginInfo.LastUsageAt,
 	}
 	result := p.db.Create(&session)
 	if result.Error != nil {
@@ -42,18 +97,39 @@ func (p *psqlSessionDAL) CreateSession(loginInfo *m.Session) (*m.ID, error) {
 }
 
 func (p *psqlSessionDAL) DeleteSession(sessionID m.ID) (bool, error) {
-	result := p.db.Where(&db.Session{
-		BaseModel: db.BaseModel{ID: *modelID2DBID(&sessionID)}}).
-		Delete(&db.Session{})
-	if result.Error != nil {
-		return false, fmt.Errorf("failed to delete session for sessionID %s (%s)", sessionID.String(), result.Error)
-	} else if result.RowsAffected < 1 {
-		return false, nil
-	} else if result.RowsAffected >= 1 {
-		return true, nil
+	isDeleted := false
+	err := p.db.Transaction(func(tx *db.PSQLDB) error {
+		result := tx.Where(&db.Session{
+			BaseModel: db.BaseModel{ID: *modelID2DBID(&sessionID)}}).
+			Delete(&db.Session{})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		isDeleted = true
+		return tx.Where(&db.RefreshToken{SessionID: *modelID2DBID(&sessionID)}).Delete(&db.RefreshToken{}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete session for sessionID %s (%s)", sessionID.String(), err)
+	}
+	if isDeleted {
+		if err := p.markRevoked(sessionID); err != nil {
+			return false, err
+		}
 	}
-	p.logger.Panicf("Unpredicted behaviour in deleting session for sessionID %s (%s)", sessionID.String(), result.Error)
-	return false, nil
+	return isDeleted, nil
+}
+
+// Mark the deleted session as revoked in the cache and remove its cached copy. A copy
+// cached by a concurrent read is ignored as long as the mark exists.
+func (p *psqlSessionDAL) markRevoked(sessionID m.ID) error {
+	if err := p.cache.cache.SetWithExpire(ck.revokedSessionKey(sessionID), "1", revokedSessionExpire); err != nil {
+		return fmt.Errorf("session %s is deleted but failed to mark it as revoked in the cache (%s)",
+			sessionID.String(), err)
+	}
+	p.cache.delete(ck.sessionByIDKey(sessionID))
+	return nil
 }
 
 func (p *psqlSessionDAL) IsMatchSessionUserID(sessionID, claimedUserID m.ID) (bool, error) {
@@ -68,15 +144,186 @@ func (p *psqlSessionDAL) IsMatchSessionUserID(sessionID, claimedUserID m.ID) (bo
 }
 
 func (p *psqlSessionDAL) GetSessionByID(sessionID m.ID) (*m.Session, error) {
-	var session db.Session
+	cacheKey := ck.sessionByIDKey(sessionID)
+	// If the cache isn't available, the session is read from the database and isn't cached.
+	isCacheUsable := true
+	var session m.Session
+	if revoked, err := p.cache.cache.Get(ck.revokedSessionKey(sessionID)); err != nil {
+		p.logger.Errorf("Failed to check revocation of session %s in the cache: %s", sessionID.String(), err.Error())
+		isCacheUsable = false
+	} else if revoked != nil {
+		return nil, nil
+	} else if err := p.cache.read(cacheKey, &session); err != nil && !errors.Is(err, e.ErrNotFound) {
+		p.logger.Debugf("Error in reading value of the key \"%s\" from the cache: %s", cacheKey, err.Error())
+	} else if err == nil {
+		return &session, nil
+	}
+
+	var dbSession db.Session
 	result := p.db.Where(&db.Session{
 		BaseModel: db.BaseModel{ID: *modelID2DBID(&sessionID)}}).
-		Unscoped().Find(&session)
+		Find(&dbSession)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to get session by id %s (%s)", sessionID.String(), result.Error)
 	} else if result.RowsAffected == 0 {
 		return nil, nil
 	}
+	session = *dbSession2ModelSession(&dbSession)
+	if isCacheUsable {
+		p.cache.setWithExpire(cacheKey, session, sessionCacheExpire)
+	}
+	return &session, nil
+}
+
+func (p *psqlSessionDAL) GetUserSessions(userID m.ID, now int64) (*[]m.Session, error) {
+	var sessions []db.Session
+	// Sessions created before refresh tokens don't have expiration time.
+	result := p.db.Where(&db.Session{UserID: *modelID2DBID(&userID)}).
+		Where("expired_at = 0 OR expired_at > ?", now).
+		Order("last_usage_at desc").Order("created_at desc").Find(&sessions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get sessions of user %s (%s)", userID.String(), result.Error)
+	}
+	modelSessions := make([]m.Session, len(sessions))
+	for i := range sessions {
+		modelSessions[i] = *dbSession2ModelSession(&sessions[i])
+	}
+	return &modelSessions, nil
+}
+
+func (p *psqlSessionDAL) DeleteUserSessions(userID, exceptSessionID m.ID) (int64, error) {
+	var sessionIDs []db.ID
+	err := p.db.Transaction(func(tx *db.PSQLDB) error {
+		query := tx.Model(&db.Session{}).Where(&db.Session{UserID: *modelID2DBID(&userID)})
+		if !exceptSessionID.IsNil() {
+			query = query.Where("id <> ?", *modelID2DBID(&exceptSessionID))
+		}
+		if err := query.Pluck("id", &sessionIDs).Error; err != nil {
+			return err
+		} else if len(sessionIDs) == 0 {
+			return nil
+		}
+		if err := tx.Where("id IN ?", sessionIDs).Delete(&db.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("session_id IN ?", sessionIDs).Delete(&db.RefreshToken{}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions of user %s (%s)", userID.String(), err)
+	}
+	for i := range sessionIDs {
+		if err := p.markRevoked(*dbID2ModelID(&sessionIDs[i])); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(sessionIDs)), nil
+}
+
+func (p *psqlSessionDAL) UpdateSession(session *m.Session) (bool, error) {
+	isUpdated, err := p.updateSession(p.db, session)
+	if isUpdated {
+		p.cache.delete(ck.sessionByIDKey(session.ID))
+	}
+	return isUpdated, err
+}
+
+func (p *psqlSessionDAL) updateSession(tx *db.PSQLDB, session *m.Session) (bool, error) {
+	result := tx.Model(&db.Session{}).
+		Where(&db.Session{BaseModel: db.BaseModel{ID: *modelID2DBID(&session.ID)}}).
+		Updates(map[string]any{"expired_at": session.ExpiredAt, "last_usage_at": session.LastUsageAt})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update session %s (%s)", session.ID.String(), result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (p *psqlSessionDAL) UpdateSessionLastUsage(sessionID m.ID, usedAt, minInterval int64) error {
+	cacheKey := ck.sessionUsedKey(sessionID)
+	if used, err := p.cache.cache.Get(cacheKey); err != nil {
+		p.logger.Debugf("Error in reading value of the key \"%s\" from the cache: %s", cacheKey, err.Error())
+	} else if used != nil {
+		return nil
+	}
+
+	result := p.db.Model(&db.Session{}).
+		Where(&db.Session{BaseModel: db.BaseModel{ID: *modelID2DBID(&sessionID)}}).
+		Where("last_usage_at IS NULL OR last_usage_at < ?", usedAt-minInterval).
+		UpdateColumn("last_usage_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update last usage of session %s (%s)", sessionID.String(), result.Error)
+	} else if result.RowsAffected > 0 {
+		p.cache.delete(ck.sessionByIDKey(sessionID))
+	}
+	p.cache.setWithExpire(cacheKey, usedAt, time.Duration(minInterval)*time.Second)
+	return nil
+}
+
+func (p *psqlSessionDAL) CreateRefreshToken(sessionID m.ID, tokenHash string, expiredAt int64) (*m.ID, error) {
+	token := db.RefreshToken{
+		SessionID: *modelID2DBID(&sessionID),
+		TokenHash: tokenHash,
+		ExpiredAt: expiredAt,
+	}
+	if result := p.db.Create(&token); result.Error != nil {
+		return nil, fmt.Errorf("failed to create refresh token for session %s (%s)", sessionID.String(), result.Error)
+	}
+	return dbID2ModelID(&token.ID), nil
+}
+
+func (p *psqlSessionDAL) GetRefreshTokenByHash(tokenHash string) (*m.RefreshToken, error) {
+	var token db.RefreshToken
+	result := p.db.Where(&db.RefreshToken{TokenHash: tokenHash}).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get refresh token (%s)", result.Error)
+	} else if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &m.RefreshToken{
+		ID:        *dbID2ModelID(&token.ID),
+		SessionID: *dbID2ModelID(&token.SessionID),
+		ExpiredAt: token.ExpiredAt,
+		UsedAt:    token.UsedAt,
+	}, nil
+}
+
+func (p *psqlSessionDAL) RotateRefreshToken(tokenID m.ID, newTokenHash string, session *m.Session) (bool, error) {
+	isRotated := false
+	err := p.db.Transaction(func(tx *db.PSQLDB) error {
+		// The condition on used_at guarantees just one of concurrent requests could use the token.
+		result := tx.Model(&db.RefreshToken{}).
+			Where(&db.RefreshToken{BaseModel: db.BaseModel{ID: *modelID2DBID(&tokenID)}}).
+			Where("used_at = 0").
+			UpdateColumn("used_at", session.LastUsageAt)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected < 1 {
+			return nil
+		}
+		newToken := db.RefreshToken{
+			SessionID: *modelID2DBID(&session.ID),
+			TokenHash: newTokenHash,
+			ExpiredAt: session.ExpiredAt,
+		}
+		if err := tx.Create(&newToken).Error; err != nil {
+			return err
+		}
+		if _, err := p.updateSession(tx, session); err != nil {
+			return err
+		}
+		isRotated = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate refresh token %s of session %s (%s)", tokenID.String(),
+			session.ID.String(), err)
+	}
+	if isRotated {
+		p.cache.delete(ck.sessionByIDKey(session.ID))
+	}
+	return isRotated, nil
+}
+
+func dbSession2ModelSession(session *db.Session) *m.Session {
 	return &m.Session{
 		ID:          *dbID2ModelID(&session.ID),
 		UserID:      *dbID2ModelID(&session.UserID),
@@ -84,5 +331,5 @@ func (p *psqlSessionDAL) GetSessionByID(sessionID m.ID) (*m.Session, error) {
 		IssuedAt:    session.CreatedAt.Unix(),
 		ExpiredAt:   session.ExpiredAt,
 		LastUsageAt: session.LastUsageAt,
-	}, nil
+	}
 }
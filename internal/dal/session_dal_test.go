@@ -0,0 +1,111 @@
+package dal
+
+import (
+	"DMS/internal/db"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestUpdateSessionLastUsageThrottled(t *testing.T) {
+	testDB := newTestDB(t)
+	testCache := newTestCache()
+	sessionDAL := newPsqlSessionDAL(testDB, testCache, l.NewSLogger(l.None, nil, io.Discard))
+	user := db.User{Name: "user", PhoneNumber: "9170000001"}
+	if err := testDB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create the user: %s", err.Error())
+	}
+	now := time.Now().UTC().Unix()
+	sessionID, err := sessionDAL.CreateSession(&m.Session{UserID: *dbID2ModelID(&user.ID), UserAgent: "test",
+		ExpiredAt: now + 3600})
+	if err != nil {
+		t.Fatalf("failed to create the session: %s", err.Error())
+	}
+	lastUsage := func() int64 {
+		var session db.Session
+		if err := testDB.Where(&db.Session{BaseModel: db.BaseModel{ID: *modelID2DBID(sessionID)}}).
+			Take(&session).Error; err != nil {
+			t.Fatalf("failed to get the session: %s", err.Error())
+		}
+		return session.LastUsageAt
+	}
+
+	if err := sessionDAL.UpdateSessionLastUsage(*sessionID, now, 60); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if usedAt := lastUsage(); usedAt != now {
+		t.Fatalf("expected last usage %d, got %d", now, usedAt)
+	}
+	// The database is changed behind the DAL, so an update that queries the database
+	// would be visible.
+	if err := testDB.Model(&db.Session{}).Where("id = ?", *modelID2DBID(sessionID)).
+		UpdateColumn("last_usage_at", 0).Error; err != nil {
+		t.Fatalf("failed to reset last usage of the session: %s", err.Error())
+	}
+	if err := sessionDAL.UpdateSessionLastUsage(*sessionID, now+120, 60); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if usedAt := lastUsage(); usedAt != 0 {
+		t.Errorf("expected the session not to be updated while it's marked as used, got %d", usedAt)
+	}
+
+	// The mark expires after the interval.
+	testCache.delete(ck.sessionUsedKey(*sessionID))
+	if err := sessionDAL.UpdateSessionLastUsage(*sessionID, now+120, 60); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if usedAt := lastUsage(); usedAt != now+120 {
+		t.Errorf("expected last usage %d after the mark expired, got %d", now+120, usedAt)
+	}
+}
+
+func TestRevokedSessionIgnoresCachedCopy(t *testing.T) {
+	testDB := newTestDB(t)
+	testCache := newTestCache()
+	sessionDAL := newPsqlSessionDAL(testDB, testCache, l.NewSLogger(l.None, nil, io.Discard))
+	user := db.User{Name: "user", PhoneNumber: "9170000001"}
+	if err := testDB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create the user: %s", err.Error())
+	}
+	sessions := map[string]m.Session{}
+	for _, name := range []string{"revoked", "current", "other"} {
+		session := m.Session{UserID: *dbID2ModelID(&user.ID), UserAgent: name, ExpiredAt: time.Now().Unix() + 3600}
+		sessionID, err := sessionDAL.CreateSession(&session)
+		if err != nil {
+			t.Fatalf("failed to create the session: %s", err.Error())
+		}
+		// Read the session, so it's cached.
+		cached, err := sessionDAL.GetSessionByID(*sessionID)
+		if err != nil || cached == nil {
+			t.Fatalf("failed to get the session: %v", err)
+		}
+		sessions[name] = *cached
+	}
+	// Cache the sessions again, like reads that raced with the revocation.
+	cacheStaleCopies := func() {
+		for _, session := range sessions {
+			testCache.setWithExpire(ck.sessionByIDKey(session.ID), session, sessionCacheExpire)
+		}
+	}
+
+	if isDeleted, err := sessionDAL.DeleteSession(sessions["revoked"].ID); err != nil || !isDeleted {
+		t.Fatalf("failed to delete the session: %v", err)
+	}
+	cacheStaleCopies()
+	if session, err := sessionDAL.GetSessionByID(sessions["revoked"].ID); err != nil || session != nil {
+		t.Errorf("expected the revoked session not to be found, got %+v (%v)", session, err)
+	}
+
+	if count, err := sessionDAL.DeleteUserSessions(sessions["current"].UserID, sessions["current"].ID); err != nil || count != 1 {
+		t.Fatalf("expected one deleted session, got %d (%v)", count, err)
+	}
+	cacheStaleCopies()
+	if session, err := sessionDAL.GetSessionByID(sessions["other"].ID); err != nil || session != nil {
+		t.Errorf("expected the revoked session not to be found, got %+v (%v)", session, err)
+	}
+	if session, err := sessionDAL.GetSessionByID(sessions["current"].ID); err != nil || session == nil {
+		t.Errorf("expected the current session to be kept, got %v", err)
+	}
+}
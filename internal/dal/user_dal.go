@@ -26,6 +26,19 @@ type UserDAL interface {
 	// Returns false if the user or job doesn't exist or one of them is deleted.
 	// (whether hard or soft delete)
 	IsExistsUserWithJP(userID, jpID m.ID) (bool, error)
+	// Update the given fields of the user. Return false if the user is not found. If
+	// another user has the phone number, return error wrapping ErrExists.
+	UpdateUser(userID m.ID, update *m.UserUpdate) (bool, error)
+	// Disable or enable the user. Return false if the user is not found.
+	SetUserDisability(userID m.ID, isDisabled bool) (bool, error)
+	// Return true if the user is created by a user that has one of the job positions.
+	IsCreatedByJPsUsers(userID m.ID, jpIDs []m.ID) (bool, error)
+	// Return some last created users (at most limit users) that come after the cursor,
+	// together with the cursor of the last returned user. If there's not any more user,
+	// the returned cursor is nil.
+	// If jpIDs be nil, return all users; otherwise just users created by users that have
+	// one of the job positions.
+	GetUsers(jpIDs *[]m.ID, cursor *m.Cursor, limit int) (*[]m.User, *m.Cursor, error)
 }
 
 // It's an implementation of UserDAL interface
@@ -118,6 +131,84 @@ func (p *psqlUserDAL) IsExistsUserWithJP(userID, jpID m.ID) (bool, error) {
 	return true, nil
 }
 
+func (d *psqlUserDAL) UpdateUser(userID m.ID, update *m.UserUpdate) (bool, error) {
+	fields := map[string]any{}
+	if update.Name != nil {
+		fields["name"] = *update.Name
+	}
+	if update.PhoneNumber != nil {
+		fields["phone_number"] = update.PhoneNumber.ToString()
+	}
+	return d.updateUser(userID, fields)
+}
+
+func (d *psqlUserDAL) SetUserDisability(userID m.ID, isDisabled bool) (bool, error) {
+	disability := db.IsNotDisabled
+	if isDisabled {
+		disability = db.IsDisabled
+	}
+	return d.updateUser(userID, map[string]any{"is_disabled": disability})
+}
+
+func (d *psqlUserDAL) updateUser(userID m.ID, fields map[string]any) (bool, error) {
+	if len(fields) == 0 {
+		var count int64
+		result := d.db.Model(&db.User{}).Where(&db.User{BaseModel: db.BaseModel{ID: *modelID2DBID(&userID)}}).
+			Count(&count)
+		if result.Error != nil {
+			return false, fmt.Errorf("failed to get user %s: %s", userID.String(), result.Error.Error())
+		}
+		return count > 0, nil
+	}
+	result := d.db.Model(&db.User{}).
+		Where(&db.User{BaseModel: db.BaseModel{ID: *modelID2DBID(&userID)}}).
+		Updates(fields)
+	if isUniqueViolation(result.Error) {
+		return false, fmt.Errorf("failed to update user %s: %w", userID.String(), e.ErrExists)
+	} else if result.Error != nil {
+		return false, fmt.Errorf("failed to update user %s: %s", userID.String(), result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (d *psqlUserDAL) IsCreatedByJPsUsers(userID m.ID, jpIDs []m.ID) (bool, error) {
+	var count int64
+	result := d.db.Model(&db.User{}).
+		Where("users.id = ? AND users.created_by_id IN (SELECT user_id FROM job_positions WHERE id IN ?)",
+			*modelID2DBID(&userID), *modelIDs2DBIDs(&jpIDs)).
+		Count(&count)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to check creator of user %s: %s", userID.String(), result.Error.Error())
+	}
+	return count > 0, nil
+}
+
+func (d *psqlUserDAL) GetUsers(jpIDs *[]m.ID, cursor *m.Cursor, limit int) (*[]m.User, *m.Cursor, error) {
+	query := d.db.Model(&db.User{})
+	if jpIDs != nil {
+		query = query.Where("users.created_by_id IN (SELECT user_id FROM job_positions WHERE id IN ?)",
+			*modelIDs2DBIDs(jpIDs))
+	}
+	// Fetch one more user to find out if there are more users after this page.
+	var users []db.User
+	result := applyCursor(query, "users", cursor).Limit(limit + 1).Find(&users)
+	if result.Error != nil {
+		return nil, nil, fmt.Errorf("failed to get last %d users after cursor %+v: %s", limit, cursor,
+			result.Error.Error())
+	}
+
+	var nextCursor *m.Cursor
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = newCursor(users[limit-1].CreatedAt, users[limit-1].ID)
+	}
+	modelUsers := make([]m.User, len(users))
+	for i := range users {
+		modelUsers[i] = *dbUser2ModelUser(&users[i])
+	}
+	return &modelUsers, nextCursor, nil
+}
+
 func dbUser2ModelUser(user *db.User) *m.User {
 	return &m.User{
 		ID:          *dbID2ModelID(&user.ID),
@@ -0,0 +1,248 @@
+package db
+
+import (
+	l "DMS/internal/logger"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SQL files of the migrations. Each migration has an up and a down file named as
+// "<version>_<name>.up.sql" and "<version>_<name>.down.sql". Versions must be unique and
+// applied migrations must never change; change the schema by adding a new migration.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Key of the PostgreSQL advisory lock held during migrating, so several replicas
+// starting together don't migrate at the same time.
+const migrationLockKey int64 = 7_362_514_009
+
+type migration struct {
+	version uint64
+	name    string
+	up      string
+	down    string
+}
+
+// Status of a migration in the database
+type MigrationStatus struct {
+	Version uint64
+	Name    string
+	// It's nil if the migration is not applied.
+	AppliedAt *time.Time
+}
+
+// Applied migrations are stored in this table
+type schemaMigration struct {
+	Version   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Read the migrations of dir and return them ordered by their versions.
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	files, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint64]*migration)
+	for _, file := range files {
+		parts := migrationFileName.FindStringSubmatch(file.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid name of migration file %s", file.Name())
+		}
+		version, _ := strconv.ParseUint(parts[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		mig, isFound := byVersion[version]
+		if !isFound {
+			mig = &migration{version: version, name: parts[2]}
+			byVersion[version] = mig
+		} else if mig.name != parts[2] {
+			return nil, fmt.Errorf("migrations %s and %s have the same version %d", mig.name, parts[2], version)
+		}
+		if parts[3] == "up" {
+			mig.up = string(content)
+		} else {
+			mig.down = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.up == "" || mig.down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", mig.version, mig.name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// Apply the migrations that are not applied yet and return number of applied migrations.
+// Each migration is applied in a transaction, so a failed migration changes nothing.
+// If the database has a migration that this binary doesn't know (e.g. it's migrated by a
+// newer version), return an error.
+func MigrateUp(db *PSQLDB, logger l.Logger) (int, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("failed to load migrations: %s", err.Error())
+	}
+	count := 0
+	err = withMigrationLock(db, logger, func(conn *PSQLDB) error {
+		applied, err := getAppliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if err := checkUnknownMigrations(migrations, applied); err != nil {
+			return err
+		}
+		for _, mig := range migrations {
+			if _, isApplied := applied[mig.version]; isApplied {
+				continue
+			}
+			err := conn.Transaction(func(tx *PSQLDB) error {
+				if err := tx.Exec(mig.up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{mig.version, mig.name, time.Now().UTC()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %s", mig.version, mig.name, err.Error())
+			}
+			logger.Infof("Applied migration %d_%s", mig.version, mig.name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Roll back the last steps applied migrations and return number of rolled back migrations.
+func MigrateDown(db *PSQLDB, steps int, logger l.Logger) (int, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("failed to load migrations: %s", err.Error())
+	}
+	count := 0
+	err = withMigrationLock(db, logger, func(conn *PSQLDB) error {
+		applied, err := getAppliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if err := checkUnknownMigrations(migrations, applied); err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+			mig := migrations[i]
+			if _, isApplied := applied[mig.version]; !isApplied {
+				continue
+			}
+			err := conn.Transaction(func(tx *PSQLDB) error {
+				if err := tx.Exec(mig.down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, mig.version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %s", mig.version, mig.name, err.Error())
+			}
+			logger.Infof("Rolled back migration %d_%s", mig.version, mig.name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Return status of all migrations ordered by their versions.
+func GetMigrationStatus(db *PSQLDB) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %s", err.Error())
+	}
+	if err := createMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, mig := range migrations {
+		statuses[i] = MigrationStatus{Version: mig.version, Name: mig.name}
+		if appliedMig, isApplied := applied[mig.version]; isApplied {
+			statuses[i].AppliedAt = &appliedMig.AppliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Hold the migration lock on a single connection of the pool and run fc with that
+// connection. If another process holds the lock, wait for it.
+func withMigrationLock(db *PSQLDB, logger l.Logger, fc func(conn *PSQLDB) error) error {
+	return db.Connection(func(conn *PSQLDB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire the migration lock: %s", err.Error())
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				logger.Errorf("Failed to release the migration lock: %s", err.Error())
+			}
+		}()
+		if err := createMigrationsTable(conn); err != nil {
+			return err
+		}
+		return fc(conn)
+	})
+}
+
+func createMigrationsTable(db *PSQLDB) error {
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now())`).Error; err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %s", err.Error())
+	}
+	return nil
+}
+
+func getAppliedMigrations(db *PSQLDB) (map[uint64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %s", err.Error())
+	}
+	applied := make(map[uint64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func checkUnknownMigrations(migrations []migration, applied map[uint64]schemaMigration) error {
+	known := make(map[uint64]bool, len(migrations))
+	for _, mig := range migrations {
+		known[mig.version] = true
+	}
+	for version, row := range applied {
+		if !known[version] {
+			return fmt.Errorf("migration %d_%s is applied to the database but it's unknown; "+
+				"the database is migrated by a newer version", version, row.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package db
+
+import (
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(content)}
+	}
+
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		// Expected versions of the loaded migrations in order
+		versions []uint64
+		// Expected part of the error. If it's empty, loading must succeed.
+		err string
+	}{
+		{
+			name: "migrations are ordered by their versions",
+			files: fstest.MapFS{
+				"migrations/0010_c.up.sql":   file("c up"),
+				"migrations/0010_c.down.sql": file("c down"),
+				"migrations/0002_b.up.sql":   file("b up"),
+				"migrations/0002_b.down.sql": file("b down"),
+				"migrations/0001_a.up.sql":   file("a up"),
+				"migrations/0001_a.down.sql": file("a down"),
+			},
+			versions: []uint64{1, 2, 10},
+		},
+		{
+			name: "file without version is invalid",
+			files: fstest.MapFS{
+				"migrations/a.up.sql":   file("a up"),
+				"migrations/a.down.sql": file("a down"),
+			},
+			err: "invalid name",
+		},
+		{
+			name: "file without direction is invalid",
+			files: fstest.MapFS{
+				"migrations/0001_a.sql": file("a"),
+			},
+			err: "invalid name",
+		},
+		{
+			name: "name with dash is invalid",
+			files: fstest.MapFS{
+				"migrations/0001_a-b.up.sql":   file("a up"),
+				"migrations/0001_a-b.down.sql": file("a down"),
+			},
+			err: "invalid name",
+		},
+		{
+			name: "migration without down file",
+			files: fstest.MapFS{
+				"migrations/0001_a.up.sql": file("a up"),
+			},
+			err: "must have both up and down files",
+		},
+		{
+			name: "migration without up file",
+			files: fstest.MapFS{
+				"migrations/0001_a.down.sql": file("a down"),
+			},
+			err: "must have both up and down files",
+		},
+		{
+			name: "migrations with the same version",
+			files: fstest.MapFS{
+				"migrations/0001_a.up.sql":   file("a up"),
+				"migrations/0001_a.down.sql": file("a down"),
+				"migrations/0001_b.up.sql":   file("b up"),
+				"migrations/0001_b.down.sql": file("b down"),
+			},
+			err: "have the same version",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			migrations, err := loadMigrations(test.files, "migrations")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if len(migrations) != len(test.versions) {
+				t.Fatalf("expected %d migrations, got %d", len(test.versions), len(migrations))
+			}
+			for i, mig := range migrations {
+				if mig.version != test.versions[i] {
+					t.Errorf("expected version %d at %d, got %d", test.versions[i], i, mig.version)
+				}
+				if mig.up != mig.name+" up" || mig.down != mig.name+" down" {
+					t.Errorf("unexpected files of migration %d_%s: %q, %q", mig.version, mig.name, mig.up, mig.down)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	for i, mig := range migrations {
+		if mig.version != uint64(i+1) {
+			t.Errorf("expected version %d, got %d_%s", i+1, mig.version, mig.name)
+		}
+	}
+}
+
+// Built-in roles are created by the migrations, so their names and actions must match
+// the role names of this package and the actions of the models package.
+func TestBuiltinRolesMigration(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	var up string
+	for _, mig := range migrations {
+		up += mig.up
+	}
+	permissions := regexp.MustCompile(`\('(\w+)', '(\w+)'\)`).FindAllStringSubmatch(up, -1)
+	actions := map[string][]models.Action{}
+	for _, permission := range permissions {
+		actions[permission[1]] = append(actions[permission[1]], models.Action(permission[2]))
+	}
+
+	for _, role := range []string{RoleMember, RoleJPManager, RoleEventApprover} {
+		if !strings.Contains(up, "('"+role+"', true, now(), now())") {
+			t.Errorf("expected built-in role %s to be created", role)
+		}
+		if len(actions[role]) == 0 {
+			t.Errorf("expected built-in role %s to have actions", role)
+		}
+	}
+	for role, roleActions := range actions {
+		for _, action := range roleActions {
+			if !action.IsValid() {
+				t.Errorf("action %s of built-in role %s is not valid", action, role)
+			}
+		}
+	}
+}
+
+// Name of the environment variable of the PostgreSQL connection string used by the
+// database tests. If it's not set, the database tests are skipped.
+const testPsqlDSNEnv = "DMS_TEST_PSQL_DSN"
+
+// Return an empty schema of the test database. It's dropped at the end of the test.
+func newTestSchema(t *testing.T) *PSQLDB {
+	t.Helper()
+	dsn := os.Getenv(testPsqlDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, so the database tests are skipped", testPsqlDSNEnv)
+	}
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %s", testPsqlDSNEnv, err.Error())
+	}
+	open := func(config *pgx.ConnConfig) *PSQLDB {
+		testDB, err := gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*config)}),
+			&gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("failed to open the test database: %s", err.Error())
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := testDB.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		return testDB
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	adminDB := open(config.Copy())
+	if err := adminDB.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp" SCHEMA public`).Error; err != nil {
+		t.Fatalf("failed to create uuid-ossp extension: %s", err.Error())
+	}
+	if err := adminDB.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("failed to create schema %s: %s", schema, err.Error())
+	}
+	t.Cleanup(func() {
+		if err := adminDB.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("failed to drop schema %s: %s", schema, err.Error())
+		}
+	})
+	config.RuntimeParams["search_path"] = schema + ", public"
+	return open(config)
+}
+
+// Apply all embedded migrations, roll all of them back and apply them again, so each
+// down file must undo its up file completely.
+func TestMigrateUpDownUp(t *testing.T) {
+	testDB := newTestSchema(t)
+	testLogger := l.NewSLogger(l.None, nil, io.Discard)
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	countTables := func() int64 {
+		var count int64
+		if err := testDB.Raw("SELECT count(*) FROM information_schema.tables " +
+			"WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'").
+			Scan(&count).Error; err != nil {
+			t.Fatalf("failed to count the tables: %s", err.Error())
+		}
+		return count
+	}
+
+	if count, err := MigrateUp(testDB, testLogger); err != nil || count != len(migrations) {
+		t.Fatalf("expected %d applied migrations, got %d (%v)", len(migrations), count, err)
+	}
+	tablesCount := countTables()
+	if count, err := MigrateUp(testDB, testLogger); err != nil || count != 0 {
+		t.Errorf("expected nothing to be applied again, got %d (%v)", count, err)
+	}
+
+	if count, err := MigrateDown(testDB, len(migrations), testLogger); err != nil || count != len(migrations) {
+		t.Fatalf("expected %d rolled back migrations, got %d (%v)", len(migrations), count, err)
+	}
+	if count := countTables(); count != 0 {
+		t.Errorf("expected all tables to be dropped, got %d tables", count)
+	}
+	statuses, err := GetMigrationStatus(testDB)
+	if err != nil {
+		t.Fatalf("failed to get status of the migrations: %s", err.Error())
+	}
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("expected migration %d_%s to be rolled back", status.Version, status.Name)
+		}
+	}
+
+	if count, err := MigrateUp(testDB, testLogger); err != nil || count != len(migrations) {
+		t.Fatalf("expected %d applied migrations again, got %d (%v)", len(migrations), count, err)
+	}
+	if count := countTables(); count != tablesCount {
+		t.Errorf("expected %d tables after migrating again, got %d", tablesCount, count)
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sessions, multimedia, docs, events, jp_permissions, job_positions, users;
@@ -0,0 +1,102 @@
+-- The schema created by the GORM auto migration before the versioned migrations. Objects
+-- are created if they don't exist, so databases migrated by GORM adopt this migration.
+-- Don't add the later columns and tables here; the next migrations add them to both new
+-- and adopted databases.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS users (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text,
+	phone_number text NOT NULL UNIQUE,
+	is_disabled smallint,
+	created_by_id uuid,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_created_by FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS job_positions (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	user_id uuid NOT NULL,
+	title text,
+	region_id uuid DEFAULT uuid_generate_v4(),
+	parent_id uuid,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_job_position FOREIGN KEY (user_id) REFERENCES users(id),
+	CONSTRAINT fk_job_positions_parent FOREIGN KEY (parent_id) REFERENCES job_positions(id)
+);
+CREATE INDEX IF NOT EXISTS idx_job_positions_deleted_at ON job_positions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS jp_permissions (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	jp_id uuid NOT NULL UNIQUE,
+	is_allow_create_jp boolean,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_job_positions_jp_permission FOREIGN KEY (jp_id) REFERENCES job_positions(id)
+);
+CREATE INDEX IF NOT EXISTS idx_jp_permissions_deleted_at ON jp_permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS events (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text,
+	created_by_id uuid NOT NULL DEFAULT uuid_generate_v4(),
+	description text,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_job_positions_event FOREIGN KEY (created_by_id) REFERENCES job_positions(id)
+);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events (deleted_at);
+
+CREATE TABLE IF NOT EXISTS docs (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	created_by_id uuid NOT NULL DEFAULT uuid_generate_v4(),
+	event_id uuid NOT NULL DEFAULT uuid_generate_v4(),
+	context text,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_job_positions_doc FOREIGN KEY (created_by_id) REFERENCES job_positions(id),
+	CONSTRAINT fk_events_doc FOREIGN KEY (event_id) REFERENCES events(id)
+);
+CREATE INDEX IF NOT EXISTS idx_docs_deleted_at ON docs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS multimedia (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	doc_id uuid NOT NULL DEFAULT uuid_generate_v4(),
+	type smallint,
+	src text,
+	file_name text,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_docs_multimedia FOREIGN KEY (doc_id) REFERENCES docs(id)
+);
+CREATE INDEX IF NOT EXISTS idx_multimedia_deleted_at ON multimedia (deleted_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	user_id uuid NOT NULL,
+	user_agent text NOT NULL,
+	expired_at bigint NOT NULL,
+	last_usage_at bigint,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_users_session FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_deleted_at ON sessions (deleted_at);
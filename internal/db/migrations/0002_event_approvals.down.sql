@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS event_approvals;
+ALTER TABLE jp_permissions DROP COLUMN IF EXISTS is_allow_approve_event;
@@ -0,0 +1,33 @@
+-- Event approval workflow. The approval flag of job positions is kept for the built-in
+-- roles migration. Databases migrated by GORM could have the column without any default,
+-- so its null values are set too.
+ALTER TABLE jp_permissions ADD COLUMN IF NOT EXISTS is_allow_approve_event boolean NOT NULL DEFAULT false;
+UPDATE jp_permissions SET is_allow_approve_event = false WHERE is_allow_approve_event IS NULL;
+ALTER TABLE jp_permissions ALTER COLUMN is_allow_approve_event SET DEFAULT false,
+	ALTER COLUMN is_allow_approve_event SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS event_approvals (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	event_id uuid NOT NULL,
+	approved_by_id uuid NOT NULL,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_event_approvals_event FOREIGN KEY (event_id) REFERENCES events(id),
+	CONSTRAINT fk_event_approvals_approved_by FOREIGN KEY (approved_by_id) REFERENCES job_positions(id)
+);
+CREATE INDEX IF NOT EXISTS idx_event_approvals_deleted_at ON event_approvals (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_event_approvals_event_id ON event_approvals (event_id);
+CREATE INDEX IF NOT EXISTS idx_event_approvals_approved_by_id ON event_approvals (approved_by_id);
+
+-- A job position approves an event once. The table could be created by GORM already, so
+-- the duplicate active approvals are revoked except the oldest one before adding the
+-- unique index.
+UPDATE event_approvals SET deleted_at = now()
+	WHERE deleted_at IS NULL AND id NOT IN (
+		SELECT DISTINCT ON (event_id, approved_by_id) id FROM event_approvals
+		WHERE deleted_at IS NULL
+		ORDER BY event_id, approved_by_id, created_at, id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_approvals_event_id_approved_by_id
+	ON event_approvals (event_id, approved_by_id) WHERE deleted_at IS NULL;
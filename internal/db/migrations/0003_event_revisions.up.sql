@@ -0,0 +1,16 @@
+-- Edit history of events
+CREATE TABLE IF NOT EXISTS event_revisions (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	event_id uuid NOT NULL,
+	edited_by_id uuid NOT NULL,
+	old_name text NOT NULL DEFAULT '',
+	new_name text NOT NULL DEFAULT '',
+	old_description text NOT NULL DEFAULT '',
+	new_description text NOT NULL DEFAULT '',
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_event_revisions_deleted_at ON event_revisions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_event_revisions_event_id ON event_revisions (event_id);
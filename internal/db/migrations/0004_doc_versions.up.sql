@@ -0,0 +1,26 @@
+-- Previous versions of the edited docs
+CREATE TABLE IF NOT EXISTS doc_versions (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	doc_id uuid NOT NULL,
+	version bigint NOT NULL,
+	edited_by_id uuid NOT NULL,
+	context text,
+	multimedia jsonb NOT NULL DEFAULT '[]',
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_doc_versions_deleted_at ON doc_versions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_doc_versions_doc_id ON doc_versions (doc_id);
+
+-- Each version number of a doc is used once. The table could be created by GORM already,
+-- so the versions of docs having duplicate version numbers are renumbered before adding
+-- the unique index.
+UPDATE doc_versions SET version = numbered.version
+	FROM (SELECT id, row_number() OVER (PARTITION BY doc_id ORDER BY version, created_at, id) AS version
+		FROM doc_versions
+		WHERE doc_id IN (SELECT doc_id FROM doc_versions GROUP BY doc_id, version HAVING count(*) > 1)
+	) AS numbered
+	WHERE doc_versions.id = numbered.id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_doc_versions_doc_id_version ON doc_versions (doc_id, version);
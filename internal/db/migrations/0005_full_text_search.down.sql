@@ -0,0 +1,4 @@
+ALTER TABLE docs DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE events DROP COLUMN IF EXISTS search_vector;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS dms_fa;
+DROP FUNCTION IF EXISTS fa_normalize(text);
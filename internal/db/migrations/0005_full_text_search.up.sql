@@ -0,0 +1,19 @@
+-- Text search configuration and normalizer of the full-text search. PostgreSQL doesn't
+-- have a Persian dictionary, so the config is a copy of the "simple" config and the
+-- normalizer unifies Arabic and Persian forms of some letters and replaces zero-width
+-- non-joiners with spaces.
+CREATE OR REPLACE FUNCTION fa_normalize(t text) RETURNS text
+	LANGUAGE sql IMMUTABLE PARALLEL SAFE AS
+	$$ SELECT translate(coalesce(t, ''), 'يكىۀ' || chr(8204), 'یکیه ') $$;
+
+DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_ts_config
+		WHERE cfgname = 'dms_fa' AND cfgnamespace = current_schema()::regnamespace) THEN
+		CREATE TEXT SEARCH CONFIGURATION dms_fa (COPY = simple);
+	END IF;
+END $$;
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (setweight(to_tsvector('dms_fa', fa_normalize(name)), 'A') || setweight(to_tsvector('dms_fa', fa_normalize(description)), 'B')) STORED;
+CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING gin (search_vector);
+ALTER TABLE docs ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (to_tsvector('dms_fa', fa_normalize(context))) STORED;
+CREATE INDEX IF NOT EXISTS idx_docs_search_vector ON docs USING gin (search_vector);
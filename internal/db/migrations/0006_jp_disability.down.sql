@@ -0,0 +1 @@
+ALTER TABLE job_positions DROP COLUMN IF EXISTS is_disabled;
@@ -0,0 +1,6 @@
+-- Job positions created before the column existed are enabled. GORM added the column
+-- without any default, so its null values are set too.
+ALTER TABLE job_positions ADD COLUMN IF NOT EXISTS is_disabled smallint NOT NULL DEFAULT 0;
+UPDATE job_positions SET is_disabled = 0 WHERE is_disabled IS NULL;
+ALTER TABLE job_positions ALTER COLUMN is_disabled SET DEFAULT 0,
+	ALTER COLUMN is_disabled SET NOT NULL;
@@ -0,0 +1,15 @@
+-- Edges of the job positions hierarchy. Each job position could have several parents.
+CREATE TABLE IF NOT EXISTS jp_edges (
+	jp_id uuid,
+	parent_id uuid,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (jp_id, parent_id)
+);
+CREATE INDEX IF NOT EXISTS idx_jp_edges_parent_id ON jp_edges (parent_id);
+
+-- Before the jp_edges table, parent of each job position was stored just in its
+-- parent_id column. Add the edge of job positions that don't have any edge yet.
+INSERT INTO jp_edges (jp_id, parent_id, created_at)
+	SELECT id, parent_id, coalesce(created_at, now()) FROM job_positions
+	WHERE parent_id IS NOT NULL AND deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM jp_edges WHERE jp_edges.jp_id = job_positions.id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS jp_roles, role_permissions, roles;
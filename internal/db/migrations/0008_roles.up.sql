@@ -0,0 +1,57 @@
+-- Roles replace the permission flags of the job positions.
+CREATE TABLE IF NOT EXISTS roles (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text NOT NULL,
+	description text NOT NULL DEFAULT '',
+	is_builtin boolean NOT NULL DEFAULT false,
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id uuid,
+	action text,
+	PRIMARY KEY (role_id, action),
+	CONSTRAINT fk_roles_role_permission FOREIGN KEY (role_id) REFERENCES roles(id)
+);
+
+CREATE TABLE IF NOT EXISTS jp_roles (
+	jp_id uuid,
+	role_id uuid,
+	is_inheritable boolean NOT NULL DEFAULT false,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (jp_id, role_id)
+);
+CREATE INDEX IF NOT EXISTS idx_jp_roles_role_id ON jp_roles (role_id);
+
+-- Built-in roles. Their names are the same as the role names in the db package and
+-- their actions are the same as the actions in the models package.
+INSERT INTO roles (name, is_builtin, created_at, updated_at)
+	VALUES ('member', true, now(), now()), ('jp_manager', true, now(), now()),
+		('event_approver', true, now(), now())
+	ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, action)
+	SELECT roles.id, actions.action FROM (VALUES
+		('member', 'create_event'), ('member', 'create_doc'), ('member', 'view_subtree'),
+		('member', 'edit_subtree'), ('jp_manager', 'create_user'), ('jp_manager', 'create_jp'),
+		('jp_manager', 'manage_jp'), ('jp_manager', 'manage_users'), ('event_approver', 'approve_event')
+	) AS actions (role, action)
+	JOIN roles ON roles.name = actions.role
+	ON CONFLICT DO NOTHING;
+
+-- If there's not any assigned role yet, assign the roles to the job positions based on
+-- their old permission flags. So the job positions could do what they could before the roles.
+INSERT INTO jp_roles (jp_id, role_id, is_inheritable, created_at)
+	SELECT job_positions.id, roles.id, false, now() FROM job_positions
+	JOIN roles ON roles.name = 'member'
+	WHERE job_positions.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM jp_roles)
+	UNION
+	SELECT jp_permissions.jp_id, roles.id, false, now() FROM jp_permissions
+	JOIN roles ON (roles.name = 'jp_manager' AND jp_permissions.is_allow_create_jp)
+		OR (roles.name = 'event_approver' AND jp_permissions.is_allow_approve_event)
+	WHERE jp_permissions.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM jp_roles);
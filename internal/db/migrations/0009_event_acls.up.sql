@@ -0,0 +1,17 @@
+-- Access control lists of the events
+CREATE TABLE IF NOT EXISTS event_acls (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	event_id uuid NOT NULL,
+	grantee_type text NOT NULL,
+	grantee_id uuid NOT NULL,
+	access text NOT NULL,
+	expires_at bigint,
+	granted_by_id uuid NOT NULL,
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_event_acls_deleted_at ON event_acls (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_event_acls_event_id ON event_acls (event_id);
+CREATE INDEX IF NOT EXISTS idx_event_acls_grantee_id ON event_acls (grantee_id);
@@ -0,0 +1,15 @@
+-- Refresh tokens of the sessions
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	session_id uuid NOT NULL,
+	token_hash text NOT NULL,
+	expired_at bigint NOT NULL,
+	used_at bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
@@ -0,0 +1,2 @@
+ALTER TABLE multimedia DROP COLUMN IF EXISTS size, DROP COLUMN IF EXISTS checksum,
+	DROP COLUMN IF EXISTS mime_type, DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- Sizes, checksums, MIME types and statuses of the multimedia files. The files created
+-- before the status existed are considered confirmed. GORM could add the columns without
+-- any default, so their null values are set too.
+ALTER TABLE multimedia ADD COLUMN IF NOT EXISTS size bigint NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS checksum text NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS mime_type text NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'confirmed';
+UPDATE multimedia SET size = coalesce(size, 0), checksum = coalesce(checksum, ''),
+	mime_type = coalesce(mime_type, ''), status = coalesce(status, 'confirmed')
+	WHERE size IS NULL OR checksum IS NULL OR mime_type IS NULL OR status IS NULL;
+ALTER TABLE multimedia ALTER COLUMN size SET DEFAULT 0, ALTER COLUMN size SET NOT NULL,
+	ALTER COLUMN checksum SET DEFAULT '', ALTER COLUMN checksum SET NOT NULL,
+	ALTER COLUMN mime_type SET DEFAULT '', ALTER COLUMN mime_type SET NOT NULL,
+	ALTER COLUMN status SET DEFAULT 'confirmed', ALTER COLUMN status SET NOT NULL;
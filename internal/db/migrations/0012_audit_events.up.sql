@@ -0,0 +1,29 @@
+-- The audit log is append-only, so a trigger rejects updating and deleting its rows even
+-- if a bug or a person tries to change it.
+CREATE TABLE IF NOT EXISTS audit_events (
+	id uuid DEFAULT uuid_generate_v4(),
+	created_at timestamptz NOT NULL,
+	actor_user_id uuid,
+	actor_jp_id uuid,
+	action text NOT NULL,
+	target_type text NOT NULL DEFAULT '',
+	target_id uuid,
+	ip text NOT NULL DEFAULT '',
+	user_agent text NOT NULL DEFAULT '',
+	outcome text NOT NULL,
+	details text NOT NULL DEFAULT '',
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_user_id ON audit_events (actor_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_jp_id ON audit_events (actor_jp_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target_id ON audit_events (target_id);
+
+CREATE OR REPLACE FUNCTION reject_audit_change() RETURNS trigger
+	LANGUAGE plpgsql AS
+	$$ BEGIN RAISE EXCEPTION 'audit_events table is append-only'; END $$;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
@@ -40,6 +40,15 @@ func (d ID) Value() (driver.Value, error) {
 	return d.ToString(), nil
 }
 
+// Encode the ID as a string in the text formats. (e.g. JSON of the exported data)
+func (d ID) MarshalText() ([]byte, error) {
+	return []byte(d.ToString()), nil
+}
+
+func (d *ID) UnmarshalText(text []byte) error {
+	return d.FromString(string(text))
+}
+
 type BaseModel struct {
 	ID        ID `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
 	CreatedAt time.Time
@@ -61,10 +70,10 @@ type User struct {
 	IsDisabled  Disability
 	// The user created the current user
 	CreatedByID *ID   `gorm:"type:uuid"`
-	CreatedBy   *User `gorm:"foreignKey:CreatedByID"`
+	CreatedBy   *User `gorm:"foreignKey:CreatedByID" json:"-"`
 	// Each user could have many job positions
-	JobPosition []JobPosition `gorm:"foreignKey:UserID"`
-	Session     []Session     `gorm:"foreignKey:UserID"`
+	JobPosition []JobPosition `gorm:"foreignKey:UserID" json:"-"`
+	Session     []Session     `gorm:"foreignKey:UserID" json:"-"`
 }
 
 type Event struct {
@@ -74,7 +83,10 @@ type Event struct {
 	// The id of job position who created the event
 	CreatedByID ID `gorm:"type:uuid;default:uuid_generate_v4();not null"`
 	Description string
-	Doc         []Doc `gorm:"foreignKey:EventID"`
+	Doc         []Doc `gorm:"foreignKey:EventID" json:"-"`
+	// Full-text search vector of name and description of the event. It's generated by
+	// the database and matches in the name have more weight.
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('dms_fa', fa_normalize(name)), 'A') || setweight(to_tsvector('dms_fa', fa_normalize(description)), 'B')) STORED;index:,type:gin;<-:false;->:false" json:"-"`
 }
 
 type Doc struct {
@@ -84,7 +96,23 @@ type Doc struct {
 	// The id of event the document is for that
 	EventID    ID `gorm:"type:uuid;default:uuid_generate_v4();not null"`
 	Context    *string
-	Multimedia *[]Multimedia `gorm:"foreignKey:DocID"`
+	Multimedia *[]Multimedia `gorm:"foreignKey:DocID" json:"-"`
+	// Full-text search vector of the context. It's generated by the database.
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (to_tsvector('dms_fa', fa_normalize(context))) STORED;index:,type:gin;<-:false;->:false" json:"-"`
+}
+
+// Each edit of a document stores the previous context and multimedia list of the
+// document as a new version.
+type DocVersion struct {
+	BaseModel
+	DocID ID `gorm:"type:uuid;not null;index;uniqueIndex:idx_doc_versions_doc_id_version"`
+	// Sequence number of the version in the document. The first version is 1.
+	Version uint `gorm:"not null;uniqueIndex:idx_doc_versions_doc_id_version"`
+	// The id of job position who edited the document and replaced this version with a newer one
+	EditedByID ID `gorm:"type:uuid;not null"`
+	Context    *string
+	// JSON encoded list of multimedia files of the document in this version
+	Multimedia string `gorm:"type:jsonb;not null;default:'[]'"`
 }
 
 type MediaType uint8
@@ -103,6 +131,14 @@ type Multimedia struct {
 	Src string
 	// Just contains filename and its type
 	FileName string
+	// Size of the file in Kbytes. It's used for calculating the storage quotas.
+	Size uint64 `gorm:"not null;default:0"`
+	// It's set when the file-transfer service confirms the file is stored.
+	Checksum string `gorm:"not null;default:''"`
+	MimeType string `gorm:"not null;default:''"`
+	// It's pending or confirmed. The files created before the status existed are
+	// considered confirmed.
+	Status string `gorm:"not null;default:'confirmed'"`
 }
 
 type JobPosition struct {
@@ -111,19 +147,129 @@ type JobPosition struct {
 	UserID   ID `gorm:"type:uuid;not null"`
 	Title    string
 	RegionID ID `gorm:"type:uuid;default:uuid_generate_v4()"`
-	// ID of parent job position the current job position is for that
+	// ID of the first parent of the job position. A job position could have several
+	// parents and all of them are stored in the jp_edges table.
 	ParentID     *ID          `gorm:"type:uuid"`
-	Parent       *JobPosition `gorm:"foreignKey:ParentID"`
-	JPPermission JPPermission `gorm:"foreignKey:JpID"`
-	Event        []Event      `gorm:"foreignKey:CreatedByID"`
-	Doc          []Doc        `gorm:"foreignKey:CreatedByID"`
+	Parent       *JobPosition `gorm:"foreignKey:ParentID" json:"-"`
+	IsDisabled   Disability   `gorm:"not null;default:0"`
+	JPPermission JPPermission `gorm:"foreignKey:JpID" json:"-"`
+	Event        []Event      `gorm:"foreignKey:CreatedByID" json:"-"`
+	Doc          []Doc        `gorm:"foreignKey:CreatedByID" json:"-"`
+}
+
+// An edge of the hierarchy of job positions. Means the job position reports to the parent.
+// Each job position could have several parents. Job positions without any parent (admins)
+// have no edge.
+type JPEdge struct {
+	JpID      ID        `gorm:"type:uuid;primaryKey"`
+	ParentID  ID        `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+func (JPEdge) TableName() string {
+	return "jp_edges"
+}
+
+// A named bundle of actions that could be assigned to job positions
+type Role struct {
+	BaseModel
+	Name        string `gorm:"not null;uniqueIndex"`
+	Description string `gorm:"not null;default:''"`
+	// Built-in roles are created by the system and couldn't be deleted.
+	IsBuiltin      bool             `gorm:"not null;default:false"`
+	RolePermission []RolePermission `gorm:"foreignKey:RoleID" json:"-"`
+}
+
+// An action allowed by a role
+type RolePermission struct {
+	RoleID ID     `gorm:"type:uuid;primaryKey"`
+	Action string `gorm:"primaryKey"`
+}
+
+// A role assigned to a job position
+type JPRole struct {
+	JpID   ID `gorm:"type:uuid;primaryKey"`
+	RoleID ID `gorm:"type:uuid;primaryKey;index"`
+	// If it's true, the role applies to all nested childs of the job position too.
+	IsInheritable bool      `gorm:"not null;default:false"`
+	CreatedAt     time.Time `gorm:"not null;default:now()"`
+}
+
+// Names of the built-in roles
+const (
+	// Each job position has this role by default.
+	RoleMember        = "member"
+	RoleJPManager     = "jp_manager"
+	RoleEventApprover = "event_approver"
+)
+
+// Each edit of an event is stored as a revision, containing values of the event before
+// and after the edit.
+type EventRevision struct {
+	BaseModel
+	EventID ID `gorm:"type:uuid;not null;index"`
+	// The id of job position who edited the event
+	EditedByID     ID     `gorm:"type:uuid;not null"`
+	OldName        string `gorm:"not null;default:''"`
+	NewName        string `gorm:"not null;default:''"`
+	OldDescription string `gorm:"not null;default:''"`
+	NewDescription string `gorm:"not null;default:''"`
+}
+
+// Approval of an event by a job position. Approved events are also called featured events.
+// Revoking an approval soft deletes its row. Each job position has one active approval
+// of an event at most.
+type EventApproval struct {
+	BaseModel
+	EventID ID     `gorm:"type:uuid;not null;index;uniqueIndex:idx_event_approvals_event_id_approved_by_id,where:deleted_at IS NULL"`
+	Event   *Event `gorm:"foreignKey:EventID" json:"-"`
+	// The id of job position who approved the event
+	ApprovedByID ID           `gorm:"type:uuid;not null;index;uniqueIndex:idx_event_approvals_event_id_approved_by_id,where:deleted_at IS NULL"`
+	ApprovedBy   *JobPosition `gorm:"foreignKey:ApprovedByID" json:"-"`
+}
+
+// An entry of the access control list of an event. It grants an access on the event to
+// a job position, a user or a job position together with all its nested childs.
+// Revoking an access soft deletes its row.
+type EventACL struct {
+	BaseModel
+	EventID ID `gorm:"type:uuid;not null;index"`
+	// One of "jp", "user" or "subtree"
+	GranteeType string `gorm:"not null"`
+	GranteeID   ID     `gorm:"type:uuid;not null;index"`
+	// One of "read" or "contribute"
+	Access string `gorm:"not null"`
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone) If it's nil, the
+	// access never expires.
+	ExpiresAt *int64
+	// The id of job position who granted the access
+	GrantedByID ID `gorm:"type:uuid;not null"`
+}
+
+// An entry of the audit log. The table is append-only, so it doesn't have the update
+// and delete timestamps and a trigger rejects updating and deleting its rows.
+type AuditEvent struct {
+	ID        ID        `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	CreatedAt time.Time `gorm:"not null;index"`
+	// The user and the job position did the action. They're null if they're unknown.
+	ActorUserID *ID    `gorm:"type:uuid;index"`
+	ActorJPID   *ID    `gorm:"type:uuid;index"`
+	Action      string `gorm:"not null;index"`
+	TargetType  string `gorm:"not null;default:''"`
+	TargetID    *ID    `gorm:"type:uuid;index"`
+	IP          string `gorm:"not null;default:''"`
+	UserAgent   string `gorm:"not null;default:''"`
+	// One of "success" or "failure"
+	Outcome string `gorm:"not null"`
+	Details string `gorm:"not null;default:''"`
 }
 
 // Permissions of a job position
 type JPPermission struct {
 	BaseModel
-	JpID            ID `gorm:"type:uuid;not null;unique"`
-	IsAllowCreateJP bool
+	JpID                ID `gorm:"type:uuid;not null;unique"`
+	IsAllowCreateJP     bool
+	IsAllowApproveEvent bool `gorm:"not null;default:false"`
 }
 
 // Customize name of the table
@@ -143,6 +289,20 @@ type Session struct {
 	LastUsageAt int64
 }
 
+// Store refresh tokens of sessions. Each refresh token could be used once and using it
+// issues a new one for the same session. So all refresh tokens of a session make a family.
+type RefreshToken struct {
+	BaseModel
+	SessionID ID `gorm:"type:uuid;not null;index"`
+	// SHA-256 hash of the token. (hex encoded)
+	TokenHash string `gorm:"not null;uniqueIndex"`
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	ExpiredAt int64 `gorm:"not null"`
+	// Time the token is used to refresh the session. Zero means it's not used yet.
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	UsedAt int64 `gorm:"not null;default:0"`
+}
+
 // A type alias for PostgreSQL database type
 type PSQLDB = gorm.DB
 
@@ -162,8 +322,9 @@ type PsqlConnDetails struct {
 
 // Create a new PostgreSQL database instance. If occured any error during connecting
 // to database, panic.
-// After creating the instance, config its options and migrate schema to the database.
-func NewPsqlConn(conn *PsqlConnDetails, doAutoMigrate bool, logger l.Logger) PSQLDB {
+// After creating the instance, config its options and if doMigrate be true, apply the
+// migrations that are not applied yet. If migrating fails, panic.
+func NewPsqlConn(conn *PsqlConnDetails, doMigrate bool, logger l.Logger) PSQLDB {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Tehran",
 		conn.Host, conn.Username, conn.Password, conn.DB, conn.Port,
@@ -176,15 +337,6 @@ func NewPsqlConn(conn *PsqlConnDetails, doAutoMigrate bool, logger l.Logger) PSQ
 			conn.DB, conn.Username, conn.Port, conn.Host, err,
 		)
 	}
-	if doAutoMigrate {
-		switch err := autoMigrate(db); err {
-		case nil:
-			logger.Info("Migrated schema to the database")
-		default:
-			logger.Errorf("Failed to migrate schema to the database. (%s)", err)
-		}
-	}
-
 	sqlDB, err := db.DB()
 	if err != nil {
 		logger.Panicf("Failed to getting created database '%s' instance", conn.DB)
@@ -193,15 +345,16 @@ func NewPsqlConn(conn *PsqlConnDetails, doAutoMigrate bool, logger l.Logger) PSQ
 	sqlDB.SetMaxOpenConns(conn.MAxOpenConns)
 	sqlDB.SetConnMaxLifetime(conn.MaxConnLifetime)
 
-	if result := db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\";"); result.Error != nil {
-		logger.Panicf("Failed to create extension 'uuid-ossp' in database '%s'", conn.DB)
+	if doMigrate {
+		count, err := MigrateUp(db, logger)
+		if err != nil {
+			logger.Panicf("Failed to migrate schema to the database '%s': %s", conn.DB, err.Error())
+		}
+		logger.Infof("Applied %d migrations to the database", count)
 	}
 	logger.Infof("Connected to database '%s' successfully.", conn.DB)
 	return *db
 }
 
-// Migrate from schema to database and update the database scheme.
-func autoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Event{}, &Doc{}, &JobPosition{}, &JPPermission{},
-		&Multimedia{}, &Session{})
-}
+// Name of the text search configuration used for full-text search
+const SearchConfig = "dms_fa"
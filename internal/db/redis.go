@@ -52,11 +52,47 @@ func (s *RedisStorage) Set(key, value string) error {
 	return result.Err()
 }
 
+// Set the key-value that expires after the given duration. Zero expiration means the
+// key-value will never expire.
+func (s *RedisStorage) SetWithExpire(key, value string, expire time.Duration) error {
+	result := s.client.Set(s.ctx, key, value, expire)
+	return result.Err()
+}
+
+// Increment the integer value of the key by one and return the new value. If the key
+// doesn't exists, it's set to 1 and expires after the given duration. (Zero expiration
+// means it will never expire)
+func (s *RedisStorage) Increment(key string, expire time.Duration) (int64, error) {
+	val, err := s.client.Incr(s.ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if val == 1 && expire > 0 {
+		if err := s.client.Expire(s.ctx, key, expire).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return val, nil
+}
+
 func (s *RedisStorage) Delete(key string) error {
 	result := s.client.Del(s.ctx, key)
 	return result.Err()
 }
 
+// Delete the key if its value equals to the given value and return true if it's deleted.
+// Checking and deleting is done atomically.
+func (s *RedisStorage) DeleteIfEqual(key, value string) (bool, error) {
+	deleted, err := deleteIfEqualScript.Run(s.ctx, s.client, []string{key}, value).Int()
+	return deleted > 0, err
+}
+
+var deleteIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
 // Clear key-values in the cahce that their keys match the pattern.
 func (s *RedisStorage) Clear(pattern string) error {
 	// pattern := fmt.Sprintf("%s:*", s.prefix)
@@ -9,6 +9,12 @@ const SeparatorMsg = ":"
 
 var ErrNotFound = errors.New("the entity is not found")
 
+// Another entity with the same unique value exists.
+var ErrExists = errors.New("the entity exists already")
+
+// The storage quota of the event or the job position doesn't allow the file.
+var ErrQuotaExceeded = errors.New("the storage quota is exceeded")
+
 type ErrorCode any
 
 type Error struct {
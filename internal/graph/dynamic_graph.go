@@ -104,8 +104,7 @@ func (g *DynamicGraph) addEdge(u, v Vertex) error {
 	// Check if edge already exists
 	if _, exists := g.graph[u_str][v_str]; !exists {
 		g.graph[u_str][v_str] = struct{}{}
-		// Invalidate cache entries that start from u
-		return g.cache.DeleteByPrefix(u)
+		return g.invalidateCache(u)
 	}
 	return nil
 }
@@ -120,8 +119,31 @@ func (g *DynamicGraph) removeEdge(u, v Vertex) error {
 	if _, exists := g.graph[u_str]; exists {
 		if _, exists := g.graph[u_str][v_str]; exists {
 			delete(g.graph[u_str], v_str)
-			// Invalidate cache entries that start from u
-			return g.cache.DeleteByPrefix(u)
+			return g.invalidateCache(u)
+		}
+	}
+	return nil
+}
+
+// Invalidate cache entries that start from u or one of its ancestors. Because paths from
+// all of them could pass through the changed edges of u. The caller must hold the lock.
+func (g *DynamicGraph) invalidateCache(u Vertex) error {
+	visited := map[string]struct{}{u.String(): {}}
+	queue := list.New()
+	queue.PushBack(u.String())
+	for queue.Len() > 0 {
+		current := queue.Remove(queue.Front()).(string)
+		if err := g.cache.DeleteByPrefix(Vertex{}.str2Vertex(current)); err != nil {
+			return err
+		}
+		for parent, neighbors := range g.graph {
+			if _, isParent := neighbors[current]; !isParent {
+				continue
+			}
+			if _, seen := visited[parent]; !seen {
+				visited[parent] = struct{}{}
+				queue.PushBack(parent)
+			}
 		}
 	}
 	return nil
@@ -243,3 +265,14 @@ func (g *DynamicGraph) GetParents(vertex Vertex) *[]Vertex {
 	}
 	return &parents
 }
+
+// GetChildren returns direct children of the given vertex.
+func (g *DynamicGraph) GetChildren(vertex Vertex) *[]Vertex {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	children := make([]Vertex, 0, len(g.graph[vertex.String()]))
+	for child := range g.graph[vertex.String()] {
+		children = append(children, Vertex{}.str2Vertex(child))
+	}
+	return &children
+}
@@ -91,7 +91,7 @@ func (s *inMemoryDBStorage) Size() (int, error) {
 }
 
 func (s *inMemoryDBStorage) DeleteByPrefix(start Vertex) error {
-	pattern := fmt.Sprintf("%s:%d:*", s.prefix, start)
+	pattern := fmt.Sprintf("%s:%s:*", s.prefix, start)
 	iter, err := s.client.Scan(pattern)
 	if err != nil {
 		return err
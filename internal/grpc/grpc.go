@@ -5,12 +5,16 @@ import (
 	m "DMS/internal/models"
 	service "DMS/internal/services"
 	"context"
+	"fmt"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	pbAuth "github.com/q-sharafian/file-transfer/pkg/pb/auth"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -24,6 +28,23 @@ func NewGRPCServer(fpService service.FilePermissionService, logger l.Logger) GRP
 	return GRPCServer{logger: logger, fpService: fpService}
 }
 
+// Return address and user agent of the client that sent the request.
+func getClientInfo(c context.Context) m.ClientInfo {
+	var client m.ClientInfo
+	if p, ok := peer.FromContext(c); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if i := strings.LastIndex(client.IP, ":"); i != -1 {
+			client.IP = client.IP[:i]
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(c); ok {
+		if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+			client.UserAgent = userAgent[0]
+		}
+	}
+	return client
+}
+
 func (s *GRPCServer) IsAllowedDownload(c context.Context, dar *pbAuth.DownloadAccessReq) (*pbAuth.AllowDownloadResult, error) {
 	objectTokens := make([]m.Token, 0)
 	for _, token := range dar.ObjectTokens {
@@ -34,7 +55,7 @@ func (s *GRPCServer) IsAllowedDownload(c context.Context, dar *pbAuth.DownloadAc
 		ObjectTokens: objectTokens,
 	}
 
-	result, err := s.fpService.IsAllowedDownload(&accessInfo)
+	result, err := s.fpService.IsAllowedDownload(&accessInfo, getClientInfo(c))
 	if err != nil {
 		s.logger.Debugf("Error in checking download permission: %s", err.Error())
 		switch err.GetCode() {
@@ -69,7 +90,7 @@ func (s *GRPCServer) IsAllowedUpload(c context.Context, uar *pbAuth.UploadAccess
 		accessInfo.ObjectTypes[m.FileExtension(k)] = uint(count)
 	}
 
-	result, err := s.fpService.IsAllowedUpload(&accessInfo)
+	result, err := s.fpService.IsAllowedUpload(&accessInfo, getClientInfo(c))
 	if err != nil {
 		s.logger.Debugf("Error in checking upload permission: %s", err.Error())
 		switch err.GetCode() {
@@ -89,12 +110,22 @@ func (s *GRPCServer) IsAllowedUpload(c context.Context, uar *pbAuth.UploadAccess
 		}
 	}
 	uploadResult := pbAuth.AllowUploadResult{StatusCode: pbAuth.StatusCode_OK, FileTypes: make([]*pbAuth.AcceptableType, 0)}
+	var reasons []string
 	for _, t := range result {
 		uploadResult.FileTypes = append(uploadResult.FileTypes, &pbAuth.AcceptableType{
 			FileType: string(t.FileType),
 			MaxSize:  t.MaxSize,
 			IsAllow:  t.IsAllow,
 		})
+		if !t.IsAllow {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", t.FileType, t.Reason))
+		}
+	}
+	// The reasons of disallowed types are returned in the error message, so the client
+	// knows why they're rejected.
+	if len(reasons) > 0 {
+		uploadResult.Errmsg = strings.Join(reasons, "; ")
+		s.logger.Debugf("Some file types are not allowed to be uploaded: %s", uploadResult.Errmsg)
 	}
 	return &uploadResult, nil
 }
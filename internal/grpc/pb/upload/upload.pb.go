@@ -0,0 +1,316 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: upload.proto
+
+package upload
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StatusCode int32
+
+const (
+	StatusCode_OK              StatusCode = 0
+	StatusCode_ErrInternal     StatusCode = 1
+	StatusCode_ErrForbidden    StatusCode = 2
+	StatusCode_ErrUnauthorized StatusCode = 3
+	StatusCode_ErrBadRequest   StatusCode = 4
+	StatusCode_ErrNotFound     StatusCode = 5
+)
+
+// Enum value maps for StatusCode.
+var (
+	StatusCode_name = map[int32]string{
+		0: "OK",
+		1: "ErrInternal",
+		2: "ErrForbidden",
+		3: "ErrUnauthorized",
+		4: "ErrBadRequest",
+		5: "ErrNotFound",
+	}
+	StatusCode_value = map[string]int32{
+		"OK":              0,
+		"ErrInternal":     1,
+		"ErrForbidden":    2,
+		"ErrUnauthorized": 3,
+		"ErrBadRequest":   4,
+		"ErrNotFound":     5,
+	}
+)
+
+func (x StatusCode) Enum() *StatusCode {
+	p := new(StatusCode)
+	*p = x
+	return p
+}
+
+func (x StatusCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StatusCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_upload_proto_enumTypes[0].Descriptor()
+}
+
+func (StatusCode) Type() protoreflect.EnumType {
+	return &file_upload_proto_enumTypes[0]
+}
+
+func (x StatusCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StatusCode.Descriptor instead.
+func (StatusCode) EnumDescriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{0}
+}
+
+type ConfirmUploadReq struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The auth token the client has uploaded the file with
+	AuthToken string `protobuf:"bytes,1,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty"`
+	// The doc the file belongs to
+	DocId string `protobuf:"bytes,2,opt,name=doc_id,json=docId,proto3" json:"doc_id,omitempty"`
+	// Name of the stored file together with its extension
+	FileName string `protobuf:"bytes,3,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	// Full path of the stored file. If it's empty, file_name is used.
+	Src string `protobuf:"bytes,4,opt,name=src,proto3" json:"src,omitempty"`
+	// Size of the stored file in Kbytes
+	Size uint64 `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	// Checksum of the stored file, e.g. "sha256:<hex>"
+	Checksum string `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// MIME type of the stored file that is detected from its content
+	MimeType      string `protobuf:"bytes,7,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmUploadReq) Reset() {
+	*x = ConfirmUploadReq{}
+	mi := &file_upload_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmUploadReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmUploadReq) ProtoMessage() {}
+
+func (x *ConfirmUploadReq) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmUploadReq.ProtoReflect.Descriptor instead.
+func (*ConfirmUploadReq) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConfirmUploadReq) GetAuthToken() string {
+	if x != nil {
+		return x.AuthToken
+	}
+	return ""
+}
+
+func (x *ConfirmUploadReq) GetDocId() string {
+	if x != nil {
+		return x.DocId
+	}
+	return ""
+}
+
+func (x *ConfirmUploadReq) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *ConfirmUploadReq) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *ConfirmUploadReq) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ConfirmUploadReq) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *ConfirmUploadReq) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+type ConfirmUploadResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StatusCode    StatusCode             `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3,enum=dms.upload.StatusCode" json:"status_code,omitempty"`
+	Errmsg        string                 `protobuf:"bytes,2,opt,name=errmsg,proto3" json:"errmsg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmUploadResult) Reset() {
+	*x = ConfirmUploadResult{}
+	mi := &file_upload_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmUploadResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmUploadResult) ProtoMessage() {}
+
+func (x *ConfirmUploadResult) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmUploadResult.ProtoReflect.Descriptor instead.
+func (*ConfirmUploadResult) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConfirmUploadResult) GetStatusCode() StatusCode {
+	if x != nil {
+		return x.StatusCode
+	}
+	return StatusCode_OK
+}
+
+func (x *ConfirmUploadResult) GetErrmsg() string {
+	if x != nil {
+		return x.Errmsg
+	}
+	return ""
+}
+
+var File_upload_proto protoreflect.FileDescriptor
+
+const file_upload_proto_rawDesc = "" +
+	"\n" +
+	"\fupload.proto\x12\n" +
+	"dms.upload\"\xc4\x01\n" +
+	"\x10ConfirmUploadReq\x12\x1d\n" +
+	"\n" +
+	"auth_token\x18\x01 \x01(\tR\tauthToken\x12\x15\n" +
+	"\x06doc_id\x18\x02 \x01(\tR\x05docId\x12\x1b\n" +
+	"\tfile_name\x18\x03 \x01(\tR\bfileName\x12\x10\n" +
+	"\x03src\x18\x04 \x01(\tR\x03src\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x04R\x04size\x12\x1a\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\x12\x1b\n" +
+	"\tmime_type\x18\a \x01(\tR\bmimeType\"f\n" +
+	"\x13ConfirmUploadResult\x127\n" +
+	"\vstatus_code\x18\x01 \x01(\x0e2\x16.dms.upload.StatusCodeR\n" +
+	"statusCode\x12\x16\n" +
+	"\x06errmsg\x18\x02 \x01(\tR\x06errmsg*p\n" +
+	"\n" +
+	"StatusCode\x12\x06\n" +
+	"\x02OK\x10\x00\x12\x0f\n" +
+	"\vErrInternal\x10\x01\x12\x10\n" +
+	"\fErrForbidden\x10\x02\x12\x13\n" +
+	"\x0fErrUnauthorized\x10\x03\x12\x11\n" +
+	"\rErrBadRequest\x10\x04\x12\x0f\n" +
+	"\vErrNotFound\x10\x052X\n" +
+	"\x06Upload\x12N\n" +
+	"\rConfirmUpload\x12\x1c.dms.upload.ConfirmUploadReq\x1a\x1f.dms.upload.ConfirmUploadResultB\x1dZ\x1bDMS/internal/grpc/pb/uploadb\x06proto3"
+
+var (
+	file_upload_proto_rawDescOnce sync.Once
+	file_upload_proto_rawDescData []byte
+)
+
+func file_upload_proto_rawDescGZIP() []byte {
+	file_upload_proto_rawDescOnce.Do(func() {
+		file_upload_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_upload_proto_rawDesc), len(file_upload_proto_rawDesc)))
+	})
+	return file_upload_proto_rawDescData
+}
+
+var file_upload_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_upload_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_upload_proto_goTypes = []any{
+	(StatusCode)(0),             // 0: dms.upload.StatusCode
+	(*ConfirmUploadReq)(nil),    // 1: dms.upload.ConfirmUploadReq
+	(*ConfirmUploadResult)(nil), // 2: dms.upload.ConfirmUploadResult
+}
+var file_upload_proto_depIdxs = []int32{
+	0, // 0: dms.upload.ConfirmUploadResult.status_code:type_name -> dms.upload.StatusCode
+	1, // 1: dms.upload.Upload.ConfirmUpload:input_type -> dms.upload.ConfirmUploadReq
+	2, // 2: dms.upload.Upload.ConfirmUpload:output_type -> dms.upload.ConfirmUploadResult
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_upload_proto_init() }
+func file_upload_proto_init() {
+	if File_upload_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_upload_proto_rawDesc), len(file_upload_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_upload_proto_goTypes,
+		DependencyIndexes: file_upload_proto_depIdxs,
+		EnumInfos:         file_upload_proto_enumTypes,
+		MessageInfos:      file_upload_proto_msgTypes,
+	}.Build()
+	File_upload_proto = out.File
+	file_upload_proto_goTypes = nil
+	file_upload_proto_depIdxs = nil
+}
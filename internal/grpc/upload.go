@@ -0,0 +1,94 @@
+package grpcserver
+
+import (
+	pbUpload "DMS/internal/grpc/pb/upload"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	service "DMS/internal/services"
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata key that the file-transfer service sends its shared secret in. It's the same
+// secret of the X-Service-Token header of the HTTP callback.
+const serviceTokenMetadata = "x-service-token"
+
+// It's called by the file-transfer service after storing the uploaded files.
+type GRPCUploadServer struct {
+	logger    l.Logger
+	fpService service.FilePermissionService
+	// Shared secret of the file-transfer service. If it's empty, the confirmations are
+	// rejected.
+	serviceSecret string
+	pbUpload.UnimplementedUploadServer
+}
+
+func NewGRPCUploadServer(fpService service.FilePermissionService, serviceSecret string, logger l.Logger) GRPCUploadServer {
+	if serviceSecret == "" {
+		logger.Warnf("Upload callback secret is not set; upload confirmations over gRPC are rejected")
+	}
+	return GRPCUploadServer{logger: logger, fpService: fpService, serviceSecret: serviceSecret}
+}
+
+// Return true if the request has the shared secret of the file-transfer service.
+func (s *GRPCUploadServer) isServiceAuthenticated(c context.Context) bool {
+	if s.serviceSecret == "" {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(c)
+	if !ok {
+		return false
+	}
+	tokens := md.Get(serviceTokenMetadata)
+	return len(tokens) == 1 && subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(s.serviceSecret)) == 1
+}
+
+func (s *GRPCUploadServer) ConfirmUpload(c context.Context, cur *pbUpload.ConfirmUploadReq) (*pbUpload.ConfirmUploadResult, error) {
+	if !s.isServiceAuthenticated(c) {
+		s.logger.Debugf("Rejected upload confirmation with wrong service token")
+		return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrForbidden,
+			Errmsg: "service token is invalid"}, nil
+	}
+	docID, err := m.ID{}.FromString2(cur.DocId)
+	if err != nil {
+		return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrBadRequest,
+			Errmsg: err.Error()}, nil
+	}
+	confirmation := m.UploadConfirmation{
+		AuthToken: m.Str2Token(cur.AuthToken),
+		DocID:     docID,
+		FileName:  cur.FileName,
+		Src:       cur.Src,
+		Size:      cur.Size,
+		Checksum:  cur.Checksum,
+		MimeType:  cur.MimeType,
+	}
+
+	if _, err := s.fpService.ConfirmUpload(&confirmation, getClientInfo(c)); err != nil {
+		s.logger.Debugf("Error in confirming upload: %s", err.Error())
+		switch err.GetCode() {
+		case service.SEInternal:
+			return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrInternal,
+				Errmsg: err.Error()}, nil
+		case service.SEForbidden:
+			return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrForbidden,
+				Errmsg: err.Error()}, nil
+		case service.SEAuthFailed:
+			return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrUnauthorized,
+				Errmsg: err.Error()}, nil
+		case service.SENotFound:
+			return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrNotFound,
+				Errmsg: err.Error()}, nil
+		case service.SEWrongParameter:
+			return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrBadRequest,
+				Errmsg: err.Error()}, nil
+		default:
+			s.logger.Infof("Unexpected error in confirming upload (err code: %d): %s", err.GetCode(), err.Error())
+			return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_ErrInternal,
+				Errmsg: err.Error()}, nil
+		}
+	}
+	return &pbUpload.ConfirmUploadResult{StatusCode: pbUpload.StatusCode_OK}, nil
+}
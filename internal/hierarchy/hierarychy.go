@@ -35,8 +35,41 @@ func (h *HierarchyTree) GetNestedChilds(nodeID graph.Vertex) ([]graph.Vertex, er
 	return h.graph.GetAllNestedChildren(nodeID), nil
 }
 
-// Return true if the given vertex is a source vertex. Means it has no parents.
+// Get direct children of the input vertex.
+func (h *HierarchyTree) GetChilds(nodeID graph.Vertex) ([]graph.Vertex, error) {
+	return *h.graph.GetChildren(nodeID), nil
+}
+
+// Get all ancestors of the input vertex without the self vertex. The nearer ancestors
+// come first. "NilVertex" is not counted as an ancestor.
+func (h *HierarchyTree) GetAncestors(nodeID graph.Vertex) ([]graph.Vertex, error) {
+	ancestors := make([]graph.Vertex, 0)
+	visited := map[string]struct{}{nodeID.String(): {}}
+	queue := []graph.Vertex{nodeID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range *h.graph.GetParents(current) {
+			if _, seen := visited[parent.String()]; seen || parent.Equals(graph.NilVertex) {
+				continue
+			}
+			visited[parent.String()] = struct{}{}
+			ancestors = append(ancestors, parent)
+			queue = append(queue, parent)
+		}
+	}
+	return ancestors, nil
+}
+
+// Return true if the given vertex is a source vertex. Means it has no parents. Edges
+// from "NilVertex" are not counted, because job positions without parent are connected
+// to it.
 func (h *HierarchyTree) IsSourceVertex(nodeID graph.Vertex) (bool, error) {
 	parents := h.graph.GetParents(nodeID)
-	return len(*parents) == 0, nil
+	for _, parent := range *parents {
+		if !parent.Equals(graph.NilVertex) {
+			return false, nil
+		}
+	}
+	return true, nil
 }
@@ -0,0 +1,132 @@
+package hierarchy
+
+import (
+	"DMS/internal/graph"
+	l "DMS/internal/logger"
+	"io"
+	"slices"
+	"testing"
+)
+
+// Return a hierarchy of the multi-parent graph below. The admin job position is connected
+// to "NilVertex" like the job positions without parent.
+//
+//	NilVertex -> admin -> left  -> bottom -> leaf
+//	                   -> right -> bottom
+func newTestHierarchy(t *testing.T) *HierarchyTree {
+	t.Helper()
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	g := graph.NewDynamicGraph(graph.NewMemoryStorage(logger), logger)
+	changes := make(chan graph.GraphChange)
+	defer close(changes)
+	go g.ProcessChanges(changes)
+	for _, edge := range [][2]graph.Vertex{
+		{graph.NilVertex, graph.Vertex("admin")},
+		{graph.Vertex("admin"), graph.Vertex("left")},
+		{graph.Vertex("admin"), graph.Vertex("right")},
+		{graph.Vertex("left"), graph.Vertex("bottom")},
+		{graph.Vertex("right"), graph.Vertex("bottom")},
+		{graph.Vertex("bottom"), graph.Vertex("leaf")},
+	} {
+		responseErr := make(chan error, 1)
+		changes <- graph.GraphChange{Type: graph.AddEdge, Edge: graph.Edge{Start: edge[0], End: edge[1]}, ResponseErr: responseErr}
+		if err := <-responseErr; err != nil {
+			t.Fatalf("failed to add edge %s -> %s: %s", edge[0].String(), edge[1].String(), err.Error())
+		}
+	}
+	return NewHierarchyTree(g, logger)
+}
+
+// Return names of the vertices in sorted order.
+func sortedNames(vertices []graph.Vertex) []string {
+	names := make([]string, len(vertices))
+	for i, vertex := range vertices {
+		names[i] = vertex.String()
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestGetChilds(t *testing.T) {
+	tree := newTestHierarchy(t)
+	tests := []struct {
+		vertex   graph.Vertex
+		expected []string
+	}{
+		{vertex: graph.Vertex("admin"), expected: []string{"left", "right"}},
+		{vertex: graph.Vertex("left"), expected: []string{"bottom"}},
+		{vertex: graph.Vertex("right"), expected: []string{"bottom"}},
+		{vertex: graph.Vertex("leaf"), expected: []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.vertex.String(), func(t *testing.T) {
+			childs, err := tree.GetChilds(test.vertex)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if names := sortedNames(childs); !slices.Equal(names, test.expected) {
+				t.Errorf("expected childs %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestGetAncestors(t *testing.T) {
+	tree := newTestHierarchy(t)
+	tests := []struct {
+		vertex graph.Vertex
+		// Expected ancestors level by level. The nearer levels come first.
+		expected [][]string
+	}{
+		{vertex: graph.Vertex("leaf"), expected: [][]string{{"bottom"}, {"left", "right"}, {"admin"}}},
+		{vertex: graph.Vertex("bottom"), expected: [][]string{{"left", "right"}, {"admin"}}},
+		{vertex: graph.Vertex("left"), expected: [][]string{{"admin"}}},
+		{vertex: graph.Vertex("admin"), expected: [][]string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.vertex.String(), func(t *testing.T) {
+			ancestors, err := tree.GetAncestors(test.vertex)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			var expectedCount int
+			for _, level := range test.expected {
+				expectedCount += len(level)
+			}
+			if len(ancestors) != expectedCount {
+				t.Fatalf("expected ancestors %v once and without NilVertex, got %v", test.expected, sortedNames(ancestors))
+			}
+			i := 0
+			for _, level := range test.expected {
+				if names := sortedNames(ancestors[i : i+len(level)]); !slices.Equal(names, level) {
+					t.Errorf("expected ancestors %v at %d, got %v", level, i, names)
+				}
+				i += len(level)
+			}
+		})
+	}
+}
+
+func TestGetNestedChilds(t *testing.T) {
+	tree := newTestHierarchy(t)
+	childs, err := tree.GetNestedChilds(graph.Vertex("admin"))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expected := []string{"admin", "bottom", "leaf", "left", "right"}
+	if names := sortedNames(childs); !slices.Equal(names, expected) {
+		t.Errorf("expected nested childs %v once, got %v", expected, names)
+	}
+	if len(childs) == 0 || !childs[0].Equals(graph.Vertex("admin")) {
+		t.Errorf("expected the vertex itself to come first, got %v", childs)
+	}
+}
+
+func TestIsSourceVertex(t *testing.T) {
+	tree := newTestHierarchy(t)
+	for vertex, expected := range map[string]bool{"admin": true, "left": false, "bottom": false} {
+		if isSource, err := tree.IsSourceVertex(graph.Vertex(vertex)); err != nil || isSource != expected {
+			t.Errorf("expected %s to be source vertex %v, got %v (%v)", vertex, expected, isSource, err)
+		}
+	}
+}
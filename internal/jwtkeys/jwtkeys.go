@@ -0,0 +1,191 @@
+// This package keeps the RSA keys that JWTs are signed and verified with. Each key has
+// an id (kid) that is written in the header of the JWTs, so the keys could be rotated
+// without invalidating the JWTs signed with the previous keys.
+package jwtkeys
+
+import (
+	m "DMS/internal/models"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Extension of files that contain private keys. These keys could sign and verify.
+	privateKeyExt = ".key"
+	// Extension of files that contain public keys. These keys could just verify.
+	publicKeyExt = ".pub"
+	// Size of generated keys in bits
+	keyBits = 2048
+	// Id of the key pair that is set in the config directly instead of the keys directory.
+	// JWTs signed before key ids are signed with it, so to keep them valid after moving to
+	// a keys directory, its public key must be kept in "env.pub" of the directory.
+	LegacyKID = "env"
+)
+
+// Keys used for signing and verifying JWTs. Just the active key signs new JWTs and the
+// others just verify JWTs signed previously.
+type KeyRing struct {
+	activeKID  string
+	activeKey  *rsa.PrivateKey
+	publicKeys map[string]*rsa.PublicKey
+}
+
+// Return id and private key of the key that signs new JWTs.
+func (k *KeyRing) Active() (kid string, key *rsa.PrivateKey) {
+	return k.activeKID, k.activeKey
+}
+
+// Return the public key with the id. If kid is empty, the legacy key is returned, so the
+// JWTs signed before key ids are still valid.
+func (k *KeyRing) PublicKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" {
+		kid = LegacyKID
+	}
+	key, ok := k.publicKeys[kid]
+	return key, ok
+}
+
+// Return all public keys in JSON Web Key Set format. The active key comes first.
+func (k *KeyRing) JWKS() m.JWKS {
+	kids := make([]string, 0, len(k.publicKeys))
+	for kid := range k.publicKeys {
+		if kid != k.activeKID {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(kids)))
+	kids = append([]string{k.activeKID}, kids...)
+
+	jwks := m.JWKS{Keys: make([]m.JWK, len(kids))}
+	for i, kid := range kids {
+		key := k.publicKeys[kid]
+		jwks.Keys[i] = m.JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	}
+	return jwks
+}
+
+// Create a key ring with a single key from PEM encoded keys.
+func NewKeyRing(kid string, privateKeyPEM, publicKeyPEM []byte) (*KeyRing, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rsa private key: %s", err.Error())
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rsa public key: %s", err.Error())
+	}
+	return &KeyRing{kid, privateKey, map[string]*rsa.PublicKey{kid: publicKey}}, nil
+}
+
+// Load keys of the directory. The name of each file without extension is the id of its
+// key. Files with ".key" extension contain private keys and files with ".pub" extension
+// contain public keys that are used just for verification. If activeKID is empty, the
+// private key with the greatest id is the active one. (Generated ids are sortable by
+// creation time) The legacy key is active just if there's not any other private key.
+func LoadKeyRing(dir, activeKID string) (*KeyRing, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys directory %s: %s", dir, err.Error())
+	}
+	privateKeys := make(map[string]*rsa.PrivateKey)
+	publicKeys := make(map[string]*rsa.PublicKey)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != privateKeyExt && ext != publicKeyExt {
+			continue
+		}
+		kid := strings.TrimSuffix(entry.Name(), ext)
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file %s: %s", entry.Name(), err.Error())
+		}
+		if ext == privateKeyExt {
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse rsa private key %s: %s", entry.Name(), err.Error())
+			}
+			privateKeys[kid] = key
+			publicKeys[kid] = &key.PublicKey
+		} else if _, ok := privateKeys[kid]; !ok {
+			key, err := jwt.ParseRSAPublicKeyFromPEM(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse rsa public key %s: %s", entry.Name(), err.Error())
+			}
+			publicKeys[kid] = key
+		}
+	}
+
+	if activeKID == "" {
+		for kid := range privateKeys {
+			if kid > activeKID && kid != LegacyKID {
+				activeKID = kid
+			}
+		}
+		if _, ok := privateKeys[LegacyKID]; activeKID == "" && ok {
+			activeKID = LegacyKID
+		}
+	}
+	activeKey, ok := privateKeys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("there's not any private key with id \"%s\" in directory %s", activeKID, dir)
+	}
+	return &KeyRing{activeKID, activeKey, publicKeys}, nil
+}
+
+// Generate a new key pair in the directory and return its id. The private key is written
+// in PKCS #8 format and the public key in PKIX format, both PEM encoded.
+func GenerateKeyPair(dir string) (string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate rsa key: %s", err.Error())
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %s", err.Error())
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %s", err.Error())
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create keys directory %s: %s", dir, err.Error())
+	}
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	privatePath := filepath.Join(dir, kid+privateKeyExt)
+	publicPath := filepath.Join(dir, kid+publicKeyExt)
+	if _, err := os.Stat(privatePath); err == nil {
+		return "", fmt.Errorf("key with id %s exists previously", kid)
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %s", err.Error())
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(publicPath, publicPEM, 0644); err != nil {
+		return "", fmt.Errorf("failed to write public key: %s", err.Error())
+	}
+	return kid, nil
+}
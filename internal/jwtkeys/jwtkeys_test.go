@@ -0,0 +1,99 @@
+package jwtkeys
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Generate a key pair in the directory and rename its files to the id.
+func generateKeyPairWithID(t *testing.T, dir, kid string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	generated, err := GenerateKeyPair(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to generate key pair: %s", err.Error())
+	}
+	for _, ext := range []string{privateKeyExt, publicKeyExt} {
+		if err := os.Rename(filepath.Join(tmpDir, generated+ext), filepath.Join(dir, kid+ext)); err != nil {
+			t.Fatalf("failed to move key file: %s", err.Error())
+		}
+	}
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	tests := []struct {
+		name string
+		// Ids of the private keys in the directory
+		kids      []string
+		activeKID string
+		// Expected active key id. If it's empty, loading must fail.
+		expectedKID string
+	}{
+		{name: "newest key is active", kids: []string{"20240101T000000Z", "20250101T000000Z"},
+			expectedKID: "20250101T000000Z"},
+		{name: "legacy key is not newer than others", kids: []string{LegacyKID, "20240101T000000Z"},
+			expectedKID: "20240101T000000Z"},
+		{name: "legacy key is active without other keys", kids: []string{LegacyKID}, expectedKID: LegacyKID},
+		{name: "configured active key", kids: []string{"20240101T000000Z", "20250101T000000Z"},
+			activeKID: "20240101T000000Z", expectedKID: "20240101T000000Z"},
+		{name: "unknown active key", kids: []string{"20240101T000000Z"}, activeKID: "unknown"},
+		{name: "without private key"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, kid := range test.kids {
+				generateKeyPairWithID(t, dir, kid)
+			}
+			keys, err := LoadKeyRing(dir, test.activeKID)
+			if test.expectedKID == "" {
+				if err == nil {
+					t.Errorf("expected error, got active key %s", keys.activeKID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if kid, _ := keys.Active(); kid != test.expectedKID {
+				t.Errorf("expected active key %s, got %s", test.expectedKID, kid)
+			}
+		})
+	}
+}
+
+func TestPublicKeyOfJWTWithoutKID(t *testing.T) {
+	dir := t.TempDir()
+	generateKeyPairWithID(t, dir, LegacyKID)
+	generateKeyPairWithID(t, dir, "20250101T000000Z")
+	// The legacy key just verifies the old JWTs.
+	if err := os.Remove(filepath.Join(dir, LegacyKID+privateKeyExt)); err != nil {
+		t.Fatalf("failed to remove legacy private key: %s", err.Error())
+	}
+	keys, err := LoadKeyRing(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	legacyKey, ok := keys.PublicKey("")
+	if !ok || !legacyKey.Equal(keys.publicKeys[LegacyKID]) {
+		t.Errorf("expected legacy key for JWTs without key id")
+	}
+	_, activeKey := keys.Active()
+	if activeKey.PublicKey.Equal(legacyKey) {
+		t.Errorf("expected active key not to verify JWTs without key id")
+	}
+
+	// Without the legacy key, JWTs without key id are not verified.
+	if err := os.Remove(filepath.Join(dir, LegacyKID+publicKeyExt)); err != nil {
+		t.Fatalf("failed to remove legacy public key: %s", err.Error())
+	}
+	keys, err = LoadKeyRing(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if key, ok := keys.PublicKey(""); ok {
+		t.Errorf("expected no key for JWTs without key id, got %v", key)
+	}
+}
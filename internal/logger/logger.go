@@ -91,6 +91,17 @@ const (
 	None
 )
 
+// Return the log level with the given name. Names are the lower case of the levels.
+// (e.g. "debug" or "none")
+func ParseLogLevel(name string) (LogLevel, error) {
+	for level, levelName := range []string{"debug", "info", "warn", "error", "fatal", "panic", "none"} {
+		if name == levelName {
+			return LogLevel(level), nil
+		}
+	}
+	return None, fmt.Errorf("unknown log level %s", name)
+}
+
 // A simple logger
 type SLogger struct {
 	// Minimum log level that processes and lower levels will be ignored.
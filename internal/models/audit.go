@@ -0,0 +1,107 @@
+package models
+
+// Details of the client that sent a request. They're stored in the audit log.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type AuditAction string
+
+const (
+	AuditUserCreate          AuditAction = "user.create"
+	AuditAdminCreate         AuditAction = "user.create_admin"
+	AuditUserUpdate          AuditAction = "user.update"
+	AuditUserDisable         AuditAction = "user.disable"
+	AuditUserEnable          AuditAction = "user.enable"
+	AuditJPCreate            AuditAction = "jp.create"
+	AuditAdminJPCreate       AuditAction = "jp.create_admin"
+	AuditJPUpdate            AuditAction = "jp.update"
+	AuditJPMove              AuditAction = "jp.move"
+	AuditJPDisable           AuditAction = "jp.disable"
+	AuditJPEnable            AuditAction = "jp.enable"
+	AuditJPDelete            AuditAction = "jp.delete"
+	AuditEventCreate         AuditAction = "event.create"
+	AuditEventUpdate         AuditAction = "event.update"
+	AuditEventDelete         AuditAction = "event.delete"
+	AuditEventApprove        AuditAction = "event.approve"
+	AuditEventRevokeApproval AuditAction = "event.revoke_approval"
+	AuditEventGrantAccess    AuditAction = "event.grant_access"
+	AuditEventRevokeAccess   AuditAction = "event.revoke_access"
+	AuditDocCreate           AuditAction = "doc.create"
+	AuditDocUpdate           AuditAction = "doc.update"
+	AuditDocDelete           AuditAction = "doc.delete"
+	AuditDocRestore          AuditAction = "doc.restore"
+	AuditRoleCreate          AuditAction = "role.create"
+	AuditRoleDelete          AuditAction = "role.delete"
+	AuditRoleAssign          AuditAction = "role.assign"
+	AuditRoleUnassign        AuditAction = "role.unassign"
+	AuditOTPRequest          AuditAction = "session.request_otp"
+	AuditLogin               AuditAction = "session.login"
+	AuditSessionRefresh      AuditAction = "session.refresh"
+	AuditLogout              AuditAction = "session.logout"
+	AuditSessionRevoke       AuditAction = "session.revoke"
+	AuditSessionsRevoke      AuditAction = "session.revoke_all"
+	AuditFileDownload        AuditAction = "file.download"
+	AuditFileUpload          AuditAction = "file.upload"
+	AuditFileConfirm         AuditAction = "file.confirm_upload"
+)
+
+// Type of the entity an audited action is done on
+type AuditTargetType string
+
+const (
+	AuditTargetUser    AuditTargetType = "user"
+	AuditTargetJP      AuditTargetType = "jp"
+	AuditTargetEvent   AuditTargetType = "event"
+	AuditTargetDoc     AuditTargetType = "doc"
+	AuditTargetRole    AuditTargetType = "role"
+	AuditTargetSession AuditTargetType = "session"
+)
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// An entry of the audit log. Nil ids mean they're unknown, e.g. the actor of a failed login.
+type AuditEvent struct {
+	ID ID `json:"id" example:"20354d7a-e4fe-47af-8ff6-187bca92f3f9"`
+	// The user who did the action
+	ActorUserID ID `json:"actor_user_id" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	// The job position the action is done with
+	ActorJPID ID          `json:"actor_jp_id" example:"54a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	Action    AuditAction `json:"action" example:"doc.create"`
+	// Type of the entity the action is done on. It's empty if the action doesn't have any target.
+	TargetType AuditTargetType `json:"target_type" example:"doc"`
+	TargetID   ID              `json:"target_id" example:"32a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	IP         string          `json:"ip" example:"192.168.1.10"`
+	UserAgent  string          `json:"user_agent"`
+	Outcome    AuditOutcome    `json:"outcome" enums:"success,failure"`
+	// Reason of the failure or more details of the action
+	Details string `json:"details,omitempty"`
+	// It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedAt int64 `json:"created_at" example:"1641011200"`
+}
+
+// Filters of the audit log. Nil fields are not applied.
+type AuditFilter struct {
+	Action  *AuditAction
+	Outcome *AuditOutcome
+	// Just events done with this job position
+	ActorJPID *ID
+	TargetID  *ID
+	// Just events created at or after this time. It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedFrom *int64
+	// Just events created at or before this time. It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedTo *int64
+}
+
+// A page of the audit log together with the cursor of the next page
+type AuditPage struct {
+	Events []AuditEvent `json:"events"`
+	// Pass it as the cursor query to get the next page. If it's empty, there's not any more event.
+	NextCursor string `json:"next_cursor,omitempty" example:"AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"`
+}
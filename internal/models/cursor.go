@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// The latest creation time that a cursor could have. Later times are out of range of the
+// database timestamps.
+var maxCursorTime = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC).UnixMicro()
+
+// Position of an item in a list ordered by creation time (newest first). It's used for
+// keyset pagination and the next page starts right after the item.
+type Cursor struct {
+	// The time the item is created. It's in UTC time zone and Unix timestamp. (in microseconds)
+	CreatedAt int64
+	ID        ID
+}
+
+// Encode the cursor to an opaque URL-safe string.
+func (c Cursor) Encode() string {
+	buf := make([]byte, 8, 8+len(c.ID))
+	binary.BigEndian.PutUint64(buf, uint64(c.CreatedAt))
+	buf = append(buf, c.ID[:]...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Decode the cursor encoded by the Encode method. Return error if the cursor is not
+// encoded or its creation time is out of range.
+func DecodeCursor(cursor string) (*Cursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cursor: %s", err.Error())
+	}
+	var id ID
+	if len(buf) != 8+len(id) {
+		return nil, fmt.Errorf("length of the cursor is %d bytes but must be %d", len(buf), 8+len(id))
+	}
+	createdAt := int64(binary.BigEndian.Uint64(buf[:8]))
+	if createdAt < 0 || createdAt > maxCursorTime {
+		return nil, fmt.Errorf("creation time %d of the cursor is out of range", createdAt)
+	}
+	copy(id[:], buf[8:])
+	return &Cursor{
+		CreatedAt: createdAt,
+		ID:        id,
+	}, nil
+}
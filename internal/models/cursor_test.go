@@ -0,0 +1,50 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	cursor := Cursor{CreatedAt: 1700000000123456, ID: ID(uuid.New())}
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if *decoded != cursor {
+		t.Errorf("expected cursor %+v, got %+v", cursor, *decoded)
+	}
+}
+
+func TestDecodeMalformedCursor(t *testing.T) {
+	// Encode the creation time with a random id like the Encode method, but without
+	// checking the time.
+	encode := func(createdAt int64) string {
+		buf := binary.BigEndian.AppendUint64(nil, uint64(createdAt))
+		id := uuid.New()
+		return base64.RawURLEncoding.EncodeToString(append(buf, id[:]...))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "standard base64", cursor: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xff}, 24))},
+		{name: "short", cursor: base64.RawURLEncoding.EncodeToString(make([]byte, 23))},
+		{name: "long", cursor: base64.RawURLEncoding.EncodeToString(make([]byte, 25))},
+		{name: "negative time", cursor: encode(-1)},
+		{name: "time out of range", cursor: encode(maxCursorTime + 1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if cursor, err := DecodeCursor(test.cursor); err == nil {
+				t.Errorf("expected error, got cursor %+v", *cursor)
+			}
+		})
+	}
+}
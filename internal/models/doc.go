@@ -19,8 +19,32 @@ type MediaPath struct {
 	Src string `json:"src"`
 	// Just contains filename and its type
 	FileName string `json:"file_name"`
+	// Size of the file in Kbytes. It's required on creating and editing docs.
+	Size uint64 `json:"size" example:"2048"`
+	// Checksum of the file, e.g. "sha256:<hex>". It's set when the upload of the file is
+	// confirmed and ignored in requests.
+	Checksum string `json:"checksum,omitempty"`
+	// MIME type of the file detected from its content. It's set when the upload of the
+	// file is confirmed and ignored in requests.
+	MimeType string `json:"mime_type,omitempty" example:"image/jpeg"`
+	// The file is pending until the file-transfer service confirms it's stored. It's set
+	// by the server in responses and ignored in requests.
+	Status MediaStatus `json:"status" example:"confirmed"`
+	// Signed and expiring token that must be sent to the file-transfer service to download
+	// the file. It's set by the server in responses and ignored in requests. Pending files
+	// don't have it.
+	ObjectToken Token `json:"object_token,omitempty"`
 }
 
+type MediaStatus string
+
+const (
+	// The file is referenced by the doc but it's not uploaded yet.
+	MediaPending MediaStatus = "pending"
+	// The file is stored by the file-transfer service.
+	MediaConfirmed MediaStatus = "confirmed"
+)
+
 type MediaType uint8
 
 const (
@@ -29,9 +53,45 @@ const (
 	MediaAudio
 )
 
+// Return true if the media type is one of the known media types.
+func (t MediaType) IsValid() bool {
+	return t <= MediaAudio
+}
+
 // Contains doc details with some additional related details
 type DocWithSomeDetails struct {
 	Doc
 	EventName string `json:"event_name"`
 	JPName    string `json:"jp_name"`
 }
+
+// A page of documents together with the cursor of the next page
+type DocsPage struct {
+	Docs []DocWithSomeDetails `json:"docs"`
+	// Pass it as the cursor query to get the next page. If it's empty, there's not any more document.
+	NextCursor string `json:"next_cursor,omitempty" example:"AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"`
+}
+
+// Contains the fields of a document that could be edited. Nil fields remain unchanged.
+type DocUpdate struct {
+	Context *string `json:"context" example:"corrected context"`
+	// If it's not nil, confirmed multimedia files of the document are reordered or removed
+	// to match it. Files are matched by their file names and new files couldn't be added.
+	Paths *[]MediaPath `json:"media_paths"`
+}
+
+// A previous version of a document. Each edit of the document creates a new version.
+type DocVersion struct {
+	DocID ID `json:"doc_id" example:"20354d7a-e4fe-47af-8ff6-187bca92f3f9"`
+	// Sequence number of the version in the document. The first version is 1.
+	Version uint    `json:"version" example:"1"`
+	Context *string `json:"context" example:"some context"`
+	// Contains path of multimedia files in this version of the document.
+	Paths []MediaPath `json:"media_paths"`
+	// The id of job position who edited the document and replaced this version with a newer one
+	EditedBy ID `json:"edited_by" example:"54a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	// Name of the person who edited the document
+	EditorName string `json:"editor_name"`
+	// The time this version is replaced with a newer one. It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedAt int64 `json:"created_at" example:"1641011200"`
+}
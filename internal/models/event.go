@@ -18,9 +18,92 @@ type Event struct {
 	Description string `json:"description"`
 }
 
+// A page of events together with the cursor of the next page
+type EventsPage struct {
+	Events []Event `json:"events"`
+	// Pass it as the cursor query to get the next page. If it's empty, there's not any more event.
+	NextCursor string `json:"next_cursor,omitempty" example:"AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"`
+}
+
+// Contains the fields of an event that could be edited. Nil fields remain unchanged.
+type EventUpdate struct {
+	Name        *string `json:"name" example:"new name"`
+	Description *string `json:"description" example:"new description"`
+}
+
+// Values of an event before and after an edit
+type EventRevision struct {
+	ID      ID `json:"id" example:"46bbd388-d251-4a53-9f5b-da2c909fe14a"`
+	EventID ID `json:"event_id" example:"46bbd388-d251-4a53-9f5b-da2c909fe14a"`
+	// ID of job position who edited the event
+	EditedBy ID `json:"edited_by" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	// Name of the person who edited the event
+	EditorName     string `json:"editor_name"`
+	OldName        string `json:"old_name"`
+	NewName        string `json:"new_name"`
+	OldDescription string `json:"old_description"`
+	NewDescription string `json:"new_description"`
+	// Date when the event is edited. Based on UTC time zone and Unix timestamp. (In seconds)
+	CreatedAt int64 `json:"created_at"`
+}
+
 type ApprovedEvent struct {
 	EventID ID `json:"event_id"`
+	// ID of the job position approved the event
+	ApprovedByJPID ID `json:"approved_by_jp_id" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
 	// Name of Person approved the event
 	ApprovedBy string    `json:"approved_by"`
 	CreatedAt  time.Time `json:"at"`
 }
+
+// An approved (featured) event together with details of its approval
+type EventWithApproval struct {
+	Event
+	Approval ApprovedEvent `json:"approval"`
+}
+
+// Type of the one an event access is granted to
+type GranteeType string
+
+const (
+	GranteeJP   GranteeType = "jp"
+	GranteeUser GranteeType = "user"
+	// The job position and all its nested childs
+	GranteeSubtree GranteeType = "subtree"
+)
+
+func (t GranteeType) IsValid() bool {
+	return t == GranteeJP || t == GranteeUser || t == GranteeSubtree
+}
+
+// An access that could be granted on an event
+type EventAccess string
+
+const (
+	// Read the event and its docs and download their files
+	EventAccessRead EventAccess = "read"
+	// Read access plus creating docs for the event and uploading files
+	EventAccessContribute EventAccess = "contribute"
+)
+
+func (a EventAccess) IsValid() bool {
+	return a == EventAccessRead || a == EventAccessContribute
+}
+
+// An entry of the access control list of an event
+type EventACL struct {
+	ID      ID `json:"id" example:"46bbd388-d251-4a53-9f5b-da2c909fe14a"`
+	EventID ID `json:"event_id" example:"46bbd388-d251-4a53-9f5b-da2c909fe14a"`
+	// The access is granted to a job position, a user or a job position and all its nested childs.
+	GranteeType GranteeType `json:"grantee_type" validate:"required" enums:"jp,user,subtree"`
+	// ID of the job position or user the access is granted to
+	GranteeID ID          `json:"grantee_id" validate:"required" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	Access    EventAccess `json:"access" validate:"required" enums:"read,contribute"`
+	// Date when the access expires. Based on UTC time zone and Unix timestamp. (In seconds)
+	// If it's nil, the access never expires.
+	ExpiresAt *int64 `json:"expires_at"`
+	// ID of the job position granted the access
+	GrantedBy ID `json:"granted_by" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	// Date when the access is granted. Based on UTC time zone and Unix timestamp. (In seconds)
+	CreatedAt int64 `json:"created_at"`
+}
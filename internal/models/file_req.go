@@ -20,3 +20,21 @@ type UploadReq struct {
 	// of files we want to upload with that extension
 	ObjectTypes map[FileExtension]uint
 }
+
+// The file-transfer service sends it after storing an uploaded file.
+type UploadConfirmation struct {
+	// The auth token the client has uploaded the file with
+	AuthToken Token `json:"auth_token" validate:"required"`
+	// The doc the file belongs to
+	DocID ID `json:"doc_id" validate:"required" example:"20354d7a-e4fe-47af-8ff6-187bca92f3f9"`
+	// Name of the stored file together with its extension
+	FileName string `json:"file_name" validate:"required" example:"photo.jpg"`
+	// Full path of the stored file. If it's empty, FileName is used.
+	Src string `json:"src" example:"events/photo.jpg"`
+	// Size of the stored file in Kbytes
+	Size uint64 `json:"size" example:"2048"`
+	// Checksum of the stored file
+	Checksum string `json:"checksum" validate:"required" example:"sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// MIME type of the stored file that is detected from its content
+	MimeType string `json:"mime_type" validate:"required" example:"image/jpeg"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// Filters that could be applied on lists of docs and events. Nil fields are not applied.
+type ListFilter struct {
+	// Just items created at or after this time. It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedFrom *int64
+	// Just items created at or before this time. It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedTo *int64
+	// Just items created by this job position
+	CreatedBy *ID
+	// Just items of this event
+	EventID *ID
+	// Just items created by job positions of this region
+	RegionID *ID
+	// If it's true, just docs that have multimedia files (or events that have such docs).
+	// If it's false, just the ones that don't have any multimedia file.
+	HasMedia *bool
+	// Just docs that have a multimedia file with this type (or events that have such docs)
+	MediaType *MediaType
+}
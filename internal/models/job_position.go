@@ -11,27 +11,56 @@ type CommonJobPosition struct {
 	RegionID ID `json:"region_id" example:"b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"`
 	// The time the JP is created with UTC timezone and unix timestamp in seconds.
 	CreatedAt int64 `json:"created_at" example:"1641011200"`
+	// A disabled JP can't be used by its user. It's ignored on creating a JP.
+	IsDisabled bool `json:"is_disabled" example:"false"`
 }
 type UserJobPosition struct {
 	CommonJobPosition
-	ParentID ID `json:"parent_id,omitempty" validate:"required" example:"5abcdeff-0685-49d1-bbdd-31ab1b4c1613"`
+	// The first parent of the job position. On creating the job position, it's added to
+	// the parents if it's set.
+	ParentID ID `json:"parent_id,omitempty" validate:"required_without=ParentIDs" example:"5abcdeff-0685-49d1-bbdd-31ab1b4c1613"`
+	// All parents of the job position. The job position reports to each of them.
+	ParentIDs []ID `json:"parent_ids" validate:"required_without=ParentID" example:"5abcdeff-0685-49d1-bbdd-31ab1b4c1613"`
+}
+
+// Return all parents of the job position without duplicates. ParentID (if it's set) comes
+// first.
+func (s *UserJobPosition) Parents() []ID {
+	parents := make([]ID, 0, len(s.ParentIDs)+1)
+	seen := make(map[ID]struct{})
+	for _, id := range append([]ID{s.ParentID}, s.ParentIDs...) {
+		if _, isSeen := seen[id]; isSeen || id.IsNil() {
+			continue
+		}
+		seen[id] = struct{}{}
+		parents = append(parents, id)
+	}
+	return parents
 }
 
 func (s UserJobPosition) MarshalJSON() ([]byte, error) {
+	parentIDs := make([]string, len(s.ParentIDs))
+	for i := range s.ParentIDs {
+		parentIDs[i] = s.ParentIDs[i].String()
+	}
 	return json.Marshal(&struct {
-		ID        string `json:"id"`
-		UserID    string `json:"user_id"`
-		RegionID  string `json:"region_id"`
-		ParentID  string `json:"parent_id"`
-		Title     string `json:"title"`
-		CreatedAt int64  `json:"created_at"`
+		ID         string   `json:"id"`
+		UserID     string   `json:"user_id"`
+		RegionID   string   `json:"region_id"`
+		ParentID   string   `json:"parent_id"`
+		ParentIDs  []string `json:"parent_ids"`
+		Title      string   `json:"title"`
+		CreatedAt  int64    `json:"created_at"`
+		IsDisabled bool     `json:"is_disabled"`
 	}{
-		ParentID:  s.ParentID.String(),
-		ID:        s.ID.String(),
-		UserID:    s.UserID.String(),
-		RegionID:  s.RegionID.String(),
-		Title:     s.Title,
-		CreatedAt: s.CreatedAt,
+		ParentID:   s.ParentID.String(),
+		ParentIDs:  parentIDs,
+		ID:         s.ID.String(),
+		UserID:     s.UserID.String(),
+		RegionID:   s.RegionID.String(),
+		Title:      s.Title,
+		CreatedAt:  s.CreatedAt,
+		IsDisabled: s.IsDisabled,
 	})
 }
 
@@ -49,3 +78,30 @@ type AdminJPWithPermission struct {
 	JobPosition AdminJobPosition `json:"job_position" validate:"required"`
 	Permission  Permission       `json:"permission" validate:"required"`
 }
+
+// Fields of a job position that could be updated. Nil fields remain unchanged.
+type JPUpdate struct {
+	Title    *string `json:"title" example:"مدیر مدرسه"`
+	RegionID *ID     `json:"region_id" example:"b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"`
+}
+
+type JPMove struct {
+	// ID of the new parent of the job position
+	ParentID ID `json:"parent_id" validate:"required" example:"5abcdeff-0685-49d1-bbdd-31ab1b4c1613"`
+}
+
+// A job position in the hierarchy tree together with its user and roles.
+type JPNode struct {
+	ID       ID     `json:"id" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	Title    string `json:"title" example:"معاون مدرسه"`
+	UserID   ID     `json:"user_id" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	UserName string `json:"user_name" example:"John Doe"`
+	RegionID ID     `json:"region_id" example:"b11c9be1-b619-4ef5-be1b-a1cd9ef265b7"`
+	// If the job position has no parent, it's empty.
+	ParentIDs  []ID `json:"parent_ids"`
+	IsDisabled bool `json:"is_disabled" example:"false"`
+	// Roles assigned directly to the job position
+	Roles []JPRole `json:"roles"`
+	// Child job positions of the job position. It's just filled in the tree responses.
+	Childs []JPNode `json:"childs,omitempty"`
+}
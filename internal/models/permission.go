@@ -18,12 +18,18 @@ func (m *Matrix) GetDim() (rows, cols int) {
 // It's possible some values be nil and empty.
 type Graph map[ID]*[]ID
 
-// List of some permissions the job position could have
+// List of some permissions the job position could have. It's a shorthand for assigning
+// the built-in roles on creating a job position. Each job position gets the "member" role,
+// IsAllowCreateJP adds the "jp_manager" role and IsAllowApproveEvent adds the
+// "event_approver" role.
 type Permission struct {
 	// ID of the job position the permission is for
-	JPID ID
+	JPID ID `json:"-"`
 	// Does the current job position is allowed to create a job position as child of himself?
 	IsAllowCreateJP bool `json:"is_allow_create_jp" validate:"required"`
+	// Does the current job position is allowed to approve (feature) events created by
+	// himself or his nested childs?
+	IsAllowApproveEvent bool `json:"is_allow_approve_event"`
 }
 
 type HierarchyTree struct {
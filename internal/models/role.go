@@ -0,0 +1,61 @@
+package models
+
+// An action a job position could be allowed to do
+type Action string
+
+const (
+	ActionCreateUser Action = "create_user"
+	// Create a job position as child of a job position
+	ActionCreateJP Action = "create_jp"
+	// Edit, move, disable and delete job positions
+	ActionManageJP     Action = "manage_jp"
+	ActionCreateEvent  Action = "create_event"
+	ActionCreateDoc    Action = "create_doc"
+	ActionApproveEvent Action = "approve_event"
+	// Read job positions, events and docs of the nested childs
+	ActionViewSubtree Action = "view_subtree"
+	// Edit and delete events and docs of the nested childs
+	ActionEditSubtree Action = "edit_subtree"
+	// Revoke sessions of users created by the users of the nested childs
+	ActionManageSessions Action = "manage_sessions"
+	// Create roles and assign them to job positions
+	ActionManageRoles Action = "manage_roles"
+	// Edit, disable and enable users created by the users of the nested childs
+	ActionManageUsers Action = "manage_users"
+)
+
+// The catalogue of all actions
+var Actions = []Action{
+	ActionCreateUser, ActionCreateJP, ActionManageJP, ActionCreateEvent, ActionCreateDoc,
+	ActionApproveEvent, ActionViewSubtree, ActionEditSubtree, ActionManageSessions,
+	ActionManageRoles, ActionManageUsers,
+}
+
+// Return true if the action is one of the actions in the catalogue.
+func (a Action) IsValid() bool {
+	for _, action := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// A named bundle of actions that could be assigned to job positions
+type Role struct {
+	ID          ID     `json:"id" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	Name        string `json:"name" validate:"required" example:"secretary"`
+	Description string `json:"description" example:"Could create events and docs"`
+	// Actions the role allows
+	Actions []Action `json:"actions" validate:"required,min=1" enums:"create_user,create_jp,manage_jp,create_event,create_doc,approve_event,view_subtree,edit_subtree,manage_sessions,manage_roles,manage_users"`
+	// Built-in roles are created by the system and couldn't be deleted.
+	IsBuiltin bool `json:"is_builtin" example:"false"`
+}
+
+// A role assigned to a job position
+type JPRole struct {
+	RoleID ID     `json:"role_id" validate:"required" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	Name   string `json:"name" example:"secretary"`
+	// If it's true, the role applies to all nested childs of the job position too.
+	IsInheritable bool `json:"is_inheritable" example:"false"`
+}
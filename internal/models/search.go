@@ -0,0 +1,34 @@
+package models
+
+// Type of the entities that could be searched
+type SearchType string
+
+const (
+	SearchDoc   SearchType = "doc"
+	SearchEvent SearchType = "event"
+)
+
+// Return true if the search type is one of the known search types.
+func (t SearchType) IsValid() bool {
+	return t == SearchDoc || t == SearchEvent
+}
+
+// A document or event matched with the search query
+type SearchResult struct {
+	Type SearchType `json:"type" enums:"doc,event"`
+	// ID of the matched document or event
+	ID ID `json:"id" example:"20354d7a-e4fe-47af-8ff6-187bca92f3f9"`
+	// ID of the event. If the result is a document, it's the event the document is for that.
+	EventID ID `json:"event_id" example:"32a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	// Name of the event. It's HTML escaped and matched words in it are wrapped in <mark> tags.
+	Title string `json:"title"`
+	// Some fragments of the context of document or description of event that contain
+	// the matched words. It's HTML escaped and matched words are wrapped in <mark> tags.
+	Snippet string `json:"snippet"`
+	// Relevance of the result to the search query. Greater is more relevant.
+	Rank float32 `json:"rank" example:"0.0607927"`
+	// The id of job position who created the document or event
+	CreatedBy ID `json:"created_by" example:"54a79030f-0685-49d1-bbdd-31ab1b4c1613"`
+	// The time the document or event is created. It's in UTC time zone and Unix timestamp. (in seconds)
+	CreatedAt int64 `json:"created_at" example:"1641011200"`
+}
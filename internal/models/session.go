@@ -17,7 +17,9 @@ type Session struct {
 	// Last usage time of the session
 	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
 	// If it be 0 means it's not used.
-	LastUsageAt int64
+	LastUsageAt int64 `json:"last_usage_at"`
+	// Is it the session the current request is sent by?
+	IsCurrent bool `json:"is_current"`
 }
 
 type JWT struct {
@@ -34,8 +36,61 @@ type JWT struct {
 	JTI ID `json:"jti"`
 }
 
+// A refresh token of a session
+type RefreshToken struct {
+	ID        ID
+	SessionID ID
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	ExpiredAt int64
+	// Time the token is used to refresh the session. Zero means it's not used yet.
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	UsedAt int64
+}
+
+// Tokens issued for a session on login or refreshing it.
+type SessionTokens struct {
+	// Short-lived JWT that is used to access the APIs
+	AccessToken string `json:"access_token"`
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	AccessTokenExpiredAt int64 `json:"access_token_expired_at"`
+	// Long-lived token that is used just for getting new tokens. Each refresh token could
+	// be used once.
+	RefreshToken string `json:"refresh_token"`
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	RefreshTokenExpiredAt int64 `json:"refresh_token_expired_at"`
+}
+
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type PhoneOTPRequest struct {
+	PhoneNumber PhoneNumber `json:"phone_number" validate:"required" example:"9171234567"`
+}
+
 type PhoneBasedLoginInfo struct {
 	PhoneNumber PhoneNumber `json:"phone_number" validate:"required" example:"9171234567"`
+	// The one-time code that is sent to the phone number.
+	Code string `json:"code" validate:"required" example:"123456"`
 	// Details of the device from which the user logged in.
 	UserAgent string `json:"user_agent" validate:"required" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/89.0.142.86 Safari/537.36"`
 }
+
+// A public key in JSON Web Key format (RFC 7517) that JWTs could be verified with.
+type JWK struct {
+	// Key type
+	Kty string `json:"kty" example:"RSA"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+	// Key id that is written in the header of JWTs signed with this key
+	Kid string `json:"kid" example:"20250101T000000Z"`
+	// Modulus of the RSA key (base64url encoded)
+	N string `json:"n"`
+	// Exponent of the RSA key (base64url encoded)
+	E string `json:"e" example:"AQAB"`
+}
+
+// A set of public keys in JSON Web Key Set format
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -13,7 +13,7 @@ type User struct {
 	// * 0: enabled user
 	// * 1: disabled user
 	IsDisabled Disability `json:"is_disabled" example:"0" enum:"0,1"`
-	// The id of job position created this user
+	// The id of user created this user. It's set by the server according to the caller.
 	CreatedBy *ID `json:"created_by" validate:"uuidv4" example:"6a79030f-0685-49d1-bbdd-31ab1b4c1613"`
 }
 
@@ -27,6 +27,19 @@ type AdminUser struct {
 	IsDisabled Disability `json:"is_disabled" example:"0" enums:"0,1"`
 }
 
+// Fields of a user that could be updated. Nil fields are not changed.
+type UserUpdate struct {
+	Name        *string      `json:"name" example:"John Doe"`
+	PhoneNumber *PhoneNumber `json:"phone_number" example:"9171234567"`
+}
+
+// A page of users together with the cursor of the next page
+type UsersPage struct {
+	Users []User `json:"users"`
+	// Pass it as the cursor query to get the next page. If it's empty, there's not any more user.
+	NextCursor string `json:"next_cursor,omitempty" example:"AAYJ2kzRJ2EgNU16W-RHr4_xhnvKLJ_z"`
+}
+
 type PhoneNumber string
 
 var NilPhone PhoneNumber = ""
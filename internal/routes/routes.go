@@ -18,6 +18,7 @@ func SetupRouter(router *gin.Engine, ctr c.HttpConrtoller) {
 	apiV1NeedNotAuth(router, ctr)
 
 	router.GET("/health", healthCheck)
+	router.GET("/.well-known/jwks.json", ctr.Session.GetJWKS)
 	// Open this path to see documentaion=> /swagger/index.html
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 }
@@ -26,18 +27,59 @@ func apiV1NeedAuth(router *gin.Engine, ctr c.HttpConrtoller) {
 	routerV1.Use(ctr.Middleware.Authentication)
 
 	routerV1.POST("/users", ctr.User.CreateUser)
+	// Admin is a sub-type of user entity
+	routerV1.POST("/users/admin", ctr.User.CreateAdmin)
 	routerV1.GET("/users/current", ctr.User.GetCurrentUserInfo)
+	routerV1.GET("/users", ctr.User.GetUsers)
+	routerV1.PATCH("/users/:id", ctr.User.UpdateUser)
+	routerV1.POST("/users/:id/disable", ctr.User.DisableUser)
+	routerV1.POST("/users/:id/enable", ctr.User.EnableUser)
 	routerV1.POST("/jps", ctr.JP.CreateUserJP)
 	routerV1.POST("/jps/admin", ctr.JP.CreateAdminJP)
+	routerV1.PATCH("/jps/:jp_id", ctr.JP.UpdateJP)
+	routerV1.DELETE("/jps/:jp_id", ctr.JP.DeleteJP)
+	routerV1.POST("/jps/:jp_id/move", ctr.JP.MoveJP)
+	routerV1.POST("/jps/:jp_id/disable", ctr.JP.DisableJP)
+	routerV1.POST("/jps/:jp_id/enable", ctr.JP.EnableJP)
+	routerV1.GET("/jps/:jp_id/children", ctr.JP.GetChildJPs)
+	routerV1.GET("/jps/:jp_id/descendants", ctr.JP.GetDescendantJPs)
+	routerV1.GET("/jps/:jp_id/ancestors", ctr.JP.GetAncestorJPs)
+	routerV1.GET("/jps/:jp_id/tree", ctr.JP.GetJPTree)
+	routerV1.GET("/jps/:jp_id/roles", ctr.Role.GetJPRoles)
+	routerV1.POST("/jps/:jp_id/roles", ctr.Role.AssignRole)
+	routerV1.DELETE("/jps/:jp_id/roles/:role_id", ctr.Role.UnassignRole)
 	routerV1.GET("/user/jps", ctr.JP.GetUserJPs)
+	routerV1.GET("/roles", ctr.Role.GetRoles)
+	routerV1.POST("/roles", ctr.Role.CreateRole)
+	routerV1.DELETE("/roles/:role_id", ctr.Role.DeleteRole)
 	// Create an event.
 	// If response http code be 200, then return json as details field of the response.
 	routerV1.POST("/events", ctr.Event.CreateEvent)
 	routerV1.GET("/events", ctr.Event.GetNLastEventsByJPID)
+	routerV1.GET("/events/approved", ctr.Event.GetApprovedEvents)
+	routerV1.POST("/events/:event_id/approval", ctr.Event.ApproveEvent)
+	routerV1.DELETE("/events/:event_id/approval", ctr.Event.RevokeApproval)
+	routerV1.GET("/events/:event_id", ctr.Event.GetEvent)
+	routerV1.PATCH("/events/:event_id", ctr.Event.UpdateEvent)
+	routerV1.DELETE("/events/:event_id", ctr.Event.DeleteEvent)
+	routerV1.GET("/events/:event_id/revisions", ctr.Event.GetEventRevisions)
+	routerV1.GET("/events/:event_id/acl", ctr.Event.GetEventACLs)
+	routerV1.POST("/events/:event_id/acl", ctr.Event.GrantEventAccess)
+	routerV1.DELETE("/events/:event_id/acl/:acl_id", ctr.Event.RevokeEventAccess)
 	routerV1.POST("/docs", ctr.Doc.CreateDoc)
 	routerV1.GET("/docs", ctr.Doc.GetNLastDocs)
+	routerV1.GET("/docs/:doc_id", ctr.Doc.GetDoc)
+	routerV1.PATCH("/docs/:doc_id", ctr.Doc.UpdateDoc)
+	routerV1.DELETE("/docs/:doc_id", ctr.Doc.DeleteDoc)
+	routerV1.GET("/docs/:doc_id/versions", ctr.Doc.GetDocVersions)
+	routerV1.POST("/docs/:doc_id/versions/:version/restore", ctr.Doc.RestoreDocVersion)
 	routerV1.GET("/jps/:jp_id/events/:event_id/docs", ctr.Doc.GetNLastDocsByEventID)
+	routerV1.GET("/search", ctr.Search.Search)
 	routerV1.POST("/logout", ctr.Session.Logout)
+	routerV1.GET("/sessions", ctr.Session.GetSessions)
+	routerV1.DELETE("/sessions", ctr.Session.RevokeSessions)
+	routerV1.DELETE("/sessions/:session_id", ctr.Session.RevokeSession)
+	routerV1.GET("/audit", ctr.Audit.GetAuditEvents)
 	// router.GET("/users/:id", controller.GetUser)
 	// router.GET("/products", controllers.GetProducts) //Example of a different controller.
 }
@@ -45,9 +87,11 @@ func apiV1NeedAuth(router *gin.Engine, ctr c.HttpConrtoller) {
 func apiV1NeedNotAuth(router *gin.Engine, ctr c.HttpConrtoller) {
 	routerV1 := router.Group("api/v1")
 
-	// Admin is a sub-type of user entity
-	routerV1.POST("/users/admin", ctr.User.CreateAdmin)
-	routerV1.POST("login/phone-based", ctr.Session.PhoneBasedLogin)
+	routerV1.POST("login/phone-based/request-otp", ctr.Session.RequestPhoneOTP)
+	routerV1.POST("login/phone-based/verify", ctr.Session.PhoneBasedLogin)
+	routerV1.POST("/token/refresh", ctr.Session.RefreshSession)
+	// It's called by the file-transfer service and authenticated by its shared secret.
+	routerV1.POST("/uploads/confirm", ctr.Upload.ConfirmUpload)
 }
 
 // Check the healthy status of services
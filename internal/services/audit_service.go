@@ -0,0 +1,92 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+)
+
+type AuditService interface {
+	// Append the event to the audit log. If err is nil, the outcome of the event is success;
+	// otherwise it's failure and the error is appended to its details. Failing to store the
+	// event is just logged, so it never blocks the action.
+	Record(event *m.AuditEvent, err *e.Error)
+	// Return some last events of the audit log (at most limit events) that match the
+	// filter and come after the cursor, together with the cursor of the last returned
+	// event. Just events done with the job position or its nested childs are returned,
+	// unless the job position be admin. The job position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser
+	GetAuditEvents(userID, jpID m.ID, cursor *m.Cursor, limit uint64, filter *m.AuditFilter) (*[]m.AuditEvent, *m.Cursor, *e.Error)
+}
+
+// It's a simple implementation of AuditService interface.
+type sAuditService struct {
+	audit         dal.AuditDAL
+	jp            dal.JPDAL
+	authorization AuthorizationService
+	logger        l.Logger
+}
+
+func newSAuditService(audit dal.AuditDAL, jp dal.JPDAL, authorization AuthorizationService, logger l.Logger) AuditService {
+	return &sAuditService{audit, jp, authorization, logger}
+}
+
+func (s *sAuditService) Record(event *m.AuditEvent, err *e.Error) {
+	event.Outcome = m.AuditSuccess
+	if err != nil {
+		event.Outcome = m.AuditFailure
+		if event.Details == "" {
+			event.Details = err.Error()
+		} else {
+			event.Details += ": " + err.Error()
+		}
+	}
+	if err := s.audit.CreateAuditEvent(event); err != nil {
+		s.logger.Errorf("Failed to record audit event %+v: %s", *event, err.Error())
+	}
+}
+
+func (s *sAuditService) GetAuditEvents(userID, jpID m.ID, cursor *m.Cursor, limit uint64, filter *m.AuditFilter) (*[]m.AuditEvent, *m.Cursor, *e.Error) {
+	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, jpID); err != nil {
+		return nil, nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
+	} else if !isExistsUser {
+		return nil, nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
+			SEJPNotMatchedUser, userID.String(), jpID.String())
+	}
+
+	jpIDs, err := s.authorization.GetAccessibleJPs(jpID)
+	if err != nil {
+		return nil, nil, err.SetCode(SEDBError)
+	}
+	events, nextCursor, err2 := s.audit.GetAuditEvents(jpIDs, cursor, int(limit), filter)
+	if err2 != nil {
+		return nil, nil, e.NewErrorP(err2.Error(), SEDBError)
+	}
+	return events, nextCursor, nil
+}
+
+// Create an audit event of the action done by the user with the job position on the
+// target. Unknown ids could be nil ids.
+func newAuditEvent(action m.AuditAction, userID, jpID m.ID, targetType m.AuditTargetType, targetID m.ID,
+	client m.ClientInfo) *m.AuditEvent {
+	return &m.AuditEvent{
+		ActorUserID: userID,
+		ActorJPID:   jpID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		IP:          client.IP,
+		UserAgent:   client.UserAgent,
+	}
+}
+
+// Return the id, or nil id if it's nil. Ids created by the actions are nil on failure.
+func idOrNil(id *m.ID) m.ID {
+	if id == nil {
+		return m.NilID
+	}
+	return *id
+}
@@ -15,11 +15,28 @@ type AuthorizationService interface {
 	// Possible error codes:
 	// SEDBError
 	IsAncestor(ancestorID, nodeID m.ID) (bool, *e.Error)
-	// List of permissions of the given job position.
+	// Return true if the job position is allowed to do the action on the resource. resource
+	// is the job position the action is done on that or on its entities. (e.g. owner of the
+	// event that is approved) If it's NilID, the action isn't related to any job position.
+	// Admin job positions could do all actions. Other job positions must be allowed to do
+	// the action by their roles or inheritable roles of their ancestors and the resource
+	// must be the job position itself or one of its nested childs.
 	//
 	// Possible error codes:
 	// SEDBError
-	GetJPPermissions(jpID m.ID) (*m.Permission, *e.Error)
+	Can(jpID m.ID, action m.Action, resource m.ID) (bool, *e.Error)
+	// Return true if the access control list of the event grants the access to the user,
+	// the job position or one of the job positions that jpID is in their subtree. Read
+	// access is granted by contribute entries too. Expired entries are ignored.
+	//
+	// Possible error codes:
+	// SEDBError
+	HasEventAccess(userID, jpID, eventID m.ID, access m.EventAccess) (bool, *e.Error)
+	// Return the roles assigned to each of the given job positions.
+	//
+	// Possible error codes:
+	// SEDBError
+	GetJPsRoles(jpIDs []m.ID) (map[m.ID][]m.JPRole, *e.Error)
 	// List of all nested child job positions of the given job position.
 	//
 	// Possible error codes:
@@ -30,22 +47,38 @@ type AuthorizationService interface {
 	// Possible error codes:
 	// SEDBError
 	IsAdminJP(jpID m.ID) (bool, *e.Error)
+	// Return true if the given job position is allowed to approve (feature) the events
+	// created by eventOwnerID. It's the same as Can with ActionApproveEvent.
+	//
+	// Possible error codes:
+	// SEDBError
+	CanApproveEvent(jpID, eventOwnerID m.ID) (bool, *e.Error)
+	// Return the job positions that the given job position could access to their docs and
+	// events. i.e. the job position itself and if it's allowed to view its subtree, its
+	// nested childs. If the job position is admin, return nil which means it could access
+	// to all job positions.
+	//
+	// Possible error codes:
+	// SEDBError
+	GetAccessibleJPs(jpID m.ID) (*[]m.ID, *e.Error)
 }
 
 // It's a simple implementation of AuthorizationService interface.
 // This implementation has minimum functionalities.
 type sAuthorizationService struct {
-	hierarchy  hierarchy.HierarchyTree
-	permission dal.PermissionDAL
-	logger     l.Logger
+	hierarchy hierarchy.HierarchyTree
+	role      dal.RoleDAL
+	eventACL  dal.EventACLDAL
+	logger    l.Logger
 }
 
 // Create a new simple authorization service
-func newSAuthorizationService(hierarchy hierarchy.HierarchyTree, permission dal.PermissionDAL,
-	logger l.Logger) AuthorizationService {
+func newSAuthorizationService(hierarchy hierarchy.HierarchyTree, role dal.RoleDAL,
+	eventACL dal.EventACLDAL, logger l.Logger) AuthorizationService {
 	sPermission := &sAuthorizationService{
 		hierarchy,
-		permission,
+		role,
+		eventACL,
 		logger,
 	}
 	return sPermission
@@ -60,12 +93,72 @@ func (s *sAuthorizationService) IsAncestor(ancestorID, nodeID m.ID) (bool, *e.Er
 	return isAncestor, nil
 }
 
-func (s *sAuthorizationService) GetJPPermissions(jpID m.ID) (*m.Permission, *e.Error) {
-	permission, err := s.permission.GetPermissionsByJPID(jpID)
+func (s *sAuthorizationService) Can(jpID m.ID, action m.Action, resource m.ID) (bool, *e.Error) {
+	if isAdmin, err := s.IsAdminJP(jpID); err != nil {
+		return false, err
+	} else if isAdmin {
+		return true, nil
+	}
+
+	if !resource.IsNil() && resource != jpID {
+		if isAncestor, err := s.IsAncestor(jpID, resource); err != nil || !isAncestor {
+			return false, err
+		}
+	}
+
+	ancestors, err := s.getAncestorIDs(jpID)
+	if err != nil {
+		return false, err
+	}
+	hasAction, err2 := s.role.HasAction(jpID, ancestors, action)
+	if err2 != nil {
+		return false, e.NewErrorP(err2.Error(), SEDBError)
+	}
+	return hasAction, nil
+}
+
+func (s *sAuthorizationService) HasEventAccess(userID, jpID, eventID m.ID, access m.EventAccess) (bool, *e.Error) {
+	ancestors, err := s.getAncestorIDs(jpID)
+	if err != nil {
+		return false, err
+	}
+	accesses := []m.EventAccess{m.EventAccessContribute}
+	if access == m.EventAccessRead {
+		accesses = append(accesses, m.EventAccessRead)
+	}
+	// The job position itself is the root of its own subtree.
+	subtreeRoots := append(ancestors, jpID)
+	hasAccess, err2 := s.eventACL.HasEventAccess(eventID, userID, jpID, subtreeRoots, accesses)
+	if err2 != nil {
+		return false, e.NewErrorP(err2.Error(), SEDBError)
+	}
+	return hasAccess, nil
+}
+
+// Return ids of all ancestors of the job position.
+func (s *sAuthorizationService) getAncestorIDs(jpID m.ID) ([]m.ID, *e.Error) {
+	ancestorVertices, err := s.hierarchy.GetAncestors(id2Vertex(jpID))
+	if err != nil {
+		return nil, e.NewErrorP("failed to get ancestors of job position id %s: %s", SEDBError,
+			jpID.String(), err.Error())
+	}
+	ancestors := make([]m.ID, 0, len(ancestorVertices))
+	for _, vertex := range ancestorVertices {
+		id, err := vertex2ID(vertex)
+		if err != nil {
+			return nil, e.NewErrorP("failed to convert vertex %s to id: %s", SEDBError, vertex.String(), err.Error())
+		}
+		ancestors = append(ancestors, id)
+	}
+	return ancestors, nil
+}
+
+func (s *sAuthorizationService) GetJPsRoles(jpIDs []m.ID) (map[m.ID][]m.JPRole, *e.Error) {
+	roles, err := s.role.GetJPsRoles(jpIDs)
 	if err != nil {
 		return nil, e.NewErrorP(err.Error(), SEDBError)
 	}
-	return permission, nil
+	return roles, nil
 }
 
 func (s *sAuthorizationService) GetNestedChilds(jpID m.ID) ([]m.ID, *e.Error) {
@@ -95,3 +188,26 @@ func (s *sAuthorizationService) IsAdminJP(jpID m.ID) (bool, *e.Error) {
 	}
 	return result, nil
 }
+
+func (s *sAuthorizationService) CanApproveEvent(jpID, eventOwnerID m.ID) (bool, *e.Error) {
+	return s.Can(jpID, m.ActionApproveEvent, eventOwnerID)
+}
+
+func (s *sAuthorizationService) GetAccessibleJPs(jpID m.ID) (*[]m.ID, *e.Error) {
+	if isAdmin, err := s.IsAdminJP(jpID); err != nil {
+		return nil, err
+	} else if isAdmin {
+		return nil, nil
+	}
+	if canView, err := s.Can(jpID, m.ActionViewSubtree, m.NilID); err != nil {
+		return nil, err
+	} else if !canView {
+		return &[]m.ID{jpID}, nil
+	}
+
+	childJPIDs, err := s.GetNestedChilds(jpID)
+	if err != nil {
+		return nil, err.AppendBegin("can't fetch nested childs")
+	}
+	return &childJPIDs, nil
+}
@@ -1,35 +1,83 @@
 package services
 
 import (
-	"DMS/internal/common"
 	"DMS/internal/dal"
 	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
+	"fmt"
 )
 
 type DocService interface {
 	// Create document for specified event and job position in the current time and return its id.
-	// At this stage, just the user created the event, could create document for the event.
+	// Just the job position created the event and the ones the access list of the event
+	// grants them contribute access, could create document for the event. The job position
+	// must be allowed to create docs. The multimedia files must be allowed by the upload
+	// policy, have their sizes and the sizes must fit in the storage quotas.
 	//
 	// Possible error codes:
-	// SEDBError- SEIsDisabled- SEEventOwnerMismatched- SENotFound
+	// SEDBError- SEIsDisabled- SEEventOwnerMismatched- SENotFound- SENotPermission- SEWrongParameter-
+	// SEQuotaExceeded
 	// TODO: implement SEIsDisabled
-	CreateDoc(doc *m.Doc, userID m.ID) (*m.ID, *e.Error)
+	CreateDoc(doc *m.Doc, userID m.ID, client m.ClientInfo) (*m.ID, *e.Error)
 	// Return n last docs by event id iff job position id have permission to read
 	// docs of the event. If eventCreatedByID be nil, we fetch event creator id from
 	// the database so for better performance, it's better to pass it to avoid more
-	// database query. jpID is a job position id that belongs to the userID.
+	// database query. jpID is a job position id that belongs to the userID. If jpID is not
+	// the event creator, it must be allowed to view its subtree or the access list of the
+	// event must grant it read access.
 	//
 	// Possible error codes:
-	// SEDBError- SEJPNotMatchedUser- SENotAncestor
+	// SEDBError- SEJPNotMatchedUser- SENotAncestor- SENotPermission
 	GetNLastDocByEventID(eventID, userID m.ID, eventCreatedByID *m.ID, jpID m.ID, n int) (*[]m.Doc, *e.Error)
-	// Get some last documents (according to the limit and offset values) that are
-	// accessible for the job position. If the job position be admin, he accesses to all docs.
+	// Get some last documents (at most limit documents after the cursor) that are accessible
+	// for the job position and match the filter, together with the cursor of the next page.
+	// If there's not any more document, the returned cursor is nil. If the job position be
+	// admin, he accesses to all docs. If cursor be nil, start from the newest document. If
+	// filter be nil, no filter is applied.
 	//
 	// Possible error codes:
 	// SEDBError- SEJPNotMatchedUser
-	GetNLastDocs(userID, claimedJPID m.ID, limit, offset uint64) (*[]m.DocWithSomeDetails, *e.Error)
+	GetNLastDocs(userID, claimedJPID m.ID, cursor *m.Cursor, limit uint64, filter *m.ListFilter) (*[]m.DocWithSomeDetails, *m.Cursor, *e.Error)
+	// Return the doc together with name of its event and title of the job position
+	// created it. Just the creator of the doc, his ancestors that are allowed to view their
+	// subtree and the ones the access list of the doc's event grants them read access
+	// could read the doc. The job position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEDocNotFound- SENotAncestor- SENotPermission
+	GetDoc(userID, jpID, docID m.ID) (*m.DocWithSomeDetails, *e.Error)
+	// Edit context and/or multimedia files of the doc. The previous context and multimedia
+	// files are kept as a new version of the doc. Just the creator of the doc and his
+	// ancestors that are allowed to edit their subtree could edit the doc. The job position
+	// must belong to the user. The multimedia files could just be reordered or removed and
+	// they're matched with the confirmed files of the doc by their file names. The pending
+	// files of the doc are kept and new files are added just by uploading them.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEDocNotFound- SENotAncestor- SEEmpty- SEWrongParameter-
+	// SENotPermission
+	UpdateDoc(userID, jpID, docID m.ID, update *m.DocUpdate, client m.ClientInfo) *e.Error
+	// Soft delete the doc and its multimedia files. Just the creator of the doc and his
+	// ancestors that are allowed to edit their subtree could delete the doc. The job
+	// position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEDocNotFound- SENotAncestor- SENotPermission
+	DeleteDoc(userID, jpID, docID m.ID, client m.ClientInfo) *e.Error
+	// Return previous versions of the doc. The newest versions come first. Access rules
+	// are the same as GetDoc.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEDocNotFound- SENotAncestor- SENotPermission
+	GetDocVersions(userID, jpID, docID m.ID) (*[]m.DocVersion, *e.Error)
+	// Replace context and multimedia files of the doc with the ones in the specified
+	// version. The current state of the doc is kept as a new version, so restoring
+	// could be undone too. Restoring needs the same access as editing the doc.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEDocNotFound- SENotAncestor- SENotFound- SENotPermission
+	RestoreDocVersion(userID, jpID, docID m.ID, version uint, client m.ClientInfo) *e.Error
 }
 
 // It's a simple implementation of DocService interface.
@@ -40,23 +88,51 @@ type sDocService struct {
 	authorization AuthorizationService
 	event         EventService
 	jp            JPService
+	objectTokens  *objectTokenSigner
+	uploadPolicy  *uploadPolicy
+	audit         AuditService
+}
+
+func (s *sDocService) CreateDoc(doc *m.Doc, userID m.ID, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.createDoc(doc, userID)
+	s.audit.Record(newAuditEvent(m.AuditDocCreate, userID, doc.CreatedBy, m.AuditTargetDoc, idOrNil(id), client), err)
+	return id, err
 }
 
-func (s *sDocService) CreateDoc(doc *m.Doc, userID m.ID) (*m.ID, *e.Error) {
+func (s *sDocService) createDoc(doc *m.Doc, userID m.ID) (*m.ID, *e.Error) {
+	if err := s.checkPaths(doc.Paths); err != nil {
+		return nil, err
+	}
 	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, doc.CreatedBy); err != nil {
 		return nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
 	} else if !isExistsUser {
 		return nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
 			SENotFound, userID.String(), doc.CreatedBy.String())
 	}
-	// Just job position who created the event could create document for that.
+	// Just job position who created the event and the contributors of the event could
+	// create document for that.
 	if eventOwner, err := s.event.GetEventOwner(doc.EventID); err != nil {
 		return nil, e.NewErrorP(err.Error(), SEDBError)
 	} else if eventOwner == nil {
 		return nil, e.NewErrorP("Event with id %s not found", SEEventNotFound, doc.EventID.String())
 	} else if *eventOwner != doc.CreatedBy {
-		return nil, e.NewErrorP("The job position %s is not ownwe of the event %s",
-			SEEventOwnerMismatched, doc.CreatedBy.String(), doc.EventID.String())
+		hasAccess, err := s.authorization.HasEventAccess(userID, doc.CreatedBy, doc.EventID, m.EventAccessContribute)
+		if err != nil {
+			return nil, err
+		} else if !hasAccess {
+			return nil, e.NewErrorP("The job position %s is not ownwe or contributor of the event %s",
+				SEEventOwnerMismatched, doc.CreatedBy.String(), doc.EventID.String())
+		}
+	}
+	if can, err := s.authorization.Can(doc.CreatedBy, m.ActionCreateDoc, m.NilID); err != nil {
+		return nil, err
+	} else if !can {
+		return nil, e.NewErrorP("the job position %s is not allowed to create docs", SENotPermission,
+			doc.CreatedBy.String())
+	}
+
+	if err := s.checkQuotas(doc.EventID, doc.CreatedBy, doc.Paths); err != nil {
+		return nil, err
 	}
 
 	eventID, err := s.doc.CreateDoc(doc)
@@ -86,83 +162,359 @@ func (s *sDocService) GetNLastDocByEventID(eventID, userID m.ID, eventCreatedByI
 	// 		JPNotMatchedUser, jpID.ToString(), eventCreatedByID.ToString())
 	// }
 
-	// The jpID must be the same as or an ancestor of the event creator's id.
-	if isAncestor, err2 := s.authorization.IsAncestor(jpID, *eventCreatedByID); err2 != nil {
-		return nil, e.NewErrorP(err2.Error(), SEDBError)
-	} else if !isAncestor {
-		return nil, e.NewErrorP("you don't have permission to read docs of event with id %s",
-			SENotAncestor, eventID.String())
+	// The jpID must be the same as or an ancestor of the event creator's id. Otherwise,
+	// the access list of the event must grant it read access.
+	if accessErr := s.checkIsOwnerOrAncestor(jpID, *eventCreatedByID, eventID, m.ActionViewSubtree); accessErr != nil {
+		if accessErr.GetCode() == SEDBError {
+			return nil, accessErr
+		}
+		if hasAccess, err2 := s.authorization.HasEventAccess(userID, jpID, eventID, m.EventAccessRead); err2 != nil {
+			return nil, err2
+		} else if !hasAccess {
+			return nil, accessErr
+		}
 	}
 	docs, err := s.doc.GetNLastDocByEventID(eventID, n)
 	if err != nil {
 		return nil, e.NewErrorP(err.Error(), SEDBError)
 	}
+	for i := range *docs {
+		s.signDocPaths(&(*docs)[i])
+	}
 	return docs, nil
 }
 
-func (s *sDocService) GetNLastDocs(userID, claimedJPID m.ID, limit, offset uint64) (*[]m.DocWithSomeDetails, *e.Error) {
+func (s *sDocService) GetNLastDocs(userID, claimedJPID m.ID, cursor *m.Cursor, limit uint64, filter *m.ListFilter) (*[]m.DocWithSomeDetails, *m.Cursor, *e.Error) {
 	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, claimedJPID); err != nil {
-		return nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
+		return nil, nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
 	} else if !isExistsUser {
-		return nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
+		return nil, nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
 			SEJPNotMatchedUser, userID.String(), claimedJPID.String())
 	}
 
-	isAdmin, err2 := s.authorization.IsAdminJP(claimedJPID)
+	jpIDs, err2 := s.authorization.GetAccessibleJPs(claimedJPID)
 	if err2 != nil {
-		return nil, e.NewErrorP("error in checking if the job position %s is admin: %s", SEDBError, claimedJPID.String(), err2.Error())
-	}
-	if isAdmin {
+		return nil, nil, err2.SetCode(SEDBError)
+	} else if jpIDs == nil {
 		s.logger.Debugf("The job position %s is admin", claimedJPID.String())
-		docs, err := s.doc.GetNLastDocs(int(limit), int(offset))
-		if err != nil {
-			return nil, e.NewErrorP("failed to get some last docs (limit: %d, offset: %d): %s", SEDBError, limit, offset, err.Error())
-		}
-		s.logger.Debugf("Got %d docs. (job position is admin)", len(*docs))
-		return docs, nil
-	}
-
-	childJPIDs, err2 := s.authorization.GetNestedChilds(claimedJPID)
-	if err2 != nil {
-		return nil, e.NewErrorP("can't fetch nested childs: %s", SEDBError, err2.Error())
 	} else {
-		s.logger.Debugf("Fetched %d child job positions", len(childJPIDs))
-		maxToShow := min(len(childJPIDs), 10)
+		s.logger.Debugf("Fetched %d child job positions", len(*jpIDs))
+		maxToShow := min(len(*jpIDs), 10)
 		s.logger.Debugf("%d nested job positions for specified job position %s: %+v",
-			maxToShow, claimedJPID, childJPIDs[:maxToShow])
+			maxToShow, claimedJPID, (*jpIDs)[:maxToShow])
+	}
+
+	docs, nextCursor, err := s.doc.GetNLastDocs(jpIDs, cursor, int(limit), filter)
+	if err != nil {
+		return nil, nil, e.NewErrorP("failed to get some last docs (limit: %d): %s", SEDBError, limit, err.Error())
+	}
+	s.logger.Debugf("Got %d docs", len(*docs))
+	for i := range *docs {
+		s.signDocPaths(&(*docs)[i].Doc)
+	}
+	return docs, nextCursor, nil
+}
+
+func (s *sDocService) GetDoc(userID, jpID, docID m.ID) (*m.DocWithSomeDetails, *e.Error) {
+	if err := s.checkUserJP(userID, jpID); err != nil {
+		return nil, err
+	}
+	doc, err := s.doc.GetDocWithDetailsByID(docID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if doc == nil {
+		return nil, e.NewErrorP("doc with id %s not found", SEDocNotFound, docID.String())
+	}
+	if err := s.checkIsCreatorOrAncestor(userID, jpID, &doc.Doc, m.ActionViewSubtree); err != nil {
+		return nil, err
 	}
+	s.signDocPaths(&doc.Doc)
+	return doc, nil
+}
+
+func (s *sDocService) UpdateDoc(userID, jpID, docID m.ID, update *m.DocUpdate, client m.ClientInfo) *e.Error {
+	err := s.updateDoc(userID, jpID, docID, update)
+	s.audit.Record(newAuditEvent(m.AuditDocUpdate, userID, jpID, m.AuditTargetDoc, docID, client), err)
+	return err
+}
 
-	docGroups := make([]*[]m.DocWithSomeDetails, 0)
-	for _, childJPID := range childJPIDs {
-		// TODO: Optimize and edit the number of documents to fetch from each job position id
-		// such that improve performance.
-		docs, err := s.doc.GetNLastDocsByJPID(childJPID, int(offset), int(limit))
+func (s *sDocService) updateDoc(userID, jpID, docID m.ID, update *m.DocUpdate) *e.Error {
+	if update.Context == nil && update.Paths == nil {
+		return e.NewErrorP("there's nothing to update in doc %s", SEEmpty, docID.String())
+	}
+	doc, err := s.checkDocAccess(userID, jpID, docID, m.ActionEditSubtree)
+	if err != nil {
+		return err
+	}
+	if update.Paths != nil {
+		paths, err := arrangeDocPaths(doc, *update.Paths)
 		if err != nil {
-			return nil, e.NewErrorP("failed to get %s docs with offset %s for child job position: %s",
-				SEDBError, limit, offset, err.Error())
+			return err
 		}
-		docGroups = append(docGroups, docs)
+		update = &m.DocUpdate{Context: update.Context, Paths: &paths}
 	}
+	return s.saveDocUpdate(jpID, docID, update)
+}
 
-	orderedDocs := common.MergeOrderedGroups(&docGroups, func(a, b m.DocWithSomeDetails) bool {
-		return a.CreatedAt < b.CreatedAt
+// Return the confirmed files of the doc in the order of the given paths followed by
+// the pending files of the doc. Stored details of the files are used and just the file
+// names of the paths are considered, so files of the doc could be reordered or removed
+// but not added or changed.
+//
+// Possible error codes:
+// SEWrongParameter
+func arrangeDocPaths(doc *m.Doc, paths []m.MediaPath) ([]m.MediaPath, *e.Error) {
+	confirmed := make(map[string]m.MediaPath)
+	for _, media := range doc.Paths {
+		if media.Status == m.MediaConfirmed {
+			confirmed[media.FileName] = media
+		}
+	}
+	arranged := make([]m.MediaPath, 0, len(doc.Paths))
+	for _, path := range paths {
+		media, ok := confirmed[path.FileName]
+		if !ok {
+			return nil, e.NewErrorP("file %s is not a confirmed file of doc %s, new files are added by uploading them",
+				SEWrongParameter, path.FileName, doc.ID.String())
+		}
+		delete(confirmed, path.FileName)
+		arranged = append(arranged, media)
+	}
+	for _, media := range doc.Paths {
+		if media.Status == m.MediaPending {
+			arranged = append(arranged, media)
+		}
+	}
+	return arranged, nil
+}
+
+func (s *sDocService) DeleteDoc(userID, jpID, docID m.ID, client m.ClientInfo) *e.Error {
+	err := s.deleteDoc(userID, jpID, docID)
+	s.audit.Record(newAuditEvent(m.AuditDocDelete, userID, jpID, m.AuditTargetDoc, docID, client), err)
+	return err
+}
+
+func (s *sDocService) deleteDoc(userID, jpID, docID m.ID) *e.Error {
+	if _, err := s.checkDocAccess(userID, jpID, docID, m.ActionEditSubtree); err != nil {
+		return err
+	}
+
+	isDeleted, err := s.doc.DeleteDoc(docID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isDeleted {
+		return e.NewErrorP("doc with id %s not found", SEDocNotFound, docID.String())
+	}
+	return nil
+}
+
+func (s *sDocService) GetDocVersions(userID, jpID, docID m.ID) (*[]m.DocVersion, *e.Error) {
+	if _, err := s.checkDocAccess(userID, jpID, docID, m.ActionViewSubtree); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.doc.GetDocVersions(docID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return versions, nil
+}
+
+func (s *sDocService) RestoreDocVersion(userID, jpID, docID m.ID, version uint, client m.ClientInfo) *e.Error {
+	err := s.restoreDocVersion(userID, jpID, docID, version)
+	event := newAuditEvent(m.AuditDocRestore, userID, jpID, m.AuditTargetDoc, docID, client)
+	event.Details = fmt.Sprintf("version %d", version)
+	s.audit.Record(event, err)
+	return err
+}
+
+func (s *sDocService) restoreDocVersion(userID, jpID, docID m.ID, version uint) *e.Error {
+	if _, err := s.checkDocAccess(userID, jpID, docID, m.ActionEditSubtree); err != nil {
+		return err
+	}
+
+	docVersion, err := s.doc.GetDocVersion(docID, version)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if docVersion == nil {
+		return e.NewErrorP("version %d of doc %s not found", SENotFound, version, docID.String())
+	}
+	return s.saveDocUpdate(jpID, docID, &m.DocUpdate{
+		Context: docVersion.Context,
+		Paths:   &docVersion.Paths,
 	})
-	if len(*orderedDocs) < int(limit) {
-		return orderedDocs, nil
-	} else {
-		limitedDocs := (*orderedDocs)[:limit]
-		return &limitedDocs, nil
+}
+
+func (s *sDocService) saveDocUpdate(jpID, docID m.ID, update *m.DocUpdate) *e.Error {
+	isUpdated, err := s.doc.UpdateDoc(docID, jpID, update)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isUpdated {
+		return e.NewErrorP("doc with id %s not found", SEDocNotFound, docID.String())
 	}
+	return nil
+}
+
+// Check the job position belongs to the user and he is the creator of the doc or an
+// ancestor of him that is allowed to do the action on its subtree. Return the doc if
+// the checks passed.
+//
+// Possible error codes:
+// SEDBError- SEJPNotMatchedUser- SEDocNotFound- SENotAncestor- SENotPermission
+func (s *sDocService) checkDocAccess(userID, jpID, docID m.ID, action m.Action) (*m.Doc, *e.Error) {
+	if err := s.checkUserJP(userID, jpID); err != nil {
+		return nil, err
+	}
+	doc, err := s.doc.GetDocByID(docID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if doc == nil {
+		return nil, e.NewErrorP("doc with id %s not found", SEDocNotFound, docID.String())
+	}
+	if err := s.checkIsCreatorOrAncestor(userID, jpID, doc, action); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Check the job position belongs to the user.
+//
+// Possible error codes:
+// SEDBError- SEJPNotMatchedUser
+func (s *sDocService) checkUserJP(userID, jpID m.ID) *e.Error {
+	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, jpID); err != nil {
+		return e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
+	} else if !isExistsUser {
+		return e.NewErrorP("there's not any user with id %s that have job position id %s",
+			SEJPNotMatchedUser, userID.String(), jpID.String())
+	}
+	return nil
+}
+
+// Check the job position is the creator of the doc or an ancestor of him that is
+// allowed to do the action on its subtree. Others could read the doc if the access list
+// of its event grants them read access.
+//
+// Possible error codes:
+// SEDBError- SENotAncestor- SENotPermission
+func (s *sDocService) checkIsCreatorOrAncestor(userID, jpID m.ID, doc *m.Doc, action m.Action) *e.Error {
+	accessErr := s.checkIsOwnerOrAncestor(jpID, doc.CreatedBy, doc.ID, action)
+	if accessErr == nil || accessErr.GetCode() == SEDBError || action != m.ActionViewSubtree {
+		return accessErr
+	}
+
+	if hasAccess, err := s.authorization.HasEventAccess(userID, jpID, doc.EventID, m.EventAccessRead); err != nil {
+		return err
+	} else if !hasAccess {
+		return accessErr
+	}
+	return nil
+}
+
+// Check the job position is the owner or an ancestor of the owner. If the job position
+// is an ancestor, it must be allowed to do the action on its subtree. resourceID is the
+// id of the doc or event and is just used in the error messages.
+//
+// Possible error codes:
+// SEDBError- SENotAncestor- SENotPermission
+func (s *sDocService) checkIsOwnerOrAncestor(jpID, ownerID, resourceID m.ID, action m.Action) *e.Error {
+	if isAncestor, err := s.authorization.IsAncestor(jpID, ownerID); err != nil {
+		return err.SetCode(SEDBError)
+	} else if !isAncestor {
+		return e.NewErrorP("the job position %s is not the owner of %s or his ancestor",
+			SENotAncestor, jpID.String(), resourceID.String())
+	}
+	if jpID == ownerID {
+		return nil
+	}
+	if can, err := s.authorization.Can(jpID, action, ownerID); err != nil {
+		return err
+	} else if !can {
+		return e.NewErrorP("the job position %s is not allowed to do %s on %s", SENotPermission,
+			jpID.String(), action, resourceID.String())
+	}
+	return nil
 }
 
 // Create an instance of sDocService struct
 func newSDocService(doc dal.DocDAL, permissionService AuthorizationService, eventService EventService,
-	jpService JPService, logger l.Logger) DocService {
+	jpService JPService, objectTokens *objectTokenSigner, uploadPolicy *uploadPolicy, audit AuditService,
+	logger l.Logger) DocService {
 	return &sDocService{
 		doc,
 		logger,
 		permissionService,
 		eventService,
 		jpService,
+		objectTokens,
+		uploadPolicy,
+		audit,
+	}
+}
+
+// Check the multimedia files of a doc sent by the client are allowed by the upload policy
+// and mark them as pending. They're confirmed when the file-transfer service stores them.
+//
+// Possible error codes:
+// SEWrongParameter
+func (s *sDocService) checkPaths(paths []m.MediaPath) *e.Error {
+	for i := range paths {
+		if paths[i].Size == 0 {
+			return e.NewErrorP("size of file %s is required", SEWrongParameter, paths[i].FileName)
+		}
+		if err := s.uploadPolicy.checkMedia(&paths[i]); err != nil {
+			return e.NewErrorP(err.Error(), SEWrongParameter)
+		}
+		paths[i].Status = m.MediaPending
+		paths[i].Checksum = ""
+		paths[i].MimeType = ""
+	}
+	return nil
+}
+
+// Check the declared sizes of the files of the new doc together with the confirmed files
+// of the event and the job position created the doc don't exceed their storage quotas.
+//
+// Possible error codes:
+// SEDBError- SEQuotaExceeded
+func (s *sDocService) checkQuotas(eventID, creatorID m.ID, paths []m.MediaPath) *e.Error {
+	if s.uploadPolicy.eventQuota == 0 && s.uploadPolicy.jpQuota == 0 {
+		return nil
+	}
+	var declaredKB uint64
+	for _, media := range paths {
+		declaredKB += media.Size
+	}
+	if declaredKB == 0 {
+		return nil
+	}
+
+	var usedEventKB, usedJPKB uint64
+	var err error
+	if s.uploadPolicy.eventQuota > 0 {
+		if usedEventKB, err = s.doc.GetEventMediaSize(eventID); err != nil {
+			return e.NewErrorP(err.Error(), SEDBError)
+		}
+	}
+	if s.uploadPolicy.jpQuota > 0 {
+		if usedJPKB, err = s.doc.GetJPMediaSize(creatorID); err != nil {
+			return e.NewErrorP(err.Error(), SEDBError)
+		}
+	}
+	if remaining, _ := s.uploadPolicy.remainingQuota(usedEventKB, usedJPKB); declaredKB > remaining {
+		return e.NewErrorP("new files need %d KB but %d KB is left of the storage quota", SEQuotaExceeded,
+			declaredKB, remaining)
+	}
+	return nil
+}
+
+// Set object tokens of the confirmed multimedia files of the doc, so the files could be
+// downloaded from the file-transfer service.
+func (s *sDocService) signDocPaths(doc *m.Doc) {
+	for i := range doc.Paths {
+		if doc.Paths[i].Status != m.MediaConfirmed {
+			continue
+		}
+		doc.Paths[i].ObjectToken = s.objectTokens.sign(doc.ID, doc.Paths[i].FileName)
 	}
 }
@@ -0,0 +1,304 @@
+package services
+
+import (
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDocCheckPaths(t *testing.T) {
+	service := &sDocService{uploadPolicy: &uploadPolicy{
+		extensions: map[models.FileExtension]models.MediaType{"jpg": models.MediaImage},
+		maxSizes:   map[models.MediaType]uint64{models.MediaImage: 100},
+	}}
+
+	tests := []struct {
+		name    string
+		paths   []models.MediaPath
+		isValid bool
+	}{
+		{name: "file with size is valid", paths: []models.MediaPath{{Type: models.MediaImage, FileName: "a.jpg", Size: 10}}, isValid: true},
+		{name: "file without size is invalid", paths: []models.MediaPath{{Type: models.MediaImage, FileName: "a.jpg"}}, isValid: false},
+		{name: "large file is invalid", paths: []models.MediaPath{{Type: models.MediaImage, FileName: "a.jpg", Size: 101}}, isValid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := service.checkPaths(test.paths)
+			if test.isValid && err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			} else if !test.isValid && (err == nil || err.GetCode() != SEWrongParameter) {
+				t.Fatalf("expected error code %d, got %v", SEWrongParameter, err)
+			}
+			if test.isValid && test.paths[0].Status != models.MediaPending {
+				t.Errorf("expected pending file, got %+v", test.paths[0])
+			}
+		})
+	}
+}
+
+func TestDocCheckQuotas(t *testing.T) {
+	eventID, jpID := models.ID(uuid.New()), models.ID(uuid.New())
+
+	tests := []struct {
+		name       string
+		eventQuota uint64
+		usedKB     uint64
+		paths      []models.MediaPath
+		isValid    bool
+	}{
+		{name: "unlimited quota", usedKB: 1000, paths: []models.MediaPath{{FileName: "c.jpg", Size: 60}}, isValid: true},
+		{name: "new files fit in the quota", eventQuota: 100, usedKB: 40, paths: []models.MediaPath{{FileName: "c.jpg", Size: 60}}, isValid: true},
+		{name: "new files exceed the quota", eventQuota: 100, usedKB: 50, paths: []models.MediaPath{{FileName: "c.jpg", Size: 60}}, isValid: false},
+		{
+			name: "all files are counted", eventQuota: 100, usedKB: 10,
+			paths: []models.MediaPath{{FileName: "a.jpg", Size: 60}, {FileName: "b.jpg", Size: 60}}, isValid: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &sDocService{
+				doc:          &memDocDAL{usedKB: test.usedKB},
+				uploadPolicy: &uploadPolicy{eventQuota: test.eventQuota},
+				logger:       l.NewSLogger(l.None, nil, io.Discard),
+			}
+			err := service.checkQuotas(eventID, jpID, test.paths)
+			if test.isValid && err != nil {
+				t.Errorf("unexpected error %s", err.Error())
+			} else if !test.isValid && (err == nil || err.GetCode() != SEQuotaExceeded) {
+				t.Errorf("expected error code %d, got %v", SEQuotaExceeded, err)
+			}
+		})
+	}
+}
+
+func (d *memDocDAL) UpdateDoc(docID, editorJPID models.ID, update *models.DocUpdate) (bool, error) {
+	doc, ok := d.docs[docID]
+	if !ok {
+		return false, nil
+	}
+	d.versions = append(d.versions, models.DocVersion{Version: uint(len(d.versions)) + 1, Context: doc.Context,
+		Paths: doc.Paths, EditedBy: editorJPID})
+	if update.Context != nil {
+		doc.Context = update.Context
+	}
+	if update.Paths != nil {
+		doc.Paths = *update.Paths
+	}
+	return true, nil
+}
+
+func (d *memDocDAL) DeleteDoc(docID models.ID) (bool, error) {
+	if _, ok := d.docs[docID]; !ok {
+		return false, nil
+	}
+	delete(d.docs, docID)
+	return true, nil
+}
+
+func (d *memDocDAL) GetDocVersion(docID models.ID, version uint) (*models.DocVersion, error) {
+	if _, ok := d.docs[docID]; !ok || version < 1 || version > uint(len(d.versions)) {
+		return nil, nil
+	}
+	return &d.versions[version-1], nil
+}
+
+// Assign a new role to the job position that allows editing its subtree.
+func assignEditorRole(roleDAL *memRoleDAL, jpID models.ID) {
+	editorRole := models.ID(uuid.New())
+	roleDAL.roles[editorRole] = models.Role{ID: editorRole, Name: "editor",
+		Actions: []models.Action{models.ActionEditSubtree}}
+	roleDAL.AssignRole(jpID, editorRole, false)
+}
+
+// Return a doc service over the job positions of the role fixture together with a doc
+// created by the child job position. It has confirmed files a.jpg and b.jpg and pending
+// file c.jpg. If isEditor is true, the manager is allowed to edit its subtree.
+func newDocEditTestService(t *testing.T, isEditor bool) (*roleFixture, *sDocService, *memDocDAL, models.ID) {
+	t.Helper()
+	f, authorization, roleDAL := newRoleFixture(t)
+	if isEditor {
+		assignEditorRole(roleDAL, f.manager)
+	}
+	docID, context := models.ID(uuid.New()), "context"
+	docDAL := &memDocDAL{docs: map[models.ID]*models.Doc{docID: {
+		ID: docID, EventID: models.ID(uuid.New()), CreatedBy: f.child, Context: &context,
+		Paths: []models.MediaPath{
+			{FileName: "a.jpg", Src: "a.jpg", Size: 10, Checksum: "a", Status: models.MediaConfirmed},
+			{FileName: "b.jpg", Src: "b.jpg", Size: 20, Checksum: "b", Status: models.MediaConfirmed},
+			{FileName: "c.jpg", Src: "c.jpg", Size: 30, Status: models.MediaPending},
+		},
+	}}}
+	jpService := &memEventJPService{jps: map[models.ID]bool{}}
+	for _, jpID := range []models.ID{f.admin, f.manager, f.child, f.grandchild, f.sibling} {
+		jpService.jps[jpID] = true
+	}
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	service := newSDocService(docDAL, authorization, nil, jpService, nil, &uploadPolicy{},
+		newSAuditService(&memAuditDAL{}, nil, nil, logger), logger).(*sDocService)
+	return f, service, docDAL, docID
+}
+
+// Callers of editing and deleting a doc or an event created by the child job position.
+// If isEditor is true, the manager is allowed to edit its subtree.
+var editorTests = []struct {
+	name     string
+	isEditor bool
+	editor   func(f *roleFixture) models.ID
+	// Expected error code. If it's nil, the caller must be allowed.
+	errCode any
+}{
+	{name: "creator of the doc", editor: func(f *roleFixture) models.ID { return f.child }},
+	{name: "admin", editor: func(f *roleFixture) models.ID { return f.admin }},
+	{name: "ancestor allowed to edit the subtree", isEditor: true, editor: func(f *roleFixture) models.ID { return f.manager }},
+	{name: "ancestor not allowed to edit the subtree", editor: func(f *roleFixture) models.ID { return f.manager },
+		errCode: SENotPermission},
+	{name: "descendant of the creator", editor: func(f *roleFixture) models.ID { return f.grandchild },
+		errCode: SENotAncestor},
+	{name: "job position out of the subtree", editor: func(f *roleFixture) models.ID { return f.sibling },
+		errCode: SENotAncestor},
+}
+
+func TestUpdateDocCallers(t *testing.T) {
+	for _, test := range editorTests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, docDAL, docID := newDocEditTestService(t, test.isEditor)
+			editor := test.editor(f)
+			context := "edited"
+			err := service.UpdateDoc(editor, editor, docID, &models.DocUpdate{Context: &context}, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				if *docDAL.docs[docID].Context != context || len(docDAL.versions) != 1 || docDAL.versions[0].EditedBy != editor {
+					t.Errorf("expected the doc to be edited and its previous version to be kept, got %+v %+v",
+						docDAL.docs[docID], docDAL.versions)
+				}
+				return
+			}
+			if err == nil || err.GetCode() != test.errCode {
+				t.Fatalf("expected error code %v, got %v", test.errCode, err)
+			}
+			if *docDAL.docs[docID].Context != "context" || len(docDAL.versions) != 0 {
+				t.Errorf("expected the doc not to be edited")
+			}
+		})
+	}
+}
+
+func TestDeleteDocCallers(t *testing.T) {
+	for _, test := range editorTests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, docDAL, docID := newDocEditTestService(t, test.isEditor)
+			editor := test.editor(f)
+			err := service.DeleteDoc(editor, editor, docID, models.ClientInfo{})
+			if test.errCode == nil && err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			} else if test.errCode != nil && (err == nil || err.GetCode() != test.errCode) {
+				t.Fatalf("expected error code %v, got %v", test.errCode, err)
+			}
+			if _, isKept := docDAL.docs[docID]; isKept != (test.errCode != nil) {
+				t.Errorf("expected the doc to be deleted just by the allowed callers")
+			}
+		})
+	}
+}
+
+func TestRestoreDocVersionCallers(t *testing.T) {
+	for _, test := range editorTests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, docDAL, docID := newDocEditTestService(t, test.isEditor)
+			context := "edited"
+			if err := service.UpdateDoc(f.child, f.child, docID, &models.DocUpdate{Context: &context},
+				models.ClientInfo{}); err != nil {
+				t.Fatalf("failed to edit the doc: %s", err.Error())
+			}
+			editor := test.editor(f)
+			err := service.RestoreDocVersion(editor, editor, docID, 1, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				if *docDAL.docs[docID].Context != "context" || len(docDAL.versions) != 2 {
+					t.Errorf("expected the first version to be restored and the edited one to be kept, got %+v %+v",
+						docDAL.docs[docID], docDAL.versions)
+				}
+				return
+			}
+			if err == nil || err.GetCode() != test.errCode {
+				t.Fatalf("expected error code %v, got %v", test.errCode, err)
+			}
+			if *docDAL.docs[docID].Context != context || len(docDAL.versions) != 1 {
+				t.Errorf("expected the doc not to be restored")
+			}
+		})
+	}
+
+	t.Run("missing version", func(t *testing.T) {
+		f, service, _, docID := newDocEditTestService(t, false)
+		if err := service.RestoreDocVersion(f.child, f.child, docID, 1, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SENotFound {
+			t.Errorf("expected error code %d, got %v", SENotFound, err)
+		}
+	})
+}
+
+func TestUpdateDocPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []models.MediaPath
+		// File names of the doc after the update. If it's nil, the update must be rejected.
+		expected []string
+	}{
+		{name: "confirmed files are reordered", paths: []models.MediaPath{{FileName: "b.jpg"}, {FileName: "a.jpg"}},
+			expected: []string{"b.jpg", "a.jpg", "c.jpg"}},
+		{name: "confirmed file is removed", paths: []models.MediaPath{{FileName: "b.jpg"}},
+			expected: []string{"b.jpg", "c.jpg"}},
+		{name: "all confirmed files are removed", paths: []models.MediaPath{}, expected: []string{"c.jpg"}},
+		{name: "new file is rejected", paths: []models.MediaPath{{FileName: "a.jpg"}, {FileName: "d.jpg", Size: 10}}},
+		{name: "pending file is rejected", paths: []models.MediaPath{{FileName: "c.jpg"}}},
+		{name: "duplicate file is rejected", paths: []models.MediaPath{{FileName: "a.jpg"}, {FileName: "a.jpg"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, docDAL, docID := newDocEditTestService(t, false)
+			err := service.UpdateDoc(f.child, f.child, docID, &models.DocUpdate{Paths: &test.paths}, models.ClientInfo{})
+			if test.expected == nil {
+				if err == nil || err.GetCode() != SEWrongParameter {
+					t.Fatalf("expected error code %d, got %v", SEWrongParameter, err)
+				}
+				if len(docDAL.docs[docID].Paths) != 3 {
+					t.Errorf("expected the files of the doc not to be changed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			paths := docDAL.docs[docID].Paths
+			if len(paths) != len(test.expected) {
+				t.Fatalf("expected files %v, got %+v", test.expected, paths)
+			}
+			for i, fileName := range test.expected {
+				if paths[i].FileName != fileName {
+					t.Errorf("expected files %v, got %+v", test.expected, paths)
+				}
+			}
+		})
+	}
+
+	t.Run("stored details of the files are kept", func(t *testing.T) {
+		f, service, docDAL, docID := newDocEditTestService(t, false)
+		paths := []models.MediaPath{{FileName: "a.jpg", Src: "other-doc/x.jpg", Size: 1, Checksum: "x"}}
+		if err := service.UpdateDoc(f.child, f.child, docID, &models.DocUpdate{Paths: &paths},
+			models.ClientInfo{}); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		media := docDAL.docs[docID].Paths[0]
+		if media.Src != "a.jpg" || media.Size != 10 || media.Checksum != "a" || media.Status != models.MediaConfirmed {
+			t.Errorf("expected the stored details of a.jpg, got %+v", media)
+		}
+	})
+}
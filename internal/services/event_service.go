@@ -5,16 +5,18 @@ import (
 	e "DMS/internal/error"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
+	"fmt"
+	"strings"
 	"time"
 )
 
 type EventService interface {
 	// Create event for specified job position and return its event ID. (In the current time)
-	// Each job position could create an event.
+	// The job position must be allowed to create events.
 	//
 	// Possible error codes:
-	// SEDBError- SENotFound
-	CreateEvent(event m.Event, userID m.ID) (*m.ID, *e.Error)
+	// SEDBError- SENotFound- SENotPermission
+	CreateEvent(event m.Event, userID m.ID, client m.ClientInfo) (*m.ID, *e.Error)
 	// Return job position id that created the event. He's owner of specified event.
 	// If no error occurs and returned event id is nil, then there is no corresponding
 	// event with this id.
@@ -22,33 +24,116 @@ type EventService interface {
 	// Possible error codes:
 	// SEDBError
 	GetEventOwner(eventID m.ID) (*m.ID, *e.Error)
-	// Get some last events (according to the limit and offset values) that are
-	// created by specified job position. If the job position be admin, return all events.
-	// if limit be equals 0, then return all events from offset to the end.
+	// Get some last events (at most limit events after the cursor) that are created by
+	// specified job position and match the filter, together with the cursor of the next page.
+	// If there's not any more event, the returned cursor is nil. If the job position be
+	// admin, return all events that match the filter. If cursor be nil, start from the
+	// newest event. If filter be nil, no filter is applied.
 	//
 	// Possible error codes:
 	// SEDBError- SEJPNotMatchedUser
-	GetNLastEventsByJPID(userID, claimedJPID m.ID, limit, offset uint64) (*[]m.Event, *e.Error)
+	GetNLastEventsByJPID(userID, claimedJPID m.ID, cursor *m.Cursor, limit uint64, filter *m.ListFilter) (*[]m.Event, *m.Cursor, *e.Error)
+	// Approve (feature) the event by the job position and return id of the approval.
+	// The job position must belong to the user and be allowed to approve the event.
+	// Approving an event that is approved previously by the job position is not an error.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SENotPermission
+	ApproveEvent(userID, jpID, eventID m.ID, client m.ClientInfo) (*m.ID, *e.Error)
+	// Revoke the approval of the event that is made previously by the job position.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound
+	RevokeApproval(userID, jpID, eventID m.ID, client m.ClientInfo) *e.Error
+	// Get some last approved events (according to the limit and offset values) that are
+	// created by the job position or his nested childs and match the filter. If the job
+	// position be admin, return approved events of all job positions. If filter be nil,
+	// no filter is applied.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser
+	ListApprovedEvents(userID, claimedJPID m.ID, limit, offset uint64, filter *m.ListFilter) (*[]m.EventWithApproval, *e.Error)
+	// Return the event. Just the owner of the event, his ancestors that are allowed to
+	// view their subtree and the ones the access list of the event grants them could read
+	// the event. The job position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SENotAncestor- SENotPermission
+	GetEvent(userID, jpID, eventID m.ID) (*m.Event, *e.Error)
+	// Edit name and/or description of the event and record the edit as a revision. Just
+	// the owner of the event and his ancestors that are allowed to edit their subtree
+	// could edit the event. The job position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SENotAncestor- SEEmpty- SEWrongParameter-
+	// SENotPermission
+	UpdateEvent(userID, jpID, eventID m.ID, update *m.EventUpdate, client m.ClientInfo) *e.Error
+	// Soft delete the event together with its docs. Just the owner of the event and his
+	// ancestors that are allowed to edit their subtree could delete the event. The job
+	// position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SENotAncestor- SENotPermission
+	DeleteEvent(userID, jpID, eventID m.ID, client m.ClientInfo) *e.Error
+	// Return edit history of the event. Access rules are the same as GetEvent.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SENotAncestor- SENotPermission
+	GetEventRevisions(userID, jpID, eventID m.ID) (*[]m.EventRevision, *e.Error)
+	// Grant an access on the event to a job position, a user or a subtree and return id
+	// of the access list entry. Just the owner of the event and admins could grant
+	// accesses. jpID must belong to the user. The granted job position (or root of the
+	// granted subtree) must exist.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SEEventOwnerMismatched- SEWrongParameter
+	GrantEventAccess(userID, jpID, eventID m.ID, acl *m.EventACL, client m.ClientInfo) (*m.ID, *e.Error)
+	// Return the access list of the event. Just the owner of the event and admins could
+	// read it.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SEEventOwnerMismatched
+	GetEventACLs(userID, jpID, eventID m.ID) (*[]m.EventACL, *e.Error)
+	// Revoke the entry of the access list of the event. Just the owner of the event and
+	// admins could revoke accesses.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SEEventOwnerMismatched- SENotFound
+	RevokeEventAccess(userID, jpID, eventID, aclID m.ID, client m.ClientInfo) *e.Error
 }
 
 // It's a simple implementation of EventService interface.
 // This implementation has minimum functionalities.
 type sEventService struct {
 	event         dal.EventDAL
+	eventACL      dal.EventACLDAL
 	jp            JPService
 	authorization AuthorizationService
+	audit         AuditService
 	logger        l.Logger
 }
 
 // Possible error codes:
 // DBError
-func (s *sEventService) CreateEvent(event m.Event, userID m.ID) (*m.ID, *e.Error) {
+func (s *sEventService) CreateEvent(event m.Event, userID m.ID, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.createEvent(event, userID)
+	s.audit.Record(newAuditEvent(m.AuditEventCreate, userID, event.CreatedBy, m.AuditTargetEvent, idOrNil(id), client), err)
+	return id, err
+}
+
+func (s *sEventService) createEvent(event m.Event, userID m.ID) (*m.ID, *e.Error) {
 	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, event.CreatedBy); err != nil {
 		return nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
 	} else if !isExistsUser {
 		return nil, e.NewErrorP("There's not any user with id %s have job position id %s",
 			SENotFound, userID.String(), event.CreatedBy.String())
 	}
+	if can, err := s.authorization.Can(event.CreatedBy, m.ActionCreateEvent, m.NilID); err != nil {
+		return nil, err
+	} else if !can {
+		return nil, e.NewErrorP("the job position %s is not allowed to create events", SENotPermission,
+			event.CreatedBy.String())
+	}
 
 	newEvent := m.Event{
 		Name:        event.Name,
@@ -74,42 +159,338 @@ func (s *sEventService) GetEventOwner(eventID m.ID) (*m.ID, *e.Error) {
 	return &event.CreatedBy, nil
 }
 
-func (s *sEventService) GetNLastEventsByJPID(userID, claimedJPID m.ID, limit, offset uint64) (*[]m.Event, *e.Error) {
+func (s *sEventService) GetNLastEventsByJPID(userID, claimedJPID m.ID, cursor *m.Cursor, limit uint64, filter *m.ListFilter) (*[]m.Event, *m.Cursor, *e.Error) {
 	isAdmin, err2 := s.authorization.IsAdminJP(claimedJPID)
 	if err2 != nil {
-		return nil, e.NewErrorP("error in checking if the job position %s is admin: %s", SEDBError, claimedJPID.String(), err2.Error())
+		return nil, nil, e.NewErrorP("error in checking if the job position %s is admin: %s", SEDBError, claimedJPID.String(), err2.Error())
 	}
+	var jpIDs *[]m.ID
 	if isAdmin {
 		s.logger.Debugf("The job position %s is admin", claimedJPID.String())
-		events, err := s.event.GetNLastEvents(int(limit), int(offset))
-		if err != nil {
-			return nil, e.NewErrorP("failed to get some last events (limit: %d, offset: %d): %s", SEDBError, limit, offset, err.Error())
+	} else {
+		if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, claimedJPID); err != nil {
+			return nil, nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
+		} else if !isExistsUser {
+			return nil, nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
+				SEJPNotMatchedUser, userID.String(), claimedJPID.String())
 		}
-		s.logger.Debugf("Got %d events. (job position is admin)", len(*events))
-		return events, nil
+		jpIDs = &[]m.ID{claimedJPID}
 	}
 
-	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, claimedJPID); err != nil {
-		return nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
-	} else if !isExistsUser {
-		return nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
-			SEJPNotMatchedUser, userID.String(), claimedJPID.String())
+	events, nextCursor, err := s.event.GetNLastEvents(jpIDs, cursor, int(limit), filter)
+	if err != nil {
+		return nil, nil, e.NewErrorP("failed to get some last events (limit: %d): %s",
+			SEDBError, limit, err.Error())
 	}
+	return events, nextCursor, nil
+}
+
+func (s *sEventService) ApproveEvent(userID, jpID, eventID m.ID, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.approveEvent(userID, jpID, eventID)
+	s.audit.Record(newAuditEvent(m.AuditEventApprove, userID, jpID, m.AuditTargetEvent, eventID, client), err)
+	return id, err
+}
 
-	events, err := s.event.GetNLastEventsByJPID(claimedJPID, int(limit), int(offset))
+func (s *sEventService) approveEvent(userID, jpID, eventID m.ID) (*m.ID, *e.Error) {
+	if err := s.checkUserJP(userID, jpID); err != nil {
+		return nil, err
+	}
+	eventOwner, err := s.GetEventOwner(eventID)
 	if err != nil {
-		return nil, e.NewErrorP("failed to get some last events (limit: %d, offset: %d): %s",
-			SEDBError, limit, offset, err.Error())
+		return nil, err
+	} else if eventOwner == nil {
+		return nil, e.NewErrorP("event with id %s not found", SEEventNotFound, eventID.String())
+	}
+
+	if canApprove, err := s.authorization.CanApproveEvent(jpID, *eventOwner); err != nil {
+		return nil, err.SetCode(SEDBError)
+	} else if !canApprove {
+		return nil, e.NewErrorP("the job position %s is not allowed to approve event %s",
+			SENotPermission, jpID.String(), eventID.String())
+	}
+
+	approvalID, err2 := s.event.ApproveEvent(eventID, jpID)
+	if err2 != nil {
+		return nil, e.NewErrorP(err2.Error(), SEDBError)
+	}
+	return approvalID, nil
+}
+
+func (s *sEventService) RevokeApproval(userID, jpID, eventID m.ID, client m.ClientInfo) *e.Error {
+	err := s.revokeApproval(userID, jpID, eventID)
+	s.audit.Record(newAuditEvent(m.AuditEventRevokeApproval, userID, jpID, m.AuditTargetEvent, eventID, client), err)
+	return err
+}
+
+func (s *sEventService) revokeApproval(userID, jpID, eventID m.ID) *e.Error {
+	if err := s.checkUserJP(userID, jpID); err != nil {
+		return err
+	}
+	isRevoked, err := s.event.RevokeApproval(eventID, jpID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isRevoked {
+		return e.NewErrorP("the job position %s has not approved event %s", SENotFound,
+			jpID.String(), eventID.String())
+	}
+	return nil
+}
+
+func (s *sEventService) ListApprovedEvents(userID, claimedJPID m.ID, limit, offset uint64, filter *m.ListFilter) (*[]m.EventWithApproval, *e.Error) {
+	if err := s.checkUserJP(userID, claimedJPID); err != nil {
+		return nil, err
+	}
+	jpIDs, err := s.authorization.GetAccessibleJPs(claimedJPID)
+	if err != nil {
+		return nil, err.SetCode(SEDBError)
+	}
+	events, err2 := s.event.GetNLastApprovedEvents(jpIDs, int(limit), int(offset), filter)
+	if err2 != nil {
+		return nil, e.NewErrorP("failed to get some last approved events (limit: %d, offset: %d): %s",
+			SEDBError, limit, offset, err2.Error())
 	}
 	return events, nil
 }
 
+func (s *sEventService) GetEvent(userID, jpID, eventID m.ID) (*m.Event, *e.Error) {
+	return s.checkEventAccess(userID, jpID, eventID, m.ActionViewSubtree)
+}
+
+func (s *sEventService) UpdateEvent(userID, jpID, eventID m.ID, update *m.EventUpdate, client m.ClientInfo) *e.Error {
+	err := s.updateEvent(userID, jpID, eventID, update)
+	s.audit.Record(newAuditEvent(m.AuditEventUpdate, userID, jpID, m.AuditTargetEvent, eventID, client), err)
+	return err
+}
+
+func (s *sEventService) updateEvent(userID, jpID, eventID m.ID, update *m.EventUpdate) *e.Error {
+	if update.Name == nil && update.Description == nil {
+		return e.NewErrorP("there's nothing to update in event %s", SEEmpty, eventID.String())
+	} else if update.Name != nil && strings.TrimSpace(*update.Name) == "" {
+		return e.NewErrorP("name of the event %s can't be empty", SEWrongParameter, eventID.String())
+	}
+	if _, err := s.checkEventAccess(userID, jpID, eventID, m.ActionEditSubtree); err != nil {
+		return err
+	}
+
+	isUpdated, err := s.event.UpdateEvent(eventID, jpID, update)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isUpdated {
+		return e.NewErrorP("event with id %s not found", SEEventNotFound, eventID.String())
+	}
+	return nil
+}
+
+func (s *sEventService) DeleteEvent(userID, jpID, eventID m.ID, client m.ClientInfo) *e.Error {
+	err := s.deleteEvent(userID, jpID, eventID)
+	s.audit.Record(newAuditEvent(m.AuditEventDelete, userID, jpID, m.AuditTargetEvent, eventID, client), err)
+	return err
+}
+
+func (s *sEventService) deleteEvent(userID, jpID, eventID m.ID) *e.Error {
+	if _, err := s.checkEventAccess(userID, jpID, eventID, m.ActionEditSubtree); err != nil {
+		return err
+	}
+
+	isDeleted, err := s.event.DeleteEvent(eventID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isDeleted {
+		return e.NewErrorP("event with id %s not found", SEEventNotFound, eventID.String())
+	}
+	return nil
+}
+
+func (s *sEventService) GetEventRevisions(userID, jpID, eventID m.ID) (*[]m.EventRevision, *e.Error) {
+	if _, err := s.checkEventAccess(userID, jpID, eventID, m.ActionViewSubtree); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.event.GetEventRevisions(eventID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return revisions, nil
+}
+
+func (s *sEventService) GrantEventAccess(userID, jpID, eventID m.ID, acl *m.EventACL, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.grantEventAccess(userID, jpID, eventID, acl)
+	event := newAuditEvent(m.AuditEventGrantAccess, userID, jpID, m.AuditTargetEvent, eventID, client)
+	event.Details = fmt.Sprintf("%s access to %s %s", acl.Access, acl.GranteeType, acl.GranteeID.String())
+	s.audit.Record(event, err)
+	return id, err
+}
+
+func (s *sEventService) grantEventAccess(userID, jpID, eventID m.ID, acl *m.EventACL) (*m.ID, *e.Error) {
+	if !acl.GranteeType.IsValid() {
+		return nil, e.NewErrorP("grantee type \"%s\" is not valid", SEWrongParameter, acl.GranteeType)
+	} else if !acl.Access.IsValid() {
+		return nil, e.NewErrorP("access \"%s\" is not valid", SEWrongParameter, acl.Access)
+	} else if acl.GranteeID.IsNil() {
+		return nil, e.NewErrorP("grantee id can't be empty", SEWrongParameter)
+	} else if acl.ExpiresAt != nil && *acl.ExpiresAt <= time.Now().UTC().Unix() {
+		return nil, e.NewErrorP("expiry time %d is passed", SEWrongParameter, *acl.ExpiresAt)
+	}
+	if _, err := s.checkEventOwner(userID, jpID, eventID); err != nil {
+		return nil, err
+	}
+	if acl.GranteeType == m.GranteeJP || acl.GranteeType == m.GranteeSubtree {
+		if isExists, err := s.jp.IsExistsJP(acl.GranteeID); err != nil {
+			return nil, err
+		} else if !isExists {
+			return nil, e.NewErrorP("grantee job position %s not found", SEWrongParameter, acl.GranteeID.String())
+		}
+	}
+
+	acl.EventID = eventID
+	acl.GrantedBy = jpID
+	aclID, err := s.eventACL.CreateEventACL(acl)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return aclID, nil
+}
+
+func (s *sEventService) GetEventACLs(userID, jpID, eventID m.ID) (*[]m.EventACL, *e.Error) {
+	if _, err := s.checkEventOwner(userID, jpID, eventID); err != nil {
+		return nil, err
+	}
+	acls, err := s.eventACL.GetEventACLs(eventID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return acls, nil
+}
+
+func (s *sEventService) RevokeEventAccess(userID, jpID, eventID, aclID m.ID, client m.ClientInfo) *e.Error {
+	err := s.revokeEventAccess(userID, jpID, eventID, aclID)
+	event := newAuditEvent(m.AuditEventRevokeAccess, userID, jpID, m.AuditTargetEvent, eventID, client)
+	event.Details = fmt.Sprintf("access list entry %s", aclID.String())
+	s.audit.Record(event, err)
+	return err
+}
+
+func (s *sEventService) revokeEventAccess(userID, jpID, eventID, aclID m.ID) *e.Error {
+	if _, err := s.checkEventOwner(userID, jpID, eventID); err != nil {
+		return err
+	}
+	isDeleted, err := s.eventACL.DeleteEventACL(eventID, aclID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isDeleted {
+		return e.NewErrorP("access %s on event %s not found", SENotFound, aclID.String(), eventID.String())
+	}
+	return nil
+}
+
+// Check the job position belongs to the user and he is the owner of the event or an
+// ancestor of the owner that is allowed to do the action on its subtree. Others could
+// read the event if the access list of the event allows. Then, return the event.
+//
+// Possible error codes:
+// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SENotAncestor- SENotPermission
+func (s *sEventService) checkEventAccess(userID, jpID, eventID m.ID, action m.Action) (*m.Event, *e.Error) {
+	event, err := s.getUserEvent(userID, jpID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkIsOwnerOrAncestor(jpID, event, action)
+	if err == nil {
+		return event, nil
+	} else if code := err.GetCode(); action != m.ActionViewSubtree || (code != SENotAncestor && code != SENotPermission) {
+		return nil, err
+	}
+	if hasAccess, err2 := s.authorization.HasEventAccess(userID, jpID, eventID, m.EventAccessRead); err2 != nil {
+		return nil, err2
+	} else if !hasAccess {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Possible error codes:
+// SEDBError- SENotAncestor- SENotPermission
+func (s *sEventService) checkIsOwnerOrAncestor(jpID m.ID, event *m.Event, action m.Action) *e.Error {
+	if isAncestor, err := s.authorization.IsAncestor(jpID, event.CreatedBy); err != nil {
+		return err.SetCode(SEDBError)
+	} else if !isAncestor {
+		return e.NewErrorP("the job position %s is not the owner of event %s or his ancestor",
+			SENotAncestor, jpID.String(), event.ID.String())
+	}
+	if jpID == event.CreatedBy {
+		return nil
+	}
+	if can, err := s.authorization.Can(jpID, action, event.CreatedBy); err != nil {
+		return err
+	} else if !can {
+		return e.NewErrorP("the job position %s is not allowed to do %s on event %s",
+			SENotPermission, jpID.String(), action, event.ID.String())
+	}
+	return nil
+}
+
+// Check the job position belongs to the user and he is the owner of the event or an
+// admin. Then, return the event.
+//
+// Possible error codes:
+// SEDBError- SEJPNotMatchedUser- SEEventNotFound- SEEventOwnerMismatched
+func (s *sEventService) checkEventOwner(userID, jpID, eventID m.ID) (*m.Event, *e.Error) {
+	event, err := s.getUserEvent(userID, jpID, eventID)
+	if err != nil {
+		return nil, err
+	} else if event.CreatedBy == jpID {
+		return event, nil
+	}
+	if isAdmin, err := s.authorization.IsAdminJP(jpID); err != nil {
+		return nil, err
+	} else if !isAdmin {
+		return nil, e.NewErrorP("the job position %s is not the owner of event %s", SEEventOwnerMismatched,
+			jpID.String(), eventID.String())
+	}
+	return event, nil
+}
+
+// Check the job position belongs to the user and return the event.
+//
+// Possible error codes:
+// SEDBError- SEJPNotMatchedUser- SEEventNotFound
+func (s *sEventService) getUserEvent(userID, jpID, eventID m.ID) (*m.Event, *e.Error) {
+	if err := s.checkUserJP(userID, jpID); err != nil {
+		return nil, err
+	}
+	event, err := s.event.GetEventByID(eventID)
+	if err != nil {
+		return nil, e.NewErrorP("failed to get event by id %s: %s", SEDBError, eventID.String(), err.Error())
+	} else if event == nil {
+		return nil, e.NewErrorP("event with id %s not found", SEEventNotFound, eventID.String())
+	}
+	return event, nil
+}
+
+// Check the job position belongs to the user.
+//
+// Possible error codes:
+// SEDBError- SEJPNotMatchedUser
+func (s *sEventService) checkUserJP(userID, jpID m.ID) *e.Error {
+	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, jpID); err != nil {
+		return e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
+	} else if !isExistsUser {
+		return e.NewErrorP("there's not any user with id %s that have job position id %s",
+			SEJPNotMatchedUser, userID.String(), jpID.String())
+	}
+	return nil
+}
+
 // Create an instance of sEventService struct
-func newSEventService(event dal.EventDAL, jp JPService, authz AuthorizationService, logger l.Logger) EventService {
+func newSEventService(event dal.EventDAL, eventACL dal.EventACLDAL, jp JPService, authz AuthorizationService,
+	audit AuditService, logger l.Logger) EventService {
 	return &sEventService{
 		event,
+		eventACL,
 		jp,
 		authz,
+		audit,
 		logger,
 	}
 }
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// It keeps the access lists of the events in memory.
+type memEventACLDAL struct {
+	dal.EventACLDAL
+	acls []models.EventACL
+}
+
+func (d *memEventACLDAL) CreateEventACL(acl *models.EventACL) (*models.ID, error) {
+	acl.ID = models.ID(uuid.New())
+	d.acls = append(d.acls, *acl)
+	return &acl.ID, nil
+}
+
+func (d *memEventACLDAL) DeleteEventACL(eventID, aclID models.ID) (bool, error) {
+	for i, acl := range d.acls {
+		if acl.ID == aclID && acl.EventID == eventID {
+			d.acls = append(d.acls[:i], d.acls[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *memEventACLDAL) HasEventAccess(eventID, userID, jpID models.ID, subtreeRootIDs []models.ID, accesses []models.EventAccess) (bool, error) {
+	now := time.Now().UTC().Unix()
+	for _, acl := range d.acls {
+		if acl.EventID != eventID || (acl.ExpiresAt != nil && *acl.ExpiresAt <= now) {
+			continue
+		}
+		isGranted := (acl.GranteeType == models.GranteeJP && acl.GranteeID == jpID) ||
+			(acl.GranteeType == models.GranteeUser && acl.GranteeID == userID)
+		for _, rootID := range subtreeRootIDs {
+			isGranted = isGranted || (acl.GranteeType == models.GranteeSubtree && acl.GranteeID == rootID)
+		}
+		for _, access := range accesses {
+			if isGranted && acl.Access == access {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Each existing job position belongs to the user with the same id.
+type memEventJPService struct {
+	JPService
+	jps map[models.ID]bool
+}
+
+func (s *memEventJPService) IsExistsUserWithJP(userID, jpID models.ID) (bool, error) {
+	return userID == jpID && s.jps[jpID], nil
+}
+
+func (s *memEventJPService) IsExistsJP(jpID models.ID) (bool, *e.Error) {
+	return s.jps[jpID], nil
+}
+
+// Return an event service over the job positions of the role fixture together with an
+// event created by the sibling job position.
+func newEventACLTestService(t *testing.T) (*roleFixture, *sEventService, *memEventACLDAL, models.ID) {
+	t.Helper()
+	f, authorization, _ := newRoleFixture(t)
+	aclDAL := &memEventACLDAL{}
+	authorization.eventACL = aclDAL
+	eventID := models.ID(uuid.New())
+	eventDAL := &memEventDAL{events: map[models.ID]models.Event{
+		eventID: {ID: eventID, Name: "event", CreatedBy: f.sibling},
+	}}
+	jpService := &memEventJPService{jps: map[models.ID]bool{}}
+	for _, jpID := range []models.ID{f.admin, f.manager, f.child, f.grandchild, f.sibling} {
+		jpService.jps[jpID] = true
+	}
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	service := newSEventService(eventDAL, aclDAL, jpService, authorization,
+		newSAuditService(&memAuditDAL{}, nil, nil, logger), logger).(*sEventService)
+	return f, service, aclDAL, eventID
+}
+
+func TestGrantEventAccess(t *testing.T) {
+	passed, future := time.Now().Add(-time.Minute).Unix(), time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name string
+		// Job position that grants the access. It's the sibling (the owner) if it's nil.
+		granter func(f *roleFixture) models.ID
+		acl     func(f *roleFixture) models.EventACL
+		// Expected error code. If it's nil, the access must be granted.
+		errCode any
+	}{
+		{name: "owner grants to a job position", acl: func(f *roleFixture) models.EventACL {
+			return models.EventACL{GranteeType: models.GranteeJP, GranteeID: f.child, Access: models.EventAccessRead}
+		}},
+		{name: "admin grants to a subtree", granter: func(f *roleFixture) models.ID { return f.admin },
+			acl: func(f *roleFixture) models.EventACL {
+				return models.EventACL{GranteeType: models.GranteeSubtree, GranteeID: f.manager,
+					Access: models.EventAccessContribute, ExpiresAt: &future}
+			}},
+		{name: "others can't grant", granter: func(f *roleFixture) models.ID { return f.manager },
+			acl: func(f *roleFixture) models.EventACL {
+				return models.EventACL{GranteeType: models.GranteeJP, GranteeID: f.child, Access: models.EventAccessRead}
+			}, errCode: SEEventOwnerMismatched},
+		{name: "unknown job position", acl: func(f *roleFixture) models.EventACL {
+			return models.EventACL{GranteeType: models.GranteeJP, GranteeID: models.ID(uuid.New()),
+				Access: models.EventAccessRead}
+		}, errCode: SEWrongParameter},
+		{name: "unknown subtree", acl: func(f *roleFixture) models.EventACL {
+			return models.EventACL{GranteeType: models.GranteeSubtree, GranteeID: models.ID(uuid.New()),
+				Access: models.EventAccessRead}
+		}, errCode: SEWrongParameter},
+		{name: "passed expiry time", acl: func(f *roleFixture) models.EventACL {
+			return models.EventACL{GranteeType: models.GranteeJP, GranteeID: f.child, Access: models.EventAccessRead,
+				ExpiresAt: &passed}
+		}, errCode: SEWrongParameter},
+		{name: "invalid access", acl: func(f *roleFixture) models.EventACL {
+			return models.EventACL{GranteeType: models.GranteeJP, GranteeID: f.child, Access: "write"}
+		}, errCode: SEWrongParameter},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, aclDAL, eventID := newEventACLTestService(t)
+			granter := f.sibling
+			if test.granter != nil {
+				granter = test.granter(f)
+			}
+			acl := test.acl(f)
+			id, err := service.GrantEventAccess(granter, granter, eventID, &acl, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				if len(aclDAL.acls) != 1 || aclDAL.acls[0].ID != *id || aclDAL.acls[0].GrantedBy != granter {
+					t.Errorf("expected access to be granted by %s, got %+v", granter.String(), aclDAL.acls)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error code %v, got nil", test.errCode)
+			} else if err.GetCode() != test.errCode {
+				t.Errorf("expected error code %v, got %v (%s)", test.errCode, err.GetCode(), err.Error())
+			}
+			if len(aclDAL.acls) != 0 {
+				t.Errorf("expected access not to be granted, got %+v", aclDAL.acls)
+			}
+		})
+	}
+}
+
+func TestEventAccessExpiry(t *testing.T) {
+	f, service, aclDAL, eventID := newEventACLTestService(t)
+	future := time.Now().Add(time.Hour).Unix()
+	acl := models.EventACL{GranteeType: models.GranteeSubtree, GranteeID: f.manager, Access: models.EventAccessRead,
+		ExpiresAt: &future}
+	if _, err := service.GrantEventAccess(f.sibling, f.sibling, eventID, &acl, models.ClientInfo{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	if _, err := service.GetEvent(f.grandchild, f.grandchild, eventID); err != nil {
+		t.Errorf("expected access of the subtree before expiry, got %s", err.Error())
+	}
+	passed := time.Now().Add(-time.Second).Unix()
+	aclDAL.acls[0].ExpiresAt = &passed
+	if _, err := service.GetEvent(f.grandchild, f.grandchild, eventID); err == nil {
+		t.Errorf("expected expired access to be rejected")
+	}
+}
+
+func TestRevokeEventAccess(t *testing.T) {
+	f, service, _, eventID := newEventACLTestService(t)
+	acl := models.EventACL{GranteeType: models.GranteeJP, GranteeID: f.child, Access: models.EventAccessRead}
+	aclID, err := service.GrantEventAccess(f.sibling, f.sibling, eventID, &acl, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if _, err := service.GetEvent(f.child, f.child, eventID); err != nil {
+		t.Fatalf("expected access of the job position, got %s", err.Error())
+	}
+
+	if err := service.RevokeEventAccess(f.manager, f.manager, eventID, *aclID, models.ClientInfo{}); err == nil ||
+		err.GetCode() != SEEventOwnerMismatched {
+		t.Errorf("expected error code %d for others, got %v", SEEventOwnerMismatched, err)
+	}
+	if err := service.RevokeEventAccess(f.sibling, f.sibling, eventID, *aclID, models.ClientInfo{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if _, err := service.GetEvent(f.child, f.child, eventID); err == nil {
+		t.Errorf("expected revoked access to be rejected")
+	}
+	if err := service.RevokeEventAccess(f.sibling, f.sibling, eventID, *aclID, models.ClientInfo{}); err == nil ||
+		err.GetCode() != SENotFound {
+		t.Errorf("expected error code %d for revoked access, got %v", SENotFound, err)
+	}
+}
+
+func (d *memEventDAL) UpdateEvent(eventID, editorJPID models.ID, update *models.EventUpdate) (bool, error) {
+	event, ok := d.events[eventID]
+	if !ok {
+		return false, nil
+	}
+	if update.Name != nil {
+		event.Name = *update.Name
+	}
+	if update.Description != nil {
+		event.Description = *update.Description
+	}
+	d.events[eventID] = event
+	return true, nil
+}
+
+func (d *memEventDAL) DeleteEvent(eventID models.ID) (bool, error) {
+	if _, ok := d.events[eventID]; !ok {
+		return false, nil
+	}
+	delete(d.events, eventID)
+	return true, nil
+}
+
+// Return an event service over the job positions of the role fixture together with an
+// event created by the child job position. If isEditor is true, the manager is allowed to
+// edit its subtree.
+func newEventEditTestService(t *testing.T, isEditor bool) (*roleFixture, *sEventService, *memEventDAL, models.ID) {
+	t.Helper()
+	f, authorization, roleDAL := newRoleFixture(t)
+	if isEditor {
+		assignEditorRole(roleDAL, f.manager)
+	}
+	aclDAL := &memEventACLDAL{}
+	authorization.eventACL = aclDAL
+	eventID := models.ID(uuid.New())
+	eventDAL := &memEventDAL{events: map[models.ID]models.Event{
+		eventID: {ID: eventID, Name: "event", Description: "description", CreatedBy: f.child},
+	}}
+	jpService := &memEventJPService{jps: map[models.ID]bool{}}
+	for _, jpID := range []models.ID{f.admin, f.manager, f.child, f.grandchild, f.sibling} {
+		jpService.jps[jpID] = true
+	}
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	service := newSEventService(eventDAL, aclDAL, jpService, authorization,
+		newSAuditService(&memAuditDAL{}, nil, nil, logger), logger).(*sEventService)
+	return f, service, eventDAL, eventID
+}
+
+func TestUpdateEventCallers(t *testing.T) {
+	for _, test := range editorTests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, eventDAL, eventID := newEventEditTestService(t, test.isEditor)
+			editor := test.editor(f)
+			name := "edited"
+			err := service.UpdateEvent(editor, editor, eventID, &models.EventUpdate{Name: &name}, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				if event := eventDAL.events[eventID]; event.Name != name || event.Description != "description" {
+					t.Errorf("expected just the name of the event to be edited, got %+v", event)
+				}
+				return
+			}
+			if err == nil || err.GetCode() != test.errCode {
+				t.Fatalf("expected error code %v, got %v", test.errCode, err)
+			}
+			if eventDAL.events[eventID].Name != "event" {
+				t.Errorf("expected the event not to be edited")
+			}
+		})
+	}
+}
+
+func TestDeleteEventCallers(t *testing.T) {
+	for _, test := range editorTests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, eventDAL, eventID := newEventEditTestService(t, test.isEditor)
+			editor := test.editor(f)
+			err := service.DeleteEvent(editor, editor, eventID, models.ClientInfo{})
+			if test.errCode == nil && err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			} else if test.errCode != nil && (err == nil || err.GetCode() != test.errCode) {
+				t.Fatalf("expected error code %v, got %v", test.errCode, err)
+			}
+			if _, isKept := eventDAL.events[eventID]; isKept != (test.errCode != nil) {
+				t.Errorf("expected the event to be deleted just by the allowed callers")
+			}
+			if test.errCode == nil {
+				if _, err := service.GetEvent(f.child, f.child, eventID); err == nil || err.GetCode() != SEEventNotFound {
+					t.Errorf("expected error code %d for the deleted event, got %v", SEEventNotFound, err)
+				}
+			}
+		})
+	}
+}
+
+func (d *memEventDAL) ApproveEvent(eventID, jpID models.ID) (*models.ID, error) {
+	if d.approvals == nil {
+		d.approvals = map[[2]models.ID]models.ID{}
+	}
+	approvalID, ok := d.approvals[[2]models.ID{eventID, jpID}]
+	if !ok {
+		approvalID = models.ID(uuid.New())
+		d.approvals[[2]models.ID{eventID, jpID}] = approvalID
+	}
+	return &approvalID, nil
+}
+
+func (d *memEventDAL) RevokeApproval(eventID, jpID models.ID) (bool, error) {
+	if _, ok := d.approvals[[2]models.ID{eventID, jpID}]; !ok {
+		return false, nil
+	}
+	delete(d.approvals, [2]models.ID{eventID, jpID})
+	return true, nil
+}
+
+func (d *memEventDAL) GetNLastApprovedEvents(jpIDs *[]models.ID, limit, offset int, filter *models.ListFilter) (*[]models.EventWithApproval, error) {
+	events := []models.EventWithApproval{}
+	for key := range d.approvals {
+		event := d.events[key[0]]
+		isIncluded := jpIDs == nil
+		for _, jpID := range derefIDs(jpIDs) {
+			isIncluded = isIncluded || jpID == event.CreatedBy
+		}
+		if isIncluded {
+			events = append(events, models.EventWithApproval{Event: event,
+				Approval: models.ApprovedEvent{EventID: event.ID, ApprovedByJPID: key[1]}})
+		}
+	}
+	return &events, nil
+}
+
+func derefIDs(ids *[]models.ID) []models.ID {
+	if ids == nil {
+		return nil
+	}
+	return *ids
+}
+
+func TestApproveEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		isApprover bool
+		approver   func(f *roleFixture) models.ID
+		// Expected error code. If it's nil, the event must be approved.
+		errCode any
+	}{
+		{name: "admin", approver: func(f *roleFixture) models.ID { return f.admin }},
+		{name: "ancestor allowed to approve", isApprover: true, approver: func(f *roleFixture) models.ID { return f.manager }},
+		{name: "ancestor not allowed to approve", approver: func(f *roleFixture) models.ID { return f.manager },
+			errCode: SENotPermission},
+		{name: "owner not allowed to approve", approver: func(f *roleFixture) models.ID { return f.child },
+			errCode: SENotPermission},
+		{name: "job position out of the subtree", isApprover: true, approver: func(f *roleFixture) models.ID { return f.sibling },
+			errCode: SENotPermission},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, eventDAL, eventID := newEventEditTestService(t, false)
+			if test.isApprover {
+				roleDAL := service.authorization.(*sAuthorizationService).role.(*memRoleDAL)
+				roleDAL.AssignRole(f.manager, f.approverRole, false)
+				roleDAL.AssignRole(f.sibling, f.approverRole, false)
+			}
+			approver := test.approver(f)
+
+			approvalID, err := service.ApproveEvent(approver, approver, eventID, models.ClientInfo{})
+			if test.errCode != nil {
+				if err == nil || err.GetCode() != test.errCode {
+					t.Fatalf("expected error code %v, got %v", test.errCode, err)
+				}
+				if len(eventDAL.approvals) != 0 {
+					t.Errorf("expected the event not to be approved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			againID, err := service.ApproveEvent(approver, approver, eventID, models.ClientInfo{})
+			if err != nil || *againID != *approvalID {
+				t.Errorf("expected approving again to return the previous approval %s, got %v (%v)",
+					approvalID.String(), againID, err)
+			}
+		})
+	}
+
+	t.Run("missing event", func(t *testing.T) {
+		f, service, _, _ := newEventEditTestService(t, false)
+		if _, err := service.ApproveEvent(f.admin, f.admin, models.ID(uuid.New()), models.ClientInfo{}); err == nil ||
+			err.GetCode() != SEEventNotFound {
+			t.Errorf("expected error code %d, got %v", SEEventNotFound, err)
+		}
+	})
+}
+
+func TestRevokeApproval(t *testing.T) {
+	f, service, eventDAL, eventID := newEventEditTestService(t, false)
+	if _, err := service.ApproveEvent(f.admin, f.admin, eventID, models.ClientInfo{}); err != nil {
+		t.Fatalf("failed to approve the event: %s", err.Error())
+	}
+
+	if err := service.RevokeApproval(f.manager, f.manager, eventID, models.ClientInfo{}); err == nil ||
+		err.GetCode() != SENotFound {
+		t.Errorf("expected error code %d for approval of others, got %v", SENotFound, err)
+	}
+	if len(eventDAL.approvals) != 1 {
+		t.Fatalf("expected approval of the admin to be kept")
+	}
+	if err := service.RevokeApproval(f.admin, f.admin, eventID, models.ClientInfo{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if len(eventDAL.approvals) != 0 {
+		t.Errorf("expected the approval to be revoked")
+	}
+	if err := service.RevokeApproval(f.admin, f.admin, eventID, models.ClientInfo{}); err == nil ||
+		err.GetCode() != SENotFound {
+		t.Errorf("expected error code %d for revoked approval, got %v", SENotFound, err)
+	}
+}
+
+func TestListApprovedEvents(t *testing.T) {
+	f, service, eventDAL, eventID := newEventEditTestService(t, false)
+	siblingEventID := models.ID(uuid.New())
+	eventDAL.events[siblingEventID] = models.Event{ID: siblingEventID, Name: "sibling event", CreatedBy: f.sibling}
+	for _, id := range []models.ID{eventID, siblingEventID} {
+		if _, err := service.ApproveEvent(f.admin, f.admin, id, models.ClientInfo{}); err != nil {
+			t.Fatalf("failed to approve the event: %s", err.Error())
+		}
+	}
+
+	tests := []struct {
+		name     string
+		jpID     func(f *roleFixture) models.ID
+		expected []models.ID
+	}{
+		{name: "admin gets all approved events", jpID: func(f *roleFixture) models.ID { return f.admin },
+			expected: []models.ID{eventID, siblingEventID}},
+		{name: "ancestor gets approved events of the subtree", jpID: func(f *roleFixture) models.ID { return f.manager },
+			expected: []models.ID{eventID}},
+		{name: "owner gets its approved events", jpID: func(f *roleFixture) models.ID { return f.child },
+			expected: []models.ID{eventID}},
+		{name: "descendant doesn't get approved events of the ancestors", jpID: func(f *roleFixture) models.ID { return f.grandchild }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jpID := test.jpID(f)
+			events, err := service.ListApprovedEvents(jpID, jpID, 10, 0, nil)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if len(*events) != len(test.expected) {
+				t.Fatalf("expected %d approved events, got %+v", len(test.expected), *events)
+			}
+			for _, expectedID := range test.expected {
+				isFound := false
+				for _, event := range *events {
+					isFound = isFound || event.ID == expectedID
+				}
+				if !isFound {
+					t.Errorf("expected approved event %s, got %+v", expectedID.String(), *events)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	m "DMS/internal/models"
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -21,44 +22,85 @@ type allowType struct {
 	IsAllow  bool
 	// Maximum size of the file with with FileType in Kbytes
 	MaxSize uint64
+	// Why the file type is not allowed. It's empty if the type is allowed.
+	Reason string
 }
 
 type FilePermissionService interface {
 	// Check if each file specified in the input is allowed to be downloaded by specified
-	// client that has 'AuthToken'.
+	// client that has 'AuthToken'. Each object token must be signed by the server, not be
+	// expired and refer to a multimedia file of a doc of the event in 'AuthToken'. The
+	// decision is recorded in the audit log.
 	//
 	// Possible error codes:
 	// SEInternal- SEForbidden- SEAuthFailed
-	IsAllowedDownload(accessInfo *m.DownloadReq) (allowDownload, *e.Error)
+	IsAllowedDownload(accessInfo *m.DownloadReq, client m.ClientInfo) (allowDownload, *e.Error)
 
 	// Check if the file type specified in the input is allowed to be uploaded and what
 	// is the maximum size of each type that could be uploaded then, return the result. these details
-	// are only usesable for the client with 'AuthToken' not anyone else.
+	// are only usesable for the client with 'AuthToken' not anyone else. The limits come
+	// from the upload policy and the remaining storage quotas of the event and the job
+	// position. Like creating docs, the job position must be the owner of the event or a
+	// contributor of it and be allowed to create docs. The decision is recorded in the
+	// audit log.
 	//
 	// Possible error codes:
 	// SEInternal- SEForbidden- SEAuthFailed
-	IsAllowedUpload(accessInfo *m.UploadReq) ([]allowType, *e.Error)
+	IsAllowedUpload(accessInfo *m.UploadReq, client m.ClientInfo) ([]allowType, *e.Error)
+
+	// Register the file stored by the file-transfer service on its doc and return it. The
+	// doc must be created by the job position of 'AuthToken' for its event and have a
+	// pending file with the same name, then that file is confirmed. The file must be
+	// allowed by the upload policy and the confirmed files of the event and the job
+	// position together with it must not exceed their storage quotas.
+	//
+	// Possible error codes:
+	// SEInternal- SEForbidden- SEAuthFailed- SENotFound- SEWrongParameter
+	ConfirmUpload(confirmation *m.UploadConfirmation, client m.ClientInfo) (*m.MediaPath, *e.Error)
 }
 
 type sFilePermissionService struct {
-	cache   dal.InMemoryDAL
-	session SessionService
-	event   dal.EventDAL
-	authz   AuthorizationService
-	logger  l.Logger
+	cache        dal.InMemoryDAL
+	session      SessionService
+	event        dal.EventDAL
+	doc          dal.DocDAL
+	authz        AuthorizationService
+	objectTokens *objectTokenSigner
+	uploadPolicy *uploadPolicy
+	audit        AuditService
+	logger       l.Logger
 }
 
-func newSFilePermissionService(cache dal.InMemoryDAL, session SessionService, event dal.EventDAL, authzService AuthorizationService, logger l.Logger) FilePermissionService {
-	return &sFilePermissionService{cache, session, event, authzService, logger}
+func newSFilePermissionService(cache dal.InMemoryDAL, session SessionService, event dal.EventDAL, doc dal.DocDAL,
+	authzService AuthorizationService, objectTokens *objectTokenSigner, uploadPolicy *uploadPolicy,
+	audit AuditService, logger l.Logger) FilePermissionService {
+	return &sFilePermissionService{cache, session, event, doc, authzService, objectTokens, uploadPolicy, audit, logger}
+}
+
+func (s *sFilePermissionService) IsAllowedDownload(accessInfo *m.DownloadReq, client m.ClientInfo) (allowDownload, *e.Error) {
+	auditEvent := newAuditEvent(m.AuditFileDownload, m.NilID, m.NilID, m.AuditTargetEvent, m.NilID, client)
+	result, err := s.isAllowedDownload(accessInfo, auditEvent)
+	if err == nil {
+		allowed := 0
+		for _, isAllowed := range result {
+			if isAllowed {
+				allowed++
+			}
+		}
+		auditEvent.Details = fmt.Sprintf("%d of %d files are allowed", allowed, len(result))
+	}
+	s.audit.Record(auditEvent, err)
+	return result, err
 }
 
 // TODO: Implement cache for it
-func (s *sFilePermissionService) IsAllowedDownload(accessInfo *m.DownloadReq) (allowDownload, *e.Error) {
+func (s *sFilePermissionService) isAllowedDownload(accessInfo *m.DownloadReq, auditEvent *m.AuditEvent) (allowDownload, *e.Error) {
 	parsedToken, err := s.parseAuthToken(accessInfo.AuthToken)
 	if err != nil {
 		return nil, e.NewErrorP("failed to parse auth token: %s", SEAuthFailed, err.Error())
 	}
-	isAllowed, err2 := s.isAllowedAuthToken(*parsedToken)
+	auditEvent.TargetID = parsedToken.EventID
+	isAllowed, err2 := s.isAllowedAuthToken(*parsedToken, m.EventAccessRead, auditEvent)
 	if err2 != nil {
 		switch err2.GetCode() {
 		case SEInternal, SEDBError:
@@ -76,18 +118,80 @@ func (s *sFilePermissionService) IsAllowedDownload(accessInfo *m.DownloadReq) (a
 
 	allowDownload := make(allowDownload)
 	for _, objToken := range accessInfo.ObjectTokens {
-		allowDownload[objToken] = true
+		isAllowed, err := s.isAllowedObjectToken(objToken, parsedToken.EventID)
+		if err != nil {
+			return nil, err
+		}
+		allowDownload[objToken] = isAllowed
 	}
 	return allowDownload, nil
 }
 
+func (s *sFilePermissionService) IsAllowedUpload(accessInfo *m.UploadReq, client m.ClientInfo) ([]allowType, *e.Error) {
+	auditEvent := newAuditEvent(m.AuditFileUpload, m.NilID, m.NilID, m.AuditTargetEvent, m.NilID, client)
+	result, err := s.isAllowedUpload(accessInfo, auditEvent)
+	if err == nil {
+		types := make([]string, len(result))
+		for i, t := range result {
+			types[i] = fmt.Sprintf("%s: %t", t.FileType, t.IsAllow)
+		}
+		auditEvent.Details = strings.Join(types, ", ")
+	}
+	s.audit.Record(auditEvent, err)
+	return result, err
+}
+
 // TODO: Implement cache for it
-func (s *sFilePermissionService) IsAllowedUpload(accessInfo *m.UploadReq) ([]allowType, *e.Error) {
+func (s *sFilePermissionService) isAllowedUpload(accessInfo *m.UploadReq, auditEvent *m.AuditEvent) ([]allowType, *e.Error) {
 	parsedToken, err := s.parseAuthToken(accessInfo.AuthToken)
 	if err != nil {
 		return nil, e.NewErrorP("failed to parse auth token: %s", SEAuthFailed, err.Error())
 	}
-	isAllowed, err2 := s.isAllowedAuthToken(*parsedToken)
+	auditEvent.TargetID = parsedToken.EventID
+	isAllowed, err2 := s.isAllowedAuthToken(*parsedToken, m.EventAccessContribute, auditEvent)
+	if err2 != nil {
+		switch err2.GetCode() {
+		case SEInternal, SEDBError:
+			return nil, err2.SetCode(SEInternal)
+		case SEAuthFailed, SENotFound:
+			return nil, err2.SetCode(SEAuthFailed)
+		default:
+			s.logger.Warnf("Unexpected error type \"%d\" in checking auth token: %s", err2.GetCode(), err2.Error())
+			return nil, err2
+		}
+	} else if !isAllowed {
+		return nil, e.NewErrorP("the job-position %s is not allow to access event %s",
+			SEForbidden, parsedToken.JobPositionID.String(), parsedToken.EventID.String())
+	}
+
+	var usedEventKB, usedJPKB uint64
+	if s.uploadPolicy.eventQuota > 0 {
+		if usedEventKB, err = s.doc.GetEventMediaSize(parsedToken.EventID); err != nil {
+			return nil, e.NewErrorP(err.Error(), SEInternal)
+		}
+	}
+	if s.uploadPolicy.jpQuota > 0 {
+		if usedJPKB, err = s.doc.GetJPMediaSize(parsedToken.JobPositionID); err != nil {
+			return nil, e.NewErrorP(err.Error(), SEInternal)
+		}
+	}
+	return s.uploadPolicy.allowTypes(accessInfo.ObjectTypes, usedEventKB, usedJPKB), nil
+}
+
+func (s *sFilePermissionService) ConfirmUpload(confirmation *m.UploadConfirmation, client m.ClientInfo) (*m.MediaPath, *e.Error) {
+	auditEvent := newAuditEvent(m.AuditFileConfirm, m.NilID, m.NilID, m.AuditTargetDoc, confirmation.DocID, client)
+	auditEvent.Details = fmt.Sprintf("file %s", confirmation.FileName)
+	media, err := s.confirmUpload(confirmation, auditEvent)
+	s.audit.Record(auditEvent, err)
+	return media, err
+}
+
+func (s *sFilePermissionService) confirmUpload(confirmation *m.UploadConfirmation, auditEvent *m.AuditEvent) (*m.MediaPath, *e.Error) {
+	parsedToken, err := s.parseAuthToken(confirmation.AuthToken)
+	if err != nil {
+		return nil, e.NewErrorP("failed to parse auth token: %s", SEAuthFailed, err.Error())
+	}
+	isAllowed, err2 := s.isAllowedAuthToken(*parsedToken, m.EventAccessContribute, auditEvent)
 	if err2 != nil {
 		switch err2.GetCode() {
 		case SEInternal, SEDBError:
@@ -103,28 +207,64 @@ func (s *sFilePermissionService) IsAllowedUpload(accessInfo *m.UploadReq) ([]all
 			SEForbidden, parsedToken.JobPositionID.String(), parsedToken.EventID.String())
 	}
 
-	var allowTypes []allowType
-	for fileType, count := range accessInfo.ObjectTypes {
-		allowTypes = append(allowTypes, allowType{
-			FileType: fileType,
-			IsAllow:  true,
-			MaxSize:  uint64(count),
-		})
+	doc, err := s.doc.GetDocByID(confirmation.DocID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEInternal)
+	} else if doc == nil {
+		return nil, e.NewErrorP("doc %s not found", SENotFound, confirmation.DocID.String())
+	} else if doc.EventID != parsedToken.EventID || doc.CreatedBy != parsedToken.JobPositionID {
+		return nil, e.NewErrorP("doc %s is not created by job-position %s for event %s", SEForbidden,
+			doc.ID.String(), parsedToken.JobPositionID.String(), parsedToken.EventID.String())
+	}
+
+	mediaType, ok := s.uploadPolicy.mediaType(confirmation.FileName)
+	if !ok {
+		return nil, e.NewErrorP("extension of file %s is not allowed", SEWrongParameter, confirmation.FileName)
+	}
+	media := m.MediaPath{
+		Type:     mediaType,
+		Src:      confirmation.Src,
+		FileName: confirmation.FileName,
+		Size:     confirmation.Size,
+		Checksum: confirmation.Checksum,
+		MimeType: confirmation.MimeType,
+		Status:   m.MediaConfirmed,
+	}
+	if media.Src == "" {
+		media.Src = media.FileName
 	}
-	return allowTypes, nil
+	if err := s.uploadPolicy.checkMedia(&media); err != nil {
+		return nil, e.NewErrorP(err.Error(), SEWrongParameter)
+	}
+	isFound, err := s.doc.ConfirmMedia(doc.ID, &media, s.uploadPolicy.eventQuota, s.uploadPolicy.jpQuota)
+	if errors.Is(err, e.ErrQuotaExceeded) {
+		return nil, e.NewErrorP(err.Error(), SEForbidden)
+	} else if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEInternal)
+	} else if !isFound {
+		return nil, e.NewErrorP("doc %s doesn't have pending file %s", SENotFound, doc.ID.String(), media.FileName)
+	}
+	s.logger.Debugf("Confirmed file %s of doc %s", media.FileName, doc.ID.String())
+	return &media, nil
 }
 
 // Check if specified job position with the given auth token exists and has access to
-// the specified event.
+// the specified event. The owner of the event and his ancestors that are allowed to view
+// their subtree have read access to the event. Others must be granted the access by the
+// access list of the event. Contribute access is checked by isAllowedContribute. The
+// actor of the audit event is set from the token, once the token is validated.
 //
 // Possible error codes:
 // SEAuthFailed- SEDBError- SENotFound- SEInternal
-func (s *sFilePermissionService) isAllowedAuthToken(parsedAuth parsedAuthToken) (bool, *e.Error) {
-	_, err := s.session.ValidateSessionJWT(parsedAuth.JWT)
+func (s *sFilePermissionService) isAllowedAuthToken(parsedAuth parsedAuthToken, access m.EventAccess,
+	auditEvent *m.AuditEvent) (bool, *e.Error) {
+	jwt, err := s.session.ValidateSessionJWT(parsedAuth.JWT)
 	if err != nil {
 		switch err.GetCode() {
 		case SEAuthFailed, SEDBError:
 			return false, err.AppendBegin("failed to validate auth token (error code %d)", err.GetCode())
+		case SEIsDisabled:
+			return false, err.AppendBegin("user of the auth token is disabled").SetCode(SEAuthFailed)
 		case SENotFound:
 			return false, err.AppendBegin("it seems the session related to jwt doesn't exists")
 		default:
@@ -132,6 +272,8 @@ func (s *sFilePermissionService) isAllowedAuthToken(parsedAuth parsedAuthToken)
 			return false, err.SetCode(SEInternal)
 		}
 	}
+	auditEvent.ActorUserID = jwt.UserID
+	auditEvent.ActorJPID = parsedAuth.JobPositionID
 
 	event, err2 := s.event.GetEventByID(parsedAuth.EventID)
 	if err2 != nil {
@@ -140,12 +282,76 @@ func (s *sFilePermissionService) isAllowedAuthToken(parsedAuth parsedAuthToken)
 		return false, nil
 	}
 
+	if access == m.EventAccessContribute {
+		return s.isAllowedContribute(jwt.UserID, parsedAuth.JobPositionID, event)
+	}
 	isAncestor, err3 := s.authz.IsAncestor(parsedAuth.JobPositionID, event.CreatedBy)
 	if err3 != nil {
 		return false, err3.AppendBegin("failed to check if job-position with id %s is ancestor of %s",
 			parsedAuth.JobPositionID.String(), event.CreatedBy.String()).SetCode(SEDBError)
+	} else if isAncestor && parsedAuth.JobPositionID == event.CreatedBy {
+		return true, nil
+	} else if isAncestor {
+		// Ancestors of the event owner must be allowed to view their subtree.
+		canView, err3 := s.authz.Can(parsedAuth.JobPositionID, m.ActionViewSubtree, event.CreatedBy)
+		if err3 != nil {
+			return false, err3.AppendBegin("failed to check if job-position with id %s could view %s",
+				parsedAuth.JobPositionID.String(), event.CreatedBy.String()).SetCode(SEDBError)
+		} else if canView {
+			return true, nil
+		}
+	}
+
+	hasAccess, err3 := s.authz.HasEventAccess(jwt.UserID, parsedAuth.JobPositionID, event.ID, access)
+	if err3 != nil {
+		return false, err3.AppendBegin("failed to check access list of event %s", event.ID.String())
+	}
+	return hasAccess, nil
+}
+
+// Check the job position could add files to the event as CreateDoc does. It must be the
+// owner of the event or the access list of the event grants it contribute access, and it
+// must be allowed to create docs. Ancestors of the owner don't contribute to the event
+// just by viewing their subtree.
+//
+// Possible error codes:
+// SEDBError
+func (s *sFilePermissionService) isAllowedContribute(userID, jpID m.ID, event *m.Event) (bool, *e.Error) {
+	if jpID != event.CreatedBy {
+		hasAccess, err := s.authz.HasEventAccess(userID, jpID, event.ID, m.EventAccessContribute)
+		if err != nil {
+			return false, err.AppendBegin("failed to check access list of event %s", event.ID.String())
+		} else if !hasAccess {
+			return false, nil
+		}
+	}
+	can, err := s.authz.Can(jpID, m.ActionCreateDoc, m.NilID)
+	if err != nil {
+		return false, err.AppendBegin("failed to check if job-position with id %s could create docs",
+			jpID.String()).SetCode(SEDBError)
+	}
+	return can, nil
+}
+
+// Check the object token is valid and refers to a multimedia file of a doc of the event.
+//
+// Possible error codes:
+// SEInternal
+func (s *sFilePermissionService) isAllowedObjectToken(objToken m.Token, eventID m.ID) (bool, *e.Error) {
+	ref, err := s.objectTokens.verify(objToken)
+	if err != nil {
+		s.logger.Debugf("Invalid object token: %s", err.Error())
+		return false, nil
+	}
+	mediaEventID, err := s.doc.GetMediaEventID(ref.DocID, ref.FileName)
+	if err != nil {
+		return false, e.NewErrorP(err.Error(), SEInternal)
+	} else if mediaEventID == nil || *mediaEventID != eventID {
+		s.logger.Debugf("File %s of doc %s doesn't exist or doesn't belong to event %s", ref.FileName,
+			ref.DocID.String(), eventID.String())
+		return false, nil
 	}
-	return isAncestor, nil
+	return true, nil
 }
 
 type parsedAuthToken struct {
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// It validates every JWT as a token of the user.
+type fakeSessionService struct {
+	SessionService
+	userID models.ID
+}
+
+func (s *fakeSessionService) ValidateSessionJWT(token models.Token) (*models.JWT, *e.Error) {
+	return &models.JWT{UserID: s.userID}, nil
+}
+
+// It keeps the events and their approvals in memory. approvals are ids of the
+// approvals by the event id and the job position id approved it.
+type memEventDAL struct {
+	dal.EventDAL
+	events    map[models.ID]models.Event
+	approvals map[[2]models.ID]models.ID
+}
+
+func (d *memEventDAL) GetEventByID(eventID models.ID) (*models.Event, error) {
+	if event, ok := d.events[eventID]; ok {
+		return &event, nil
+	}
+	return nil, nil
+}
+
+// Each job position is only the ancestor of itself and could just create docs.
+type selfAuthorization struct {
+	AuthorizationService
+}
+
+func (a *selfAuthorization) IsAncestor(ancestorID, nodeID models.ID) (bool, *e.Error) {
+	return ancestorID == nodeID, nil
+}
+
+func (a *selfAuthorization) Can(jpID models.ID, action models.Action, resource models.ID) (bool, *e.Error) {
+	return action == models.ActionCreateDoc, nil
+}
+
+func (a *selfAuthorization) HasEventAccess(userID, jpID, eventID models.ID, access models.EventAccess) (bool, *e.Error) {
+	return false, nil
+}
+
+// It keeps the docs and the versions of the edited docs in memory. usedKB is the size of
+// the confirmed files of the other docs that is counted against the quotas.
+type memDocDAL struct {
+	dal.DocDAL
+	docs     map[models.ID]*models.Doc
+	versions []models.DocVersion
+	usedKB   uint64
+}
+
+func (d *memDocDAL) GetDocByID(docID models.ID) (*models.Doc, error) {
+	return d.docs[docID], nil
+}
+
+func (d *memDocDAL) GetEventMediaSize(eventID models.ID) (uint64, error) {
+	return d.usedKB, nil
+}
+
+func (d *memDocDAL) GetJPMediaSize(jpID models.ID) (uint64, error) {
+	return d.usedKB, nil
+}
+
+func (d *memDocDAL) ConfirmMedia(docID models.ID, media *models.MediaPath, eventQuotaKB, jpQuotaKB uint64) (bool, error) {
+	doc, ok := d.docs[docID]
+	if !ok {
+		return false, nil
+	}
+	for i := range doc.Paths {
+		if doc.Paths[i].FileName != media.FileName || doc.Paths[i].Status != models.MediaPending {
+			continue
+		}
+		for _, quota := range []uint64{eventQuotaKB, jpQuotaKB} {
+			if quota > 0 && d.usedKB+media.Size > quota {
+				return false, fmt.Errorf("failed to confirm file %s: %w", media.FileName, e.ErrQuotaExceeded)
+			}
+		}
+		doc.Paths[i] = *media
+		return true, nil
+	}
+	return false, nil
+}
+
+func TestConfirmUpload(t *testing.T) {
+	userID, jpID, otherJPID := models.ID(uuid.New()), models.ID(uuid.New()), models.ID(uuid.New())
+	eventID, docID, otherDocID := models.ID(uuid.New()), models.ID(uuid.New()), models.ID(uuid.New())
+	authToken := func(jpID models.ID) models.Token {
+		return models.Token(base64.StdEncoding.EncodeToString(
+			[]byte(eventID.String() + ":jwt:" + jpID.String())))
+	}
+	policy := &uploadPolicy{
+		extensions: map[models.FileExtension]models.MediaType{"jpg": models.MediaImage},
+		maxSizes:   map[models.MediaType]uint64{models.MediaImage: 100},
+		eventQuota: 500,
+	}
+
+	tests := []struct {
+		name         string
+		confirmation models.UploadConfirmation
+		usedKB       uint64
+		// Expected error code. If it's nil, the file must be confirmed.
+		errCode any
+	}{
+		{
+			name:         "pending file is confirmed",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: docID, FileName: "a.jpg", Size: 50},
+		},
+		{
+			name:         "confirmed file is not confirmed again",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: docID, FileName: "b.jpg", Size: 50},
+			errCode:      SENotFound,
+		},
+		{
+			name:         "unknown file is not added",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: docID, FileName: "c.jpg", Size: 50},
+			errCode:      SENotFound,
+		},
+		{
+			name:         "file exceeding the quota is rejected",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: docID, FileName: "a.jpg", Size: 50},
+			usedKB:       480,
+			errCode:      SEForbidden,
+		},
+		{
+			name:         "file not allowed by the policy is rejected",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: docID, FileName: "a.jpg", Size: 101},
+			errCode:      SEWrongParameter,
+		},
+		{
+			name:         "doc of another job position is forbidden",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: otherDocID, FileName: "a.jpg", Size: 50},
+			errCode:      SEForbidden,
+		},
+		{
+			name:         "job position without access to the event is forbidden",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(otherJPID), DocID: otherDocID, FileName: "a.jpg", Size: 50},
+			errCode:      SEForbidden,
+		},
+		{
+			name:         "unknown doc is not found",
+			confirmation: models.UploadConfirmation{AuthToken: authToken(jpID), DocID: models.ID(uuid.New()), FileName: "a.jpg", Size: 50},
+			errCode:      SENotFound,
+		},
+		{
+			name:         "invalid auth token fails",
+			confirmation: models.UploadConfirmation{AuthToken: "invalid", DocID: docID, FileName: "a.jpg", Size: 50},
+			errCode:      SEAuthFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			docDAL := &memDocDAL{usedKB: test.usedKB, docs: map[models.ID]*models.Doc{
+				docID: {ID: docID, CreatedBy: jpID, EventID: eventID, Paths: []models.MediaPath{
+					{Type: models.MediaImage, FileName: "a.jpg", Src: "a.jpg", Status: models.MediaPending},
+					{Type: models.MediaImage, FileName: "b.jpg", Src: "b.jpg", Status: models.MediaConfirmed},
+				}},
+				otherDocID: {ID: otherDocID, CreatedBy: otherJPID, EventID: eventID, Paths: []models.MediaPath{
+					{Type: models.MediaImage, FileName: "a.jpg", Src: "a.jpg", Status: models.MediaPending},
+				}},
+			}}
+			logger := l.NewSLogger(l.None, nil, io.Discard)
+			auditDAL := &memAuditDAL{}
+			service := newSFilePermissionService(nil, &fakeSessionService{userID: userID},
+				&memEventDAL{events: map[models.ID]models.Event{eventID: {ID: eventID, CreatedBy: jpID}}},
+				docDAL, &selfAuthorization{}, nil, policy, newSAuditService(auditDAL, nil, nil, logger), logger)
+
+			media, err := service.ConfirmUpload(&test.confirmation, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				stored := docDAL.docs[test.confirmation.DocID].Paths[0]
+				if media.Status != models.MediaConfirmed || stored != *media {
+					t.Errorf("expected stored file to be confirmed as %+v, got %+v", media, stored)
+				}
+			} else if err == nil {
+				t.Fatalf("expected error code %v, got nil", test.errCode)
+			} else if err.GetCode() != test.errCode {
+				t.Errorf("expected error code %v, got %v (%s)", test.errCode, err.GetCode(), err.Error())
+			}
+			if len(auditDAL.events) != 1 {
+				t.Errorf("expected 1 audit event, got %d", len(auditDAL.events))
+			}
+		})
+	}
+}
+
+func (d *memDocDAL) GetMediaEventID(docID models.ID, fileName string) (*models.ID, error) {
+	if doc, ok := d.docs[docID]; ok {
+		for _, media := range doc.Paths {
+			if media.FileName == fileName {
+				return &doc.EventID, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func TestFilePermissionDecisionsAudit(t *testing.T) {
+	userID, jpID, otherJPID := models.ID(uuid.New()), models.ID(uuid.New()), models.ID(uuid.New())
+	eventID, docID := models.ID(uuid.New()), models.ID(uuid.New())
+	authToken := func(jpID models.ID) models.Token {
+		return models.Token(base64.StdEncoding.EncodeToString(
+			[]byte(eventID.String() + ":jwt:" + jpID.String())))
+	}
+	signer := newObjectTokenSigner([]byte("secret"), time.Minute)
+	client := models.ClientInfo{IP: "10.0.0.1", UserAgent: "file-transfer"}
+
+	tests := []struct {
+		name   string
+		action models.AuditAction
+		// It asks the decision of the service.
+		decide  func(service FilePermissionService) *e.Error
+		outcome models.AuditOutcome
+		// Expected actor job position. (the target is the event)
+		actorJP models.ID
+		details string
+	}{
+		{
+			name: "allowed download", action: models.AuditFileDownload, outcome: models.AuditSuccess, actorJP: jpID,
+			decide: func(service FilePermissionService) *e.Error {
+				_, err := service.IsAllowedDownload(&models.DownloadReq{AuthToken: authToken(jpID),
+					ObjectTokens: []models.Token{signer.sign(docID, "a.jpg"), "invalid"}}, client)
+				return err
+			},
+			details: "1 of 2 files are allowed",
+		},
+		{
+			name: "denied download", action: models.AuditFileDownload, outcome: models.AuditFailure, actorJP: otherJPID,
+			decide: func(service FilePermissionService) *e.Error {
+				_, err := service.IsAllowedDownload(&models.DownloadReq{AuthToken: authToken(otherJPID)}, client)
+				return err
+			},
+		},
+		{
+			name: "allowed upload", action: models.AuditFileUpload, outcome: models.AuditSuccess, actorJP: jpID,
+			decide: func(service FilePermissionService) *e.Error {
+				_, err := service.IsAllowedUpload(&models.UploadReq{AuthToken: authToken(jpID),
+					ObjectTypes: map[models.FileExtension]uint{"jpg": 1}}, client)
+				return err
+			},
+			details: "jpg: true",
+		},
+		{
+			name: "denied upload", action: models.AuditFileUpload, outcome: models.AuditFailure, actorJP: otherJPID,
+			decide: func(service FilePermissionService) *e.Error {
+				_, err := service.IsAllowedUpload(&models.UploadReq{AuthToken: authToken(otherJPID),
+					ObjectTypes: map[models.FileExtension]uint{"jpg": 1}}, client)
+				return err
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			docDAL := &memDocDAL{docs: map[models.ID]*models.Doc{
+				docID: {ID: docID, CreatedBy: jpID, EventID: eventID, Paths: []models.MediaPath{
+					{Type: models.MediaImage, FileName: "a.jpg", Src: "a.jpg", Status: models.MediaConfirmed},
+				}},
+			}}
+			policy := &uploadPolicy{
+				extensions: map[models.FileExtension]models.MediaType{"jpg": models.MediaImage},
+				maxSizes:   map[models.MediaType]uint64{models.MediaImage: 100},
+			}
+			logger := l.NewSLogger(l.None, nil, io.Discard)
+			auditDAL := &memAuditDAL{}
+			service := newSFilePermissionService(nil, &fakeSessionService{userID: userID},
+				&memEventDAL{events: map[models.ID]models.Event{eventID: {ID: eventID, CreatedBy: jpID}}},
+				docDAL, &selfAuthorization{}, signer, policy, newSAuditService(auditDAL, nil, nil, logger), logger)
+
+			err := test.decide(service)
+			if test.outcome == models.AuditSuccess && err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			} else if test.outcome == models.AuditFailure && (err == nil || err.GetCode() != SEForbidden) {
+				t.Fatalf("expected error code %d, got %v", SEForbidden, err)
+			}
+			if len(auditDAL.events) != 1 {
+				t.Fatalf("expected 1 audit event, got %d", len(auditDAL.events))
+			}
+			stored := auditDAL.events[0]
+			if stored.Action != test.action || stored.Outcome != test.outcome || stored.ActorUserID != userID ||
+				stored.ActorJPID != test.actorJP || stored.TargetType != models.AuditTargetEvent ||
+				stored.TargetID != eventID || stored.IP != client.IP {
+				t.Errorf("unexpected audit event %+v", stored)
+			}
+			if test.details != "" && stored.Details != test.details {
+				t.Errorf("expected details %q, got %q", test.details, stored.Details)
+			}
+		})
+	}
+}
+
+func TestFilePermissionEventAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		// Job position of the auth token and the owner of the event
+		jp, owner func(f *roleFixture) models.ID
+		// If it's true, the job position is allowed to create docs by the writer role.
+		isWriter bool
+		// It's granted to the job position by the access list of the event, if it's not empty.
+		acl         models.EventAccess
+		canDownload bool
+		canUpload   bool
+	}{
+		{name: "owner allowed to create docs", jp: func(f *roleFixture) models.ID { return f.sibling },
+			owner: func(f *roleFixture) models.ID { return f.sibling }, isWriter: true, canDownload: true, canUpload: true},
+		{name: "owner not allowed to create docs", jp: func(f *roleFixture) models.ID { return f.child },
+			owner: func(f *roleFixture) models.ID { return f.child }, canDownload: true},
+		{name: "ancestor allowed to view the subtree and create docs", jp: func(f *roleFixture) models.ID { return f.manager },
+			owner: func(f *roleFixture) models.ID { return f.child }, canDownload: true},
+		{name: "reader of the access list", jp: func(f *roleFixture) models.ID { return f.sibling },
+			owner: func(f *roleFixture) models.ID { return f.child }, isWriter: true, acl: models.EventAccessRead, canDownload: true},
+		{name: "contributor of the access list not allowed to create docs", jp: func(f *roleFixture) models.ID { return f.grandchild },
+			owner: func(f *roleFixture) models.ID { return f.sibling }, acl: models.EventAccessContribute, canDownload: true},
+		{name: "contributor of the access list allowed to create docs", jp: func(f *roleFixture) models.ID { return f.sibling },
+			owner: func(f *roleFixture) models.ID { return f.child }, isWriter: true, acl: models.EventAccessContribute,
+			canDownload: true, canUpload: true},
+		{name: "job position without access", jp: func(f *roleFixture) models.ID { return f.sibling },
+			owner: func(f *roleFixture) models.ID { return f.child }, isWriter: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, authorization, roleDAL := newRoleFixture(t)
+			aclDAL := &memEventACLDAL{}
+			authorization.eventACL = aclDAL
+			jpID, eventID := test.jp(f), models.ID(uuid.New())
+			if test.isWriter {
+				roleDAL.AssignRole(jpID, f.writerRole, false)
+			}
+			if test.acl != "" {
+				aclDAL.CreateEventACL(&models.EventACL{EventID: eventID, GranteeType: models.GranteeJP,
+					GranteeID: jpID, Access: test.acl})
+			}
+			logger := l.NewSLogger(l.None, nil, io.Discard)
+			service := newSFilePermissionService(nil, &fakeSessionService{userID: jpID},
+				&memEventDAL{events: map[models.ID]models.Event{eventID: {ID: eventID, CreatedBy: test.owner(f)}}},
+				&memDocDAL{}, authorization, nil, &uploadPolicy{
+					extensions: map[models.FileExtension]models.MediaType{"jpg": models.MediaImage},
+					maxSizes:   map[models.MediaType]uint64{models.MediaImage: 100},
+				}, newSAuditService(&memAuditDAL{}, nil, nil, logger), logger)
+			authToken := models.Token(base64.StdEncoding.EncodeToString(
+				[]byte(eventID.String() + ":jwt:" + jpID.String())))
+
+			_, err := service.IsAllowedDownload(&models.DownloadReq{AuthToken: authToken}, models.ClientInfo{})
+			if test.canDownload && err != nil {
+				t.Errorf("unexpected download error %s", err.Error())
+			} else if !test.canDownload && (err == nil || err.GetCode() != SEForbidden) {
+				t.Errorf("expected download error code %d, got %v", SEForbidden, err)
+			}
+			_, err = service.IsAllowedUpload(&models.UploadReq{AuthToken: authToken,
+				ObjectTypes: map[models.FileExtension]uint{"jpg": 1}}, models.ClientInfo{})
+			if test.canUpload && err != nil {
+				t.Errorf("unexpected upload error %s", err.Error())
+			} else if !test.canUpload && (err == nil || err.GetCode() != SEForbidden) {
+				t.Errorf("expected upload error code %d, got %v", SEForbidden, err)
+			}
+		})
+	}
+}
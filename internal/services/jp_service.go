@@ -7,6 +7,7 @@ import (
 	"DMS/internal/hierarchy"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
+	"errors"
 	"fmt"
 )
 
@@ -17,28 +18,109 @@ type JPService interface {
 	// SEDBError- SENotFound
 	GetUserJPs(user *m.User) (*[]m.UserJobPosition, *e.Error)
 	// Create user job position with its permissions for the given user and details then, reutrn its id.
+	// The job position could have several parents and all of them must exist. callerJPID
+	// is the job position of the user userID and must be allowed to create job positions
+	// under each of the parents.
 	//
 	// Possible error codes the function could returns:
-	// SEDBError- SENotFound- InMemoryUpdateFailed
-	CreateUserJP(jp *m.UserJobPosition, permissions *m.Permission) (*m.ID, *e.Error)
+	// SEDBError- SENotFound- SEWrongParameter- InMemoryUpdateFailed- SEJPNotMatchedUser-
+	// SENotPermission
+	CreateUserJP(userID, callerJPID m.ID, jp *m.UserJobPosition, permissions *m.Permission, client m.ClientInfo) (*m.ID, *e.Error)
 	// Create admin job position with its permissions for the given user and details then, reutrn its id.
+	// callerJPID is the job position of the user userID and must be an admin job position.
 	//
 	// Possible error codes the function could returns:
-	// SEDBError
-	CreateAdminJP(jp *m.AdminJobPosition, permissions *m.Permission) (*m.ID, *e.Error)
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- InMemoryUpdateFailed
+	CreateAdminJP(userID, callerJPID m.ID, jp *m.AdminJobPosition, permissions *m.Permission, client m.ClientInfo) (*m.ID, *e.Error)
+	// Create the first admin with the name and the phone number together with its admin
+	// job position jp with all permissions, and return their ids. It's just allowed while
+	// there's not any admin job position, so it could be done once at setup. The check
+	// and the creation are done in a transaction, so concurrent calls create just one
+	// admin. If a user with the phone number exists, it must be an admin (not created by
+	// another user). It's not exposed by the API.
+	//
+	// Possible error codes the function could returns:
+	// SEDBError- SEForbidden- SEExists- InMemoryUpdateFailed
+	BootstrapAdmin(name string, phone m.PhoneNumber, jp *m.AdminJobPosition, client m.ClientInfo) (userID, jpID *m.ID, err *e.Error)
 	// Return true if a job position with given ID belongs to a user with given ID.
 	//
 	// Possible error codes:
 	// SEDBError
 	IsExistsUserWithJP(userID, jpID m.ID) (bool, error)
+	// Return true if the job position exists. (It's not deleted)
+	//
+	// Possible error codes:
+	// SEDBError
+	IsExistsJP(jpID m.ID) (bool, *e.Error)
+	// Update title or region of the job position jpID. callerJPID is the job position of
+	// the user who does the action and must be admin or an ancestor of jpID that is
+	// allowed to manage job positions.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	UpdateJP(userID, callerJPID, jpID m.ID, update *m.JPUpdate, client m.ClientInfo) *e.Error
+	// Replace all parents of the job position jpID with newParentID. The caller must be
+	// admin or an ancestor of both of them. Moving a job position under itself or one of
+	// its nested childs is rejected.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SEWrongParameter-
+	// SEInMemoryUpdateFailed- SENotPermission
+	MoveJP(userID, callerJPID, jpID, newParentID m.ID, client m.ClientInfo) *e.Error
+	// Disable the job position jpID, so its user can't use it anymore. The caller must be
+	// admin or an ancestor of jpID.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	DisableJP(userID, callerJPID, jpID m.ID, client m.ClientInfo) *e.Error
+	// Enable the disabled job position jpID. The caller must be admin or an ancestor of jpID.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	EnableJP(userID, callerJPID, jpID m.ID, client m.ClientInfo) *e.Error
+	// Soft delete the job position jpID. The caller must be admin or an ancestor of jpID.
+	// Job positions that have childs couldn't be deleted.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SEJPHasChilds-
+	// SEInMemoryUpdateFailed- SENotPermission
+	DeleteJP(userID, callerJPID, jpID m.ID, client m.ClientInfo) *e.Error
+	// Return direct child job positions of jpID. A job position could just browse its own
+	// subtree. Means callerJPID must be jpID, its ancestor or an admin. Browsing the
+	// subtree needs to be allowed to view it.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	GetChildJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error)
+	// Return all nested child job positions of jpID without itself. A job position could
+	// just browse its own subtree.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	GetDescendantJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error)
+	// Return ancestors of jpID. The nearer ancestors come first. Ancestors out of the
+	// subtree of callerJPID are not returned, except the caller is admin.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	GetAncestorJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error)
+	// Return the subtree of jpID as nested job positions. depth is the number of levels of
+	// childs in the tree. e.g. If it's 1, just direct childs are returned. A job position
+	// could just browse its own subtree.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotFound- SENotAncestor- SENotPermission
+	GetJPTree(userID, callerJPID, jpID m.ID, depth int) (*m.JPNode, *e.Error)
 }
 
 // It's a simple implementation of JPService interface.
 // This implementation has minimum functionalities.
 type sJPService struct {
-	jp        dal.JPDAL
-	logger    l.Logger
-	hierarchy *hierarchy.HierarchyTree
+	jp            dal.JPDAL
+	logger        l.Logger
+	hierarchy     *hierarchy.HierarchyTree
+	authorization AuthorizationService
+	audit         AuditService
 }
 
 func (s *sJPService) GetUserJPs(user *m.User) (*[]m.UserJobPosition, *e.Error) {
@@ -53,7 +135,31 @@ func (s *sJPService) GetUserJPs(user *m.User) (*[]m.UserJobPosition, *e.Error) {
 
 // Note that in this implementation, createdTime value doesn't matter and createdTime
 // is always the current time.
-func (s *sJPService) CreateUserJP(jp *m.UserJobPosition, permissions *m.Permission) (*m.ID, *e.Error) {
+func (s *sJPService) CreateUserJP(userID, callerJPID m.ID, jp *m.UserJobPosition, permissions *m.Permission, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.createUserJP(userID, callerJPID, jp, permissions)
+	s.audit.Record(newAuditEvent(m.AuditJPCreate, userID, callerJPID, m.AuditTargetJP, idOrNil(id), client), err)
+	return id, err
+}
+
+func (s *sJPService) createUserJP(userID, callerJPID m.ID, jp *m.UserJobPosition, permissions *m.Permission) (*m.ID, *e.Error) {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return nil, err
+	}
+	parents := jp.Parents()
+	if len(parents) == 0 {
+		return nil, e.NewErrorP("job position must have at least one parent", SEWrongParameter)
+	}
+	for _, parentID := range parents {
+		if parent, err := s.jp.GetJPByID(parentID); err != nil {
+			return nil, e.NewErrorP(err.Error(), SEDBError)
+		} else if parent == nil {
+			return nil, e.NewErrorP("parent job position with id %s not found", SENotFound, parentID.String())
+		}
+		if err := s.checkCan(callerJPID, m.ActionCreateJP, parentID); err != nil {
+			return nil, err
+		}
+	}
+
 	jpID, err := s.jp.CreateUserJPWithPermissions(jp, permissions)
 	if err != nil {
 		return nil, e.NewErrorP(err.Error(), SEDBError).
@@ -65,26 +171,58 @@ func (s *sJPService) CreateUserJP(jp *m.UserJobPosition, permissions *m.Permissi
 			)
 	}
 
-	addEdge := make(chan graph.GraphChange, 1)
-	defer close(addEdge)
-	responseErr := make(chan error)
-	defer close(responseErr)
-	addEdge <- graph.GraphChange{
-		Type:        graph.AddEdge,
-		Edge:        *jpEdge2GraphEdge(dal.JPEdge{JP: *jpID, Parent: jp.ParentID}),
-		ResponseErr: responseErr,
-	}
-	err = <-responseErr
+	err = pushGraphChanges(s.hierarchy.Graph(), parentEdgeChanges(graph.AddEdge, *jpID, parents)...)
 	if err != nil {
 		return nil, e.NewErrorP("failed to update hierarchy tree: %s", SEInMemoryUpdateFailed, err.Error())
 	}
-	s.hierarchy.Graph().ProcessChanges(addEdge)
 	return jpID, nil
 }
 
 // Note that in this implementation, createdTime value doesn't matter and createdTime
 // is always the current time.
-func (s *sJPService) CreateAdminJP(jp *m.AdminJobPosition, permissions *m.Permission) (*m.ID, *e.Error) {
+func (s *sJPService) CreateAdminJP(userID, callerJPID m.ID, jp *m.AdminJobPosition, permissions *m.Permission,
+	client m.ClientInfo) (*m.ID, *e.Error) {
+	var id *m.ID
+	err := s.checkIsAdminCaller(userID, callerJPID)
+	if err == nil {
+		id, err = s.createAdminJP(jp, permissions)
+	}
+	event := newAuditEvent(m.AuditAdminJPCreate, userID, callerJPID, m.AuditTargetJP, idOrNil(id), client)
+	event.Details = fmt.Sprintf("admin job position of user %s", jp.UserID.String())
+	s.audit.Record(event, err)
+	return id, err
+}
+
+func (s *sJPService) BootstrapAdmin(name string, phone m.PhoneNumber, jp *m.AdminJobPosition, client m.ClientInfo) (*m.ID, *m.ID, *e.Error) {
+	userID, jpID, err := s.bootstrapAdmin(name, phone, jp)
+	userEvent := newAuditEvent(m.AuditAdminCreate, m.NilID, m.NilID, m.AuditTargetUser, idOrNil(userID), client)
+	userEvent.Details = "bootstrap admin"
+	s.audit.Record(userEvent, err)
+	jpEvent := newAuditEvent(m.AuditAdminJPCreate, m.NilID, m.NilID, m.AuditTargetJP, idOrNil(jpID), client)
+	jpEvent.Details = fmt.Sprintf("bootstrap admin job position of user %s", idOrNil(userID).String())
+	s.audit.Record(jpEvent, err)
+	return userID, jpID, err
+}
+
+func (s *sJPService) bootstrapAdmin(name string, phone m.PhoneNumber, jp *m.AdminJobPosition) (*m.ID, *m.ID, *e.Error) {
+	userID, jpID, err := s.jp.BootstrapAdmin(name, phone, jp,
+		&m.Permission{IsAllowCreateJP: true, IsAllowApproveEvent: true})
+	if errors.Is(err, e.ErrExists) {
+		return nil, nil, e.NewErrorP(err.Error(), SEExists)
+	} else if err != nil {
+		return nil, nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if jpID == nil {
+		return nil, nil, e.NewErrorP("there's an admin job position already and bootstrap is done", SEForbidden)
+	}
+
+	err = pushGraphChanges(s.hierarchy.Graph(), parentEdgeChanges(graph.AddEdge, *jpID, nil)...)
+	if err != nil {
+		return userID, jpID, e.NewErrorP("failed to update hierarchy tree: %s", SEInMemoryUpdateFailed, err.Error())
+	}
+	return userID, jpID, nil
+}
+
+func (s *sJPService) createAdminJP(jp *m.AdminJobPosition, permissions *m.Permission) (*m.ID, *e.Error) {
 	jpID, err := s.jp.CreateAdminJPWithPermissions(jp, permissions)
 	if err != nil {
 		return nil, e.NewErrorP(err.Error(), SEDBError).
@@ -95,6 +233,11 @@ func (s *sJPService) CreateAdminJP(jp *m.AdminJobPosition, permissions *m.Permis
 				),
 			)
 	}
+
+	err = pushGraphChanges(s.hierarchy.Graph(), parentEdgeChanges(graph.AddEdge, *jpID, nil)...)
+	if err != nil {
+		return nil, e.NewErrorP("failed to update hierarchy tree: %s", SEInMemoryUpdateFailed, err.Error())
+	}
 	return jpID, nil
 }
 
@@ -106,7 +249,420 @@ func (s *sJPService) IsExistsUserWithJP(userID, jpID m.ID) (bool, error) {
 	return isExists, nil
 }
 
+func (s *sJPService) IsExistsJP(jpID m.ID) (bool, *e.Error) {
+	jp, err := s.jp.GetJPByID(jpID)
+	if err != nil {
+		return false, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return jp != nil, nil
+}
+
+func (s *sJPService) UpdateJP(userID, callerJPID, jpID m.ID, update *m.JPUpdate, client m.ClientInfo) *e.Error {
+	err := s.updateJP(userID, callerJPID, jpID, update)
+	s.audit.Record(newAuditEvent(m.AuditJPUpdate, userID, callerJPID, m.AuditTargetJP, jpID, client), err)
+	return err
+}
+
+func (s *sJPService) updateJP(userID, callerJPID, jpID m.ID, update *m.JPUpdate) *e.Error {
+	if _, err := s.checkManageAccess(userID, callerJPID, jpID); err != nil {
+		return err
+	}
+	isFound, err := s.jp.UpdateJP(jpID, update)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isFound {
+		return e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+	return nil
+}
+
+func (s *sJPService) MoveJP(userID, callerJPID, jpID, newParentID m.ID, client m.ClientInfo) *e.Error {
+	err := s.moveJP(userID, callerJPID, jpID, newParentID)
+	event := newAuditEvent(m.AuditJPMove, userID, callerJPID, m.AuditTargetJP, jpID, client)
+	event.Details = fmt.Sprintf("new parent %s", newParentID.String())
+	s.audit.Record(event, err)
+	return err
+}
+
+func (s *sJPService) moveJP(userID, callerJPID, jpID, newParentID m.ID) *e.Error {
+	jp, err := s.checkManageAccess(userID, callerJPID, jpID)
+	if err != nil {
+		return err
+	}
+	if len(jp.ParentIDs) == 1 && newParentID == jp.ParentIDs[0] {
+		return nil
+	} else if newParentID == jpID {
+		return e.NewErrorP("job position %s can't be parent of itself", SEWrongParameter, jpID.String())
+	}
+
+	newParent, dbErr := s.jp.GetJPByID(newParentID)
+	if dbErr != nil {
+		return e.NewErrorP(dbErr.Error(), SEDBError)
+	} else if newParent == nil {
+		return e.NewErrorP("new parent job position with id %s not found", SEWrongParameter,
+			newParentID.String())
+	}
+	if err := s.checkIsAdminOrAncestor(callerJPID, newParentID); err != nil {
+		return err
+	}
+	if err := s.checkCan(callerJPID, m.ActionManageJP, newParentID); err != nil {
+		return err
+	}
+	// If the new parent is one of nested childs of the job position, the move makes a cycle.
+	isCycle, err := s.authorization.IsAncestor(jpID, newParentID)
+	if err != nil {
+		return err
+	} else if isCycle {
+		return e.NewErrorP("job position %s is an ancestor of %s and can't be its child",
+			SEWrongParameter, jpID.String(), newParentID.String())
+	}
+
+	isFound, dbErr := s.jp.MoveJP(jpID, newParentID)
+	if dbErr != nil {
+		return e.NewErrorP(dbErr.Error(), SEDBError)
+	} else if !isFound {
+		return e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+
+	changes := append(parentEdgeChanges(graph.RemoveEdge, jpID, jp.ParentIDs),
+		parentEdgeChanges(graph.AddEdge, jpID, []m.ID{newParentID})...)
+	dbErr = pushGraphChanges(s.hierarchy.Graph(), changes...)
+	if dbErr != nil {
+		return e.NewErrorP("failed to update hierarchy tree: %s", SEInMemoryUpdateFailed, dbErr.Error())
+	}
+	return nil
+}
+
+func (s *sJPService) DisableJP(userID, callerJPID, jpID m.ID, client m.ClientInfo) *e.Error {
+	err := s.setJPDisability(userID, callerJPID, jpID, true)
+	s.audit.Record(newAuditEvent(m.AuditJPDisable, userID, callerJPID, m.AuditTargetJP, jpID, client), err)
+	return err
+}
+
+func (s *sJPService) EnableJP(userID, callerJPID, jpID m.ID, client m.ClientInfo) *e.Error {
+	err := s.setJPDisability(userID, callerJPID, jpID, false)
+	s.audit.Record(newAuditEvent(m.AuditJPEnable, userID, callerJPID, m.AuditTargetJP, jpID, client), err)
+	return err
+}
+
+func (s *sJPService) setJPDisability(userID, callerJPID, jpID m.ID, isDisabled bool) *e.Error {
+	if _, err := s.checkManageAccess(userID, callerJPID, jpID); err != nil {
+		return err
+	}
+	isFound, err := s.jp.SetJPDisability(jpID, isDisabled)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isFound {
+		return e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+	return nil
+}
+
+func (s *sJPService) DeleteJP(userID, callerJPID, jpID m.ID, client m.ClientInfo) *e.Error {
+	err := s.deleteJP(userID, callerJPID, jpID)
+	s.audit.Record(newAuditEvent(m.AuditJPDelete, userID, callerJPID, m.AuditTargetJP, jpID, client), err)
+	return err
+}
+
+func (s *sJPService) deleteJP(userID, callerJPID, jpID m.ID) *e.Error {
+	jp, err := s.checkManageAccess(userID, callerJPID, jpID)
+	if err != nil {
+		return err
+	}
+	// Nested childs contain the job position itself.
+	childs, err := s.authorization.GetNestedChilds(jpID)
+	if err != nil {
+		return err
+	} else if len(childs) > 1 {
+		return e.NewErrorP("job position %s has %d nested childs", SEJPHasChilds, jpID.String(), len(childs)-1)
+	}
+
+	isFound, dbErr := s.jp.DeleteJP(jpID)
+	if dbErr != nil {
+		return e.NewErrorP(dbErr.Error(), SEDBError)
+	} else if !isFound {
+		return e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+
+	dbErr = pushGraphChanges(s.hierarchy.Graph(), parentEdgeChanges(graph.RemoveEdge, jpID, jp.ParentIDs)...)
+	if dbErr != nil {
+		return e.NewErrorP("failed to update hierarchy tree: %s", SEInMemoryUpdateFailed, dbErr.Error())
+	}
+	return nil
+}
+
+func (s *sJPService) GetChildJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error) {
+	if err := s.checkBrowseAccess(userID, callerJPID, jpID); err != nil {
+		return nil, err
+	}
+	childs, err := s.hierarchy.GetChilds(id2Vertex(jpID))
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return s.getJPNodes(jpID, childs)
+}
+
+func (s *sJPService) GetDescendantJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error) {
+	if err := s.checkBrowseAccess(userID, callerJPID, jpID); err != nil {
+		return nil, err
+	}
+	// Nested childs contain the job position itself as the first item.
+	descendants, err := s.hierarchy.GetNestedChilds(id2Vertex(jpID))
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	if len(descendants) > 0 {
+		descendants = descendants[1:]
+	}
+	return s.getJPNodes(jpID, descendants)
+}
+
+func (s *sJPService) GetAncestorJPs(userID, callerJPID, jpID m.ID) (*[]m.JPNode, *e.Error) {
+	if err := s.checkBrowseAccess(userID, callerJPID, jpID); err != nil {
+		return nil, err
+	}
+	isAdmin, err := s.authorization.IsAdminJP(callerJPID)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, dbErr := s.hierarchy.GetAncestors(id2Vertex(jpID))
+	if dbErr != nil {
+		return nil, e.NewErrorP(dbErr.Error(), SEDBError)
+	}
+
+	accessibleAncestors := make([]graph.Vertex, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		if !isAdmin {
+			ancestorID, err := vertex2ID(ancestor)
+			if err != nil {
+				return nil, e.NewErrorP(err.Error(), SEDBError)
+			}
+			if isAccessible, err := s.authorization.IsAncestor(callerJPID, ancestorID); err != nil {
+				return nil, err
+			} else if !isAccessible {
+				continue
+			}
+		}
+		accessibleAncestors = append(accessibleAncestors, ancestor)
+	}
+	return s.getJPNodes(jpID, accessibleAncestors)
+}
+
+func (s *sJPService) GetJPTree(userID, callerJPID, jpID m.ID, depth int) (*m.JPNode, *e.Error) {
+	if err := s.checkBrowseAccess(userID, callerJPID, jpID); err != nil {
+		return nil, err
+	}
+
+	// Collect childs of each job position in the tree level by level.
+	childs := make(map[m.ID][]m.ID)
+	ids := []m.ID{jpID}
+	level := []m.ID{jpID}
+	for i := 0; i < depth && len(level) > 0; i++ {
+		nextLevel := make([]m.ID, 0)
+		for _, id := range level {
+			vertices, err := s.hierarchy.GetChilds(id2Vertex(id))
+			if err != nil {
+				return nil, e.NewErrorP(err.Error(), SEDBError)
+			}
+			for _, vertex := range vertices {
+				childID, err := vertex2ID(vertex)
+				if err != nil {
+					return nil, e.NewErrorP(err.Error(), SEDBError)
+				}
+				childs[id] = append(childs[id], childID)
+				nextLevel = append(nextLevel, childID)
+			}
+		}
+		ids = append(ids, nextLevel...)
+		level = nextLevel
+	}
+
+	nodes, err := s.getJPNodesWithRoles(ids)
+	if err != nil {
+		return nil, err
+	}
+	nodesByID := make(map[m.ID]m.JPNode, len(*nodes))
+	for _, node := range *nodes {
+		nodesByID[node.ID] = node
+	}
+	if _, isFound := nodesByID[jpID]; !isFound {
+		return nil, e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+	root := buildJPTree(jpID, childs, nodesByID)
+	return &root, nil
+}
+
+// Build the subtree of the job position id from the childs of each job position.
+// Job positions without details in nodes are ignored.
+func buildJPTree(id m.ID, childs map[m.ID][]m.ID, nodes map[m.ID]m.JPNode) m.JPNode {
+	node := nodes[id]
+	for _, childID := range childs[id] {
+		if _, isFound := nodes[childID]; isFound {
+			node.Childs = append(node.Childs, buildJPTree(childID, childs, nodes))
+		}
+	}
+	return node
+}
+
+// Return details of the given job positions in their order. jpID is the job position
+// the vertices are related to and if it doesn't exist, returns SENotFound error.
+func (s *sJPService) getJPNodes(jpID m.ID, vertices []graph.Vertex) (*[]m.JPNode, *e.Error) {
+	ids := []m.ID{jpID}
+	for _, vertex := range vertices {
+		id, err := vertex2ID(vertex)
+		if err != nil {
+			return nil, e.NewErrorP(err.Error(), SEDBError)
+		}
+		if !id.IsNil() {
+			ids = append(ids, id)
+		}
+	}
+	nodes, err := s.getJPNodesWithRoles(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	nodesByID := make(map[m.ID]m.JPNode, len(*nodes))
+	for _, node := range *nodes {
+		nodesByID[node.ID] = node
+	}
+	if _, isFound := nodesByID[jpID]; !isFound {
+		return nil, e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+	result := make([]m.JPNode, 0, len(ids)-1)
+	for _, id := range ids[1:] {
+		if node, isFound := nodesByID[id]; isFound {
+			result = append(result, node)
+		}
+	}
+	return &result, nil
+}
+
+// Return details of the given job positions with their roles.
+func (s *sJPService) getJPNodesWithRoles(ids []m.ID) (*[]m.JPNode, *e.Error) {
+	nodes, err := s.jp.GetJPNodes(ids)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	roles, err2 := s.authorization.GetJPsRoles(ids)
+	if err2 != nil {
+		return nil, err2
+	}
+	for i := range *nodes {
+		(*nodes)[i].Roles = roles[(*nodes)[i].ID]
+	}
+	return nodes, nil
+}
+
+// Return changes of the edges from each parent to the job position. If there's not any
+// parent, the edge from "NilVertex" is used.
+func parentEdgeChanges(changeType graph.GraphChangeType, jpID m.ID, parents []m.ID) []graph.GraphChange {
+	if len(parents) == 0 {
+		parents = []m.ID{m.NilID}
+	}
+	changes := make([]graph.GraphChange, len(parents))
+	for i, parentID := range parents {
+		changes[i] = graph.GraphChange{
+			Type: changeType,
+			Edge: *jpEdge2GraphEdge(dal.JPEdge{JP: jpID, Parent: parentID}),
+		}
+	}
+	return changes
+}
+
+// Check the caller job position belongs to the user and jpID is in its subtree. Means
+// the caller is jpID itself, its ancestor or an admin. The caller must be allowed to
+// view its subtree.
+func (s *sJPService) checkBrowseAccess(userID, callerJPID, jpID m.ID) *e.Error {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return err
+	}
+	if callerJPID != jpID {
+		if err := s.checkIsAdminOrAncestor(callerJPID, jpID); err != nil {
+			return err
+		}
+	}
+	return s.checkCan(callerJPID, m.ActionViewSubtree, jpID)
+}
+
+// Check the caller job position belongs to the user and it could manage the job
+// position jpID. Means it's admin or an ancestor of jpID that is allowed to manage job
+// positions. A job position can't manage itself. Return the job position jpID.
+func (s *sJPService) checkManageAccess(userID, callerJPID, jpID m.ID) (*m.UserJobPosition, *e.Error) {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return nil, err
+	}
+
+	jp, err := s.jp.GetJPByID(jpID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if jp == nil {
+		return nil, e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+	if callerJPID == jpID {
+		return nil, e.NewErrorP("job position %s can't manage itself", SENotAncestor, jpID.String())
+	}
+	if err := s.checkIsAdminOrAncestor(callerJPID, jpID); err != nil {
+		return nil, err
+	}
+	if err := s.checkCan(callerJPID, m.ActionManageJP, jpID); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+// Check the caller job position belongs to the user.
+func (s *sJPService) checkCallerJP(userID, callerJPID m.ID) *e.Error {
+	if isExists, err := s.IsExistsUserWithJP(userID, callerJPID); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isExists {
+		return e.NewErrorP("job position %s doesn't belong to user %s", SEJPNotMatchedUser,
+			callerJPID.String(), userID.String())
+	}
+	return nil
+}
+
+// Check the caller job position belongs to the user and it's an admin job position.
+func (s *sJPService) checkIsAdminCaller(userID, callerJPID m.ID) *e.Error {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return err
+	}
+	if isAdmin, err := s.authorization.IsAdminJP(callerJPID); err != nil {
+		return err
+	} else if !isAdmin {
+		return e.NewErrorP("job position %s is not admin", SENotPermission, callerJPID.String())
+	}
+	return nil
+}
+
+// Check the caller job position is allowed to do the action on the resource.
+func (s *sJPService) checkCan(callerJPID m.ID, action m.Action, resource m.ID) *e.Error {
+	if can, err := s.authorization.Can(callerJPID, action, resource); err != nil {
+		return err
+	} else if !can {
+		return e.NewErrorP("job position %s is not allowed to do %s on %s", SENotPermission,
+			callerJPID.String(), action, resource.String())
+	}
+	return nil
+}
+
+func (s *sJPService) checkIsAdminOrAncestor(callerJPID, jpID m.ID) *e.Error {
+	if isAdmin, err := s.authorization.IsAdminJP(callerJPID); err != nil {
+		return err
+	} else if isAdmin {
+		return nil
+	}
+	if isAncestor, err := s.authorization.IsAncestor(callerJPID, jpID); err != nil {
+		return err
+	} else if !isAncestor {
+		return e.NewErrorP("job position %s is not an ancestor of %s", SENotAncestor,
+			callerJPID.String(), jpID.String())
+	}
+	return nil
+}
+
 // Create an instance of sJPService struct
-func newSJPService(jp dal.JPDAL, hierarchy *hierarchy.HierarchyTree, logger l.Logger) JPService {
-	return &sJPService{jp, logger, hierarchy}
+func newSJPService(jp dal.JPDAL, hierarchy *hierarchy.HierarchyTree, authorization AuthorizationService,
+	audit AuditService, logger l.Logger) JPService {
+	return &sJPService{jp, logger, hierarchy, authorization, audit}
 }
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	"DMS/internal/graph"
+	"DMS/internal/hierarchy"
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// It bootstraps the admin like the database does in a transaction. users are the
+// creators of the users by their phone numbers. (nil for admins)
+type memBootstrapJPDAL struct {
+	dal.JPDAL
+	users    map[models.PhoneNumber]*models.ID
+	adminJPs []models.AdminJobPosition
+}
+
+func (d *memBootstrapJPDAL) BootstrapAdmin(name string, phone models.PhoneNumber, jp *models.AdminJobPosition, permission *models.Permission) (*models.ID, *models.ID, error) {
+	if len(d.adminJPs) > 0 {
+		return nil, nil, nil
+	}
+	if createdBy, ok := d.users[phone]; ok && createdBy != nil {
+		return nil, nil, fmt.Errorf("the user with phone number %s is not admin: %w", phone.ToString(), e.ErrExists)
+	}
+	d.users[phone] = nil
+	userID, jpID := models.ID(uuid.New()), models.ID(uuid.New())
+	jp.UserID = userID
+	d.adminJPs = append(d.adminJPs, *jp)
+	return &userID, &jpID, nil
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	newService := func(jpDAL *memBootstrapJPDAL) (*sJPService, *memAuditDAL) {
+		tree := hierarchy.NewHierarchyTree(graph.NewDynamicGraph(graph.NewMemoryStorage(logger), logger), logger)
+		auditDAL := &memAuditDAL{}
+		service := newSJPService(jpDAL, tree, nil, newSAuditService(auditDAL, nil, nil, logger), logger)
+		return service.(*sJPService), auditDAL
+	}
+	creator := models.ID(uuid.New())
+
+	t.Run("first admin is created once", func(t *testing.T) {
+		jpDAL := &memBootstrapJPDAL{users: map[models.PhoneNumber]*models.ID{}}
+		service, auditDAL := newService(jpDAL)
+		jp := models.AdminJobPosition{CommonJobPosition: models.CommonJobPosition{Title: "Admin"}}
+		userID, jpID, err := service.BootstrapAdmin("admin", "9170000001", &jp, models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if isSource, _ := service.hierarchy.IsSourceVertex(id2Vertex(*jpID)); !isSource {
+			t.Errorf("expected admin job position to be added to the hierarchy")
+		}
+		if len(auditDAL.events) != 2 || auditDAL.events[0].TargetID != *userID || auditDAL.events[1].TargetID != *jpID {
+			t.Errorf("expected audit events of the admin and its job position, got %+v", auditDAL.events)
+		}
+
+		jp2 := models.AdminJobPosition{CommonJobPosition: models.CommonJobPosition{Title: "Admin 2"}}
+		if _, _, err := service.BootstrapAdmin("admin 2", "9170000002", &jp2, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SEForbidden {
+			t.Errorf("expected error code %d for the second bootstrap, got %v", SEForbidden, err)
+		}
+		if len(jpDAL.adminJPs) != 1 {
+			t.Errorf("expected just one admin job position, got %d", len(jpDAL.adminJPs))
+		}
+	})
+
+	t.Run("phone number of a non-admin user", func(t *testing.T) {
+		jpDAL := &memBootstrapJPDAL{users: map[models.PhoneNumber]*models.ID{"9170000001": &creator}}
+		service, _ := newService(jpDAL)
+		jp := models.AdminJobPosition{CommonJobPosition: models.CommonJobPosition{Title: "Admin"}}
+		if _, _, err := service.BootstrapAdmin("admin", "9170000001", &jp, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SEExists {
+			t.Errorf("expected error code %d, got %v", SEExists, err)
+		}
+		if len(jpDAL.adminJPs) != 0 {
+			t.Errorf("expected admin job position not to be created")
+		}
+	})
+}
+
+// It keeps the job positions of a hierarchy in memory. Each job position belongs to the user with the
+// same id and disabled job positions don't belong to any user.
+type memHierarchyJPDAL struct {
+	dal.JPDAL
+	jps map[models.ID]*models.UserJobPosition
+}
+
+func (d *memHierarchyJPDAL) IsExistsUserWithJP(userID, jpID models.ID) (bool, error) {
+	jp, ok := d.jps[jpID]
+	return ok && userID == jpID && !jp.IsDisabled, nil
+}
+
+func (d *memHierarchyJPDAL) GetJPByID(jpID models.ID) (*models.UserJobPosition, error) {
+	if jp, ok := d.jps[jpID]; ok {
+		copied := *jp
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (d *memHierarchyJPDAL) UpdateJP(jpID models.ID, update *models.JPUpdate) (bool, error) {
+	jp, ok := d.jps[jpID]
+	if ok && update.Title != nil {
+		jp.Title = *update.Title
+	}
+	return ok, nil
+}
+
+func (d *memHierarchyJPDAL) MoveJP(jpID, newParentID models.ID) (bool, error) {
+	jp, ok := d.jps[jpID]
+	if ok {
+		jp.ParentID, jp.ParentIDs = newParentID, []models.ID{newParentID}
+	}
+	return ok, nil
+}
+
+func (d *memHierarchyJPDAL) SetJPDisability(jpID models.ID, isDisabled bool) (bool, error) {
+	jp, ok := d.jps[jpID]
+	if ok {
+		jp.IsDisabled = isDisabled
+	}
+	return ok, nil
+}
+
+func (d *memHierarchyJPDAL) DeleteJP(jpID models.ID) (bool, error) {
+	_, ok := d.jps[jpID]
+	delete(d.jps, jpID)
+	return ok, nil
+}
+
+func (d *memHierarchyJPDAL) GetJPNodes(jpIDs []models.ID) (*[]models.JPNode, error) {
+	nodes := make([]models.JPNode, 0, len(jpIDs))
+	for _, id := range jpIDs {
+		if jp, ok := d.jps[id]; ok {
+			nodes = append(nodes, models.JPNode{ID: id, UserID: jp.UserID, Title: jp.Title, ParentIDs: jp.ParentIDs})
+		}
+	}
+	return &nodes, nil
+}
+
+func (d *memRoleDAL) GetJPsRoles(jpIDs []models.ID) (map[models.ID][]models.JPRole, error) {
+	roles := map[models.ID][]models.JPRole{}
+	for _, jpRole := range d.jpRoles {
+		if slices.Contains(jpIDs, jpRole.jpID) {
+			roles[jpRole.jpID] = append(roles[jpRole.jpID], models.JPRole{RoleID: jpRole.roleID,
+				Name: d.roles[jpRole.roleID].Name, IsInheritable: jpRole.isInheritable})
+		}
+	}
+	return roles, nil
+}
+
+// Return a job position service over the job positions of the role fixture. The manager
+// is allowed to manage job positions of its subtree.
+func newJPManageTestService(t *testing.T) (*roleFixture, *sJPService, *memHierarchyJPDAL) {
+	t.Helper()
+	f, authorization, roleDAL := newRoleFixture(t)
+	jpManagerRole := models.ID(uuid.New())
+	roleDAL.roles[jpManagerRole] = models.Role{ID: jpManagerRole, Name: "jp manager",
+		Actions: []models.Action{models.ActionManageJP}}
+	roleDAL.AssignRole(f.manager, jpManagerRole, false)
+
+	jpDAL := &memHierarchyJPDAL{jps: map[models.ID]*models.UserJobPosition{}}
+	for id, parentID := range map[models.ID]models.ID{f.admin: models.NilID, f.manager: f.admin,
+		f.child: f.manager, f.grandchild: f.child, f.sibling: f.admin} {
+		jp := &models.UserJobPosition{CommonJobPosition: models.CommonJobPosition{ID: id, UserID: id}}
+		if !parentID.IsNil() {
+			jp.ParentID, jp.ParentIDs = parentID, []models.ID{parentID}
+		}
+		jpDAL.jps[id] = jp
+	}
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	service := newSJPService(jpDAL, &authorization.hierarchy, authorization,
+		newSAuditService(&memAuditDAL{}, nil, nil, logger), logger)
+	return f, service.(*sJPService), jpDAL
+}
+
+func TestManageJPCallers(t *testing.T) {
+	title := "edited"
+	actions := map[string]func(s *sJPService, callerJPID, jpID models.ID) *e.Error{
+		"update": func(s *sJPService, callerJPID, jpID models.ID) *e.Error {
+			return s.UpdateJP(callerJPID, callerJPID, jpID, &models.JPUpdate{Title: &title}, models.ClientInfo{})
+		},
+		"disable": func(s *sJPService, callerJPID, jpID models.ID) *e.Error {
+			return s.DisableJP(callerJPID, callerJPID, jpID, models.ClientInfo{})
+		},
+		"enable": func(s *sJPService, callerJPID, jpID models.ID) *e.Error {
+			return s.EnableJP(callerJPID, callerJPID, jpID, models.ClientInfo{})
+		},
+		"delete": func(s *sJPService, callerJPID, jpID models.ID) *e.Error {
+			return s.DeleteJP(callerJPID, callerJPID, jpID, models.ClientInfo{})
+		},
+	}
+	// The callers manage the grandchild job position.
+	tests := []struct {
+		name   string
+		caller func(f *roleFixture) models.ID
+		// Expected error code. If it's nil, the caller must be allowed.
+		errCode any
+	}{
+		{name: "admin", caller: func(f *roleFixture) models.ID { return f.admin }},
+		{name: "ancestor allowed to manage job positions", caller: func(f *roleFixture) models.ID { return f.manager }},
+		{name: "ancestor not allowed to manage job positions", caller: func(f *roleFixture) models.ID { return f.child },
+			errCode: SENotPermission},
+		{name: "job position out of the subtree", caller: func(f *roleFixture) models.ID { return f.sibling },
+			errCode: SENotAncestor},
+		{name: "the job position itself", caller: func(f *roleFixture) models.ID { return f.grandchild },
+			errCode: SENotAncestor},
+	}
+	for actionName, action := range actions {
+		for _, test := range tests {
+			t.Run(actionName+" by "+test.name, func(t *testing.T) {
+				f, service, jpDAL := newJPManageTestService(t)
+				err := action(service, test.caller(f), f.grandchild)
+				if test.errCode == nil && err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				} else if test.errCode != nil && (err == nil || err.GetCode() != test.errCode) {
+					t.Fatalf("expected error code %v, got %v", test.errCode, err)
+				}
+				if test.errCode != nil && (jpDAL.jps[f.grandchild] == nil || jpDAL.jps[f.grandchild].Title != "" ||
+					jpDAL.jps[f.grandchild].IsDisabled) {
+					t.Errorf("expected the job position not to be changed, got %+v", jpDAL.jps[f.grandchild])
+				}
+			})
+		}
+	}
+}
+
+func TestMoveJP(t *testing.T) {
+	tests := []struct {
+		name      string
+		caller    func(f *roleFixture) models.ID
+		jp        func(f *roleFixture) models.ID
+		newParent func(f *roleFixture) models.ID
+		// Expected error code. If it's nil, the move must be done.
+		errCode any
+	}{
+		{name: "under another branch by admin", caller: func(f *roleFixture) models.ID { return f.admin },
+			jp: func(f *roleFixture) models.ID { return f.grandchild }, newParent: func(f *roleFixture) models.ID { return f.sibling }},
+		{name: "under a nested child", caller: func(f *roleFixture) models.ID { return f.admin },
+			jp: func(f *roleFixture) models.ID { return f.manager }, newParent: func(f *roleFixture) models.ID { return f.grandchild },
+			errCode: SEWrongParameter},
+		{name: "under itself", caller: func(f *roleFixture) models.ID { return f.admin },
+			jp: func(f *roleFixture) models.ID { return f.child }, newParent: func(f *roleFixture) models.ID { return f.child },
+			errCode: SEWrongParameter},
+		{name: "under a job position out of the subtree of the caller", caller: func(f *roleFixture) models.ID { return f.manager },
+			jp: func(f *roleFixture) models.ID { return f.grandchild }, newParent: func(f *roleFixture) models.ID { return f.sibling },
+			errCode: SENotAncestor},
+		{name: "job position out of the subtree of the caller", caller: func(f *roleFixture) models.ID { return f.sibling },
+			jp: func(f *roleFixture) models.ID { return f.grandchild }, newParent: func(f *roleFixture) models.ID { return f.sibling },
+			errCode: SENotAncestor},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, service, jpDAL := newJPManageTestService(t)
+			jpID, newParentID := test.jp(f), test.newParent(f)
+			oldParentID := jpDAL.jps[jpID].ParentID
+			err := service.MoveJP(test.caller(f), test.caller(f), jpID, newParentID, models.ClientInfo{})
+			if test.errCode != nil {
+				if err == nil || err.GetCode() != test.errCode {
+					t.Fatalf("expected error code %v, got %v", test.errCode, err)
+				}
+				if jpDAL.jps[jpID].ParentID != oldParentID {
+					t.Errorf("expected the job position not to be moved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if jpDAL.jps[jpID].ParentID != newParentID {
+				t.Errorf("expected the parent to be changed, got %+v", jpDAL.jps[jpID])
+			}
+			if isAncestor, _ := service.authorization.IsAncestor(newParentID, jpID); !isAncestor {
+				t.Errorf("expected the new parent to be an ancestor in the hierarchy")
+			}
+			if isAncestor, _ := service.authorization.IsAncestor(oldParentID, jpID); isAncestor {
+				t.Errorf("expected the old parent not to be an ancestor in the hierarchy")
+			}
+		})
+	}
+}
+
+func TestDeleteJPWithChilds(t *testing.T) {
+	f, service, jpDAL := newJPManageTestService(t)
+	if err := service.DeleteJP(f.admin, f.admin, f.child, models.ClientInfo{}); err == nil || err.GetCode() != SEJPHasChilds {
+		t.Fatalf("expected error code %d, got %v", SEJPHasChilds, err)
+	}
+	if _, ok := jpDAL.jps[f.child]; !ok {
+		t.Fatalf("expected the job position with childs not to be deleted")
+	}
+
+	if err := service.DeleteJP(f.admin, f.admin, f.grandchild, models.ClientInfo{}); err != nil {
+		t.Fatalf("failed to delete the child: %s", err.Error())
+	}
+	if err := service.DeleteJP(f.admin, f.admin, f.child, models.ClientInfo{}); err != nil {
+		t.Errorf("expected the job position without childs to be deleted, got %s", err.Error())
+	}
+}
+
+func TestDisabledJPCantBeUsed(t *testing.T) {
+	f, service, _ := newJPManageTestService(t)
+	title := "edited"
+	update := func() *e.Error {
+		return service.UpdateJP(f.manager, f.manager, f.grandchild, &models.JPUpdate{Title: &title}, models.ClientInfo{})
+	}
+	if err := service.DisableJP(f.admin, f.admin, f.manager, models.ClientInfo{}); err != nil {
+		t.Fatalf("failed to disable the job position: %s", err.Error())
+	}
+	if err := update(); err == nil || err.GetCode() != SEJPNotMatchedUser {
+		t.Errorf("expected error code %d for the disabled job position, got %v", SEJPNotMatchedUser, err)
+	}
+
+	if err := service.EnableJP(f.admin, f.admin, f.manager, models.ClientInfo{}); err != nil {
+		t.Fatalf("failed to enable the job position: %s", err.Error())
+	}
+	if err := update(); err != nil {
+		t.Errorf("expected the enabled job position to be used, got %s", err.Error())
+	}
+}
+
+// Return a job position service like newJPManageTestService, but the grandchild has the
+// sibling as its second parent.
+func newJPBrowseTestService(t *testing.T) (*roleFixture, *sJPService) {
+	t.Helper()
+	f, service, jpDAL := newJPManageTestService(t)
+	if err := pushGraphChanges(service.hierarchy.Graph(),
+		parentEdgeChanges(graph.AddEdge, f.grandchild, []models.ID{f.sibling})...); err != nil {
+		t.Fatalf("failed to add the second parent: %s", err.Error())
+	}
+	jpDAL.jps[f.grandchild].ParentIDs = append(jpDAL.jps[f.grandchild].ParentIDs, f.sibling)
+	return f, service
+}
+
+func TestBrowseJPCallers(t *testing.T) {
+	browsers := map[string]func(s *sJPService, userID, callerJPID, jpID models.ID) *e.Error{
+		"childs": func(s *sJPService, userID, callerJPID, jpID models.ID) *e.Error {
+			_, err := s.GetChildJPs(userID, callerJPID, jpID)
+			return err
+		},
+		"descendants": func(s *sJPService, userID, callerJPID, jpID models.ID) *e.Error {
+			_, err := s.GetDescendantJPs(userID, callerJPID, jpID)
+			return err
+		},
+		"ancestors": func(s *sJPService, userID, callerJPID, jpID models.ID) *e.Error {
+			_, err := s.GetAncestorJPs(userID, callerJPID, jpID)
+			return err
+		},
+		"tree": func(s *sJPService, userID, callerJPID, jpID models.ID) *e.Error {
+			_, err := s.GetJPTree(userID, callerJPID, jpID, 3)
+			return err
+		},
+	}
+	tests := []struct {
+		name string
+		// Return the user, its job position and the browsed job position.
+		params func(f *roleFixture) (userID, callerJPID, jpID models.ID)
+		// Expected error code. If it's nil, the caller must be allowed.
+		errCode any
+	}{
+		{name: "admin", params: func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.admin, f.admin, f.grandchild }},
+		{name: "ancestor allowed to view the subtree",
+			params: func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.manager, f.manager, f.grandchild }},
+		{name: "the job position itself",
+			params: func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.child, f.child, f.child }},
+		{name: "ancestor not allowed to view the subtree",
+			params:  func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.sibling, f.sibling, f.grandchild },
+			errCode: SENotPermission},
+		{name: "descendant", params: func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.grandchild, f.grandchild, f.child },
+			errCode: SENotAncestor},
+		{name: "job position out of the subtree",
+			params:  func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.sibling, f.sibling, f.child },
+			errCode: SENotAncestor},
+		{name: "job position of another user",
+			params:  func(f *roleFixture) (models.ID, models.ID, models.ID) { return f.child, f.manager, f.grandchild },
+			errCode: SEJPNotMatchedUser},
+	}
+	for browserName, browse := range browsers {
+		for _, test := range tests {
+			t.Run(browserName+" by "+test.name, func(t *testing.T) {
+				f, service := newJPBrowseTestService(t)
+				userID, callerJPID, jpID := test.params(f)
+				err := browse(service, userID, callerJPID, jpID)
+				if test.errCode == nil && err != nil {
+					t.Errorf("unexpected error %s", err.Error())
+				} else if test.errCode != nil && (err == nil || err.GetCode() != test.errCode) {
+					t.Errorf("expected error code %v, got %v", test.errCode, err)
+				}
+			})
+		}
+	}
+}
+
+func TestGetAncestorJPs(t *testing.T) {
+	f, service := newJPBrowseTestService(t)
+	tests := []struct {
+		name   string
+		caller models.ID
+		// Expected ancestors level by level. The nearer levels come first.
+		expected [][]models.ID
+	}{
+		{name: "admin gets all ancestors once", caller: f.admin,
+			expected: [][]models.ID{{f.child, f.sibling}, {f.manager, f.admin}}},
+		{name: "ancestors out of the subtree of the caller are skipped", caller: f.manager,
+			expected: [][]models.ID{{f.child}, {f.manager}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ancestors, err := service.GetAncestorJPs(test.caller, test.caller, f.grandchild)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			i := 0
+			for _, level := range test.expected {
+				if i+len(level) > len(*ancestors) {
+					t.Fatalf("expected ancestors %v, got %+v", test.expected, *ancestors)
+				}
+				for _, ancestor := range (*ancestors)[i : i+len(level)] {
+					if !slices.Contains(level, ancestor.ID) {
+						t.Errorf("expected one of %v at level of %d, got %s", level, i, ancestor.ID.String())
+					}
+				}
+				i += len(level)
+			}
+			if i != len(*ancestors) {
+				t.Errorf("expected %d ancestors, got %+v", i, *ancestors)
+			}
+		})
+	}
+}
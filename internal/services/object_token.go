@@ -0,0 +1,85 @@
+package services
+
+import (
+	m "DMS/internal/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A verified reference to a multimedia file of a document
+type objectRef struct {
+	DocID    m.ID
+	FileName string
+	// It's stored as a Unix timestamp. (In seconds and UTC time zone)
+	ExpiredAt int64
+}
+
+// Sign and verify object tokens. An object token is a reference to a multimedia file of
+// a document that is given to the clients with the document, and they send it to the
+// file-transfer service to download the file. Its format is "payload.signature" that
+// payload is "doc-id:expiration-time:file-name" and signature is HMAC-SHA256 of the
+// payload, both base64url encoded. So clients can't forge tokens for other files.
+type objectTokenSigner struct {
+	secret []byte
+	// Time the tokens expire after they're issued
+	expire time.Duration
+}
+
+func newObjectTokenSigner(secret []byte, expire time.Duration) *objectTokenSigner {
+	return &objectTokenSigner{secret, expire}
+}
+
+// Return a new object token for the file of the document.
+func (o *objectTokenSigner) sign(docID m.ID, fileName string) m.Token {
+	expiredAt := time.Now().UTC().Add(o.expire).Unix()
+	payload := fmt.Sprintf("%s:%d:%s", docID.String(), expiredAt, fileName)
+	return m.Token(base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(o.mac([]byte(payload))))
+}
+
+// Verify the signature and the expiration time of the object token and return the file
+// it refers to.
+func (o *objectTokenSigner) verify(token m.Token) (*objectRef, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(token.String(), ".")
+	if !found {
+		return nil, fmt.Errorf("object token doesn't have signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload of object token: %s", err.Error())
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature of object token: %s", err.Error())
+	}
+	if !hmac.Equal(signature, o.mac(payload)) {
+		return nil, fmt.Errorf("signature of object token is invalid")
+	}
+
+	parts := strings.SplitN(string(payload), ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected 3 parts in payload of object token but got %d", len(parts))
+	}
+	docID, err := m.ID{}.FromString2(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse doc id %s of object token: %s", parts[0], err.Error())
+	}
+	expiredAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expiration time %s of object token: %s", parts[1], err.Error())
+	} else if expiredAt <= time.Now().UTC().Unix() {
+		return nil, fmt.Errorf("object token of doc %s is expired", docID.String())
+	}
+	return &objectRef{docID, parts[2], expiredAt}, nil
+}
+
+func (o *objectTokenSigner) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, o.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
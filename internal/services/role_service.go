@@ -0,0 +1,281 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"fmt"
+	"strings"
+)
+
+type RoleService interface {
+	// Create a role with the given actions and return its id. callerJPID is the job
+	// position of the user userID and must be allowed to manage roles.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SEWrongParameter- SEExists
+	CreateRole(userID, callerJPID m.ID, role *m.Role, client m.ClientInfo) (*m.ID, *e.Error)
+	// Return all roles with their actions.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser
+	GetRoles(userID, callerJPID m.ID) (*[]m.Role, *e.Error)
+	// Delete the role and unassign it from all job positions. Built-in roles couldn't be
+	// deleted. The caller must be allowed to manage roles.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SERoleNotFound- SEWrongParameter
+	DeleteRole(userID, callerJPID, roleID m.ID, client m.ClientInfo) *e.Error
+	// Assign the role to the job position jpID. If isInheritable be true, the role applies
+	// to all nested childs of jpID too. The caller must be allowed to manage roles and
+	// jpID must be one of its nested childs. (Admins could assign roles to all job positions)
+	// The caller must be allowed to do all actions of the role too, so it can't grant more
+	// than it has.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound- SERoleNotFound
+	AssignRole(userID, callerJPID, jpID, roleID m.ID, isInheritable bool, client m.ClientInfo) *e.Error
+	// Unassign the role from the job position jpID. Access rules are the same as AssignRole.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound- SERoleNotFound
+	UnassignRole(userID, callerJPID, jpID, roleID m.ID, client m.ClientInfo) *e.Error
+	// Return the roles assigned to the job position jpID. The caller must be jpID itself
+	// or allowed to view the subtree it's in.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound
+	GetJPRoles(userID, callerJPID, jpID m.ID) (*[]m.JPRole, *e.Error)
+}
+
+// It's a simple implementation of RoleService interface.
+type sRoleService struct {
+	role          dal.RoleDAL
+	jp            dal.JPDAL
+	authorization AuthorizationService
+	audit         AuditService
+	logger        l.Logger
+}
+
+func (s *sRoleService) CreateRole(userID, callerJPID m.ID, role *m.Role, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.createRole(userID, callerJPID, role)
+	event := newAuditEvent(m.AuditRoleCreate, userID, callerJPID, m.AuditTargetRole, idOrNil(id), client)
+	event.Details = fmt.Sprintf("role %s", role.Name)
+	s.audit.Record(event, err)
+	return id, err
+}
+
+func (s *sRoleService) createRole(userID, callerJPID m.ID, role *m.Role) (*m.ID, *e.Error) {
+	if err := s.checkCan(userID, callerJPID, m.ActionManageRoles, m.NilID); err != nil {
+		return nil, err
+	}
+	role.Name = strings.TrimSpace(role.Name)
+	if role.Name == "" {
+		return nil, e.NewErrorP("name of the role can't be empty", SEWrongParameter)
+	}
+	for _, action := range role.Actions {
+		if !action.IsValid() {
+			return nil, e.NewErrorP("action \"%s\" is not valid", SEWrongParameter, action)
+		}
+	}
+
+	roles, err := s.role.GetRoles()
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	for _, r := range *roles {
+		if r.Name == role.Name {
+			return nil, e.NewErrorP("role with name %s exists previously", SEExists, role.Name)
+		}
+	}
+
+	roleID, err := s.role.CreateRole(role)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return roleID, nil
+}
+
+func (s *sRoleService) GetRoles(userID, callerJPID m.ID) (*[]m.Role, *e.Error) {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return nil, err
+	}
+	roles, err := s.role.GetRoles()
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return roles, nil
+}
+
+func (s *sRoleService) DeleteRole(userID, callerJPID, roleID m.ID, client m.ClientInfo) *e.Error {
+	err := s.deleteRole(userID, callerJPID, roleID)
+	s.audit.Record(newAuditEvent(m.AuditRoleDelete, userID, callerJPID, m.AuditTargetRole, roleID, client), err)
+	return err
+}
+
+func (s *sRoleService) deleteRole(userID, callerJPID, roleID m.ID) *e.Error {
+	if err := s.checkCan(userID, callerJPID, m.ActionManageRoles, m.NilID); err != nil {
+		return err
+	}
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return err
+	} else if role.IsBuiltin {
+		return e.NewErrorP("built-in role %s couldn't be deleted", SEWrongParameter, role.Name)
+	}
+
+	isDeleted, dbErr := s.role.DeleteRole(roleID)
+	if dbErr != nil {
+		return e.NewErrorP(dbErr.Error(), SEDBError)
+	} else if !isDeleted {
+		return e.NewErrorP("role with id %s not found", SERoleNotFound, roleID.String())
+	}
+	return nil
+}
+
+func (s *sRoleService) AssignRole(userID, callerJPID, jpID, roleID m.ID, isInheritable bool, client m.ClientInfo) *e.Error {
+	err := s.assignRole(userID, callerJPID, jpID, roleID, isInheritable)
+	event := newAuditEvent(m.AuditRoleAssign, userID, callerJPID, m.AuditTargetJP, jpID, client)
+	event.Details = fmt.Sprintf("role %s (inheritable: %t)", roleID.String(), isInheritable)
+	s.audit.Record(event, err)
+	return err
+}
+
+func (s *sRoleService) assignRole(userID, callerJPID, jpID, roleID m.ID, isInheritable bool) *e.Error {
+	if err := s.checkAssignAccess(userID, callerJPID, jpID); err != nil {
+		return err
+	}
+	role, err := s.getRole(roleID)
+	if err != nil {
+		return err
+	}
+	for _, action := range role.Actions {
+		if can, err := s.authorization.Can(callerJPID, action, m.NilID); err != nil {
+			return err
+		} else if !can {
+			return e.NewErrorP("job position %s can't assign role %s, because it's not allowed to do %s",
+				SENotPermission, callerJPID.String(), role.Name, action)
+		}
+	}
+	if err := s.role.AssignRole(jpID, roleID, isInheritable); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	}
+	return nil
+}
+
+func (s *sRoleService) UnassignRole(userID, callerJPID, jpID, roleID m.ID, client m.ClientInfo) *e.Error {
+	err := s.unassignRole(userID, callerJPID, jpID, roleID)
+	event := newAuditEvent(m.AuditRoleUnassign, userID, callerJPID, m.AuditTargetJP, jpID, client)
+	event.Details = fmt.Sprintf("role %s", roleID.String())
+	s.audit.Record(event, err)
+	return err
+}
+
+func (s *sRoleService) unassignRole(userID, callerJPID, jpID, roleID m.ID) *e.Error {
+	if err := s.checkAssignAccess(userID, callerJPID, jpID); err != nil {
+		return err
+	}
+	isUnassigned, err := s.role.UnassignRole(jpID, roleID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isUnassigned {
+		return e.NewErrorP("role %s is not assigned to job position %s", SERoleNotFound,
+			roleID.String(), jpID.String())
+	}
+	return nil
+}
+
+func (s *sRoleService) GetJPRoles(userID, callerJPID, jpID m.ID) (*[]m.JPRole, *e.Error) {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return nil, err
+	}
+	if err := s.checkJPExists(jpID); err != nil {
+		return nil, err
+	}
+	if callerJPID != jpID {
+		if err := s.checkCan(userID, callerJPID, m.ActionViewSubtree, jpID); err != nil {
+			return nil, err
+		}
+	}
+
+	roles, err := s.authorization.GetJPsRoles([]m.ID{jpID})
+	if err != nil {
+		return nil, err
+	}
+	jpRoles := roles[jpID]
+	if jpRoles == nil {
+		jpRoles = []m.JPRole{}
+	}
+	return &jpRoles, nil
+}
+
+// Check the caller could assign roles to the job position jpID. A job position can't
+// assign roles to itself, except it's admin.
+func (s *sRoleService) checkAssignAccess(userID, callerJPID, jpID m.ID) *e.Error {
+	if err := s.checkJPExists(jpID); err != nil {
+		return err
+	}
+	if err := s.checkCan(userID, callerJPID, m.ActionManageRoles, jpID); err != nil {
+		return err
+	}
+	if callerJPID != jpID {
+		return nil
+	}
+	if isAdmin, err := s.authorization.IsAdminJP(callerJPID); err != nil {
+		return err
+	} else if !isAdmin {
+		return e.NewErrorP("job position %s can't change its own roles", SENotPermission, jpID.String())
+	}
+	return nil
+}
+
+// Check the caller job position belongs to the user and it's allowed to do the action
+// on the resource.
+func (s *sRoleService) checkCan(userID, callerJPID m.ID, action m.Action, resource m.ID) *e.Error {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return err
+	}
+	if can, err := s.authorization.Can(callerJPID, action, resource); err != nil {
+		return err
+	} else if !can {
+		return e.NewErrorP("job position %s is not allowed to do %s on %s", SENotPermission,
+			callerJPID.String(), action, resource.String())
+	}
+	return nil
+}
+
+func (s *sRoleService) checkCallerJP(userID, callerJPID m.ID) *e.Error {
+	if isExists, err := s.jp.IsExistsUserWithJP(userID, callerJPID); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isExists {
+		return e.NewErrorP("job position %s doesn't belong to user %s", SEJPNotMatchedUser,
+			callerJPID.String(), userID.String())
+	}
+	return nil
+}
+
+func (s *sRoleService) checkJPExists(jpID m.ID) *e.Error {
+	if jp, err := s.jp.GetJPByID(jpID); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if jp == nil {
+		return e.NewErrorP("job position with id %s not found", SENotFound, jpID.String())
+	}
+	return nil
+}
+
+func (s *sRoleService) getRole(roleID m.ID) (*m.Role, *e.Error) {
+	role, err := s.role.GetRoleByID(roleID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if role == nil {
+		return nil, e.NewErrorP("role with id %s not found", SERoleNotFound, roleID.String())
+	}
+	return role, nil
+}
+
+// Create an instance of sRoleService struct
+func newSRoleService(role dal.RoleDAL, jp dal.JPDAL, authorization AuthorizationService, audit AuditService,
+	logger l.Logger) RoleService {
+	return &sRoleService{role, jp, authorization, audit, logger}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	"DMS/internal/graph"
+	"DMS/internal/hierarchy"
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// A role assigned to a job position in memRoleDAL
+type memJPRole struct {
+	jpID, roleID  models.ID
+	isInheritable bool
+}
+
+// It keeps the roles and their assignments in memory.
+type memRoleDAL struct {
+	dal.RoleDAL
+	roles   map[models.ID]models.Role
+	jpRoles []memJPRole
+}
+
+func (d *memRoleDAL) GetRoleByID(roleID models.ID) (*models.Role, error) {
+	if role, ok := d.roles[roleID]; ok {
+		return &role, nil
+	}
+	return nil, nil
+}
+
+func (d *memRoleDAL) AssignRole(jpID, roleID models.ID, isInheritable bool) error {
+	d.jpRoles = append(d.jpRoles, memJPRole{jpID, roleID, isInheritable})
+	return nil
+}
+
+func (d *memRoleDAL) HasAction(jpID models.ID, ancestorIDs []models.ID, action models.Action) (bool, error) {
+	for _, jpRole := range d.jpRoles {
+		isApplied := jpRole.jpID == jpID
+		for _, ancestorID := range ancestorIDs {
+			isApplied = isApplied || (jpRole.isInheritable && jpRole.jpID == ancestorID)
+		}
+		if !isApplied {
+			continue
+		}
+		for _, roleAction := range d.roles[jpRole.roleID].Actions {
+			if roleAction == action {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Job positions and roles of the authorization tests.
+//
+// admin -> manager -> child -> grandchild
+// admin -> sibling
+type roleFixture struct {
+	admin, manager, child, grandchild, sibling models.ID
+	// It allows managing roles and viewing the subtree and it's inheritable.
+	managerRole models.ID
+	// It allows creating docs and it's not inheritable.
+	writerRole models.ID
+	// It allows approving events and it's not assigned to any job position.
+	approverRole models.ID
+}
+
+func newRoleFixture(t *testing.T) (*roleFixture, *sAuthorizationService, *memRoleDAL) {
+	t.Helper()
+	f := &roleFixture{}
+	for _, id := range []*models.ID{&f.admin, &f.manager, &f.child, &f.grandchild, &f.sibling,
+		&f.managerRole, &f.writerRole, &f.approverRole} {
+		*id = models.ID(uuid.New())
+	}
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	tree := hierarchy.NewHierarchyTree(graph.NewDynamicGraph(graph.NewMemoryStorage(logger), logger), logger)
+	changes := parentEdgeChanges(graph.AddEdge, f.admin, nil)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, f.manager, []models.ID{f.admin})...)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, f.child, []models.ID{f.manager})...)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, f.grandchild, []models.ID{f.child})...)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, f.sibling, []models.ID{f.admin})...)
+	if err := pushGraphChanges(tree.Graph(), changes...); err != nil {
+		t.Fatalf("failed to build the graph: %s", err.Error())
+	}
+
+	roleDAL := &memRoleDAL{
+		roles: map[models.ID]models.Role{
+			f.managerRole:  {ID: f.managerRole, Name: "manager", Actions: []models.Action{models.ActionManageRoles, models.ActionViewSubtree}},
+			f.writerRole:   {ID: f.writerRole, Name: "writer", Actions: []models.Action{models.ActionCreateDoc}},
+			f.approverRole: {ID: f.approverRole, Name: "approver", Actions: []models.Action{models.ActionApproveEvent}},
+		},
+		jpRoles: []memJPRole{
+			{jpID: f.manager, roleID: f.managerRole, isInheritable: true},
+			{jpID: f.manager, roleID: f.writerRole, isInheritable: false},
+		},
+	}
+	authorization := newSAuthorizationService(*tree, roleDAL, nil, logger).(*sAuthorizationService)
+	return f, authorization, roleDAL
+}
+
+func TestCan(t *testing.T) {
+	f, authorization, _ := newRoleFixture(t)
+	tests := []struct {
+		name     string
+		jpID     models.ID
+		action   models.Action
+		resource models.ID
+		expected bool
+	}{
+		{name: "admin could do all actions", jpID: f.admin, action: models.ActionApproveEvent, resource: f.grandchild, expected: true},
+		{name: "action of the role", jpID: f.manager, action: models.ActionCreateDoc, resource: models.NilID, expected: true},
+		{name: "action not in the roles", jpID: f.manager, action: models.ActionApproveEvent, resource: models.NilID},
+		{name: "action on the job position itself", jpID: f.manager, action: models.ActionManageRoles, resource: f.manager, expected: true},
+		{name: "action on a nested child", jpID: f.manager, action: models.ActionManageRoles, resource: f.grandchild, expected: true},
+		{name: "action on a job position out of the subtree", jpID: f.manager, action: models.ActionManageRoles, resource: f.sibling},
+		{name: "action on an ancestor", jpID: f.child, action: models.ActionManageRoles, resource: f.manager},
+		{name: "inheritable role of the parent", jpID: f.child, action: models.ActionViewSubtree, resource: models.NilID, expected: true},
+		{name: "inheritable role of an ancestor", jpID: f.grandchild, action: models.ActionManageRoles, resource: models.NilID, expected: true},
+		{name: "non-inheritable role of the parent", jpID: f.child, action: models.ActionCreateDoc, resource: models.NilID},
+		{name: "roles of other job positions", jpID: f.sibling, action: models.ActionViewSubtree, resource: models.NilID},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			can, err := authorization.Can(test.jpID, test.action, test.resource)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if can != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, can)
+			}
+		})
+	}
+}
+
+// Each job position exists and belongs to the user with the same id.
+type selfOwnedJPDAL struct {
+	dal.JPDAL
+}
+
+func (d *selfOwnedJPDAL) IsExistsUserWithJP(userID, jpID models.ID) (bool, error) {
+	return userID == jpID, nil
+}
+
+func (d *selfOwnedJPDAL) GetJPByID(jpID models.ID) (*models.UserJobPosition, error) {
+	return &models.UserJobPosition{}, nil
+}
+
+func TestAssignRole(t *testing.T) {
+	f, authorization, roleDAL := newRoleFixture(t)
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	service := newSRoleService(roleDAL, &selfOwnedJPDAL{}, authorization,
+		newSAuditService(&memAuditDAL{}, nil, nil, logger), logger)
+
+	tests := []struct {
+		name           string
+		callerJP, jpID models.ID
+		roleID         models.ID
+		// Expected error code. If it's nil, the role must be assigned.
+		errCode any
+	}{
+		{name: "role with the actions of the caller", callerJP: f.manager, jpID: f.child, roleID: f.writerRole},
+		{name: "role with an action the caller doesn't have", callerJP: f.manager, jpID: f.child,
+			roleID: f.approverRole, errCode: SENotPermission},
+		{name: "admin assigns every role", callerJP: f.admin, jpID: f.child, roleID: f.approverRole},
+		{name: "job position out of the subtree", callerJP: f.manager, jpID: f.sibling, roleID: f.writerRole,
+			errCode: SENotPermission},
+		{name: "unknown role", callerJP: f.manager, jpID: f.child, roleID: models.ID(uuid.New()),
+			errCode: SERoleNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assignedCount := len(roleDAL.jpRoles)
+			err := service.AssignRole(test.callerJP, test.callerJP, test.jpID, test.roleID, false, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				if len(roleDAL.jpRoles) != assignedCount+1 {
+					t.Errorf("expected role to be assigned")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error code %v, got nil", test.errCode)
+			} else if err.GetCode() != test.errCode {
+				t.Errorf("expected error code %v, got %v (%s)", test.errCode, err.GetCode(), err.Error())
+			}
+			if len(roleDAL.jpRoles) != assignedCount {
+				t.Errorf("expected role not to be assigned")
+			}
+		})
+	}
+}
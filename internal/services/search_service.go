@@ -0,0 +1,71 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"strings"
+)
+
+type SearchService interface {
+	// Search the query in docs or events (according to the search type) and return the
+	// matched ones ordered by their relevance. Just docs and events created by the job
+	// position or its nested childs are searched, unless the job position be admin. The
+	// job position must belong to the user.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SEEmpty- SEWrongParameter
+	Search(userID, jpID m.ID, query string, searchType m.SearchType, limit, offset uint64) (*[]m.SearchResult, *e.Error)
+}
+
+// It's a simple implementation of SearchService interface.
+type sSearchService struct {
+	search        dal.SearchDAL
+	jp            JPService
+	authorization AuthorizationService
+	logger        l.Logger
+}
+
+func (s *sSearchService) Search(userID, jpID m.ID, query string, searchType m.SearchType, limit, offset uint64) (*[]m.SearchResult, *e.Error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, e.NewErrorP("the search query is empty", SEEmpty)
+	} else if !searchType.IsValid() {
+		return nil, e.NewErrorP("search type \"%s\" is not valid", SEWrongParameter, searchType)
+	}
+	if isExistsUser, err := s.jp.IsExistsUserWithJP(userID, jpID); err != nil {
+		return nil, e.NewErrorP("error in checking if user exists: %s", SEDBError, err.Error())
+	} else if !isExistsUser {
+		return nil, e.NewErrorP("there's not any user with id %s that have job position id %s",
+			SEJPNotMatchedUser, userID.String(), jpID.String())
+	}
+
+	jpIDs, err := s.authorization.GetAccessibleJPs(jpID)
+	if err != nil {
+		return nil, err.SetCode(SEDBError)
+	}
+	var results *[]m.SearchResult
+	var err2 error
+	switch searchType {
+	case m.SearchDoc:
+		results, err2 = s.search.SearchDocs(query, jpIDs, int(limit), int(offset))
+	case m.SearchEvent:
+		results, err2 = s.search.SearchEvents(query, jpIDs, int(limit), int(offset))
+	}
+	if err2 != nil {
+		return nil, e.NewErrorP(err2.Error(), SEDBError)
+	}
+	s.logger.Debugf("Found %d %ss for query \"%s\"", len(*results), searchType, query)
+	return results, nil
+}
+
+// Create an instance of sSearchService struct
+func newSSearchService(search dal.SearchDAL, jp JPService, authz AuthorizationService, logger l.Logger) SearchService {
+	return &sSearchService{
+		search,
+		jp,
+		authz,
+		logger,
+	}
+}
@@ -1,12 +1,15 @@
 package services
 
 import (
+	"DMS/internal/config"
 	"DMS/internal/dal"
 	"DMS/internal/graph"
 	"DMS/internal/hierarchy"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
+	"DMS/internal/sms"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -52,6 +55,18 @@ const (
 	SEInternal = 16
 	// The user/job position is denied to perform the action
 	SEForbidden = 17
+	// Document not found
+	SEDocNotFound = 18
+	// The job position has some child job positions and the action can't be done on it
+	SEJPHasChilds = 19
+	// Role not found or it's not assigned to the job position
+	SERoleNotFound = 20
+	// The user has sent too many requests or failed attempts in a period of time
+	SETooManyRequests = 21
+	// The storage quota of the event or the job position is exceeded
+	SEQuotaExceeded = 22
+	// The user can't do the action on itself
+	SESelfAction = 23
 )
 
 type Service struct {
@@ -62,13 +77,16 @@ type Service struct {
 	Authorization AuthorizationService
 	Session       SessionService
 	FilePer       FilePermissionService
+	Search        SearchService
+	Role          RoleService
+	Audit         AuditService
 }
 
 // Create a new service
-func NewService(dal *dal.DAL, hierarchy *hierarchy.HierarchyTree, cache dal.InMemoryDAL, logger l.Logger) Service {
+func NewService(dal *dal.DAL, hierarchy *hierarchy.HierarchyTree, cache dal.InMemoryDAL, smsSender sms.SMSSender,
+	cfg *config.Config, logger l.Logger) Service {
 	// Fetching job position relations
-	// TODO: handle limit value in a better way
-	batchSize := 500
+	batchSize := cfg.Hierarchy.BatchSize
 	jpIter := dal.JP.GetJPEdgeIter(batchSize)
 	changes := make(chan graph.GraphChange, batchSize)
 	defer close(changes)
@@ -95,19 +113,27 @@ func NewService(dal *dal.DAL, hierarchy *hierarchy.HierarchyTree, cache dal.InMe
 	logger.Infof("Added %d vertices to the hierarchy graph", edgeCount)
 	logger.Debugf("The graph:\n%s", hierarchy.Graph().String())
 
-	session := newSSessionService(dal.Session, dal.User, logger)
-	jp := newSJPService(dal.JP, hierarchy, logger)
-	authorization := newSAuthorizationService(*hierarchy, dal.Permission, logger)
-	event := newSEventService(dal.Event, jp, authorization, logger)
-	filePermission := newSFilePermissionService(cache, session, dal.Event, authorization, logger)
+	authorization := newSAuthorizationService(*hierarchy, dal.Role, dal.EventACL, logger)
+	audit := newSAuditService(dal.Audit, dal.JP, authorization, logger)
+	session := newSSessionService(dal.Session, dal.User, cache, smsSender, &cfg.JWT, &cfg.OTP, audit, logger)
+	jp := newSJPService(dal.JP, hierarchy, authorization, audit, logger)
+	event := newSEventService(dal.Event, dal.EventACL, jp, authorization, audit, logger)
+	objectTokens := newObjectTokenSigner([]byte(cfg.ObjectToken.Secret),
+		time.Minute*time.Duration(cfg.ObjectToken.LifetimeMin))
+	uploadPolicy := newUploadPolicy(&cfg.Upload)
+	filePermission := newSFilePermissionService(cache, session, dal.Event, dal.Doc, authorization, objectTokens,
+		uploadPolicy, audit, logger)
 	s := Service{
-		Doc:           newSDocService(dal.Doc, authorization, event, jp, logger),
+		Doc:           newSDocService(dal.Doc, authorization, event, jp, objectTokens, uploadPolicy, audit, logger),
 		Event:         event,
 		JP:            jp,
-		User:          newSUserService(dal.User, dal.JP, logger),
+		User:          newSUserService(dal.User, dal.JP, dal.Session, authorization, audit, logger),
 		Authorization: authorization,
 		Session:       session,
 		FilePer:       filePermission,
+		Search:        newSSearchService(dal.Search, jp, authorization, logger),
+		Role:          newSRoleService(dal.Role, dal.JP, authorization, audit, logger),
+		Audit:         audit,
 	}
 	return s
 }
@@ -123,6 +149,24 @@ func jpEdge2GraphEdge(jpEdge dal.JPEdge) *graph.Edge {
 	}
 }
 
+// Apply the given changes to the graph one by one and return the first error occurred.
+// Changes after the failed one are not applied.
+func pushGraphChanges(g *graph.DynamicGraph, changes ...graph.GraphChange) error {
+	changesChan := make(chan graph.GraphChange)
+	defer close(changesChan)
+	go g.ProcessChanges(changesChan)
+	for _, change := range changes {
+		responseErr := make(chan error, 1)
+		change.ResponseErr = responseErr
+		changesChan <- change
+		if err := <-responseErr; err != nil {
+			return fmt.Errorf("failed to apply change %d on edge %s -> %s: %s", change.Type,
+				change.Edge.Start.String(), change.Edge.End.String(), err.Error())
+		}
+	}
+	return nil
+}
+
 func id2Vertex(id m.ID) graph.Vertex {
 	if id.IsNil() {
 		return graph.NilVertex
@@ -1,10 +1,17 @@
 package services
 
 import (
+	e "DMS/internal/error"
 	"DMS/internal/graph"
+	"DMS/internal/hierarchy"
+	l "DMS/internal/logger"
 	"DMS/internal/models"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -54,3 +61,248 @@ func TestVertex2ID(t *testing.T) {
 		})
 	}
 }
+
+func TestDAGHierarchy(t *testing.T) {
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	tree := hierarchy.NewHierarchyTree(graph.NewDynamicGraph(graph.NewMemoryStorage(logger), logger), logger)
+	admin := models.ID(uuid.New())
+	a := models.ID(uuid.New())
+	b := models.ID(uuid.New())
+	shared := models.ID(uuid.New())
+	child := models.ID(uuid.New())
+	// admin -> a -> shared -> child
+	// admin -> b -> shared
+	changes := parentEdgeChanges(graph.AddEdge, admin, nil)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, a, []models.ID{admin})...)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, b, []models.ID{admin})...)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, shared, []models.ID{a, b})...)
+	changes = append(changes, parentEdgeChanges(graph.AddEdge, child, []models.ID{shared})...)
+	err := pushGraphChanges(tree.Graph(), changes...)
+	if err != nil {
+		t.Fatalf("failed to build the graph: %s", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		ancestor models.ID
+		node     models.ID
+		expected bool
+	}{
+		{name: "first parent is ancestor", ancestor: a, node: shared, expected: true},
+		{name: "second parent is ancestor", ancestor: b, node: shared, expected: true},
+		{name: "ancestor through second parent", ancestor: b, node: child, expected: true},
+		{name: "child is not ancestor", ancestor: child, node: b, expected: false},
+		{name: "siblings are not ancestors", ancestor: a, node: b, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isAncestor, err := tree.IsAncestor(id2Vertex(test.ancestor), id2Vertex(test.node))
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if isAncestor != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, isAncestor)
+			}
+		})
+	}
+
+	t.Run("nested childs are not duplicated", func(t *testing.T) {
+		childs, _ := tree.GetNestedChilds(id2Vertex(admin))
+		if len(childs) != 5 {
+			t.Errorf("expected 5 nested childs, got %d", len(childs))
+		}
+	})
+	t.Run("ancestors of all parents", func(t *testing.T) {
+		ancestors, _ := tree.GetAncestors(id2Vertex(child))
+		if len(ancestors) != 4 {
+			t.Errorf("expected 4 ancestors, got %d", len(ancestors))
+		}
+	})
+	t.Run("removing an edge invalidates cached paths of ancestors", func(t *testing.T) {
+		// Cache the path before removing the edges
+		if isAncestor, _ := tree.IsAncestor(id2Vertex(admin), id2Vertex(child)); !isAncestor {
+			t.Fatalf("expected admin to be ancestor of child")
+		}
+		if err := pushGraphChanges(tree.Graph(), parentEdgeChanges(graph.RemoveEdge, shared, []models.ID{a, b})...); err != nil {
+			t.Fatalf("failed to remove the edges: %s", err.Error())
+		}
+		if isAncestor, _ := tree.IsAncestor(id2Vertex(admin), id2Vertex(child)); isAncestor {
+			t.Errorf("expected admin not to be ancestor of child after removing the edges")
+		}
+	})
+}
+
+func TestObjectTokenSigner(t *testing.T) {
+	signer := newObjectTokenSigner([]byte("secret"), time.Minute)
+	docID := models.ID(uuid.New())
+	token := signer.sign(docID, "photo.jpg")
+	payload, signature, _ := strings.Cut(token.String(), ".")
+	otherToken := newObjectTokenSigner([]byte("other secret"), time.Minute).sign(docID, "photo.jpg")
+	expiredToken := newObjectTokenSigner([]byte("secret"), -time.Minute).sign(docID, "photo.jpg")
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte(docID.String() + ":9999999999:other.jpg"))
+
+	tests := []struct {
+		name    string
+		token   models.Token
+		isValid bool
+	}{
+		{name: "signed token is valid", token: token, isValid: true},
+		{name: "token signed with another secret is invalid", token: otherToken, isValid: false},
+		{name: "expired token is invalid", token: expiredToken, isValid: false},
+		{name: "token with changed payload is invalid", token: models.Token(forgedPayload + "." + signature), isValid: false},
+		{name: "token without signature is invalid", token: models.Token(payload), isValid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref, err := signer.verify(test.token)
+			if test.isValid && err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			} else if !test.isValid && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if test.isValid && (ref.DocID != docID || ref.FileName != "photo.jpg") {
+				t.Errorf("expected reference to photo.jpg of doc %s, got %+v", docID.String(), ref)
+			}
+		})
+	}
+}
+
+func TestUploadPolicy(t *testing.T) {
+	policy := &uploadPolicy{
+		extensions: map[models.FileExtension]models.MediaType{"jpg": models.MediaImage, "mp4": models.MediaVideo},
+		maxSizes:   map[models.MediaType]uint64{models.MediaImage: 100, models.MediaVideo: 1000},
+		maxFiles:   3,
+		eventQuota: 500,
+	}
+
+	tests := []struct {
+		name        string
+		objectTypes map[models.FileExtension]uint
+		usedEventKB uint64
+		expected    []allowType
+	}{
+		{
+			name:        "allowed types get their max size",
+			objectTypes: map[models.FileExtension]uint{"jpg": 1},
+			expected:    []allowType{{FileType: "jpg", IsAllow: true, MaxSize: 100}},
+		},
+		{
+			name:        "extension is case insensitive",
+			objectTypes: map[models.FileExtension]uint{".JPG": 1},
+			expected:    []allowType{{FileType: ".JPG", IsAllow: true, MaxSize: 100}},
+		},
+		{
+			name:        "unknown extension is not allowed",
+			objectTypes: map[models.FileExtension]uint{"exe": 1, "jpg": 1},
+			expected: []allowType{
+				{FileType: "exe", Reason: "extension exe is not allowed"},
+				{FileType: "jpg", IsAllow: true, MaxSize: 100},
+			},
+		},
+		{
+			name:        "too many files are not allowed",
+			objectTypes: map[models.FileExtension]uint{"jpg": 4},
+			expected:    []allowType{{FileType: "jpg", Reason: "at most 3 files could be uploaded at once"}},
+		},
+		{
+			name:        "remaining quota is shared by files",
+			objectTypes: map[models.FileExtension]uint{"mp4": 2},
+			usedEventKB: 300,
+			expected:    []allowType{{FileType: "mp4", IsAllow: true, MaxSize: 100}},
+		},
+		{
+			name:        "exceeded quota is not allowed",
+			objectTypes: map[models.FileExtension]uint{"jpg": 1},
+			usedEventKB: 600,
+			expected:    []allowType{{FileType: "jpg", Reason: "storage quota is exceeded"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := policy.allowTypes(test.objectTypes, test.usedEventKB, 0)
+			if fmt.Sprint(result) != fmt.Sprint(test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestUploadPolicyCheckMedia(t *testing.T) {
+	policy := &uploadPolicy{
+		extensions: map[models.FileExtension]models.MediaType{"jpg": models.MediaImage, "mp4": models.MediaVideo},
+		maxSizes:   map[models.MediaType]uint64{models.MediaImage: 100, models.MediaVideo: 1000},
+	}
+
+	tests := []struct {
+		name    string
+		media   models.MediaPath
+		isValid bool
+	}{
+		{name: "allowed file is valid", media: models.MediaPath{Type: models.MediaImage, FileName: "a.JPG", Size: 100}, isValid: true},
+		{name: "matched MIME type is valid", media: models.MediaPath{Type: models.MediaImage, FileName: "a.jpg", MimeType: "image/jpeg"}, isValid: true},
+		{name: "unknown extension is invalid", media: models.MediaPath{Type: models.MediaImage, FileName: "a.exe"}, isValid: false},
+		{name: "mismatched media type is invalid", media: models.MediaPath{Type: models.MediaVideo, FileName: "a.jpg"}, isValid: false},
+		{name: "large file is invalid", media: models.MediaPath{Type: models.MediaImage, FileName: "a.jpg", Size: 101}, isValid: false},
+		{name: "mismatched MIME type is invalid", media: models.MediaPath{Type: models.MediaImage, FileName: "a.jpg", MimeType: "application/x-msdownload"}, isValid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := policy.checkMedia(&test.media)
+			if test.isValid && err != nil {
+				t.Errorf("unexpected error %s", err.Error())
+			} else if !test.isValid && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
+
+// It keeps the audit events in memory.
+type memAuditDAL struct {
+	events []models.AuditEvent
+}
+
+func (d *memAuditDAL) CreateAuditEvent(event *models.AuditEvent) error {
+	d.events = append(d.events, *event)
+	return nil
+}
+
+func (d *memAuditDAL) GetAuditEvents(jpIDs *[]models.ID, cursor *models.Cursor, limit int,
+	filter *models.AuditFilter) (*[]models.AuditEvent, *models.Cursor, error) {
+	return &d.events, nil, nil
+}
+
+func TestAuditRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		details string
+		err     *e.Error
+		outcome models.AuditOutcome
+		// Expected details of the stored event
+		storedDetails string
+	}{
+		{name: "success", details: "version 2", outcome: models.AuditSuccess, storedDetails: "version 2"},
+		{name: "failure without details", err: e.NewErrorP("not found", SENotFound), outcome: models.AuditFailure,
+			storedDetails: "not found"},
+		{name: "failure with details", details: "version 2", err: e.NewErrorP("not found", SENotFound),
+			outcome: models.AuditFailure, storedDetails: "version 2: not found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auditDAL := &memAuditDAL{}
+			audit := newSAuditService(auditDAL, nil, nil, l.NewSLogger(l.None, nil, io.Discard))
+			event := newAuditEvent(models.AuditDocRestore, models.NilID, models.NilID, models.AuditTargetDoc,
+				models.NilID, models.ClientInfo{IP: "127.0.0.1", UserAgent: "test"})
+			event.Details = test.details
+			audit.Record(event, test.err)
+
+			if len(auditDAL.events) != 1 {
+				t.Fatalf("expected 1 stored event, got %d", len(auditDAL.events))
+			}
+			stored := auditDAL.events[0]
+			if stored.Outcome != test.outcome || stored.Details != test.storedDetails || stored.IP != "127.0.0.1" {
+				t.Errorf("unexpected stored event %+v", stored)
+			}
+		})
+	}
+}
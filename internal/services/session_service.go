@@ -1,14 +1,21 @@
 package services
 
 import (
+	"DMS/internal/config"
 	"DMS/internal/dal"
 	e "DMS/internal/error"
+	"DMS/internal/jwtkeys"
 	l "DMS/internal/logger"
 	m "DMS/internal/models"
-	"crypto/rsa"
+	"DMS/internal/sms"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
-	"os"
-	"strconv"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,22 +23,66 @@ import (
 
 // Contains interface for all functionalities related to sessions.
 type SessionService interface {
-	// Create a login for the specified user and return corresponding jwt.
+	// Generate a one-time code for the user with the phone number and send it to him by
+	// SMS. The code expires after a while and the number of requests for each phone number
+	// and each IP address in a period of time is limited. If the phone doesn't belong to
+	// any enabled user, nothing is sent but the result is the same, so the registered
+	// phone numbers couldn't be enumerated.
 	//
 	// Possible error codes:
-	// SEDBError- SENotFound- SEEncodingError
-	CreateSessionJustByPhone(details *m.PhoneBasedLoginInfo) (*string, *e.Error)
+	// SEDBError- SETooManyRequests- SEInternal
+	RequestPhoneOTP(phone m.PhoneNumber, client m.ClientInfo) *e.Error
+	// Create a login for the user with the phone number and return its tokens, if the
+	// one-time code is correct. After some failed attempts the code is revoked and the
+	// user must request a new one. Disabled users can't login.
+	//
+	// Possible error codes:
+	// SEDBError- SENotFound- SEIsDisabled- SEAuthFailed- SETooManyRequests- SEEncodingError- SEInternal
+	CreateSessionByPhoneOTP(details *m.PhoneBasedLoginInfo, client m.ClientInfo) (*m.SessionTokens, *e.Error)
+	// Issue new tokens for the session of the refresh token. Each refresh token could be
+	// used once and the session expiration slides forward on each refresh. If a used
+	// refresh token is presented again, the whole session is revoked. Sessions of disabled
+	// users can't be refreshed.
+	//
+	// Possible error codes:
+	// SEDBError- SEAuthFailed- SEIsDisabled- SEEncodingError- SEInternal
+	RefreshSession(refreshToken string, client m.ClientInfo) (*m.SessionTokens, *e.Error)
+	// Set last usage time of the session to now. To reduce database writes, it's not updated
+	// if the session is used recently.
+	//
+	// Possible error codes:
+	// SEDBError
+	MarkSessionUsed(sessionID m.ID) *e.Error
 	// Delete the session associated with the JWT. Note that the user id that sends the session deletion
 	// The request must match the user id that the session is created for.
 	//
 	// Possible error codes:
 	// SEDBError- SENotFound- SEDeletedPreviously
-	DeleteSession(jwt *m.JWT) *e.Error
+	DeleteSession(jwt *m.JWT, client m.ClientInfo) *e.Error
+	// Return active sessions of the owner of the JWT. The session of the JWT is marked as
+	// the current one.
+	//
+	// Possible error codes:
+	// SEDBError
+	GetUserSessions(jwt *m.JWT) (*[]m.Session, *e.Error)
+	// Revoke the session sessionID of the owner of the JWT. Its tokens stop working
+	// immediately.
+	//
+	// Possible error codes:
+	// SEDBError- SENotFound
+	RevokeSession(jwt *m.JWT, sessionID m.ID, client m.ClientInfo) *e.Error
+	// Revoke all sessions of the owner of the JWT and return number of revoked sessions.
+	// If exceptCurrent be true, the session of the JWT is kept.
+	//
+	// Possible error codes:
+	// SEDBError
+	RevokeUserSessions(jwt *m.JWT, exceptCurrent bool, client m.ClientInfo) (int64, *e.Error)
 	// Validate session based on the input jwt token. We must remove any prefix like "Bearer " from the
-	// input JWT token before calling the method wih that value.
+	// input JWT token before calling the method wih that value. Sessions of disabled users
+	// are rejected.
 	//
 	// Possible error codes:
-	// SEAuthFailed- SENotFound- SEDBError
+	// SEAuthFailed- SENotFound- SEIsDisabled- SEDBError
 	ValidateSessionJWT(token m.Token) (*m.JWT, *e.Error)
 	// If both error and session be nil, means there's not any matched session.
 	// (whether disabled, removed, and etc.)
@@ -39,48 +90,106 @@ type SessionService interface {
 	// Possible error codes:
 	// SEDBError
 	GetSessionByID(sessionID *m.ID) (*m.Session, *e.Error)
+	// Return public keys that JWTs could be verified with.
+	GetJWKS() m.JWKS
 	// GetSessionByToken(token string) (*m.Session, *e.Error)
-	// Update some details of session, e.g. expiration time
-
+	// Update expiration time and last usage time of the session.
+	//
+	// Possible error codes:
+	// SEDBError- SENotFound
 	UpdateSession(session *m.Session) *e.Error
 }
 
 // A new simple session service that contains basic functionalities.
 type sSessionService struct {
-	session       dal.SessionDAL
-	user          dal.UserDAL
-	logger        l.Logger
-	rsaPrivateKey rsa.PrivateKey
-	rsaPublicKey  rsa.PublicKey
+	session   dal.SessionDAL
+	user      dal.UserDAL
+	cache     dal.InMemoryDAL
+	smsSender sms.SMSSender
+	audit     AuditService
+	logger    l.Logger
+	// Keys that JWTs are signed and verified with
+	keys *jwtkeys.KeyRing
+	otp  otpConfig
+	// Lifetime of access tokens (JWTs)
+	accessTokenExpire time.Duration
+	// Lifetime of refresh tokens. A session expires if it isn't refreshed in this period.
+	refreshTokenExpire time.Duration
+}
+
+// Minimum interval between two updates of the last usage time of a session. (In seconds)
+const sessionUsageUpdateInterval = 60
+
+// Settings of one-time codes used for phone based login
+type otpConfig struct {
+	// Number of digits of the code
+	length int
+	// Time the code expires after it is generated
+	expire time.Duration
+	// Maximum number of codes could be requested for a phone number in each window
+	maxRequests   int64
+	requestWindow time.Duration
+	// Maximum number of codes could be requested from an IP address in each window
+	maxRequestsPerIP int64
+	// Maximum number of wrong codes could be entered before the code is revoked
+	maxAttempts int64
 }
 
-func newSSessionService(session dal.SessionDAL, user dal.UserDAL, logger l.Logger) SessionService {
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(os.Getenv("JWT_PRIVATE_KEY")))
+func newSSessionService(session dal.SessionDAL, user dal.UserDAL, cache dal.InMemoryDAL,
+	smsSender sms.SMSSender, jwtConfig *config.JWTConfig, otp *config.OTPConfig, audit AuditService,
+	logger l.Logger) SessionService {
+	keys, err := loadJWTKeys(jwtConfig)
 	if err != nil {
-		logger.Panicf("Failed to parse jwt rsa private key. (%s)", err.Error())
-	}
-	publicKey, err2 := jwt.ParseRSAPublicKeyFromPEM([]byte(os.Getenv("JWT_PUBLIC_KEY")))
-	if err2 != nil {
-		logger.Panicf("Failed to parse jwt rsa public key. (%s)", err.Error())
+		logger.Panicf("Failed to load jwt keys. (%s)", err.Error())
 	}
+	activeKID, _ := keys.Active()
+	logger.Infof("JWTs are signed with key %s", activeKID)
 
 	return &sSessionService{
 		session,
 		user,
+		cache,
+		smsSender,
+		audit,
 		logger,
-		*privateKey,
-		*publicKey,
+		keys,
+		otpConfig{
+			length:           otp.Length,
+			expire:           time.Second * time.Duration(otp.ExpireSec),
+			maxRequests:      int64(otp.MaxRequests),
+			requestWindow:    time.Second * time.Duration(otp.RequestWindowSec),
+			maxRequestsPerIP: int64(otp.MaxRequestsPerIP),
+			maxAttempts:      int64(otp.MaxAttempts),
+		},
+		time.Minute * time.Duration(jwtConfig.LifetimeMin),
+		time.Minute * time.Duration(jwtConfig.RefreshTokenLifetimeMin),
 	}
 }
 
 type loginType int
 
 const (
-	// Just by entering phone number, we can login and create a session.
-	LoginJustByPhone loginType = iota
+	// By entering phone number and the one-time code sent to it, we can login and create
+	// a session.
+	LoginByPhoneOTP loginType = iota
+)
+
+// Prefixes of keys of one-time codes in the in-memory database. Each key is followed by
+// the phone number, except otpIPRequestKeyPrefix that is followed by the IP address.
+const (
+	otpCodeKeyPrefix      = "otp:code:"
+	otpRequestKeyPrefix   = "otp:request:"
+	otpIPRequestKeyPrefix = "otp:ip-request:"
+	otpAttemptKeyPrefix   = "otp:attempt:"
 )
 
-func (s *sSessionService) DeleteSession(jwt *m.JWT) *e.Error {
+func (s *sSessionService) DeleteSession(jwt *m.JWT, client m.ClientInfo) *e.Error {
+	err := s.deleteSession(jwt)
+	s.audit.Record(newAuditEvent(m.AuditLogout, jwt.UserID, jwt.JPID, m.AuditTargetSession, jwt.JTI, client), err)
+	return err
+}
+
+func (s *sSessionService) deleteSession(jwt *m.JWT) *e.Error {
 	session, err := s.session.GetSessionByID(jwt.JTI)
 	if err != nil {
 		return e.NewErrorP("failed to get session id %s. (%s)", SEDBError, jwt.JTI.String(), err.Error())
@@ -105,38 +214,369 @@ func (s *sSessionService) DeleteSession(jwt *m.JWT) *e.Error {
 	return nil
 }
 
-func (s *sSessionService) CreateSessionJustByPhone(details *m.PhoneBasedLoginInfo) (*string, *e.Error) {
+func (s *sSessionService) GetUserSessions(jwt *m.JWT) (*[]m.Session, *e.Error) {
+	sessions, err := s.session.GetUserSessions(jwt.UserID, time.Now().UTC().Unix())
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	for i := range *sessions {
+		(*sessions)[i].IsCurrent = (*sessions)[i].ID == jwt.JTI
+	}
+	return sessions, nil
+}
+
+func (s *sSessionService) RevokeSession(jwt *m.JWT, sessionID m.ID, client m.ClientInfo) *e.Error {
+	err := s.revokeSession(jwt, sessionID)
+	s.audit.Record(newAuditEvent(m.AuditSessionRevoke, jwt.UserID, jwt.JPID, m.AuditTargetSession, sessionID, client), err)
+	return err
+}
+
+func (s *sSessionService) revokeSession(jwt *m.JWT, sessionID m.ID) *e.Error {
+	session, err := s.session.GetSessionByID(sessionID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if session == nil || session.UserID != jwt.UserID {
+		// Don't reveal sessions of other users.
+		return e.NewErrorP("session %s of user %s not found", SENotFound, sessionID.String(), jwt.UserID.String())
+	}
+	isDeleted, err := s.session.DeleteSession(sessionID)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isDeleted {
+		return e.NewErrorP("session %s of user %s not found", SENotFound, sessionID.String(), jwt.UserID.String())
+	}
+	return nil
+}
+
+func (s *sSessionService) RevokeUserSessions(jwt *m.JWT, exceptCurrent bool, client m.ClientInfo) (int64, *e.Error) {
+	count, err := s.revokeUserSessions(jwt, exceptCurrent)
+	event := newAuditEvent(m.AuditSessionsRevoke, jwt.UserID, jwt.JPID, m.AuditTargetUser, jwt.UserID, client)
+	event.Details = fmt.Sprintf("%d sessions (except current: %t)", count, exceptCurrent)
+	s.audit.Record(event, err)
+	return count, err
+}
+
+func (s *sSessionService) revokeUserSessions(jwt *m.JWT, exceptCurrent bool) (int64, *e.Error) {
+	exceptSessionID := m.NilID
+	if exceptCurrent {
+		exceptSessionID = jwt.JTI
+	}
+	count, err := s.session.DeleteUserSessions(jwt.UserID, exceptSessionID)
+	if err != nil {
+		return 0, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return count, nil
+}
+
+func (s *sSessionService) RequestPhoneOTP(phone m.PhoneNumber, client m.ClientInfo) *e.Error {
+	event := newAuditEvent(m.AuditOTPRequest, m.NilID, m.NilID, "", m.NilID, client)
+	err := s.requestPhoneOTP(phone, client.IP, event)
+	s.audit.Record(event, withoutPhone(err))
+	if err != nil && (err.GetCode() == SENotFound || err.GetCode() == SEIsDisabled) {
+		s.logger.Debugf("One-time code is not sent: %s", err.Error())
+		return nil
+	}
+	return err
+}
+
+// Generate and send a one-time code to the phone. The requests are counted before looking
+// up the user, so unknown phone numbers are limited too. The user of the phone is set as
+// the actor of the audit event, once it's found.
+//
+// Possible error codes:
+// SEDBError- SENotFound- SEIsDisabled- SETooManyRequests- SEInternal
+func (s *sSessionService) requestPhoneOTP(phone m.PhoneNumber, ip string, event *m.AuditEvent) *e.Error {
+	requests, err := s.cache.Increment(otpRequestKeyPrefix+phone.ToString(), s.otp.requestWindow)
+	if err != nil {
+		return e.NewErrorP("failed to count one-time code requests of phone %s. (%s)", SEInternal,
+			phone.ToString(), err.Error())
+	} else if requests > s.otp.maxRequests {
+		return e.NewErrorP("phone %s requested one-time code %d times in the last %s", SETooManyRequests,
+			phone.ToString(), requests, s.otp.requestWindow.String())
+	}
+	if ip != "" {
+		requests, err := s.cache.Increment(otpIPRequestKeyPrefix+ip, s.otp.requestWindow)
+		if err != nil {
+			return e.NewErrorP("failed to count one-time code requests of IP %s. (%s)", SEInternal,
+				ip, err.Error())
+		} else if requests > s.otp.maxRequestsPerIP {
+			return e.NewErrorP("IP %s requested one-time code %d times in the last %s", SETooManyRequests,
+				ip, requests, s.otp.requestWindow.String())
+		}
+	}
+
+	user, err := s.user.GetUserByPhone(phone)
+	if err != nil {
+		return e.NewErrorP("failed to get user by its phone %s. (%s)", SEDBError, phone.ToString(), err.Error())
+	} else if user == nil {
+		return e.NewErrorP("user with phone %s not found", SENotFound, phone.ToString())
+	}
+	event.ActorUserID = user.ID
+	if user.IsDisabled == m.IsDisabled {
+		return e.NewErrorP("user with phone %s is disabled", SEIsDisabled, phone.ToString())
+	}
+
+	code, err := generateOTP(s.otp.length)
+	if err != nil {
+		return e.NewErrorP("failed to generate one-time code. (%s)", SEInternal, err.Error())
+	}
+	hashedCode, err := hashOTP(code)
+	if err != nil {
+		return e.NewErrorP("failed to hash one-time code. (%s)", SEInternal, err.Error())
+	}
+	// A new code replaces the previous one, so its failed attempts must be reset too.
+	if err := s.cache.Delete(otpAttemptKeyPrefix + phone.ToString()); err != nil {
+		return e.NewErrorP("failed to reset one-time code attempts of phone %s. (%s)", SEInternal,
+			phone.ToString(), err.Error())
+	}
+	if err := s.cache.SetWithExpire(otpCodeKeyPrefix+phone.ToString(), hashedCode, s.otp.expire); err != nil {
+		return e.NewErrorP("failed to store one-time code of phone %s. (%s)", SEInternal,
+			phone.ToString(), err.Error())
+	}
+	if err := s.smsSender.Send(phone, fmt.Sprintf(otpMessageC, code)); err != nil {
+		return e.NewErrorP("failed to send one-time code to phone %s. (%s)", SEInternal,
+			phone.ToString(), err.Error())
+	}
+	return nil
+}
+
+func (s *sSessionService) CreateSessionByPhoneOTP(details *m.PhoneBasedLoginInfo, client m.ClientInfo) (*m.SessionTokens, *e.Error) {
+	event := newAuditEvent(m.AuditLogin, m.NilID, m.NilID, m.AuditTargetSession, m.NilID, client)
+	tokens, err := s.createSessionByPhoneOTP(details, event)
+	s.audit.Record(event, withoutPhone(err))
+	return tokens, err
+}
+
+// Return an error with the same code and a general message, because messages of errors of
+// the phone based login contain the phone number that must not be kept in the audit log.
+// The user is kept as the actor of the audit event instead.
+func withoutPhone(err *e.Error) *e.Error {
+	if err == nil {
+		return nil
+	}
+	var message string
+	switch err.GetCode() {
+	case SENotFound:
+		message = "user not found"
+	case SEIsDisabled:
+		message = "user is disabled"
+	case SETooManyRequests:
+		message = "too many requests or attempts"
+	case SEAuthFailed:
+		message = "one-time code is wrong, expired or used"
+	default:
+		message = "internal error"
+	}
+	return e.NewErrorP(message, err.GetCode())
+}
+
+// Login the user of the phone. The user and the created session are set as the actor and
+// the target of the audit event, once they're known.
+func (s *sSessionService) createSessionByPhoneOTP(details *m.PhoneBasedLoginInfo, event *m.AuditEvent) (*m.SessionTokens, *e.Error) {
+	if err := s.verifyOTP(details.PhoneNumber, details.Code); err != nil {
+		return nil, err
+	}
 	user, err := s.user.GetUserByPhone(details.PhoneNumber)
 	if err != nil {
 		return nil, e.NewErrorP("failed to get user by its phone %s. (%s)", SEDBError, details.PhoneNumber.ToString(), err.Error())
 	} else if user == nil {
 		return nil, e.NewErrorP("user with phone %s not found", SENotFound, details.PhoneNumber.ToString())
 	}
-	expiredTime, _ := strconv.ParseInt(os.Getenv("JWT_EXPIRED_TIME_MIN"), 10, 64)
+	event.ActorUserID = user.ID
+	if user.IsDisabled == m.IsDisabled {
+		return nil, e.NewErrorP("user with phone %s is disabled", SEIsDisabled, details.PhoneNumber.ToString())
+	}
+	return s.createSession(user, details.UserAgent, event)
+}
+
+// Check the one-time code of the phone is correct. The code is revoked if it's correct
+// or there are too many failed attempts. A correct code is consumed atomically, so it
+// couldn't be used by two concurrent logins.
+//
+// Possible error codes:
+// SEAuthFailed- SETooManyRequests- SEInternal
+func (s *sSessionService) verifyOTP(phone m.PhoneNumber, code string) *e.Error {
+	codeKey := otpCodeKeyPrefix + phone.ToString()
+	attemptKey := otpAttemptKeyPrefix + phone.ToString()
+	hashedCode, err := s.cache.Get(codeKey)
+	if err != nil {
+		return e.NewErrorP("failed to get one-time code of phone %s. (%s)", SEInternal, phone.ToString(), err.Error())
+	} else if hashedCode == nil {
+		return e.NewErrorP("there's not any valid one-time code for phone %s", SEAuthFailed, phone.ToString())
+	}
+
+	attempts, err := s.cache.Increment(attemptKey, s.otp.expire)
+	if err != nil {
+		return e.NewErrorP("failed to count one-time code attempts of phone %s. (%s)", SEInternal,
+			phone.ToString(), err.Error())
+	} else if attempts > s.otp.maxAttempts {
+		s.revokeOTP(phone)
+		return e.NewErrorP("too many failed attempts for one-time code of phone %s", SETooManyRequests,
+			phone.ToString())
+	}
 
+	if !isOTPMatched(code, *hashedCode) {
+		return e.NewErrorP("one-time code of phone %s is wrong", SEAuthFailed, phone.ToString())
+	}
+	isConsumed, err := s.cache.DeleteIfEqual(codeKey, *hashedCode)
+	if err != nil {
+		return e.NewErrorP("failed to revoke one-time code of phone %s. (%s)", SEInternal, phone.ToString(),
+			err.Error())
+	} else if !isConsumed {
+		return e.NewErrorP("one-time code of phone %s is used or replaced", SEAuthFailed, phone.ToString())
+	}
+	if err := s.cache.DeleteWithTry(attemptKey, 2); err != nil {
+		s.logger.Errorf("Failed to reset one-time code attempts of phone %s. (%s)", phone.ToString(), err.Error())
+	}
+	return nil
+}
+
+// Remove the one-time code of the phone and its failed attempts. Failures are just logged,
+// because the code expires anyway.
+func (s *sSessionService) revokeOTP(phone m.PhoneNumber) {
+	if err := s.cache.DeleteWithTry(otpCodeKeyPrefix+phone.ToString(), 2); err != nil {
+		s.logger.Errorf("Failed to revoke one-time code of phone %s. (%s)", phone.ToString(), err.Error())
+	}
+	if err := s.cache.DeleteWithTry(otpAttemptKeyPrefix+phone.ToString(), 2); err != nil {
+		s.logger.Errorf("Failed to reset one-time code attempts of phone %s. (%s)", phone.ToString(), err.Error())
+	}
+}
+
+// Create a session for the user and return its tokens.
+//
+// Possible error codes:
+// SEDBError- SEEncodingError- SEInternal
+func (s *sSessionService) createSession(user *m.User, userAgent string, event *m.AuditEvent) (*m.SessionTokens, *e.Error) {
+	now := time.Now().UTC()
 	session := &m.Session{
-		UserAgent:   details.UserAgent,
+		UserAgent:   userAgent,
 		UserID:      user.ID,
-		IssuedAt:    time.Now().Unix(),
-		ExpiredAt:   time.Now().Add(time.Minute * time.Duration(expiredTime)).Unix(),
-		LastUsageAt: 0,
+		IssuedAt:    now.Unix(),
+		ExpiredAt:   now.Add(s.refreshTokenExpire).Unix(),
+		LastUsageAt: now.Unix(),
 	}
 	sessionID, err := s.session.CreateSession(session)
 	if err != nil {
 		return nil, e.NewErrorP("failed to create session for user id %s. (%s)", SEDBError, session.UserID, err.Error())
 	}
+	session.ID = *sessionID
+	event.TargetID = session.ID
+
+	refreshToken, tokenHash, err := generateRefreshToken()
+	if err != nil {
+		return nil, e.NewErrorP("failed to generate refresh token. (%s)", SEInternal, err.Error())
+	}
+	if _, err := s.session.CreateRefreshToken(session.ID, tokenHash, session.ExpiredAt); err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return s.issueTokens(session, refreshToken, now)
+}
+
+func (s *sSessionService) RefreshSession(refreshToken string, client m.ClientInfo) (*m.SessionTokens, *e.Error) {
+	event := newAuditEvent(m.AuditSessionRefresh, m.NilID, m.NilID, m.AuditTargetSession, m.NilID, client)
+	tokens, err := s.refreshSession(refreshToken, event)
+	s.audit.Record(event, err)
+	return tokens, err
+}
+
+// Rotate the refresh token. The session of the token and its user are set as the target
+// and the actor of the audit event, once they're known.
+func (s *sSessionService) refreshSession(refreshToken string, event *m.AuditEvent) (*m.SessionTokens, *e.Error) {
+	token, err := s.session.GetRefreshTokenByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if token == nil {
+		return nil, e.NewErrorP("refresh token not found", SEAuthFailed)
+	}
+	event.TargetID = token.SessionID
+	if token.UsedAt != 0 {
+		return nil, s.revokeReusedSession(token.SessionID)
+	}
+	now := time.Now().UTC()
+	if token.ExpiredAt <= now.Unix() {
+		return nil, e.NewErrorP("refresh token of session %s is expired", SEAuthFailed, token.SessionID.String())
+	}
+	session, err := s.session.GetSessionByID(token.SessionID)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if session == nil {
+		return nil, e.NewErrorP("session %s of refresh token is deleted", SEAuthFailed, token.SessionID.String())
+	}
+	event.ActorUserID = session.UserID
+	if isDisabled, err := s.user.IsDisabledByID(session.UserID); err != nil {
+		return nil, e.NewErrorP("failed to check if user %s is disabled. (%s)", SEDBError,
+			session.UserID.String(), err.Error())
+	} else if isDisabled {
+		return nil, e.NewErrorP("user %s of session %s is disabled", SEIsDisabled, session.UserID.String(),
+			session.ID.String())
+	}
+
+	newRefreshToken, newTokenHash, err := generateRefreshToken()
+	if err != nil {
+		return nil, e.NewErrorP("failed to generate refresh token. (%s)", SEInternal, err.Error())
+	}
+	// The session slides forward on each refresh.
+	session.ExpiredAt = now.Add(s.refreshTokenExpire).Unix()
+	session.LastUsageAt = now.Unix()
+	isRotated, err := s.session.RotateRefreshToken(token.ID, newTokenHash, session)
+	if err != nil {
+		return nil, e.NewErrorP(err.Error(), SEDBError)
+	} else if !isRotated {
+		// Another request used the token at the same time.
+		return nil, s.revokeReusedSession(token.SessionID)
+	}
+	return s.issueTokens(session, newRefreshToken, now)
+}
+
+// Delete the session whose used refresh token is presented again. It means the token is
+// probably stolen, so all tokens of the session are revoked. The returned error always
+// has code SEAuthFailed, except it couldn't delete the session.
+//
+// Possible error codes:
+// SEAuthFailed- SEDBError
+func (s *sSessionService) revokeReusedSession(sessionID m.ID) *e.Error {
+	s.logger.Warnf("Reuse of refresh token of session %s is detected. The session is revoked.", sessionID.String())
+	if _, err := s.session.DeleteSession(sessionID); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	}
+	return e.NewErrorP("refresh token of session %s is used previously", SEAuthFailed, sessionID.String())
+}
+
+// Return the access token and the refresh token of the session. The access token is
+// issued at the given time.
+//
+// Possible error codes:
+// SEEncodingError
+func (s *sSessionService) issueTokens(session *m.Session, refreshToken string, issuedAt time.Time) (*m.SessionTokens, *e.Error) {
 	jwt := &m.JWT{
-		JTI:    *sessionID,
-		UserID: user.ID,
+		JTI:    session.ID,
+		UserID: session.UserID,
 		JPID:   m.NilID,
-		IAT:    session.IssuedAt,
-		EXP:    session.ExpiredAt,
+		IAT:    issuedAt.Unix(),
+		EXP:    issuedAt.Add(s.accessTokenExpire).Unix(),
+	}
+	// The access token mustn't outlive its session.
+	if jwt.EXP > session.ExpiredAt {
+		jwt.EXP = session.ExpiredAt
 	}
-	if jwtStr, err := s.generateJWT(jwt); err != nil {
+	jwtStr, err := s.generateJWT(jwt)
+	if err != nil {
 		return nil, err.AppendBegin("failed to encodeing JWT")
-	} else {
-		return &jwtStr, nil
 	}
+	return &m.SessionTokens{
+		AccessToken:           jwtStr,
+		AccessTokenExpiredAt:  jwt.EXP,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiredAt: session.ExpiredAt,
+	}, nil
+}
+
+func (s *sSessionService) MarkSessionUsed(sessionID m.ID) *e.Error {
+	if err := s.session.UpdateSessionLastUsage(sessionID, time.Now().UTC().Unix(),
+		sessionUsageUpdateInterval); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	}
+	return nil
 }
 
 func (s *sSessionService) ValidateSessionJWT(token m.Token) (*m.JWT, *e.Error) {
@@ -151,6 +591,13 @@ func (s *sSessionService) ValidateSessionJWT(token m.Token) (*m.JWT, *e.Error) {
 	} else if session == nil {
 		return nil, e.NewErrorP("session with id %s not found", SENotFound, validJWT.JTI)
 	}
+	if isDisabled, err := s.user.IsDisabledByID(validJWT.UserID); err != nil {
+		return nil, e.NewErrorP("failed to check if user %s is disabled. (%s)", SEDBError,
+			validJWT.UserID.String(), err.Error())
+	} else if isDisabled {
+		return nil, e.NewErrorP("user %s of session %s is disabled", SEIsDisabled, validJWT.UserID.String(),
+			validJWT.JTI.String())
+	}
 	return validJWT, nil
 }
 
@@ -164,7 +611,13 @@ func (s *sSessionService) validateJWT(token m.Token) (*m.JWT, *e.Error) {
 		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("JWT token is invalid")
 		}
-		return &s.rsaPublicKey, nil
+		// JWTs signed before key ids don't have kid.
+		kid, _ := t.Header["kid"].(string)
+		key, ok := s.keys.PublicKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("JWT key id \"%s\" is unknown", kid)
+		}
+		return key, nil
 	})
 	if err != nil {
 		return nil, e.NewErrorP(err.Error(), SEAuthFailed)
@@ -203,7 +656,17 @@ func (s *sSessionService) validateJWT(token m.Token) (*m.JWT, *e.Error) {
 }
 
 func (s *sSessionService) UpdateSession(session *m.Session) *e.Error {
-	panic("UpdateSessionExp doesn't implemented")
+	isUpdated, err := s.session.UpdateSession(session)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isUpdated {
+		return e.NewErrorP("session with id %s not found", SENotFound, session.ID.String())
+	}
+	return nil
+}
+
+func (s *sSessionService) GetJWKS() m.JWKS {
+	return s.keys.JWKS()
 }
 
 func (s *sSessionService) GetSessionByID(sessionID *m.ID) (*m.Session, *e.Error) {
@@ -214,6 +677,75 @@ func (s *sSessionService) GetSessionByID(sessionID *m.ID) (*m.Session, *e.Error)
 	return session, nil
 }
 
+// The text of the SMS that contains the one-time code
+const otpMessageC = "کد ورود شما به سامانه: %s"
+
+// Generate a random numeric code with the given number of digits.
+func generateOTP(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n.Int64()), nil
+}
+
+// Hash the one-time code with a random salt. The result has format "salt:hash" and both
+// parts are hex encoded.
+func hashOTP(code string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(append(salt, []byte(code)...))
+	return hex.EncodeToString(salt) + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+// Return true if the code matches the hashed code generated by hashOTP.
+func isOTPMatched(code, hashedCode string) bool {
+	saltHex, hashHex, found := strings.Cut(hashedCode, ":")
+	if !found {
+		return false
+	}
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(hashHex)
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256(append(salt, []byte(code)...))
+	return subtle.ConstantTimeCompare(hash[:], expected) == 1
+}
+
+// Generate a random refresh token and return it together with its hash.
+func generateRefreshToken() (token, tokenHash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+// Return SHA-256 hash of the refresh token. (hex encoded) Refresh tokens are random
+// enough, so they don't need salt.
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Load keys of JWTs from the keys directory. The active key is the configured one or the
+// newest key if it's empty. If the directory is not set, the single key pair of the
+// config is used.
+func loadJWTKeys(cfg *config.JWTConfig) (*jwtkeys.KeyRing, error) {
+	if cfg.KeysDir != "" {
+		return jwtkeys.LoadKeyRing(cfg.KeysDir, cfg.ActiveKeyID)
+	}
+	return jwtkeys.NewKeyRing(jwtkeys.LegacyKID, []byte(cfg.PrivateKey), []byte(cfg.PublicKey))
+}
+
 // Possible error codes:
 // SEEncodingError
 func (s *sSessionService) generateJWT(j *m.JWT) (string, *e.Error) {
@@ -225,8 +757,10 @@ func (s *sSessionService) generateJWT(j *m.JWT) (string, *e.Error) {
 		"jti":     j.JTI.String(),
 		"user_id": j.UserID.String(),
 	})
+	kid, key := s.keys.Active()
+	token.Header["kid"] = kid
 
-	tokenString, err := token.SignedString(&s.rsaPrivateKey)
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", e.NewErrorP("failed to generate JWT token. (%s)", SEEncodingError, err.Error())
 	}
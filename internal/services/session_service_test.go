@@ -0,0 +1,408 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	"DMS/internal/jwtkeys"
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// It keeps the key-values in memory. Expirations are ignored.
+type memInMemoryDAL struct {
+	dal.InMemoryDAL
+	values map[string]string
+}
+
+func newMemInMemoryDAL() *memInMemoryDAL {
+	return &memInMemoryDAL{values: make(map[string]string)}
+}
+
+func (d *memInMemoryDAL) Get(key string) (*string, error) {
+	if value, ok := d.values[key]; ok {
+		return &value, nil
+	}
+	return nil, nil
+}
+
+func (d *memInMemoryDAL) Set(key, value string) error {
+	d.values[key] = value
+	return nil
+}
+
+func (d *memInMemoryDAL) SetWithExpire(key, value string, expire time.Duration) error {
+	d.values[key] = value
+	return nil
+}
+
+func (d *memInMemoryDAL) Increment(key string, expire time.Duration) (int64, error) {
+	n, _ := strconv.ParseInt(d.values[key], 10, 64)
+	d.values[key] = strconv.FormatInt(n+1, 10)
+	return n + 1, nil
+}
+
+func (d *memInMemoryDAL) Delete(key string) error {
+	delete(d.values, key)
+	return nil
+}
+
+func (d *memInMemoryDAL) DeleteIfEqual(key, value string) (bool, error) {
+	if current, ok := d.values[key]; !ok || current != value {
+		return false, nil
+	}
+	delete(d.values, key)
+	return true, nil
+}
+
+func (d *memInMemoryDAL) DeleteWithTry(key string, tryTimes int) error {
+	return d.Delete(key)
+}
+
+// It keeps the users in memory and counts the lookups by phone. jpUsers is the user of
+// each job position.
+type memUserDAL struct {
+	dal.UserDAL
+	users        []models.User
+	jpUsers      map[models.ID]models.ID
+	phoneLookups int
+}
+
+func (d *memUserDAL) GetUserByPhone(phoneNumber models.PhoneNumber) (*models.User, error) {
+	d.phoneLookups++
+	for _, user := range d.users {
+		if user.PhoneNumber == phoneNumber {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *memUserDAL) IsDisabledByID(id models.ID) (bool, error) {
+	for _, user := range d.users {
+		if user.ID == id {
+			return user.IsDisabled == models.IsDisabled, nil
+		}
+	}
+	return false, nil
+}
+
+// It keeps the sent messages of each phone number.
+type memSMSSender struct {
+	messages map[models.PhoneNumber][]string
+}
+
+func (s *memSMSSender) Send(phone models.PhoneNumber, message string) error {
+	s.messages[phone] = append(s.messages[phone], message)
+	return nil
+}
+
+func newTestSessionService(users ...models.User) (*sSessionService, *memUserDAL, *memSMSSender) {
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	userDAL := &memUserDAL{users: users}
+	sender := &memSMSSender{messages: make(map[models.PhoneNumber][]string)}
+	service := &sSessionService{
+		user:      userDAL,
+		cache:     newMemInMemoryDAL(),
+		smsSender: sender,
+		audit:     newSAuditService(&memAuditDAL{}, nil, nil, logger),
+		logger:    logger,
+		otp: otpConfig{
+			length:           6,
+			expire:           time.Minute,
+			maxRequests:      2,
+			requestWindow:    time.Minute,
+			maxRequestsPerIP: 3,
+			maxAttempts:      3,
+		},
+	}
+	return service, userDAL, sender
+}
+
+func TestRequestPhoneOTP(t *testing.T) {
+	enabled := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000001"}
+	disabled := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000002", IsDisabled: models.IsDisabled}
+
+	t.Run("unknown and disabled phones get the same result without any SMS", func(t *testing.T) {
+		service, _, sender := newTestSessionService(enabled, disabled)
+		for _, phone := range []models.PhoneNumber{"9179999999", disabled.PhoneNumber, enabled.PhoneNumber} {
+			if err := service.RequestPhoneOTP(phone, models.ClientInfo{}); err != nil {
+				t.Fatalf("unexpected error for phone %s: %s", phone, err.Error())
+			}
+		}
+		if len(sender.messages) != 1 || len(sender.messages[enabled.PhoneNumber]) != 1 {
+			t.Errorf("expected just 1 SMS to phone %s, got %v", enabled.PhoneNumber, sender.messages)
+		}
+	})
+
+	t.Run("requests of a phone are limited before looking up the user", func(t *testing.T) {
+		service, userDAL, _ := newTestSessionService(enabled)
+		for i := 0; i < 2; i++ {
+			if err := service.RequestPhoneOTP("9179999999", models.ClientInfo{}); err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+		}
+		err := service.RequestPhoneOTP("9179999999", models.ClientInfo{})
+		if err == nil || err.GetCode() != SETooManyRequests {
+			t.Fatalf("expected error code %d, got %v", SETooManyRequests, err)
+		}
+		if userDAL.phoneLookups != 2 {
+			t.Errorf("expected 2 lookups of the user, got %d", userDAL.phoneLookups)
+		}
+	})
+
+	t.Run("requests of an IP are limited", func(t *testing.T) {
+		service, _, _ := newTestSessionService(enabled)
+		client := models.ClientInfo{IP: "10.0.0.1"}
+		for _, phone := range []models.PhoneNumber{"9179999991", "9179999992", "9179999993"} {
+			if err := service.RequestPhoneOTP(phone, client); err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+		}
+		err := service.RequestPhoneOTP(enabled.PhoneNumber, client)
+		if err == nil || err.GetCode() != SETooManyRequests {
+			t.Fatalf("expected error code %d, got %v", SETooManyRequests, err)
+		}
+		if err := service.RequestPhoneOTP(enabled.PhoneNumber, models.ClientInfo{IP: "10.0.0.2"}); err != nil {
+			t.Errorf("unexpected error for another IP %s", err.Error())
+		}
+	})
+}
+
+func TestVerifyOTP(t *testing.T) {
+	user := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000001"}
+	service, _, sender := newTestSessionService(user)
+	if err := service.RequestPhoneOTP(user.PhoneNumber, models.ClientInfo{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	message := sender.messages[user.PhoneNumber][0]
+	code := message[len(message)-service.otp.length:]
+
+	if err := service.verifyOTP(user.PhoneNumber, "wrong"); err == nil || err.GetCode() != SEAuthFailed {
+		t.Fatalf("expected error code %d for wrong code, got %v", SEAuthFailed, err)
+	}
+	if err := service.verifyOTP(user.PhoneNumber, code); err != nil {
+		t.Fatalf("unexpected error for correct code %s", err.Error())
+	}
+	if err := service.verifyOTP(user.PhoneNumber, code); err == nil || err.GetCode() != SEAuthFailed {
+		t.Errorf("expected error code %d for used code, got %v", SEAuthFailed, err)
+	}
+}
+
+func TestPhoneOTPAuditWithoutPhone(t *testing.T) {
+	user := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000001"}
+	service, _, _ := newTestSessionService(user)
+	auditDAL := &memAuditDAL{}
+	service.audit = newSAuditService(auditDAL, nil, nil, service.logger)
+
+	service.RequestPhoneOTP(user.PhoneNumber, models.ClientInfo{})
+	service.RequestPhoneOTP("9179999999", models.ClientInfo{})
+	service.CreateSessionByPhoneOTP(&models.PhoneBasedLoginInfo{PhoneNumber: user.PhoneNumber, Code: "wrong"},
+		models.ClientInfo{})
+
+	if len(auditDAL.events) != 3 {
+		t.Fatalf("expected 3 audit events, got %d", len(auditDAL.events))
+	}
+	for _, event := range auditDAL.events {
+		if strings.Contains(event.Details, "917") {
+			t.Errorf("expected audit event without phone number, got %+v", event)
+		}
+	}
+	if auditDAL.events[0].ActorUserID != user.ID || auditDAL.events[0].Outcome != models.AuditSuccess {
+		t.Errorf("expected successful audit event of user %s, got %+v", user.ID.String(), auditDAL.events[0])
+	}
+}
+
+func (d *memSessionDAL) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	if token, ok := d.tokens[tokenHash]; ok {
+		copied := *token
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (d *memSessionDAL) GetSessionByID(sessionID models.ID) (*models.Session, error) {
+	if session, ok := d.sessions[sessionID]; ok {
+		return &session, nil
+	}
+	return nil, nil
+}
+
+func (d *memSessionDAL) RotateRefreshToken(tokenID models.ID, newTokenHash string, session *models.Session) (bool, error) {
+	for _, token := range d.tokens {
+		if token.ID != tokenID {
+			continue
+		}
+		if token.UsedAt != 0 {
+			return false, nil
+		}
+		token.UsedAt = time.Now().Unix()
+		d.tokens[newTokenHash] = &models.RefreshToken{ID: models.ID(uuid.New()), SessionID: session.ID,
+			ExpiredAt: session.ExpiredAt}
+		d.sessions[session.ID] = *session
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *memSessionDAL) DeleteSession(sessionID models.ID) (bool, error) {
+	_, ok := d.sessions[sessionID]
+	delete(d.sessions, sessionID)
+	for hash, token := range d.tokens {
+		if token.SessionID == sessionID {
+			delete(d.tokens, hash)
+		}
+	}
+	return ok, nil
+}
+
+// Return a session service with a session of each user and the refresh tokens of the
+// sessions.
+func newRefreshTestService(t *testing.T, users ...models.User) (*sSessionService, *memSessionDAL, map[models.ID]string) {
+	t.Helper()
+	service, _, _ := newTestSessionService(users...)
+	dir := t.TempDir()
+	kid, err := jwtkeys.GenerateKeyPair(dir)
+	if err != nil {
+		t.Fatalf("failed to generate key pair: %s", err.Error())
+	}
+	if service.keys, err = jwtkeys.LoadKeyRing(dir, kid); err != nil {
+		t.Fatalf("failed to load key ring: %s", err.Error())
+	}
+	service.accessTokenExpire = time.Minute
+	service.refreshTokenExpire = time.Hour
+
+	sessionDAL := &memSessionDAL{sessions: map[models.ID]models.Session{}, tokens: map[string]*models.RefreshToken{}}
+	refreshTokens := map[models.ID]string{}
+	expiredAt := time.Now().Add(time.Hour).Unix()
+	for _, user := range users {
+		session := models.Session{ID: models.ID(uuid.New()), UserID: user.ID, ExpiredAt: expiredAt}
+		refreshToken, tokenHash, err := generateRefreshToken()
+		if err != nil {
+			t.Fatalf("failed to generate refresh token: %s", err.Error())
+		}
+		sessionDAL.sessions[session.ID] = session
+		sessionDAL.tokens[tokenHash] = &models.RefreshToken{ID: models.ID(uuid.New()), SessionID: session.ID,
+			ExpiredAt: expiredAt}
+		refreshTokens[user.ID] = refreshToken
+	}
+	service.session = sessionDAL
+	return service, sessionDAL, refreshTokens
+}
+
+func TestRefreshSession(t *testing.T) {
+	enabled := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000001"}
+	disabled := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000002", IsDisabled: models.IsDisabled}
+
+	t.Run("refresh token is rotated", func(t *testing.T) {
+		service, sessionDAL, refreshTokens := newRefreshTestService(t, enabled)
+		tokens, err := service.RefreshSession(refreshTokens[enabled.ID], models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.RefreshToken == refreshTokens[enabled.ID] {
+			t.Errorf("expected new access and refresh tokens, got %+v", tokens)
+		}
+		if tokens.AccessTokenExpiredAt > tokens.RefreshTokenExpiredAt {
+			t.Errorf("expected access token not to outlive its session, got %+v", tokens)
+		}
+		if _, err := service.RefreshSession(tokens.RefreshToken, models.ClientInfo{}); err != nil {
+			t.Errorf("unexpected error for the new refresh token %s", err.Error())
+		}
+		if len(sessionDAL.sessions) != 1 {
+			t.Errorf("expected session to remain, got %d sessions", len(sessionDAL.sessions))
+		}
+	})
+
+	t.Run("reused refresh token revokes the session", func(t *testing.T) {
+		service, sessionDAL, refreshTokens := newRefreshTestService(t, enabled)
+		tokens, err := service.RefreshSession(refreshTokens[enabled.ID], models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if _, err := service.RefreshSession(refreshTokens[enabled.ID], models.ClientInfo{}); err == nil ||
+			err.GetCode() != SEAuthFailed {
+			t.Fatalf("expected error code %d for reused token, got %v", SEAuthFailed, err)
+		}
+		if len(sessionDAL.sessions) != 0 {
+			t.Errorf("expected session to be revoked")
+		}
+		if _, err := service.RefreshSession(tokens.RefreshToken, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SEAuthFailed {
+			t.Errorf("expected error code %d for token of the revoked session, got %v", SEAuthFailed, err)
+		}
+	})
+
+	t.Run("session of disabled user is not refreshed", func(t *testing.T) {
+		service, sessionDAL, refreshTokens := newRefreshTestService(t, disabled)
+		if _, err := service.RefreshSession(refreshTokens[disabled.ID], models.ClientInfo{}); err == nil ||
+			err.GetCode() != SEIsDisabled {
+			t.Fatalf("expected error code %d, got %v", SEIsDisabled, err)
+		}
+		for _, token := range sessionDAL.tokens {
+			if token.UsedAt != 0 {
+				t.Errorf("expected refresh token not to be used")
+			}
+		}
+	})
+
+	t.Run("unknown refresh token", func(t *testing.T) {
+		service, _, _ := newRefreshTestService(t, enabled)
+		if _, err := service.RefreshSession("unknown", models.ClientInfo{}); err == nil || err.GetCode() != SEAuthFailed {
+			t.Errorf("expected error code %d, got %v", SEAuthFailed, err)
+		}
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	owner := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000001"}
+	other := models.User{ID: models.ID(uuid.New()), PhoneNumber: "9170000002"}
+	// Return the session of the user and its access token.
+	accessToken := func(t *testing.T, service *sSessionService, sessionDAL *memSessionDAL, userID models.ID) (*models.JWT, models.Token) {
+		t.Helper()
+		for _, session := range sessionDAL.sessions {
+			if session.UserID != userID {
+				continue
+			}
+			jwt := &models.JWT{JTI: session.ID, UserID: userID, IAT: time.Now().Unix(), EXP: time.Now().Add(time.Minute).Unix()}
+			token, err := service.generateJWT(jwt)
+			if err != nil {
+				t.Fatalf("failed to generate JWT: %s", err.Error())
+			}
+			return jwt, models.Token(token)
+		}
+		t.Fatalf("session of user %s not found", userID.String())
+		return nil, ""
+	}
+
+	t.Run("revoked session fails on the next request", func(t *testing.T) {
+		service, sessionDAL, _ := newRefreshTestService(t, owner)
+		jwt, token := accessToken(t, service, sessionDAL, owner.ID)
+		if _, err := service.ValidateSessionJWT(token); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if err := service.RevokeSession(jwt, jwt.JTI, models.ClientInfo{}); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if _, err := service.ValidateSessionJWT(token); err == nil || err.GetCode() != SENotFound {
+			t.Errorf("expected error code %d for the revoked session, got %v", SENotFound, err)
+		}
+	})
+
+	t.Run("session of another user is not revoked", func(t *testing.T) {
+		service, sessionDAL, _ := newRefreshTestService(t, owner, other)
+		jwt, _ := accessToken(t, service, sessionDAL, owner.ID)
+		otherJWT, otherToken := accessToken(t, service, sessionDAL, other.ID)
+		if err := service.RevokeSession(jwt, otherJWT.JTI, models.ClientInfo{}); err == nil || err.GetCode() != SENotFound {
+			t.Errorf("expected error code %d, got %v", SENotFound, err)
+		}
+		if _, err := service.ValidateSessionJWT(otherToken); err != nil {
+			t.Errorf("expected the session of the other user to be kept, got %s", err.Error())
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"DMS/internal/config"
+	m "DMS/internal/models"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Rules of uploading multimedia files. All sizes are in Kbytes.
+type uploadPolicy struct {
+	// Allowed extensions (lower case and without dot) and the media type of each of them
+	extensions map[m.FileExtension]m.MediaType
+	// Maximum size of each file of the media type
+	maxSizes map[m.MediaType]uint64
+	// Maximum number of files could be uploaded in a request. Zero means unlimited.
+	maxFiles uint
+	// Maximum total size of the files of an event. Zero means unlimited.
+	eventQuota uint64
+	// Maximum total size of the files uploaded by a job position. Zero means unlimited.
+	jpQuota uint64
+}
+
+func newUploadPolicy(cfg *config.UploadConfig) *uploadPolicy {
+	policy := &uploadPolicy{
+		extensions: make(map[m.FileExtension]m.MediaType),
+		maxSizes: map[m.MediaType]uint64{
+			m.MediaImage: cfg.ImageMaxSizeKB,
+			m.MediaVideo: cfg.VideoMaxSizeKB,
+			m.MediaAudio: cfg.AudioMaxSizeKB,
+		},
+		maxFiles:   cfg.MaxFiles,
+		eventQuota: cfg.EventQuotaKB,
+		jpQuota:    cfg.JPQuotaKB,
+	}
+	extensions := map[m.MediaType][]string{
+		m.MediaImage: cfg.ImageExtensions,
+		m.MediaVideo: cfg.VideoExtensions,
+		m.MediaAudio: cfg.AudioExtensions,
+	}
+	for mediaType, exts := range extensions {
+		for _, ext := range exts {
+			policy.extensions[normalizeExtension(m.FileExtension(ext))] = mediaType
+		}
+	}
+	return policy
+}
+
+// Return the limits of uploading the requested files. The key of objectTypes is the
+// extension and its value is the number of files with that extension. usedEventKB and
+// usedJPKB are the total size of the files of the event and the files uploaded by the job
+// position. The result is sorted by the extension.
+func (p *uploadPolicy) allowTypes(objectTypes map[m.FileExtension]uint, usedEventKB, usedJPKB uint64) []allowType {
+	var filesCount uint
+	exts := make([]m.FileExtension, 0, len(objectTypes))
+	for ext, count := range objectTypes {
+		filesCount += count
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool { return exts[i] < exts[j] })
+
+	// The remaining quota is shared by all requested files.
+	remaining, isLimited := p.remainingQuota(usedEventKB, usedJPKB)
+	allowTypes := make([]allowType, 0, len(exts))
+	for _, ext := range exts {
+		t := allowType{FileType: ext}
+		mediaType, isKnown := p.extensions[normalizeExtension(ext)]
+		switch {
+		case p.maxFiles > 0 && filesCount > p.maxFiles:
+			t.Reason = fmt.Sprintf("at most %d files could be uploaded at once", p.maxFiles)
+		case !isKnown:
+			t.Reason = fmt.Sprintf("extension %s is not allowed", ext)
+		case objectTypes[ext] == 0:
+			t.Reason = "number of files is zero"
+		case isLimited && remaining/uint64(filesCount) == 0:
+			t.Reason = "storage quota is exceeded"
+		default:
+			t.IsAllow = true
+			t.MaxSize = p.maxSizes[mediaType]
+			if isLimited {
+				t.MaxSize = min(t.MaxSize, remaining/uint64(filesCount))
+			}
+		}
+		allowTypes = append(allowTypes, t)
+	}
+	return allowTypes
+}
+
+// Return the storage that is left of the quotas of the event and the job position. If
+// none of the quotas is limited, the second returned value is false.
+func (p *uploadPolicy) remainingQuota(usedEventKB, usedJPKB uint64) (uint64, bool) {
+	var remaining uint64
+	isLimited := false
+	for _, quota := range [][2]uint64{{p.eventQuota, usedEventKB}, {p.jpQuota, usedJPKB}} {
+		if quota[0] == 0 {
+			continue
+		}
+		var left uint64
+		if quota[1] < quota[0] {
+			left = quota[0] - quota[1]
+		}
+		if !isLimited || left < remaining {
+			remaining = left
+		}
+		isLimited = true
+	}
+	return remaining, isLimited
+}
+
+// Check the file name has an allowed extension that matches the media type and its size
+// doesn't exceed the limit of the media type. If the MIME type is set, it must match the
+// media type too.
+func (p *uploadPolicy) checkMedia(media *m.MediaPath) error {
+	if !media.Type.IsValid() {
+		return fmt.Errorf("media type %d of file %s is not valid", media.Type, media.FileName)
+	}
+	ext := normalizeExtension(m.FileExtension(filepath.Ext(media.FileName)))
+	if mediaType, ok := p.extensions[ext]; !ok {
+		return fmt.Errorf("extension of file %s is not allowed", media.FileName)
+	} else if mediaType != media.Type {
+		return fmt.Errorf("extension of file %s doesn't match media type %d", media.FileName, media.Type)
+	}
+	if media.Size > p.maxSizes[media.Type] {
+		return fmt.Errorf("size of file %s is %d KB but at most %d KB is allowed", media.FileName,
+			media.Size, p.maxSizes[media.Type])
+	}
+	if media.MimeType != "" && !strings.HasPrefix(media.MimeType, mimePrefixes[media.Type]) {
+		return fmt.Errorf("MIME type %s of file %s doesn't match media type %d", media.MimeType,
+			media.FileName, media.Type)
+	}
+	return nil
+}
+
+// Return the media type of the file according to its extension. If the extension is not
+// allowed, the second returned value is false.
+func (p *uploadPolicy) mediaType(fileName string) (m.MediaType, bool) {
+	mediaType, ok := p.extensions[normalizeExtension(m.FileExtension(filepath.Ext(fileName)))]
+	return mediaType, ok
+}
+
+// Prefix of MIME types of each media type
+var mimePrefixes = map[m.MediaType]string{
+	m.MediaImage: "image/",
+	m.MediaVideo: "video/",
+	m.MediaAudio: "audio/",
+}
+
+func normalizeExtension(ext m.FileExtension) m.FileExtension {
+	return m.FileExtension(strings.ToLower(strings.TrimPrefix(string(ext), ".")))
+}
@@ -7,51 +7,120 @@ import (
 	m "DMS/internal/models"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type UserService interface {
 	// Create a user. If the creator job position be allowed and
 	// not being disabled. At the end, return the id of the created user.
+	// createdBy is the user creates the new user and callerJPID is one of its job
+	// positions that must be allowed to create users.
 	//
 	// Possible error codes the function could returns:
-	// IsDisabled-UserExists- DBError
+	// IsDisabled-UserExists- DBError- SEJPNotMatchedUser- SENotPermission
 	//
 	// Note that users are persons that must always have created by another user.
-	CreateUser(name string, phone m.PhoneNumber, CreatedBy m.ID) (*m.ID, *e.Error)
+	CreateUser(name string, phone m.PhoneNumber, createdBy, callerJPID m.ID, client m.ClientInfo) (*m.ID, *e.Error)
 	// Get specified user details.
 	// TODO: Add the feature that just job-positions who have permission could read user details.
 	//
 	// Possible error codes the function could returns:
 	// DBError- SENotFound
 	GetUserByID(id m.ID) (*m.User, *e.Error)
-	// Create an andmin. Return id of created admin.
+	// Create an andmin. Return id of created admin. callerJPID is the job position of
+	// the user userID and must be an admin job position.
 	// Note that admins are persons that don't have created by anyperson.
 	//
 	// Possible error codes the function could returns:
-	// IsDisabled-UserExists- DBError
-	CreateAdmin(name string, phone m.PhoneNumber) (*m.ID, *e.Error)
+	// UserExists- DBError- SEJPNotMatchedUser- SENotPermission
+	CreateAdmin(userID, callerJPID m.ID, name string, phone m.PhoneNumber, client m.ClientInfo) (*m.ID, *e.Error)
+	// Update name or phone number of the user targetUserID. callerJPID is the job position
+	// of the user who does the action and must be allowed to manage users. Non-admin job
+	// positions could just manage users created by users of their subtree.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound- SEExists- SEWrongParameter
+	UpdateUser(userID, callerJPID, targetUserID m.ID, update *m.UserUpdate, client m.ClientInfo) *e.Error
+	// Disable the user targetUserID and revoke all of its sessions, so it can't login or
+	// use the APIs anymore. Access of the caller is the same as UpdateUser. Users can't
+	// disable themselves.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound- SESelfAction
+	DisableUser(userID, callerJPID, targetUserID m.ID, client m.ClientInfo) *e.Error
+	// Enable the disabled user targetUserID. Access of the caller is the same as UpdateUser.
+	// Users can't enable themselves.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound- SESelfAction
+	EnableUser(userID, callerJPID, targetUserID m.ID, client m.ClientInfo) *e.Error
+	// Revoke all sessions of the user targetUserID and return number of revoked sessions.
+	// The caller must be allowed to manage sessions and the same as UpdateUser, non-admin
+	// job positions could just revoke sessions of users created by users of their subtree.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser- SENotPermission- SENotFound
+	RevokeUserSessions(userID, callerJPID, targetUserID m.ID, client m.ClientInfo) (int64, *e.Error)
+	// Return some last created users (at most limit users) that come after the cursor,
+	// together with the cursor of the last returned user. Just users created by users of
+	// the job position or its nested childs are returned, unless the job position be admin.
+	//
+	// Possible error codes:
+	// SEDBError- SEJPNotMatchedUser
+	GetUsers(userID, callerJPID m.ID, cursor *m.Cursor, limit uint64) (*[]m.User, *m.Cursor, *e.Error)
 }
 
 // It's a simple implementation of UserService interface.
 // This implementation has minimum functionalities.
 type sUserService struct {
-	user   dal.UserDAL
-	jp     dal.JPDAL
-	logger l.Logger
+	user          dal.UserDAL
+	jp            dal.JPDAL
+	session       dal.SessionDAL
+	authorization AuthorizationService
+	audit         AuditService
+	logger        l.Logger
 }
 
 // Create an admin and return the admin id. If couldn't create admin, return error.
 // In this implemented method, each admin could create user
 // and doesn't matter the user is allow or not.
 // Note that admins are persons that don't have created by anyperson.
-func (s *sUserService) CreateAdmin(name string, phone m.PhoneNumber) (*m.ID, *e.Error) {
+func (s *sUserService) CreateAdmin(userID, callerJPID m.ID, name string, phone m.PhoneNumber,
+	client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.createAdmin(userID, callerJPID, name, phone)
+	s.audit.Record(newAuditEvent(m.AuditAdminCreate, userID, callerJPID, m.AuditTargetUser, idOrNil(id), client), err)
+	return id, err
+}
+
+func (s *sUserService) createAdmin(userID, callerJPID m.ID, name string, phone m.PhoneNumber) (*m.ID, *e.Error) {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return nil, err
+	}
+	if isAdmin, err := s.authorization.IsAdminJP(callerJPID); err != nil {
+		return nil, err
+	} else if !isAdmin {
+		return nil, e.NewErrorP("job position %s is not admin", SENotPermission, callerJPID.String())
+	}
 	return s.createPerson(name, phone, nil, true)
 }
 
-// Create a user and return the user id. If couldn't create user, return error. In
-// this implemented method, each user could create user
-// and doesn't matter the user is allow or not.
-func (s *sUserService) CreateUser(name string, phone m.PhoneNumber, createdBy m.ID) (*m.ID, *e.Error) {
+// Create a user and return the user id. If couldn't create user, return error.
+func (s *sUserService) CreateUser(name string, phone m.PhoneNumber, createdBy, callerJPID m.ID, client m.ClientInfo) (*m.ID, *e.Error) {
+	id, err := s.createUser(name, phone, createdBy, callerJPID)
+	s.audit.Record(newAuditEvent(m.AuditUserCreate, createdBy, callerJPID, m.AuditTargetUser, idOrNil(id), client), err)
+	return id, err
+}
+
+func (s *sUserService) createUser(name string, phone m.PhoneNumber, createdBy, callerJPID m.ID) (*m.ID, *e.Error) {
+	if err := s.checkCallerJP(createdBy, callerJPID); err != nil {
+		return nil, err
+	}
+	if can, err := s.authorization.Can(callerJPID, m.ActionCreateUser, m.NilID); err != nil {
+		return nil, err
+	} else if !can {
+		return nil, e.NewErrorP("job position %s is not allowed to create users", SENotPermission,
+			callerJPID.String())
+	}
 	return s.createPerson(name, phone, &createdBy, false)
 }
 
@@ -101,11 +170,174 @@ func (s *sUserService) GetUserByID(id m.ID) (*m.User, *e.Error) {
 	return user, nil
 }
 
+func (s *sUserService) UpdateUser(userID, callerJPID, targetUserID m.ID, update *m.UserUpdate, client m.ClientInfo) *e.Error {
+	err := s.updateUser(userID, callerJPID, targetUserID, update)
+	s.audit.Record(newAuditEvent(m.AuditUserUpdate, userID, callerJPID, m.AuditTargetUser, targetUserID, client), err)
+	return err
+}
+
+func (s *sUserService) updateUser(userID, callerJPID, targetUserID m.ID, update *m.UserUpdate) *e.Error {
+	if err := s.checkManageAccess(userID, callerJPID, targetUserID, m.ActionManageUsers); err != nil {
+		return err
+	}
+	if update.Name != nil && strings.TrimSpace(*update.Name) == "" {
+		return e.NewErrorP("name of user %s can't be empty", SEWrongParameter, targetUserID.String())
+	}
+	if update.PhoneNumber != nil {
+		if update.PhoneNumber.IsNil() {
+			return e.NewErrorP("phone number of user %s can't be empty", SEWrongParameter, targetUserID.String())
+		}
+		user, err := s.user.GetUserByPhone(*update.PhoneNumber)
+		if err != nil {
+			return e.NewErrorP(err.Error(), SEDBError)
+		} else if user != nil && user.ID != targetUserID {
+			return e.NewErrorP("another user with phone %s exists", SEExists, update.PhoneNumber.ToString())
+		}
+	}
+
+	// Another user could take the phone number after the check.
+	isFound, err := s.user.UpdateUser(targetUserID, update)
+	if errors.Is(err, e.ErrExists) {
+		return e.NewErrorP("another user with phone %s exists: %s", SEExists, update.PhoneNumber.ToString(), err.Error())
+	} else if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isFound {
+		return e.NewErrorP("user with id %s not found", SENotFound, targetUserID.String())
+	}
+	return nil
+}
+
+func (s *sUserService) DisableUser(userID, callerJPID, targetUserID m.ID, client m.ClientInfo) *e.Error {
+	revokedCount, err := s.disableUser(userID, callerJPID, targetUserID)
+	event := newAuditEvent(m.AuditUserDisable, userID, callerJPID, m.AuditTargetUser, targetUserID, client)
+	if err == nil {
+		event.Details = fmt.Sprintf("%d sessions are revoked", revokedCount)
+	}
+	s.audit.Record(event, err)
+	return err
+}
+
+// Disable the user and return number of its revoked sessions.
+func (s *sUserService) disableUser(userID, callerJPID, targetUserID m.ID) (int64, *e.Error) {
+	if err := s.setUserDisability(userID, callerJPID, targetUserID, true); err != nil {
+		return 0, err
+	}
+	revokedCount, err := s.session.DeleteUserSessions(targetUserID, m.NilID)
+	if err != nil {
+		return 0, e.NewErrorP("user %s is disabled but failed to revoke its sessions: %s", SEDBError,
+			targetUserID.String(), err.Error())
+	}
+	return revokedCount, nil
+}
+
+func (s *sUserService) EnableUser(userID, callerJPID, targetUserID m.ID, client m.ClientInfo) *e.Error {
+	err := s.setUserDisability(userID, callerJPID, targetUserID, false)
+	s.audit.Record(newAuditEvent(m.AuditUserEnable, userID, callerJPID, m.AuditTargetUser, targetUserID, client), err)
+	return err
+}
+
+func (s *sUserService) setUserDisability(userID, callerJPID, targetUserID m.ID, isDisabled bool) *e.Error {
+	if userID == targetUserID {
+		return e.NewErrorP("user %s can't disable or enable itself", SESelfAction, userID.String())
+	}
+	if err := s.checkManageAccess(userID, callerJPID, targetUserID, m.ActionManageUsers); err != nil {
+		return err
+	}
+	isFound, err := s.user.SetUserDisability(targetUserID, isDisabled)
+	if err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isFound {
+		return e.NewErrorP("user with id %s not found", SENotFound, targetUserID.String())
+	}
+	return nil
+}
+
+func (s *sUserService) RevokeUserSessions(userID, callerJPID, targetUserID m.ID, client m.ClientInfo) (int64, *e.Error) {
+	revokedCount, err := s.revokeUserSessions(userID, callerJPID, targetUserID)
+	event := newAuditEvent(m.AuditSessionsRevoke, userID, callerJPID, m.AuditTargetUser, targetUserID, client)
+	if err == nil {
+		event.Details = fmt.Sprintf("%d sessions are revoked", revokedCount)
+	}
+	s.audit.Record(event, err)
+	return revokedCount, err
+}
+
+func (s *sUserService) revokeUserSessions(userID, callerJPID, targetUserID m.ID) (int64, *e.Error) {
+	if err := s.checkManageAccess(userID, callerJPID, targetUserID, m.ActionManageSessions); err != nil {
+		return 0, err
+	}
+	revokedCount, err := s.session.DeleteUserSessions(targetUserID, m.NilID)
+	if err != nil {
+		return 0, e.NewErrorP(err.Error(), SEDBError)
+	}
+	return revokedCount, nil
+}
+
+func (s *sUserService) GetUsers(userID, callerJPID m.ID, cursor *m.Cursor, limit uint64) (*[]m.User, *m.Cursor, *e.Error) {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return nil, nil, err
+	}
+	jpIDs, err := s.authorization.GetAccessibleJPs(callerJPID)
+	if err != nil {
+		return nil, nil, err.SetCode(SEDBError)
+	}
+	users, nextCursor, err2 := s.user.GetUsers(jpIDs, cursor, int(limit))
+	if err2 != nil {
+		return nil, nil, e.NewErrorP(err2.Error(), SEDBError)
+	}
+	return users, nextCursor, nil
+}
+
+// Check the caller job position belongs to the user and it could do the action on the user
+// targetUserID. Means it's allowed to do the action and it's admin or the target user is
+// created by a user of its subtree. Users out of the subtree are reported as not found.
+func (s *sUserService) checkManageAccess(userID, callerJPID, targetUserID m.ID, action m.Action) *e.Error {
+	if err := s.checkCallerJP(userID, callerJPID); err != nil {
+		return err
+	}
+	if can, err := s.authorization.Can(callerJPID, action, m.NilID); err != nil {
+		return err
+	} else if !can {
+		return e.NewErrorP("job position %s is not allowed to do %s", SENotPermission,
+			callerJPID.String(), action)
+	}
+
+	jpIDs, err := s.authorization.GetAccessibleJPs(callerJPID)
+	if err != nil {
+		return err.SetCode(SEDBError)
+	} else if jpIDs == nil {
+		return nil
+	}
+	isCreated, err2 := s.user.IsCreatedByJPsUsers(targetUserID, *jpIDs)
+	if err2 != nil {
+		return e.NewErrorP(err2.Error(), SEDBError)
+	} else if !isCreated {
+		return e.NewErrorP("user %s not found in subtree of job position %s", SENotFound,
+			targetUserID.String(), callerJPID.String())
+	}
+	return nil
+}
+
+// Check the caller job position belongs to the user.
+func (s *sUserService) checkCallerJP(userID, callerJPID m.ID) *e.Error {
+	if isExists, err := s.jp.IsExistsUserWithJP(userID, callerJPID); err != nil {
+		return e.NewErrorP(err.Error(), SEDBError)
+	} else if !isExists {
+		return e.NewErrorP("job position %s doesn't belong to user %s", SEJPNotMatchedUser,
+			callerJPID.String(), userID.String())
+	}
+	return nil
+}
+
 // Create an instance of sUserService struct
-func newSUserService(user dal.UserDAL, jp dal.JPDAL, logger l.Logger) UserService {
+func newSUserService(user dal.UserDAL, jp dal.JPDAL, session dal.SessionDAL, authorization AuthorizationService,
+	audit AuditService, logger l.Logger) UserService {
 	return &sUserService{
 		user,
 		jp,
+		session,
+		authorization,
+		audit,
 		logger,
 	}
 }
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"DMS/internal/dal"
+	e "DMS/internal/error"
+	l "DMS/internal/logger"
+	"DMS/internal/models"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func (d *memUserDAL) IsCreatedByJPsUsers(userID models.ID, jpIDs []models.ID) (bool, error) {
+	for _, user := range d.users {
+		if user.ID != userID || user.CreatedBy == nil {
+			continue
+		}
+		for _, jpID := range jpIDs {
+			if d.jpUsers[jpID] == *user.CreatedBy {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (d *memUserDAL) SetUserDisability(userID models.ID, isDisabled bool) (bool, error) {
+	for i := range d.users {
+		if d.users[i].ID == userID {
+			d.users[i].IsDisabled = models.IsNotDisabled
+			if isDisabled {
+				d.users[i].IsDisabled = models.IsDisabled
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Phone numbers of the other users are checked by the database too, so they're rejected
+// even if they're taken after GetUserByPhone.
+func (d *memUserDAL) UpdateUser(userID models.ID, update *models.UserUpdate) (bool, error) {
+	index := -1
+	for i := range d.users {
+		if d.users[i].ID == userID {
+			index = i
+		} else if update.PhoneNumber != nil && d.users[i].PhoneNumber == *update.PhoneNumber {
+			return false, fmt.Errorf("failed to update user %s: %w", userID.String(), e.ErrExists)
+		}
+	}
+	if index < 0 {
+		return false, nil
+	}
+	if update.Name != nil {
+		d.users[index].Name = *update.Name
+	}
+	if update.PhoneNumber != nil {
+		d.users[index].PhoneNumber = *update.PhoneNumber
+	}
+	return true, nil
+}
+
+// It just knows the users of the job positions.
+type memJPDAL struct {
+	dal.JPDAL
+	jpUsers map[models.ID]models.ID
+}
+
+func (d *memJPDAL) IsExistsUserWithJP(userID, jpID models.ID) (bool, error) {
+	owner, ok := d.jpUsers[jpID]
+	return ok && owner == userID, nil
+}
+
+// Each user has two sessions that are revoked by deleting them. Sessions and refresh
+// tokens (by their hashes) are kept for refreshing the sessions.
+type memSessionDAL struct {
+	dal.SessionDAL
+	revokedUsers []models.ID
+	sessions     map[models.ID]models.Session
+	tokens       map[string]*models.RefreshToken
+}
+
+func (d *memSessionDAL) DeleteUserSessions(userID, exceptSessionID models.ID) (int64, error) {
+	d.revokedUsers = append(d.revokedUsers, userID)
+	return 2, nil
+}
+
+// Job positions are allowed to do the given actions and have access to the given
+// subtrees. Job positions without subtree are admins.
+type subtreeAuthorization struct {
+	AuthorizationService
+	actions  map[models.ID][]models.Action
+	subtrees map[models.ID][]models.ID
+}
+
+func (a *subtreeAuthorization) Can(jpID models.ID, action models.Action, resource models.ID) (bool, *e.Error) {
+	for _, allowed := range a.actions[jpID] {
+		if allowed == action {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *subtreeAuthorization) GetAccessibleJPs(jpID models.ID) (*[]models.ID, *e.Error) {
+	if subtree, ok := a.subtrees[jpID]; ok {
+		return &subtree, nil
+	}
+	return nil, nil
+}
+
+// Users and job positions of the user management tests. The manager has access to its
+// child, the admin has access to all job positions and the member is not allowed to
+// manage users.
+type userManagementFixture struct {
+	admin, manager, child, member models.ID
+	adminJP, managerJP, childJP   models.ID
+	memberJP                      models.ID
+	// Users created by the child and the admin
+	childCreated, adminCreated models.ID
+}
+
+func newUserManagementFixture() *userManagementFixture {
+	f := &userManagementFixture{}
+	for _, id := range []*models.ID{&f.admin, &f.manager, &f.child, &f.member, &f.adminJP, &f.managerJP,
+		&f.childJP, &f.memberJP, &f.childCreated, &f.adminCreated} {
+		*id = models.ID(uuid.New())
+	}
+	return f
+}
+
+func (f *userManagementFixture) newService() (*sUserService, *memUserDAL, *memSessionDAL, *memAuditDAL) {
+	jpUsers := map[models.ID]models.ID{f.adminJP: f.admin, f.managerJP: f.manager, f.childJP: f.child,
+		f.memberJP: f.member}
+	userDAL := &memUserDAL{jpUsers: jpUsers, users: []models.User{
+		{ID: f.admin, PhoneNumber: "9170000001"},
+		{ID: f.manager, PhoneNumber: "9170000002", CreatedBy: &f.admin},
+		{ID: f.child, PhoneNumber: "9170000003", CreatedBy: &f.manager},
+		{ID: f.member, PhoneNumber: "9170000004", CreatedBy: &f.admin},
+		{ID: f.childCreated, PhoneNumber: "9170000005", CreatedBy: &f.child},
+		{ID: f.adminCreated, PhoneNumber: "9170000006", CreatedBy: &f.admin},
+	}}
+	authorization := &subtreeAuthorization{
+		actions: map[models.ID][]models.Action{
+			f.adminJP:   {models.ActionManageUsers, models.ActionManageSessions},
+			f.managerJP: {models.ActionManageUsers},
+		},
+		subtrees: map[models.ID][]models.ID{
+			f.managerJP: {f.managerJP, f.childJP},
+			f.childJP:   {f.childJP},
+			f.memberJP:  {f.memberJP},
+		},
+	}
+	sessionDAL := &memSessionDAL{}
+	auditDAL := &memAuditDAL{}
+	logger := l.NewSLogger(l.None, nil, io.Discard)
+	service := newSUserService(userDAL, &memJPDAL{jpUsers: jpUsers}, sessionDAL, authorization,
+		newSAuditService(auditDAL, nil, nil, logger), logger).(*sUserService)
+	return service, userDAL, sessionDAL, auditDAL
+}
+
+func TestCheckManageAccess(t *testing.T) {
+	f := newUserManagementFixture()
+	tests := []struct {
+		name     string
+		user, jp models.ID
+		target   models.ID
+		action   models.Action
+		// Expected error code. If it's nil, the access must be granted.
+		errCode any
+	}{
+		{name: "user created in the subtree", user: f.manager, jp: f.managerJP, target: f.childCreated,
+			action: models.ActionManageUsers},
+		{name: "user created by the child itself", user: f.manager, jp: f.managerJP, target: f.child,
+			action: models.ActionManageUsers},
+		{name: "user created out of the subtree is not found", user: f.manager, jp: f.managerJP,
+			target: f.adminCreated, action: models.ActionManageUsers, errCode: SENotFound},
+		{name: "admin manages all users", user: f.admin, jp: f.adminJP, target: f.childCreated,
+			action: models.ActionManageUsers},
+		{name: "action not allowed", user: f.manager, jp: f.managerJP, target: f.childCreated,
+			action: models.ActionManageSessions, errCode: SENotPermission},
+		{name: "member is not allowed", user: f.member, jp: f.memberJP, target: f.adminCreated,
+			action: models.ActionManageUsers, errCode: SENotPermission},
+		{name: "job position of another user", user: f.member, jp: f.managerJP, target: f.childCreated,
+			action: models.ActionManageUsers, errCode: SEJPNotMatchedUser},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, _, _, _ := f.newService()
+			err := service.checkManageAccess(test.user, test.jp, test.target, test.action)
+			if test.errCode == nil {
+				if err != nil {
+					t.Errorf("unexpected error %s", err.Error())
+				}
+			} else if err == nil {
+				t.Errorf("expected error code %v, got nil", test.errCode)
+			} else if err.GetCode() != test.errCode {
+				t.Errorf("expected error code %v, got %v (%s)", test.errCode, err.GetCode(), err.Error())
+			}
+		})
+	}
+}
+
+func TestDisableEnableUser(t *testing.T) {
+	f := newUserManagementFixture()
+	isDisabled := func(userDAL *memUserDAL, userID models.ID) bool {
+		for _, user := range userDAL.users {
+			if user.ID == userID {
+				return user.IsDisabled == models.IsDisabled
+			}
+		}
+		return false
+	}
+
+	t.Run("disabling revokes the sessions and enabling reverts it", func(t *testing.T) {
+		service, userDAL, sessionDAL, auditDAL := f.newService()
+		if err := service.DisableUser(f.manager, f.managerJP, f.childCreated, models.ClientInfo{}); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if !isDisabled(userDAL, f.childCreated) {
+			t.Errorf("expected user to be disabled")
+		}
+		if len(sessionDAL.revokedUsers) != 1 || sessionDAL.revokedUsers[0] != f.childCreated {
+			t.Errorf("expected sessions of the user to be revoked, got %v", sessionDAL.revokedUsers)
+		}
+		if len(auditDAL.events) != 1 || auditDAL.events[0].Details != "2 sessions are revoked" {
+			t.Errorf("expected audit event with the revoked sessions, got %+v", auditDAL.events)
+		}
+
+		if err := service.EnableUser(f.manager, f.managerJP, f.childCreated, models.ClientInfo{}); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if isDisabled(userDAL, f.childCreated) {
+			t.Errorf("expected user to be enabled")
+		}
+	})
+
+	t.Run("users can't disable or enable themselves", func(t *testing.T) {
+		service, userDAL, _, _ := f.newService()
+		if err := service.DisableUser(f.admin, f.adminJP, f.admin, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SESelfAction {
+			t.Errorf("expected error code %d, got %v", SESelfAction, err)
+		}
+		if err := service.EnableUser(f.admin, f.adminJP, f.admin, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SESelfAction {
+			t.Errorf("expected error code %d, got %v", SESelfAction, err)
+		}
+		if isDisabled(userDAL, f.admin) {
+			t.Errorf("expected user not to be disabled")
+		}
+	})
+
+	t.Run("users out of the subtree are not disabled", func(t *testing.T) {
+		service, userDAL, sessionDAL, _ := f.newService()
+		if err := service.DisableUser(f.manager, f.managerJP, f.adminCreated, models.ClientInfo{}); err == nil ||
+			err.GetCode() != SENotFound {
+			t.Errorf("expected error code %d, got %v", SENotFound, err)
+		}
+		if isDisabled(userDAL, f.adminCreated) || len(sessionDAL.revokedUsers) != 0 {
+			t.Errorf("expected user not to be disabled")
+		}
+	})
+}
+
+// It doesn't find any user by phone number, like when the phone number is taken after
+// looking up the users.
+type racedUserDAL struct {
+	*memUserDAL
+}
+
+func (d *racedUserDAL) GetUserByPhone(phoneNumber models.PhoneNumber) (*models.User, error) {
+	return nil, nil
+}
+
+func TestUpdateUserPhone(t *testing.T) {
+	f := newUserManagementFixture()
+	service, userDAL, _, _ := f.newService()
+	takenPhone, freePhone := models.PhoneNumber("9170000001"), models.PhoneNumber("9179999999")
+
+	err := service.UpdateUser(f.manager, f.managerJP, f.childCreated,
+		&models.UserUpdate{PhoneNumber: &takenPhone}, models.ClientInfo{})
+	if err == nil || err.GetCode() != SEExists {
+		t.Errorf("expected error code %d, got %v", SEExists, err)
+	}
+
+	service.user = &racedUserDAL{userDAL}
+	err = service.UpdateUser(f.manager, f.managerJP, f.childCreated,
+		&models.UserUpdate{PhoneNumber: &takenPhone}, models.ClientInfo{})
+	if err == nil || err.GetCode() != SEExists {
+		t.Errorf("expected error code %d for phone taken concurrently, got %v", SEExists, err)
+	}
+
+	err = service.UpdateUser(f.manager, f.managerJP, f.childCreated,
+		&models.UserUpdate{PhoneNumber: &freePhone}, models.ClientInfo{})
+	if err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	f := newUserManagementFixture()
+	tests := []struct {
+		name     string
+		user, jp models.ID
+		target   models.ID
+		// Expected error code. If it's nil, the sessions must be revoked.
+		errCode any
+	}{
+		{name: "allowed to manage sessions", user: f.admin, jp: f.adminJP, target: f.childCreated},
+		{name: "not allowed to manage sessions", user: f.manager, jp: f.managerJP, target: f.childCreated,
+			errCode: SENotPermission},
+		{name: "member", user: f.member, jp: f.memberJP, target: f.adminCreated, errCode: SENotPermission},
+		{name: "job position of another user", user: f.member, jp: f.adminJP, target: f.adminCreated,
+			errCode: SEJPNotMatchedUser},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, _, sessionDAL, _ := f.newService()
+			count, err := service.RevokeUserSessions(test.user, test.jp, test.target, models.ClientInfo{})
+			if test.errCode == nil {
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				if count != 2 || len(sessionDAL.revokedUsers) != 1 || sessionDAL.revokedUsers[0] != test.target {
+					t.Errorf("expected sessions of the user to be revoked, got %d %v", count, sessionDAL.revokedUsers)
+				}
+				return
+			}
+			if err == nil || err.GetCode() != test.errCode {
+				t.Fatalf("expected error code %v, got %v", test.errCode, err)
+			}
+			if len(sessionDAL.revokedUsers) != 0 {
+				t.Errorf("expected sessions not to be revoked, got %v", sessionDAL.revokedUsers)
+			}
+		})
+	}
+}
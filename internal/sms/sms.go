@@ -0,0 +1,58 @@
+package sms
+
+import (
+	l "DMS/internal/logger"
+	m "DMS/internal/models"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Deliver text messages to phone numbers. Each SMS provider must implement it.
+type SMSSender interface {
+	// Send the message to the phone number.
+	Send(phone m.PhoneNumber, message string) error
+}
+
+// It writes messages in the logs instead of sending them. It's useful for local testing.
+type consoleSMSSender struct {
+	logger l.Logger
+}
+
+func (s *consoleSMSSender) Send(phone m.PhoneNumber, message string) error {
+	s.logger.Infof("SMS to %s: %s", phone.ToString(), message)
+	return nil
+}
+
+// Create an SMS sender that writes messages in the logs.
+func NewConsoleSMSSender(logger l.Logger) SMSSender {
+	return &consoleSMSSender{logger}
+}
+
+// It appends messages to a file instead of sending them. It's useful for local testing.
+type fileSMSSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSMSSender) Send(phone m.PhoneNumber, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open SMS file %s: %s", s.path, err.Error())
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "%s\t%s\t%s\n", time.Now().UTC().Format(time.RFC3339), phone.ToString(), message)
+	if err != nil {
+		return fmt.Errorf("failed to write SMS to file %s: %s", s.path, err.Error())
+	}
+	return nil
+}
+
+// Create an SMS sender that appends messages to the file path. Each line contains the
+// time, the phone number and the message separated by tabs.
+func NewFileSMSSender(path string) SMSSender {
+	return &fileSMSSender{path: path}
+}